@@ -4,6 +4,10 @@ nnoremap <silent><Plug>(nvim-go-analyzeview)  :<C-u>GoAnalyzeView<CR>
 " GoBuild
 nnoremap <silent><Plug>(nvim-go-build)  :<C-u>Gobuild<CR>
 
+" GoDef
+nnoremap <silent><Plug>(nvim-go-def)          :<C-u>GoDef<CR>
+nnoremap <silent><Plug>(nvim-go-def-stack-pop)  :<C-u>GoDefStackPop<CR>
+
 " GoGenerate
 nnoremap <silent><Plug>(nvim-go-generatetest)   :<C-u>GoGenerateTest<CR>
 
@@ -14,10 +18,12 @@ nnoremap <silent><Plug>(nvim-go-callstack)     :<C-u>call GoGuru('callstack')<CR
 nnoremap <silent><Plug>(nvim-go-definition)    :<C-u>call GoGuru('definition')<CR>
 nnoremap <silent><Plug>(nvim-go-describe)      :<C-u>call GoGuru('describe')<CR>
 nnoremap <silent><Plug>(nvim-go-freevars)      :<C-u>call GoGuru('freevars')<CR>
+xnoremap <silent><Plug>(nvim-go-freevars)      :GoFreeVars<CR>
 nnoremap <silent><Plug>(nvim-go-implements)    :<C-u>call GoGuru('implements')<CR>
 nnoremap <silent><Plug>(nvim-go-channelpeers)  :<C-u>call GoGuru('peers')<CR>
 nnoremap <silent><Plug>(nvim-go-pointsto)      :<C-u>call GoGuru('pointsto')<CR>
 nnoremap <silent><Plug>(nvim-go-referrers)     :<C-u>call GoGuru('referrers')<CR>
+nnoremap <silent><Plug>(nvim-go-what)          :<C-u>call GoGuru('what')<CR>
 nnoremap <silent><Plug>(nvim-go-whicherrs)     :<C-u>call GoGuru('whicherrs')<CR>
 
 " GoIferr
@@ -52,6 +58,7 @@ nnoremap <silent><Plug>(nvim-go-delve-connect)  :<C-u>DlvConnct<CR>
 
 " Set (Break|Trace)point
 nnoremap <silent><Plug>(nvim-go-delve-breakpoint)  :<C-u>DlvBreakpoint<CR>
+nnoremap <silent><Plug>(nvim-go-delve-toggle-breakpoint)  :<C-u>DlvToggleBreakpoint<CR>
 nnoremap <silent><Plug>(nvim-go-delve-tracepoint)  :<C-u>DlvTracepoint<CR>
 
 " Stepping execution (program counter)
@@ -15,25 +15,68 @@ let g:loaded_nvim_go = 1
 
 " Global
 let g:go#global#errorlisttype = get(g:, 'go#global#errorlisttype', 'locationlist')
+let g:go#global#listtype      = get(g:, 'go#global#listtype', {})
+let g:go#global#autoclose     = get(g:, 'go#global#autoclose', 1)
+let g:go#global#relative_paths = get(g:, 'go#global#relative_paths', 1)
+let g:go#global#max_height     = get(g:, 'go#global#max_height', 10)
+let g:go#global#dedup          = get(g:, 'go#global#dedup', 1)
+let g:go#global#goflags        = get(g:, 'go#global#goflags', [])
+let g:go#global#loglevel       = get(g:, 'go#global#loglevel', 'info')
+
+" Autocmd
+let g:go#autocmd#enable = get(g:, 'go#autocmd#enable', 1)
+
+" GoBench
+let g:go#bench#mem      = get(g:, 'go#bench#mem', 1)
+let g:go#bench#time     = get(g:, 'go#bench#time', '')
+let g:go#bench#baseline = get(g:, 'go#bench#baseline', '')
+let g:go#bench#pprof    = get(g:, 'go#bench#pprof', 'terminal')
 
 " GoBuild
 let g:go#build#autosave = get(g:, 'go#build#autosave', 0)
 let g:go#build#force = get(g:, 'go#build#force', 0)
 let g:go#build#flags = get(g:, 'go#build#flags', [])
+let g:go#build#run_vet = get(g:, 'go#build#run_vet', 0)
+let g:go#build#tags = get(g:, 'go#build#tags', [])
+let g:go#build#goos = get(g:, 'go#build#goos', '')
+let g:go#build#goarch = get(g:, 'go#build#goarch', '')
+
+" GoCallgraph
+let g:go#callgraph#depth  = get(g:, 'go#callgraph#depth', 3)
+let g:go#callgraph#format = get(g:, 'go#callgraph#format', 'svg')
 
 " GoCover
 let g:go#cover#flags = get(g:, 'go#cover#flags', [])
 let g:go#cover#mode  = get(g:, 'g:go#cover#mode', 'atomic')
 
+" GoDef
+let g:go#def#tool  = get(g:, 'go#def#tool', ['gopls', 'guru', 'godef'])
+let g:go#def#debug = get(g:, 'go#def#debug', 0)
+
+" GoDeps
+let g:go#deps#hide_stdlib = get(g:, 'go#deps#hide_stdlib', 0)
+
+" Delve
+let g:go#delve#breakpoint_symbol = get(g:, 'go#delve#breakpoint_symbol', '')
+let g:go#delve#pc_symbol         = get(g:, 'go#delve#pc_symbol', '')
+let g:go#delve#asm_flavor        = get(g:, 'go#delve#asm_flavor', 'gnu')
+let g:go#delve#connect_timeout   = get(g:, 'go#delve#connect_timeout', 10)
+let g:go#delve#rpc_timeout       = get(g:, 'go#delve#rpc_timeout', 30)
+let g:go#delve#backend           = get(g:, 'go#delve#backend', 'default')
+let g:go#delve#layout            = get(g:, 'go#delve#layout', ['terminal:vsplit:2/5', 'context:split:2/3', 'thread:split:1/5', 'breakpoint:split:1/5'])
+
 " GoFmt
 let g:go#fmt#autosave = get(g:, 'go#fmt#autosave', 0)
+let g:go#fmt#autosave_continue_on_error = get(g:, 'go#fmt#autosave_continue_on_error', 0)
 let g:go#fmt#mode = get(g:, 'go#fmt#mode', 'goimports')
+let g:go#fmt#import_preferences = get(g:, 'go#fmt#import_preferences', [])
 
-" GoGenerateTest
+" GoGenerate, GoGenerateTest
 let g:go#generate#test#allfuncs      = get(g:, 'go#generate#test#allfuncs', 1)
 let g:go#generate#test#exclude       = get(g:, 'go#generate#test#exclude', 'init$')
 let g:go#generate#test#exportedfuncs = get(g:, 'go#generate#test#exportedfuncs', 0)
 let g:go#generate#test#subtest       = get(g:, 'go#generate#test#subtest', 1)
+let g:go#generate#env                = get(g:, 'go#generate#env', [])
 
 " GoGuru
 let g:go#guru#reflection  = get(g:, 'go#guru#reflection', 0)
@@ -49,14 +92,29 @@ let g:go#guru#keep_cursor = get(g:, 'go#guru#keep_cursor',
       \ 'peers': 0,
       \ 'pointsto': 0,
       \ 'referrers': 0,
+      \ 'what': 0,
       \ 'whicherrs': 0
       \ })
 let g:go#guru#jump_first  = get(g:, 'go#guru#jump_first', 0)
+let g:go#guru#cache       = get(g:, 'go#guru#cache', 1)
+let g:go#guru#scope       = get(g:, 'go#guru#scope', [])
+let g:go#guru#definition_mode = get(g:, 'go#guru#definition_mode', 'edit')
+let g:go#guru#describe_verbose = get(g:, 'go#guru#describe_verbose', 0)
+
+" GoHighlightReferences
+let g:go#highlight#auto_references = get(g:, 'go#highlight#auto_references', 0)
+
+" GoHints
+let g:go#hints#enable = get(g:, 'go#hints#enable', 0)
 
 " GoIferr
 let g:go#iferr#autosave = get(g:, 'go#iferr#autosave', 0)
 
+" GoImplements
+let g:go#implements#include_stdlib = get(g:, 'go#implements#include_stdlib', 1)
+
 " Lint tools
+let g:go#lint#errcheck#ignore           = get(g:, 'go#lint#errcheck#ignore', [])
 let g:go#lint#golint#autosave           = get(g:, 'go#lint#golint#autosave', 0)
 let g:go#lint#golint#ignore             = get(g:, 'go#lint#golint#ignore', [])
 let g:go#lint#golint#min_confidence     = get(g:, 'go#lint#golint#min_confidence', 0.8)
@@ -70,9 +128,29 @@ let g:go#lint#metalinter#deadline       = get(g:, 'go#lint#metalinter#deadline',
 let g:go#lint#metalinter#tools          = get(g:, 'go#lint#metalinter#tools', ['vet', 'golint', 'errcheck'])
 let g:go#lint#metalinter#skip_dir       = get(g:, 'go#lint#metalinter#skip_dir', [])
 
+" GoAlternateMock
+let g:go#mock#suffix = get(g:, 'go#mock#suffix', '_mock.go')
+
+" GoReferrers
+let g:go#referrers#same_only = get(g:, 'go#referrers#same_only', 0)
+
 " Gorename
 let g:go#rename#prefill = get(g:, 'go#rename#prefill', 0)
 
+" Sign
+let g:go#sign#highlight = get(g:, 'go#sign#highlight', {})
+
+" GoStaticcheck
+let g:go#staticcheck#checks   = get(g:, 'go#staticcheck#checks', [])
+let g:go#staticcheck#severity = get(g:, 'go#staticcheck#severity', 'warning')
+let g:go#staticcheck#signs    = get(g:, 'go#staticcheck#signs', 1)
+
+" GoSymbols
+let g:go#symbols#scope = get(g:, 'go#symbols#scope', 'package')
+
+" GoAddTags
+let g:go#tags#case = get(g:, 'go#tags#case', 'snake')
+
 " Terminal
 let g:go#terminal#mode        = get(g:, 'go#terminal#mode', 'vsplit')
 let g:go#terminal#position    = get(g:, 'go#terminal#position', 'belowright')
@@ -84,6 +162,22 @@ let g:go#terminal#stop_insert = get(g:, 'go#terminal#stop_insert', 1)
 let g:go#test#all_package = get(g:, 'go#test#all_package', 0)
 let g:go#test#autosave    = get(g:, 'go#test#autosave', 0)
 let g:go#test#flags       = get(g:, 'go#test#flags', [])
+let g:go#test#race        = get(g:, 'go#test#race', 0)
+let g:go#test#compile_autosave = get(g:, 'go#test#compile_autosave', 0)
+
+" GoToolsInstall
+let g:go#tools#packages = get(g:, 'go#tools#packages', [
+      \ 'github.com/alecthomas/gometalinter',
+      \ 'github.com/cweill/gotests/gotests',
+      \ 'github.com/derekparker/delve/cmd/dlv',
+      \ 'github.com/golang/mock/mockgen',
+      \ 'github.com/koron/iferr',
+      \ 'github.com/rogpeppe/godef',
+      \ 'golang.org/x/perf/cmd/benchstat',
+      \ 'golang.org/x/tools/cmd/goimports',
+      \ 'golang.org/x/tools/cmd/gopls',
+      \ 'golang.org/x/tools/cmd/guru',
+      \ ])
 
 " Debugging
 let g:go#debug       = get(g:, 'go#debug', 0)
@@ -119,39 +213,122 @@ endfunction
 call remote#host#Register(s:plugin_name, '*', function('s:RequireNvimGo'))
 call remote#host#RegisterPlugin('nvim-go', '0', [
 \ {'type': 'autocmd', 'name': 'BufEnter', 'sync': 1, 'opts': {'eval': '{''BufNr'': bufnr(''%''), ''WinID'': win_getid(), ''Dir'': expand(''%:p:h'')}', 'group': 'nvim-go', 'pattern': '*.go'}},
-\ {'type': 'autocmd', 'name': 'BufWritePost', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'BufWritePost', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line(''w0''), line(''w$'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'BufWritePre', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
-\ {'type': 'autocmd', 'name': 'VimEnter', 'sync': 0, 'opts': {'eval': '{''Global'': {''ServerName'': v:servername, ''ErrorListType'': g:go#global#errorlisttype}, ''Build'': {''Autosave'': g:go#build#autosave, ''Force'': g:go#build#force, ''Flags'': g:go#build#flags}, ''Cover'': {''Flags'': g:go#cover#flags, ''Mode'': g:go#cover#mode}, ''Fmt'': {''Autosave'': g:go#fmt#autosave, ''Mode'': g:go#fmt#mode}, ''Generate'': {''TestAllFuncs'': g:go#generate#test#allfuncs, ''TestExclFuncs'': g:go#generate#test#exclude, ''TestExportedFuncs'': g:go#generate#test#exportedfuncs, ''TestSubTest'': g:go#generate#test#subtest}, ''Guru'': {''Reflection'': g:go#guru#reflection, ''KeepCursor'': g:go#guru#keep_cursor, ''JumpFirst'': g:go#guru#jump_first}, ''Iferr'': {''Autosave'': g:go#iferr#autosave}, ''Lint'': {''GolintAutosave'': g:go#lint#golint#autosave, ''GolintIgnore'': g:go#lint#golint#ignore, ''GolintMinConfidence'': g:go#lint#golint#min_confidence, ''GolintMode'': g:go#lint#golint#mode, ''GoVetAutosave'': g:go#lint#govet#autosave, ''GoVetFlags'': g:go#lint#govet#flags, ''GoVetIgnore'': g:go#lint#govet#ignore, ''MetalinterAutosave'': g:go#lint#metalinter#autosave, ''MetalinterAutosaveTools'': g:go#lint#metalinter#autosave#tools, ''MetalinterTools'': g:go#lint#metalinter#tools, ''MetalinterDeadline'': g:go#lint#metalinter#deadline, ''MetalinterSkipDir'': g:go#lint#metalinter#skip_dir}, ''Rename'': {''Prefill'': g:go#rename#prefill}, ''Terminal'': {''Mode'': g:go#terminal#mode, ''Position'': g:go#terminal#position, ''Height'': g:go#terminal#height, ''Width'': g:go#terminal#width, ''StopInsert'': g:go#terminal#stop_insert}, ''Test'': {''AllPackage'': g:go#test#all_package, ''Autosave'': g:go#test#autosave, ''Flags'': g:go#test#flags}, ''Debug'': {''Enable'': g:go#debug, ''Pprof'': g:go#debug#pprof}}', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'ColorScheme', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*'}},
+\ {'type': 'autocmd', 'name': 'CursorHold', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2), b:changedtick]', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'CursorMoved', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line(''.'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'TextChanged', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), bufnr(''%'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'VimEnter', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'VimLeavePre', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'VimLeavePre', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*.go,terminal,context,thread'}},
+\ {'type': 'command', 'name': 'DlvAttachRemote', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '1'}},
 \ {'type': 'command', 'name': 'DlvBreakpoint', 'sync': 0, 'opts': {'complete': 'customlist,FunctionsCompletion', 'eval': '[expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvCommand', 'sync': 0, 'opts': {'complete': 'customlist,DlvCommandCompletion', 'nargs': '+'}},
 \ {'type': 'command', 'name': 'DlvConnect', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'DlvContinue', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'DlvDebug', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'DlvDetach', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'DlvDisassemble', 'sync': 0, 'opts': {'complete': 'customlist,DlvAsmFlavorCompletion', 'eval': '[expand(''%:p:h'')]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'DlvKill', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'DlvNext', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvRecord', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DlvReplay', 'sync': 0, 'opts': {'complete': 'dir', 'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '+'}},
 \ {'type': 'command', 'name': 'DlvRestart', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'DlvReverseNext', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvRewind', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]'}},
+\ {'type': 'command', 'name': 'DlvSet', 'sync': 0, 'opts': {'eval': '[expand(''%:p:h'')]', 'nargs': '1'}},
 \ {'type': 'command', 'name': 'DlvState', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'DlvStdin', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'DlvToggleBreakpoint', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line(''.'')]'}},
+\ {'type': 'command', 'name': 'GoAddTags', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '+', 'range': ''}},
+\ {'type': 'command', 'name': 'GoAlternateMock', 'sync': 0, 'opts': {'complete': 'customlist,GoAlternateMockCompletion', 'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoAutocmdToggle', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoBench', 'sync': 0, 'opts': {'bang': '', 'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]'}},
 \ {'type': 'command', 'name': 'GoBuffers', 'sync': 1, 'opts': {}},
-\ {'type': 'command', 'name': 'GoByteOffset', 'sync': 1, 'opts': {'eval': 'expand(''%:p'')', 'range': '%'}},
+\ {'type': 'command', 'name': 'GoBuildTagsToggle', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '1'}},
+\ {'type': 'command', 'name': 'GoByteOffset', 'sync': 1, 'opts': {'eval': '[expand(''%:p''), getpos("''<"), getpos("''>")]', 'range': ''}},
+\ {'type': 'command', 'name': 'GoCallgraph', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoConfigDump', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoConfigReload', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'GoCover', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoDef', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoDefStackClear', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoDefStackPop', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoDeps', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'command', 'name': 'GoDoc', 'sync': 0, 'opts': {'complete': 'customlist,GoDocCompletion', 'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoErrcheck', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoErrcheckFix', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line(''.'')]'}},
+\ {'type': 'command', 'name': 'GoErrors', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoFillStruct', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoFixImports', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
+\ {'type': 'command', 'name': 'GoFmtAutosaveToggle', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoFmtSelection', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'range': ''}},
+\ {'type': 'command', 'name': 'GoFreeVars', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2), getpos("''<"), getpos("''>")]', 'range': ''}},
+\ {'type': 'command', 'name': 'GoGenerate', 'sync': 0, 'opts': {'bang': '', 'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'GoGenerateTest', 'sync': 0, 'opts': {'addr': 'line', 'bang': '', 'complete': 'file', 'eval': 'expand(''%:p:h'')', 'nargs': '*', 'range': '%'}},
+\ {'type': 'command', 'name': 'GoHighlightReferences', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2), b:changedtick]'}},
+\ {'type': 'command', 'name': 'GoHints', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line(''w0''), line(''w$'')]'}},
+\ {'type': 'command', 'name': 'GoHintsClear', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoHintsToggle', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line(''w0''), line(''w$'')]'}},
 \ {'type': 'command', 'name': 'GoIferr', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
+\ {'type': 'command', 'name': 'GoImpl', 'sync': 0, 'opts': {'bang': '', 'eval': 'expand(''%:p'')', 'nargs': '+'}},
+\ {'type': 'command', 'name': 'GoImplements', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoInfo', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoKeyify', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoLogClear', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoLogOpen', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoModDownload', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoModTidy', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoModVerify', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoOutline', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
+\ {'type': 'command', 'name': 'GoPackagesRefresh', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'command', 'name': 'GoProfile', 'sync': 0, 'opts': {'bang': '', 'complete': 'customlist,GoProfileCompletion', 'eval': 'expand(''%:p:h'')', 'nargs': '1'}},
+\ {'type': 'command', 'name': 'GoProfileReport', 'sync': 0, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'GoReferrers', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoRemoveTags', 'sync': 0, 'opts': {'eval': '[expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '+', 'range': ''}},
+\ {'type': 'command', 'name': 'GoStaticcheck', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoStop', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'GoSwitchTest', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'command', 'name': 'GoSymbols', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '?'}},
 \ {'type': 'command', 'name': 'GoTabpages', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'GoTestCompile', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'command', 'name': 'GoTestCoverageToggle', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), b:changedtick]'}},
+\ {'type': 'command', 'name': 'GoTestRace', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoTestReport', 'sync': 0, 'opts': {'complete': 'customlist,GoPackagesCompletion', 'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoTools', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoToolsInstall', 'sync': 0, 'opts': {'complete': 'customlist,GoToolsCompletion', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoToolsUpdate', 'sync': 0, 'opts': {'complete': 'customlist,GoToolsCompletion', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoVendorAdd', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '+'}},
+\ {'type': 'command', 'name': 'GoVendorList', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'command', 'name': 'GoVendorUpdate', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoVetAutosaveToggle', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'GoWindows', 'sync': 1, 'opts': {}},
-\ {'type': 'command', 'name': 'Gobuild', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'Gobuild', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'Gofmt', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
 \ {'type': 'command', 'name': 'Golint', 'sync': 0, 'opts': {'complete': 'customlist,GoLintCompletion', 'eval': 'expand(''%:p'')', 'nargs': '?'}},
 \ {'type': 'command', 'name': 'Gometalinter', 'sync': 0, 'opts': {'eval': 'getcwd()'}},
 \ {'type': 'command', 'name': 'Gorename', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p''), expand(''<cword>'')]', 'nargs': '?'}},
 \ {'type': 'command', 'name': 'Gorun', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'GorunLast', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
-\ {'type': 'command', 'name': 'Gotest', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'Gotest', 'sync': 0, 'opts': {'complete': 'customlist,GoPackagesCompletion', 'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'Govet', 'sync': 0, 'opts': {'complete': 'customlist,GoVetCompletion', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'function', 'name': 'DlvAsmFlavorCompletion', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DlvCommandCompletion', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'FunctionsCompletion', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'GoAlternateMockCompletion', 'sync': 1, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'function', 'name': 'GoDepsJump', 'sync': 0, 'opts': {}},
+\ {'type': 'function', 'name': 'GoDepsToggle', 'sync': 0, 'opts': {}},
+\ {'type': 'function', 'name': 'GoDocCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
 \ {'type': 'function', 'name': 'GoGuru', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'function', 'name': 'GoGuruCompletion', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'GoLintCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
+\ {'type': 'function', 'name': 'GoPackagesCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
+\ {'type': 'function', 'name': 'GoProfileCompletion', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'GoReferrersJump', 'sync': 0, 'opts': {}},
+\ {'type': 'function', 'name': 'GoStatus', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'GoTestReportJump', 'sync': 0, 'opts': {}},
+\ {'type': 'function', 'name': 'GoToolsCompletion', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'GoVetCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
 \ ])
 
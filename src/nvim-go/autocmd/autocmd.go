@@ -6,6 +6,7 @@ package autocmd
 
 import (
 	"sync"
+	"time"
 
 	"nvim-go/command"
 	"nvim-go/ctx"
@@ -27,8 +28,23 @@ type Autocmd struct {
 	wg               sync.WaitGroup
 
 	errs *syncmap.Map
+
+	// writing tracks the files which BufWritePre is processing.
+	writing *syncmap.Map
+
+	// lintDebouncer debounces the async vet and lint on BufWritePost.
+	lintDebouncer *debouncer
+	// outlineDebouncer debounces the GoOutline sidebar follow on CursorMoved and
+	// the re-render on TextChanged.
+	outlineDebouncer *debouncer
 }
 
+// lintDebounceDelay is the delay of the async vet and lint on BufWritePost.
+const lintDebounceDelay = 500 * time.Millisecond
+
+// outlineFollowDelay is the delay of the GoOutline sidebar follow on CursorMoved.
+const outlineFollowDelay = 100 * time.Millisecond
+
 // Register register autocmd to nvim.
 func Register(p *plugin.Plugin, ctx *ctx.Context, cmd *command.Command) {
 	autocmd := &Autocmd{
@@ -38,10 +54,18 @@ func Register(p *plugin.Plugin, ctx *ctx.Context, cmd *command.Command) {
 		bufWritePreChan:  make(chan interface{}),
 		bufWritePostChan: make(chan error),
 		errs:             new(syncmap.Map),
+		writing:          new(syncmap.Map),
+		lintDebouncer:    newDebouncer(lintDebounceDelay),
+		outlineDebouncer: newDebouncer(outlineFollowDelay),
 	}
 
 	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufEnter", Pattern: "*.go", Group: "nvim-go", Eval: "*"}, autocmd.BufEnter)
-	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufWritePost", Pattern: "*.go", Group: "nvim-go", Eval: "[getcwd(), expand('%:p')]"}, autocmd.bufWritePost)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufWritePost", Pattern: "*.go", Group: "nvim-go", Eval: "[getcwd(), expand('%:p'), line('w0'), line('w$')]"}, autocmd.bufWritePost)
 	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufWritePre", Pattern: "*.go", Group: "nvim-go", Eval: "[getcwd(), expand('%:p')]"}, autocmd.bufWritePre)
-	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimEnter", Pattern: "*.go", Group: "nvim-go", Eval: "*"}, autocmd.VimEnter)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "ColorScheme", Pattern: "*", Group: "nvim-go"}, autocmd.ColorScheme)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "CursorHold", Pattern: "*.go", Group: "nvim-go", Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2), b:changedtick]"}, autocmd.CursorHold)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "CursorMoved", Pattern: "*.go", Group: "nvim-go", Eval: "[expand('%:p'), line('.')]"}, autocmd.CursorMoved)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "TextChanged", Pattern: "*.go", Group: "nvim-go", Eval: "[expand('%:p'), bufnr('%')]"}, autocmd.TextChanged)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimEnter", Pattern: "*.go", Group: "nvim-go"}, autocmd.VimEnter)
+	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimLeavePre", Pattern: "*.go", Group: "nvim-go"}, autocmd.VimLeavePre)
 }
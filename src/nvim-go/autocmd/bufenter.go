@@ -11,7 +11,8 @@ type bufEnterEval struct {
 	Dir   string `eval:"expand('%:p:h')"`
 }
 
-// BufEnter gets the current buffer number, windows ID and set context from the directory structure on BufEnter autocmd.
+// BufEnter gets the current buffer number, windows ID and set the build context from the directory structure on BufEnter autocmd.
+// It also warms the package cache of the project.
 func (a *Autocmd) BufEnter(eval *bufEnterEval) error {
 	a.mu.Lock()
 	a.ctx.BufNr = eval.BufNr
@@ -19,5 +20,6 @@ func (a *Autocmd) BufEnter(eval *bufEnterEval) error {
 	a.mu.Unlock()
 
 	a.ctx.SetContext(eval.Dir)
+	a.cmd.WarmPackages(eval.Dir)
 	return nil
 }
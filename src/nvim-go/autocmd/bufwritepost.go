@@ -15,8 +15,10 @@ import (
 )
 
 type bufWritePostEval struct {
-	Cwd  string `msgpack:",array"`
-	File string
+	Cwd    string `msgpack:",array"`
+	File   string
+	Top    int // the visible line range of the window
+	Bottom int
 }
 
 func (a *Autocmd) bufWritePost(eval *bufWritePostEval) {
@@ -27,20 +29,26 @@ func (a *Autocmd) bufWritePost(eval *bufWritePostEval) {
 func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 	dir := filepath.Dir(eval.File)
 
-	if config.FmtAutosave {
+	// The saved file may change the result of the pointer analysis.
+	a.cmd.InvalidateGuruCache()
+	a.cmd.InvalidateSymbols(eval.File)
+	// The saved file may add the new package.
+	a.cmd.UpdatePackages(dir)
+
+	if !config.AutocmdEnable() {
+		return nil
+	}
+
+	if config.FmtAutosave() {
 		err := <-a.bufWritePreChan
-		switch e := err.(type) {
-		case error:
-			return nvimutil.ErrorWrap(a.Nvim, e)
-		case []*nvim.QuickfixError:
-			errlist := make(map[string][]*nvim.QuickfixError)
-			errlist["Fmt"] = e
-			return nvimutil.ErrorList(a.Nvim, errlist, true)
+		switch err.(type) {
+		case error, []*nvim.QuickfixError:
+			return a.reportFmt(eval.Cwd, err)
 		}
 	}
 
-	if config.BuildAutosave {
-		err := a.cmd.Build(config.BuildForce, &command.CmdBuildEval{
+	if config.BuildAutosave() {
+		err := a.cmd.Build(nil, config.BuildForce(), &command.CmdBuildEval{
 			Cwd:  eval.Cwd,
 			File: eval.File,
 		})
@@ -50,11 +58,11 @@ func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 		case []*nvim.QuickfixError:
 			errlist := make(map[string][]*nvim.QuickfixError)
 			errlist["Build"] = e
-			return nvimutil.ErrorList(a.Nvim, errlist, true)
+			return nvimutil.ErrorList(a.Nvim, eval.Cwd, errlist, true)
 		}
 	}
 
-	if config.GolintAutosave {
+	if config.GolintAutosave() {
 		a.wg.Add(1)
 		go func() {
 			defer a.wg.Done()
@@ -69,38 +77,45 @@ func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 		}()
 	}
 
-	if config.GoVetAutosave {
+	if config.GoVetAutosave() || config.MetalinterAutosave() {
+		// use the single key since the results are stored in the single
+		// "Vet" errs entry and the metalinter checks the whole Cwd, so only
+		// the last save in a burst across the files needs to run
+		a.lintDebouncer.run("lint", func() { a.lintAsync(eval) })
+	}
+
+	if config.TestCompileAutosave() {
 		a.wg.Add(1)
-		a.mu.Lock()
 		go func() {
-			defer func() {
-				a.wg.Done()
-				a.mu.Unlock()
-			}()
-
-			a.errs.Delete("Vet")
-			err := a.cmd.Vet(nil, &command.CmdVetEval{
-				Cwd:  eval.Cwd,
-				File: eval.File,
-			})
-			switch e := err.(type) {
+			defer a.wg.Done()
+
+			a.errs.Delete("TestCompile")
+			switch e := a.cmd.TestCompile(dir).(type) {
 			case error:
 				nvimutil.ErrorWrap(a.Nvim, e)
 			case []*nvim.QuickfixError:
-				a.errs.Store("Vet", e)
+				a.errs.Store("TestCompile", e)
 			}
 		}()
 	}
 
-	if config.MetalinterAutosave {
+	if config.HintsEnabled() {
 		a.wg.Add(1)
 		go func() {
 			defer a.wg.Done()
-			a.cmd.Metalinter(eval.Cwd)
+
+			err := a.cmd.Hints(&command.CmdHintsEval{
+				File:   eval.File,
+				Top:    eval.Top,
+				Bottom: eval.Bottom,
+			})
+			if err != nil {
+				nvimutil.ErrorWrap(a.Nvim, err)
+			}
 		}()
 	}
 
-	if config.TestAutosave {
+	if config.TestAutosave() {
 		a.wg.Add(1)
 		go func() {
 			defer a.wg.Done()
@@ -109,6 +124,40 @@ func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 	}
 
 	a.wg.Wait()
+
+	return a.updateErrorList(eval.Cwd)
+}
+
+// lintAsync runs the vet and metalinter without blocking the write, and
+// updates the error list. These never modify the buffer.
+func (a *Autocmd) lintAsync(eval *bufWritePostEval) {
+	if config.GoVetAutosave() {
+		a.mu.Lock()
+		a.errs.Delete("Vet")
+		err := a.cmd.Vet(nil, &command.CmdVetEval{
+			Cwd:  eval.Cwd,
+			File: eval.File,
+		})
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(a.Nvim, e)
+		case []*nvim.QuickfixError:
+			a.errs.Store("Vet", e)
+		}
+		a.mu.Unlock()
+
+		a.updateErrorList(eval.Cwd)
+	}
+
+	if config.MetalinterAutosave() {
+		a.cmd.Metalinter(eval.Cwd)
+	}
+}
+
+// updateErrorList updates the error list with the stored errors, or clears it
+// if there are no errors. The cwd is the Neovim current directory of the
+// written buffer.
+func (a *Autocmd) updateErrorList(cwd string) error {
 	errlist := make(map[string][]*nvim.QuickfixError)
 	a.errs.Range(func(ki, vi interface{}) bool {
 		k, v := ki.(string), vi.([]*nvim.QuickfixError)
@@ -117,7 +166,7 @@ func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 	})
 
 	if len(errlist) > 0 {
-		return nvimutil.ErrorList(a.Nvim, errlist, true)
+		return nvimutil.ErrorList(a.Nvim, cwd, errlist, true)
 	}
 
 	return nvimutil.ClearErrorlist(a.Nvim, true)
@@ -8,6 +8,9 @@ import (
 	"path/filepath"
 
 	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
 )
 
 type bufWritePreEval struct {
@@ -21,20 +24,90 @@ func (a *Autocmd) bufWritePre(eval *bufWritePreEval) {
 
 // BufWritePre run the commands on BufWritePre autocmd.
 func (a *Autocmd) BufWritePre(eval *bufWritePreEval) {
+	if !config.AutocmdEnable() {
+		return
+	}
+
 	dir := filepath.Dir(eval.File)
 
-	// Iferr need execute before Fmt function because that function calls "noautocmd write"
-	// Also do not use goroutine.
-	if config.IferrAutosave {
-		err := a.cmd.Iferr(eval.File)
-		if err != nil {
-			return
+	// The write of Fmt must not re-trigger the handler while processing the same file.
+	if !a.enterWrite(eval.File) {
+		if config.FmtAutosave() {
+			// BufWritePost waits for the Fmt result.
+			go func() {
+				a.bufWritePreChan <- nil
+			}()
+		}
+		return
+	}
+
+	// Report the Fmt result of the previous save if BufWritePost did not read it.
+	a.drainBufWritePre(eval.Cwd)
+
+	// Run the iferr and the single format pass at the end instead of formatting
+	// twice, which fights over the cursor position.
+	if config.IferrAutosave() && config.FmtAutosave() {
+		go func() {
+			defer a.leaveWrite(eval.File)
+			a.bufWritePreChan <- a.cmd.IferrFmt(eval.File, a.onIferrError)
+		}()
+		return
+	}
+
+	if config.IferrAutosave() {
+		if err := a.cmd.Iferr(eval.File); err != nil {
+			a.onIferrError(err)
 		}
 	}
 
-	if config.FmtAutosave {
+	if config.FmtAutosave() {
 		go func() {
+			defer a.leaveWrite(eval.File)
 			a.bufWritePreChan <- a.cmd.Fmt(dir)
 		}()
+		return
+	}
+	a.leaveWrite(eval.File)
+}
+
+// onIferrError reports the err of the iferr on save, and reports whether the
+// formatting should continue.
+func (a *Autocmd) onIferrError(err error) bool {
+	nvimutil.Echoerr(a.Nvim, "GoIferr: %v", err)
+	return config.FmtAutosaveContinueOnError()
+}
+
+// enterWrite marks the file as processing by BufWritePre, and reports whether
+// the file was not already processing.
+func (a *Autocmd) enterWrite(file string) bool {
+	_, loaded := a.writing.LoadOrStore(file, true)
+	return !loaded
+}
+
+// leaveWrite unmarks the file as processing by BufWritePre.
+func (a *Autocmd) leaveWrite(file string) {
+	a.writing.Delete(file)
+}
+
+// drainBufWritePre drains the unread Fmt result from bufWritePreChan, and reports it.
+func (a *Autocmd) drainBufWritePre(cwd string) {
+	select {
+	case err := <-a.bufWritePreChan:
+		a.reportFmt(cwd, err)
+	default:
+	}
+}
+
+// reportFmt reports the err result of the Fmt command. The cwd is the Neovim
+// current directory of the written buffer.
+func (a *Autocmd) reportFmt(cwd string, err interface{}) error {
+	switch e := err.(type) {
+	case error:
+		return nvimutil.ErrorWrap(a.Nvim, e)
+	case []*nvim.QuickfixError:
+		errlist := make(map[string][]*nvim.QuickfixError)
+		errlist["Fmt"] = e
+		return nvimutil.ErrorList(a.Nvim, cwd, errlist, true)
 	}
+	return nil
 }
@@ -0,0 +1,109 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nvim-go/command"
+	"nvim-go/config"
+	"nvim-go/ctx"
+	"nvim-go/nvimutil"
+
+	"golang.org/x/sync/syncmap"
+)
+
+var (
+	testCwd, _ = os.Getwd()
+	brokenMain = filepath.Join(testCwd, "../testdata/go/src/broken/broken.go")
+)
+
+func TestAutocmd_BufWritePreIferrError(t *testing.T) {
+	defer config.Set(config.Update(func(cfg *config.Config) {
+		cfg.Autocmd.Enable = 1
+		cfg.Iferr.Autosave = 1
+		cfg.Fmt.Autosave = 0
+	}))
+
+	n := nvimutil.TestNvim(t, brokenMain)
+	b, err := n.CurrentBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ctx.NewContext()
+	c.BufNr = int(b)
+	a := &Autocmd{
+		Nvim:            n,
+		ctx:             c,
+		cmd:             command.NewCommand(n, c),
+		bufWritePreChan: make(chan interface{}),
+		writing:         new(syncmap.Map),
+	}
+
+	a.BufWritePre(&bufWritePreEval{Cwd: filepath.Dir(brokenMain), File: brokenMain})
+
+	var errmsg string
+	if err := n.Eval("v:errmsg", &errmsg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(errmsg, "GoIferr") {
+		t.Errorf("BufWritePre(%s) v:errmsg = %q, want contains %q", brokenMain, errmsg, "GoIferr")
+	}
+}
+
+func TestAutocmd_enterWrite(t *testing.T) {
+	a := &Autocmd{writing: new(syncmap.Map)}
+
+	// iferr -> fmt -> "noautocmd write" sequence of the same file
+	if !a.enterWrite("foo.go") {
+		t.Fatalf("enterWrite(%q) = false, want true", "foo.go")
+	}
+	if a.enterWrite("foo.go") {
+		t.Errorf("re-entrant enterWrite(%q) = true, want false", "foo.go")
+	}
+	if !a.enterWrite("bar.go") {
+		t.Errorf("enterWrite(%q) of the other file = false, want true", "bar.go")
+	}
+
+	a.leaveWrite("foo.go")
+	if !a.enterWrite("foo.go") {
+		t.Errorf("enterWrite(%q) after leaveWrite = false, want true", "foo.go")
+	}
+}
+
+func TestAutocmd_BufWritePreToggle(t *testing.T) {
+	defer config.Set(config.Update(func(cfg *config.Config) { cfg.Iferr.Autosave = 0 }))
+
+	tests := []struct {
+		name          string
+		autocmdEnable int64
+		fmtAutosave   int64
+	}{
+		{name: "fmt autosave toggled off", autocmdEnable: 1, fmtAutosave: 0},
+		{name: "autocmd toggled off", autocmdEnable: 0, fmtAutosave: 1},
+	}
+	for _, tt := range tests {
+		config.Update(func(cfg *config.Config) {
+			cfg.Autocmd.Enable = tt.autocmdEnable
+			cfg.Fmt.Autosave = tt.fmtAutosave
+		})
+
+		a := &Autocmd{
+			bufWritePreChan: make(chan interface{}),
+			writing:         new(syncmap.Map),
+		}
+		a.BufWritePre(&bufWritePreEval{Cwd: testCwd, File: brokenMain})
+
+		select {
+		case <-a.bufWritePreChan:
+			t.Errorf("%q. BufWritePre spawned the fmt goroutine", tt.name)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import "nvim-go/nvimutil"
+
+// ColorScheme re-defines the sign highlight groups when autocmd ColorScheme,
+// so the signs survive the colorscheme switches.
+func (a *Autocmd) ColorScheme() {
+	nvimutil.DefineSignHighlights(a.Nvim)
+}
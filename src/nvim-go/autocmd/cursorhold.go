@@ -0,0 +1,30 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import (
+	"nvim-go/command"
+	"nvim-go/config"
+)
+
+type cursorHoldEval struct {
+	File   string `msgpack:",array"`
+	Offset int
+	Tick   int // b:changedtick
+}
+
+// CursorHold highlights the references of the identifier under the cursor if
+// config.AutoHighlightReferences is set. The cursor which is not on the
+// identifier is not the error.
+func (a *Autocmd) CursorHold(eval *cursorHoldEval) {
+	if !config.AutoHighlightReferences() {
+		return
+	}
+	go a.cmd.HighlightReferences(&command.CmdHighlightReferencesEval{
+		File:   eval.File,
+		Offset: eval.Offset,
+		Tick:   eval.Tick,
+	})
+}
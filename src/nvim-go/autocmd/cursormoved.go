@@ -0,0 +1,19 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+type cursorMovedEval struct {
+	File string `msgpack:",array"`
+	Line int
+}
+
+// CursorMoved follows the cursor in the GoOutline sidebar, and clears the
+// highlights of GoHighlightReferences.
+// The sidebar follows the cursor after the cursor stops for the
+// outlineFollowDelay, instead of on each cursor motion.
+func (a *Autocmd) CursorMoved(eval *cursorMovedEval) {
+	a.outlineDebouncer.run("outline", func() { a.cmd.OutlineFollow(eval.File, eval.Line) })
+	go a.cmd.ClearReferences()
+}
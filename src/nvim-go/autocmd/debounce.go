@@ -0,0 +1,49 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer delays the function call until the delay has elapsed since the
+// last call with the same key.
+type debouncer struct {
+	mu     sync.Mutex
+	delay  time.Duration
+	timers map[string]*time.Timer
+}
+
+// newDebouncer returns the new debouncer which delays the function call by delay.
+func newDebouncer(delay time.Duration) *debouncer {
+	return &debouncer{
+		delay:  delay,
+		timers: make(map[string]*time.Timer),
+	}
+}
+
+// run calls f in its own goroutine after the delay. If run is called again
+// with the same key before the delay has elapsed, the previous f is discarded.
+func (d *debouncer) run(key string, f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.timers[key]; ok {
+		t.Stop()
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		if d.timers[key] == t {
+			delete(d.timers, key)
+		}
+		d.mu.Unlock()
+
+		f()
+	})
+	d.timers[key] = t
+}
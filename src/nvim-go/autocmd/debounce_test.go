@@ -0,0 +1,48 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDebouncer_run(t *testing.T) {
+	d := newDebouncer(50 * time.Millisecond)
+
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int)
+		wg    sync.WaitGroup
+	)
+	wg.Add(2)
+	call := func(key string) func() {
+		return func() {
+			mu.Lock()
+			calls[key]++
+			mu.Unlock()
+			wg.Done()
+		}
+	}
+
+	// rapid successive saves of the same file such as ":wa"
+	for i := 0; i < 5; i++ {
+		d.run("foo.go", call("foo.go"))
+	}
+	d.run("bar.go", call("bar.go"))
+	wg.Wait()
+
+	// wait for the discarded calls if any
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, key := range []string{"foo.go", "bar.go"} {
+		if calls[key] != 1 {
+			t.Errorf("debouncer.run(%q) called %d times, want 1", key, calls[key])
+		}
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import "github.com/neovim/go-client/nvim"
+
+type textChangedEval struct {
+	File   string `msgpack:",array"`
+	Buffer int
+}
+
+// TextChanged re-renders the GoOutline sidebar of the changed buffer after
+// the changes stop for the outlineFollowDelay.
+func (a *Autocmd) TextChanged(eval *textChangedEval) {
+	a.outlineDebouncer.run("outline-refresh", func() { a.cmd.OutlineRefresh(eval.File, nvim.Buffer(eval.Buffer)) })
+}
@@ -4,11 +4,14 @@
 
 package autocmd
 
-import "nvim-go/config"
+import (
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+)
 
-// VimEnter gets user config variables and assign to global variable when autocmd VimEnter.
-func (a *Autocmd) VimEnter(cfg *config.Config) {
-	cfg.Global.ChannelID = a.Nvim.ChannelID()
-
-	config.Get(a.Nvim, cfg)
+// VimEnter loads the user config variables when autocmd VimEnter.
+func (a *Autocmd) VimEnter() {
+	if _, err := config.Load(a.Nvim); err != nil {
+		nvimutil.ErrorWrap(a.Nvim, err)
+	}
 }
@@ -0,0 +1,12 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+// VimLeavePre cancels all of the in-flight command operations, and cleans up
+// the processes and files of the session when autocmd VimLeavePre.
+func (a *Autocmd) VimLeavePre() {
+	a.cmd.CancelAll()
+	a.cmd.Cleanup()
+}
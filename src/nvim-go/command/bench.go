@@ -0,0 +1,242 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/pathutil"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const pkgBench = "GoBench"
+
+type cmdBenchEval struct {
+	File   string `msgpack:",array"`
+	Offset int
+}
+
+func (c *Command) cmdBench(bang bool, eval *cmdBenchEval) {
+	go func() {
+		err := c.Bench(bang, eval)
+		c.saveError("Bench", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Bench runs the benchmark function under the cursor, or the all benchmarks
+// of the package if bang is true, and shows the results table in the scratch
+// buffer. The results are compared with the config.BenchBaseline file by
+// benchstat if it's available.
+func (c *Command) Bench(bang bool, eval *cmdBenchEval) error {
+	defer nvimutil.Profile(time.Now(), pkgBench)
+	dir := filepath.Dir(eval.File)
+	c.ctx.SetContext(dir)
+
+	pattern := "."
+	if !bang {
+		_, _, src, err := c.bufferSource()
+		if err != nil {
+			return err
+		}
+		name, err := benchFuncName(src, eval.Offset)
+		if err != nil {
+			return err
+		}
+		pattern = "^" + name + "$"
+	}
+
+	ctx, done := c.startOp(pkgBench)
+	defer done()
+
+	nvimutil.EchoProgress(c.Nvim, pkgBench, "go test -bench=%s", pattern)
+	out, err := c.benchCmd(ctx, dir, pattern).CombinedOutput()
+	if err != nil {
+		return errors.Errorf("go test -bench=%s: %s", pattern, bytes.TrimSpace(out))
+	}
+	results := parseBench(out)
+	if len(results) == 0 {
+		return errors.Errorf("no benchmark matched %s", pattern)
+	}
+
+	text := formatBench(results)
+	if stat, err := benchstat(ctx, config.BenchBaseline(), out); err != nil {
+		return err
+	} else if stat != nil {
+		text = append(text, fmt.Sprintf("\n$ benchstat %s\n", config.BenchBaseline())...)
+		text = append(text, stat...)
+	}
+
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create("__GoBench__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := buf.SetBufferLines(0, -1, true, bytes.TrimSuffix(text, []byte{'\n'})); err != nil {
+		return err
+	}
+	// the increased time or memory of benchstat delta column is the regression
+	return errors.WithStack(c.Nvim.Command(`call clearmatches() | call matchadd('WarningMsg', '\v\+\d+(\.\d+)?\%')`))
+}
+
+// benchCmd returns the "go test -bench" command which runs the pattern
+// benchmarks of the dir package without the tests.
+func (c *Command) benchCmd(ctx context.Context, dir, pattern string, flags ...string) *exec.Cmd {
+	args := []string{"test", "-run=^$", "-bench=" + pattern}
+	if config.BenchMem() {
+		args = append(args, "-benchmem")
+	}
+	if t := config.BenchTime(); t != "" {
+		args = append(args, "-benchtime="+t)
+	}
+	args = append(args, c.mergeGoFlags(dir, flags, nil)...)
+
+	cmd := exec.CommandContext(ctx, "go", append(args, ".")...)
+	cmd.Dir = dir
+	cmd.Env = c.ctx.Build.Environ()
+	return cmd
+}
+
+// benchFuncName returns the name of the benchmark function which encloses
+// the offset of src.
+func benchFuncName(src []byte, offset int) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	pos := fset.File(file.Pos()).Pos(offset)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Pos() > pos || pos > fn.End() {
+			continue
+		}
+		if strings.HasPrefix(fn.Name.Name, "Benchmark") {
+			return fn.Name.Name, nil
+		}
+	}
+	return "", errors.New("no benchmark function under the cursor, use GoBench! for the all benchmarks")
+}
+
+// benchResult represents a result line of the benchmark.
+type benchResult struct {
+	name        string
+	n           int
+	nsPerOp     float64
+	bytesPerOp  float64
+	allocsPerOp float64
+	mem         bool // has the -benchmem results
+}
+
+// parseBench parses the benchmark results of the "go test -bench" output.
+// like:
+//  BenchmarkFoo-8   1000000   1052 ns/op   128 B/op   2 allocs/op
+func parseBench(out []byte) []benchResult {
+	var results []benchResult
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		r := benchResult{name: fields[0], n: n}
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				r.nsPerOp = v
+			case "B/op":
+				r.bytesPerOp = v
+				r.mem = true
+			case "allocs/op":
+				r.allocsPerOp = v
+				r.mem = true
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// formatBench formats the results to the aligned table.
+func formatBench(results []benchResult) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "name\titerations\tns/op\tB/op\tallocs/op")
+	for _, r := range results {
+		mem := "-\t-"
+		if r.mem {
+			mem = fmt.Sprintf("%g\t%g", r.bytesPerOp, r.allocsPerOp)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%g\t%s\n", r.name, r.n, r.nsPerOp, mem)
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// benchstat compares the baseline benchmark results file with out by the
+// benchstat tool. Returns nil if the baseline is empty, or the benchstat or
+// the baseline file is not found.
+func benchstat(ctx context.Context, baseline string, out []byte) ([]byte, error) {
+	if baseline == "" || !pathutil.IsExist(baseline) {
+		return nil, nil
+	}
+	bin, err := tools.Look("benchstat")
+	if err != nil {
+		return nil, nil
+	}
+
+	tmp, err := ioutil.TempFile("", "nvim-go-bench")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return nil, errors.WithStack(err)
+	}
+	tmp.Close()
+
+	stat, err := exec.CommandContext(ctx, bin, baseline, tmp.Name()).CombinedOutput()
+	if err != nil {
+		return nil, errors.Errorf("benchstat: %s", bytes.TrimSpace(stat))
+	}
+	return stat, nil
+}
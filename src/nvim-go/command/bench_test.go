@@ -0,0 +1,80 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBench(t *testing.T) {
+	out := `goos: linux
+goarch: amd64
+pkg: foo
+BenchmarkFoo-8         	 1000000	      1052 ns/op	     128 B/op	       2 allocs/op
+BenchmarkBar/small-8   	  500000	      2104.5 ns/op
+BenchmarkBroken-8      	 --- FAIL: BenchmarkBroken
+PASS
+ok  	foo	2.345s
+`
+	want := []benchResult{
+		{name: "BenchmarkFoo-8", n: 1000000, nsPerOp: 1052, bytesPerOp: 128, allocsPerOp: 2, mem: true},
+		{name: "BenchmarkBar/small-8", n: 500000, nsPerOp: 2104.5},
+	}
+	if got := parseBench([]byte(out)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBench() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatBench(t *testing.T) {
+	results := []benchResult{
+		{name: "BenchmarkFoo-8", n: 1000000, nsPerOp: 1052, bytesPerOp: 128, allocsPerOp: 2, mem: true},
+		{name: "BenchmarkBar-8", n: 500000, nsPerOp: 2104.5},
+	}
+	want := `name            iterations  ns/op   B/op  allocs/op
+BenchmarkFoo-8  1000000     1052    128   2
+BenchmarkBar-8  500000      2104.5  -     -
+`
+	if got := string(formatBench(results)); got != want {
+		t.Errorf("formatBench() = \n%s, want \n%s", got, want)
+	}
+}
+
+func TestBenchFuncName(t *testing.T) {
+	src := `package foo
+
+import "testing"
+
+func BenchmarkFoo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+	}
+}
+
+func TestFoo(t *testing.T) {}
+`
+	tests := []struct {
+		name    string
+		at      string
+		want    string
+		wantErr bool
+	}{
+		{name: "body", at: "b.N", want: "BenchmarkFoo"},
+		{name: "name", at: "BenchmarkFoo", want: "BenchmarkFoo"},
+		{name: "test function", at: "TestFoo", wantErr: true},
+		{name: "outside", at: "import", wantErr: true},
+	}
+	for _, tt := range tests {
+		offset := strings.Index(src, tt.at)
+		got, err := benchFuncName([]byte(src), offset)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. benchFuncName(%v) error = %v, wantErr %v", tt.name, offset, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. benchFuncName(%v) = %v, want %v", tt.name, offset, got, tt.want)
+		}
+	}
+}
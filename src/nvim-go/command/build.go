@@ -6,10 +6,13 @@ package command
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"nvim-go/config"
@@ -25,15 +28,19 @@ type CmdBuildEval struct {
 	File string
 }
 
-func (c *Command) cmdBuild(bang bool, eval *CmdBuildEval) {
+func (c *Command) cmdBuild(args []string, bang bool, eval *CmdBuildEval) {
 	go func() {
 		c.errs.Delete("Build")
 
-		err := c.Build(bang, eval)
+		err := c.Build(args, bang, eval)
 		switch e := err.(type) {
 		case error:
+			c.saveError("Build", e)
 			nvimutil.ErrorWrap(c.Nvim, e)
+		case nil:
+			c.saveError("Build", nil)
 		case []*nvim.QuickfixError:
+			c.saveError("Build", nil)
 			c.errs.Store("Build", e)
 			errlist := make(map[string][]*nvim.QuickfixError)
 			c.errs.Range(func(ki, vi interface{}) bool {
@@ -41,21 +48,31 @@ func (c *Command) cmdBuild(bang bool, eval *CmdBuildEval) {
 				errlist[k] = append(errlist[k], v...)
 				return true
 			})
-			nvimutil.ErrorList(c.Nvim, errlist, true)
+			nvimutil.ErrorList(c.Nvim, eval.Cwd, errlist, true)
 		}
 	}()
 }
 
 // Build builds the current buffers package use compile tool that determined
 // from the package directory structure.
-func (c *Command) Build(bang bool, eval *CmdBuildEval) interface{} {
+// The args are the "{goos} {goarch}" of the target platform, which overrides
+// the config.BuildGOOS and config.BuildGOARCH for this build.
+func (c *Command) Build(args []string, bang bool, eval *CmdBuildEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoBuild")
+	c.ctx.SetContext(filepath.Dir(eval.File))
 
 	if !bang {
-		bang = config.BuildForce
+		bang = config.BuildForce()
 	}
+	platform, err := parseBuildPlatform(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, done := c.startOp("GoBuild")
+	defer done()
 
-	cmd, err := c.compileCmd(bang, filepath.Dir(eval.File))
+	cmd, err := c.compileCmd(ctx, bang, filepath.Dir(eval.File), platform)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -73,23 +90,117 @@ func (c *Command) Build(bang bool, eval *CmdBuildEval) interface{} {
 		return errors.WithStack(buildErr)
 	}
 
-	return nvimutil.EchoSuccess(c.Nvim, "GoBuild", fmt.Sprintf("compiler: %s", c.ctx.Build.Tool))
+	if config.BuildRunVet() && c.ctx.Build.Tool == "go" {
+		errlist, err := c.buildVet(ctx, filepath.Dir(eval.File), eval.Cwd, platform)
+		if err != nil {
+			return err
+		}
+		if len(errlist) > 0 {
+			return errlist
+		}
+	}
+
+	msg := fmt.Sprintf("compiler: %s", c.ctx.Build.Tool)
+	if platform != (buildPlatform{}) {
+		msg += ", platform: " + platform.String()
+	}
+	return nvimutil.EchoSuccess(c.Nvim, "GoBuild", msg)
+}
+
+// buildPlatform represents the target platform of GoBuild. The empty field
+// is the host's.
+type buildPlatform struct {
+	goos   string
+	goarch string
+}
+
+func (p buildPlatform) String() string {
+	goos, goarch := p.goos, p.goarch
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos + "/" + goarch
+}
+
+// env returns the copy of environ which the GOOS and GOARCH are set to p.
+// Returns environ as is if p is the host platform.
+func (p buildPlatform) env(environ []string) []string {
+	if p == (buildPlatform{}) {
+		return environ
+	}
+
+	env := make([]string, 0, len(environ)+2)
+	for _, kv := range environ {
+		if (p.goos != "" && strings.HasPrefix(kv, "GOOS=")) || (p.goarch != "" && strings.HasPrefix(kv, "GOARCH=")) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	if p.goos != "" {
+		env = append(env, "GOOS="+p.goos)
+	}
+	if p.goarch != "" {
+		env = append(env, "GOARCH="+p.goarch)
+	}
+	return env
+}
+
+// parseBuildPlatform parses the "{goos} {goarch}" args of GoBuild. Returns
+// the config.BuildGOOS and config.BuildGOARCH platform if args is empty.
+func parseBuildPlatform(args []string) (buildPlatform, error) {
+	switch len(args) {
+	case 0:
+		return buildPlatform{goos: config.BuildGOOS(), goarch: config.BuildGOARCH()}, nil
+	case 2:
+		return buildPlatform{goos: args[0], goarch: args[1]}, nil
+	}
+	return buildPlatform{}, errors.New("usage: GoBuild[!] [{goos} {goarch}]")
+}
+
+// buildVet runs "go vet" for the dir package which is built successfully, and
+// returns the diagnostics as the warnings which are tagged by "vet: ".
+// The vet checks the same platform files as the build.
+func (c *Command) buildVet(ctx context.Context, dir, cwd string, platform buildPlatform) ([]*nvim.QuickfixError, error) {
+	args := append([]string{"vet"}, c.mergeGoFlags(dir, nil, nil)...)
+	cmd := exec.CommandContext(ctx, "go", append(args, ".")...)
+	cmd.Dir = dir
+	cmd.Env = platform.env(c.ctx.Build.Environ())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, errors.WithStack(err)
+		}
+	}
+	errlist, err := nvimutil.ParseError(stderr.Bytes(), cwd, &c.ctx.Build, config.GoVetIgnore())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, e := range errlist {
+		e.Text = "vet: " + e.Text
+		e.Type = "W"
+	}
+	return errlist, nil
 }
 
 // compileCmd returns the *exec.Cmd corresponding to the compile tool.
-func (c *Command) compileCmd(bang bool, dir string) (*exec.Cmd, error) {
+// The GOOS and GOARCH of the platform are set to only the command environment,
+// and the go/build context of the location list paths is kept.
+func (c *Command) compileCmd(ctx context.Context, bang bool, dir string, platform buildPlatform) (*exec.Cmd, error) {
 	bin, err := exec.LookPath(c.ctx.Build.Tool)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	args := []string{}
-	if len(config.BuildFlags) > 0 {
-		args = append(args, config.BuildFlags...)
-	}
+	args := c.mergeGoFlags(dir, nil, config.BuildFlags())
 
-	cmd := exec.Command(bin, "build")
-	cmd.Dir = dir
+	cmd := exec.CommandContext(ctx, bin, "build")
+	cmd.Dir = c.ctx.Build.WorkDir(dir)
+	cmd.Env = platform.env(c.ctx.Build.Environ())
 
 	switch c.ctx.Build.Tool {
 	case "go":
@@ -97,8 +208,10 @@ func (c *Command) compileCmd(bang bool, dir string) (*exec.Cmd, error) {
 		if !bang {
 			args = append(args, "-o", os.DevNull)
 		}
-	case "gb":
-		cmd.Dir = c.ctx.Build.ProjectRoot
+		// build the dir package instead of the module root package
+		if c.ctx.Build.ModuleRoot != "" {
+			args = append(args, dir)
+		}
 	}
 
 	cmd.Args = append(cmd.Args, args...)
@@ -1,7 +1,11 @@
 package command
 
 import (
+	"context"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"nvim-go/ctx"
@@ -88,7 +92,7 @@ func TestCommands_Build(t *testing.T) {
 			c := NewCommand(tt.fields.Nvim, tt.fields.ctx)
 			c.ctx.SetContext(filepath.Dir(tt.args.eval.File))
 
-			err := c.Build(tt.args.bang, tt.args.eval)
+			err := c.Build(nil, tt.args.bang, tt.args.eval)
 			if e, ok := err.(error); ok {
 				if (err != nil) != tt.wantErr {
 					t.Errorf("err: %v, wantErr %v", e, tt.wantErr)
@@ -110,7 +114,7 @@ func BenchmarkBuildGo(b *testing.B) {
 	c := NewCommand(benchVim(b, astdumpMain), ctx)
 
 	for i := 0; i < b.N; i++ {
-		c.Build(false, &CmdBuildEval{
+		c.Build(nil, false, &CmdBuildEval{
 			Cwd:  astdump,
 			File: astdump,
 		})
@@ -125,7 +129,7 @@ func BenchmarkBuildGb(b *testing.B) {
 	c := NewCommand(benchVim(b, gsftpMain), ctx)
 
 	for i := 0; i < b.N; i++ {
-		c.Build(false, &CmdBuildEval{
+		c.Build(nil, false, &CmdBuildEval{
 			Cwd:  gsftpRoot,
 			File: gsftpRoot,
 		})
@@ -214,3 +218,122 @@ func BenchmarkBuildGb(b *testing.B) {
 // 		})
 // 	}
 // }
+
+func TestCommand_buildVet(t *testing.T) {
+	vetErr := "package foo\n\nimport \"fmt\"\n\nfunc Foo() {\n\tfmt.Printf(\"%d\\n\", \"foo\")\n}\n"
+	tests := []struct {
+		name     string
+		file     string
+		src      string
+		platform buildPlatform
+		want     int // the number of the vet entries
+	}{
+		{
+			name: "vet error",
+			file: "foo.go",
+			src:  vetErr,
+			want: 1,
+		},
+		{
+			name:     "platform file",
+			file:     "foo_windows.go",
+			src:      vetErr,
+			platform: buildPlatform{goos: "windows", goarch: "amd64"},
+			want:     1,
+		},
+		{
+			name: "clean",
+			file: "foo.go",
+			src:  "package foo\n\nimport \"fmt\"\n\nfunc Foo() {\n\tfmt.Printf(\"%s\\n\", \"foo\")\n}\n",
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		dir, cleanup := writePackage(t, map[string]string{"go.mod": "module foo\n", "bar.go": "package foo\n", tt.file: tt.src})
+
+		c := NewCommand(nil, ctx.NewContext())
+		c.ctx.SetContext(dir)
+		got, err := c.buildVet(context.Background(), dir, dir, tt.platform)
+		cleanup()
+		if err != nil {
+			t.Errorf("%q. buildVet(%v) error = %v", tt.name, dir, err)
+			continue
+		}
+		if len(got) != tt.want {
+			t.Errorf("%q. buildVet(%v) = %d entries, want %d", tt.name, dir, len(got), tt.want)
+			continue
+		}
+		for _, e := range got {
+			if !strings.HasPrefix(e.Text, "vet: ") || e.Type != "W" || e.LNum != 6 {
+				t.Errorf("%q. buildVet(%v) entry = %+v, want the vet warning at line 6", tt.name, dir, e)
+			}
+		}
+	}
+}
+
+func TestBuildPlatform_env(t *testing.T) {
+	environ := []string{"HOME=/home/gopher", "GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0"}
+
+	tests := []struct {
+		name     string
+		platform buildPlatform
+		want     []string
+	}{
+		{
+			name: "host",
+			want: environ,
+		},
+		{
+			name:     "goos and goarch",
+			platform: buildPlatform{goos: "windows", goarch: "386"},
+			want:     []string{"HOME=/home/gopher", "CGO_ENABLED=0", "GOOS=windows", "GOARCH=386"},
+		},
+		{
+			name:     "goarch only",
+			platform: buildPlatform{goarch: "arm"},
+			want:     []string{"HOME=/home/gopher", "GOOS=linux", "CGO_ENABLED=0", "GOARCH=arm"},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.platform.env(environ); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. buildPlatform.env(%v) = %v, want %v", tt.name, environ, got, tt.want)
+		}
+	}
+}
+
+func TestCommands_compileCmdPlatform(t *testing.T) {
+	dir := astdump
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	goos, goarch := os.Getenv("GOOS"), os.Getenv("GOARCH")
+
+	cmd, err := c.compileCmd(context.Background(), false, dir, buildPlatform{goos: "windows", goarch: "arm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasEnv(cmd.Env, "GOOS=windows") || !hasEnv(cmd.Env, "GOARCH=arm") {
+		t.Errorf("compileCmd(%v).Env = %v, want GOOS=windows and GOARCH=arm", dir, cmd.Env)
+	}
+	if os.Getenv("GOOS") != goos || os.Getenv("GOARCH") != goarch {
+		t.Errorf("the nvim-go GOOS and GOARCH changed to %q and %q", os.Getenv("GOOS"), os.Getenv("GOARCH"))
+	}
+
+	// the next build without the platform uses the build environment
+	cmd, err = c.compileCmd(context.Background(), false, dir, buildPlatform{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := c.ctx.Build.Environ(); !reflect.DeepEqual(cmd.Env, want) {
+		t.Errorf("compileCmd(%v).Env = %v, want %v", dir, cmd.Env, want)
+	}
+}
+
+func hasEnv(env []string, kv string) bool {
+	for _, e := range env {
+		if e == kv {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/internal/pathutil"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const pkgCallgraph = "GoCallgraph"
+
+func (c *Command) cmdCallgraph(eval *funcGuruEval) {
+	go func() {
+		err := c.Callgraph(eval)
+		c.saveError("Callgraph", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Callgraph exports the static call graph of the function under the cursor
+// to the DOT file, and renders it by dot with the config.CallgraphFormat if
+// it's installed. The depth of the calls is bounded by config.CallgraphDepth.
+func (c *Command) Callgraph(eval *funcGuruEval) error {
+	defer nvimutil.Profile(time.Now(), pkgCallgraph)
+	c.ctx.SetContext(filepath.Dir(eval.File))
+
+	query, err := c.guruQuery(eval)
+	if err != nil {
+		return err
+	}
+	if len(config.GuruScope()) > 0 {
+		query.Scope = config.GuruScope()
+	}
+	query.Depth = int(config.CallgraphDepth())
+
+	ctx, done := c.startOp(pkgCallgraph)
+	defer done()
+
+	var graph *guru.Callgraph
+	query.Output = func(fset *token.FileSet, qr guru.QueryResult) {
+		graph, _ = qr.Result(fset).(*guru.Callgraph)
+	}
+	nvimutil.EchoProgress(c.Nvim, pkgCallgraph, "analysing callgraph")
+	if err := guru.Run("callgraph", query); err != nil {
+		return errors.WithStack(err)
+	}
+	if graph == nil {
+		return nvimutil.Info(c.Nvim, pkgCallgraph, "not found")
+	}
+
+	tmp, err := c.callgraph.dir()
+	if err != nil {
+		return err
+	}
+	dot := filepath.Join(tmp, "callgraph.dot")
+	if err := ioutil.WriteFile(dot, callgraphDOT(graph), 0644); err != nil {
+		return errors.WithStack(err)
+	}
+
+	format := config.CallgraphFormat()
+	bin, err := tools.Look("dot")
+	if format == "" || err != nil {
+		return nvimutil.Echomsg(c.Nvim, pkgCallgraph+":", fmt.Sprintf("%d calls of %s are written to %s", len(graph.Edges), graph.Root, dot))
+	}
+
+	out := filepath.Join(tmp, "callgraph."+format)
+	if b, err := exec.CommandContext(ctx, bin, "-T"+format, "-o", out, dot).CombinedOutput(); err != nil {
+		return errors.Errorf("dot: %s", bytes.TrimSpace(b))
+	}
+	return nvimutil.Echomsg(c.Nvim, pkgCallgraph+":", fmt.Sprintf("%d calls of %s are written to %s and rendered to %s", len(graph.Edges), graph.Root, dot, out))
+}
+
+// callgraphState represents the output directory of GoCallgraph.
+type callgraphState struct {
+	mu sync.Mutex
+	// tmp is the temporary directory of the session, which each run
+	// overwrites the output files in. It's removed on VimLeavePre.
+	tmp string
+}
+
+// dir returns the output directory. The directory is created at the first
+// call, and reused after that.
+func (s *callgraphState) dir() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tmp != "" && pathutil.IsExist(s.tmp) {
+		return s.tmp, nil
+	}
+	tmp, err := ioutil.TempDir("", "nvim-go-callgraph")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	s.tmp = tmp
+	return tmp, nil
+}
+
+// cleanup removes the output directory.
+func (s *callgraphState) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tmp != "" {
+		os.RemoveAll(s.tmp)
+		s.tmp = ""
+	}
+}
+
+// callgraphDOT returns the graph in the DOT language. The root function is
+// emphasized, and the call site is the tooltip of each edge.
+// like:
+//  digraph callgraph {
+//  	"main" [shape=box];
+//  	"main" -> "foo" [tooltip="main.go:4:5"];
+//  }
+func callgraphDOT(graph *guru.Callgraph) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("digraph callgraph {\n")
+	fmt.Fprintf(&buf, "\t%s [shape=box];\n", strconv.Quote(graph.Root))
+	for _, e := range graph.Edges {
+		fmt.Fprintf(&buf, "\t%s -> %s [tooltip=%s];\n", strconv.Quote(e.Caller), strconv.Quote(e.Callee), strconv.Quote(e.Pos))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"go/build"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nvim-go/internal/guru"
+	"nvim-go/internal/pathutil"
+)
+
+func TestCallgraphDOT(t *testing.T) {
+	src := `package foo
+
+func a() {
+	b()
+	b()
+}
+
+func b() {
+	c()
+}
+
+func c() {
+	d()
+}
+
+func d() {}
+`
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": src})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+	// the call site is the left parenthesis of the call
+	pos := func(call string) string {
+		off := strings.Index(src, call) + strings.Index(call, "(")
+		line := strings.Count(src[:off], "\n") + 1
+		col := off - strings.LastIndex(src[:off], "\n")
+		return fmt.Sprintf("%s:%d:%d", fname, line, col)
+	}
+
+	tests := []struct {
+		depth int
+		want  string
+	}{
+		{
+			depth: 2,
+			want: `digraph callgraph {
+	"a" [shape=box];
+	"a" -> "b" [tooltip="` + pos("b()\n\tb()") + `"];
+	"b" -> "c" [tooltip="` + pos("c()\n}") + `"];
+}
+`,
+		},
+		{
+			depth: 0,
+			want: `digraph callgraph {
+	"a" [shape=box];
+	"a" -> "b" [tooltip="` + pos("b()\n\tb()") + `"];
+	"b" -> "c" [tooltip="` + pos("c()\n}") + `"];
+	"c" -> "d" [tooltip="` + pos("d()\n}") + `"];
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		var graph *guru.Callgraph
+		query := guru.Query{
+			Pos:   guruPos(fname, strings.Index(src, "b()"), 0),
+			Build: &build.Default,
+			Depth: tt.depth,
+			Output: func(fset *token.FileSet, qr guru.QueryResult) {
+				graph, _ = qr.Result(fset).(*guru.Callgraph)
+			},
+		}
+		if err := guru.Run("callgraph", &query); err != nil {
+			t.Fatalf("guru.Run(callgraph, %v) error = %v", query.Pos, err)
+		}
+		if graph == nil {
+			t.Fatalf("guru.Run(callgraph, %v) returns no graph", query.Pos)
+		}
+
+		if got := string(callgraphDOT(graph)); got != tt.want {
+			t.Errorf("callgraphDOT(depth %d) = %s, want %s", tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestCallgraphState(t *testing.T) {
+	var s callgraphState
+	first, err := s.dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(first)
+
+	if second, err := s.dir(); err != nil || second != first {
+		t.Errorf("dir() = %v, %v, want the reused %v", second, err, first)
+	}
+	s.cleanup()
+	if pathutil.IsExist(first) {
+		t.Errorf("cleanup() left the output directory %v", first)
+	}
+}
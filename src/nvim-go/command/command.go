@@ -6,6 +6,7 @@ package command
 
 import (
 	"nvim-go/ctx"
+	"nvim-go/internal/guru"
 
 	"github.com/neovim/go-client/nvim"
 	"github.com/neovim/go-client/nvim/plugin"
@@ -16,46 +17,139 @@ import (
 type Command struct {
 	Nvim *nvim.Nvim
 
-	ctx *ctx.Context
-	errs *syncmap.Map
+	ctx       *ctx.Context
+	errs      *syncmap.Map
+	errlog    *syncmap.Map
+	guruCache *guru.Cache
+	defStack  defStack
+	ops       operations
+	outline   outlineState
+	profiles  profileState
+	symbols   symbolCache
+
+	references referencesState
+	coverage   coverageState
+	packages   packagesState
+	deps       depsState
+	testReport testReportState
+	callgraph  callgraphState
 }
 
 // NewCommand return the new Command type with initialize some variables.
 func NewCommand(v *nvim.Nvim, ctx *ctx.Context) *Command {
 	return &Command{
-		Nvim: v,
-		ctx:  ctx,
-		errs: new(syncmap.Map),
+		Nvim:      v,
+		ctx:       ctx,
+		errs:      new(syncmap.Map),
+		errlog:    new(syncmap.Map),
+		guruCache: new(guru.Cache),
 	}
 }
 
+// Cleanup stops the background processes, and removes the temporary files of
+// the session, such as the GoProfile profiles and the GoCallgraph outputs.
+// It's called on VimLeavePre.
+func (c *Command) Cleanup() {
+	c.profiles.cleanup()
+	c.callgraph.cleanup()
+}
+
 // Register register nvim-go command or function to Neovim over the msgpack-rpc plugin interface.
 func Register(p *plugin.Plugin, ctx *ctx.Context) *Command {
 	c := NewCommand(p.Nvim, ctx)
 
 	// Register command and function
 	// CommandOptions order: Name, NArgs, Range, Count, Addr, Bang, Register, Eval, Bar, Complete
-	p.HandleCommand(&plugin.CommandOptions{Name: "Gobuild", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdBuild)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoAddTags", NArgs: "+", Range: ".", Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdAddTags)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoAlternateMock", NArgs: "?", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]", Complete: "customlist,GoAlternateMockCompletion"}, c.cmdAlternateMock)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoAutocmdToggle"}, c.cmdAutocmdToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBench", Bang: true, Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdBench)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBuildTagsToggle", NArgs: "1", Eval: "expand('%:p:h')"}, c.cmdBuildTagsToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "Gobuild", NArgs: "*", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdBuild)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCallgraph", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.cmdCallgraph)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoConfigDump"}, c.cmdConfigDump)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoConfigReload"}, c.cmdConfigReload)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoCover", Eval: "[getcwd(), expand('%:p')]"}, c.cmdCover)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDef", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.cmdDef)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDefStackClear"}, c.cmdDefStackClear)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDefStackPop", Eval: "[getcwd(), expand('%:p')]"}, c.cmdDefStackPop)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDeps", Eval: "expand('%:p:h')"}, c.cmdDeps)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoDepsJump"}, c.funcDepsJump)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoDepsToggle"}, c.funcDepsToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDoc", NArgs: "?", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]", Complete: "customlist,GoDocCompletion"}, c.cmdDoc)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoErrcheck", Eval: "[getcwd(), expand('%:p')]"}, c.cmdErrcheck)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoErrcheckFix", Eval: "[expand('%:p'), line('.')]"}, c.cmdErrcheckFix)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoErrors"}, c.cmdErrors)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoFillStruct", Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdFillStruct)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoFixImports", Eval: "expand('%:p')"}, c.cmdFixImports)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoFreeVars", Range: ".", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2), getpos(\"'<\"), getpos(\"'>\")]"}, c.cmdFreeVars)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoFmtAutosaveToggle"}, c.cmdFmtAutosaveToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoFmtSelection", Range: ".", Eval: "expand('%:p:h')"}, c.cmdFmtSelection)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gofmt", Eval: "expand('%:p:h')"}, c.cmdFmt)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoGenerate", NArgs: "*", Bang: true, Eval: "expand('%:p:h')"}, c.cmdGenerate)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoGenerateTest", NArgs: "*", Range: "%", Addr: "line", Bang: true, Eval: "expand('%:p:h')", Complete: "file"}, c.cmdGenerateTest)
 	p.HandleFunction(&plugin.FunctionOptions{Name: "GoGuru", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.funcGuru)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoHighlightReferences", Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2), b:changedtick]"}, c.cmdHighlightReferences)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoHints", Eval: "[expand('%:p'), line('w0'), line('w$')]"}, c.cmdHints)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoHintsClear"}, c.cmdHintsClear)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoHintsToggle", Eval: "[expand('%:p'), line('w0'), line('w$')]"}, c.cmdHintsToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoImpl", NArgs: "+", Bang: true, Eval: "expand('%:p')"}, c.cmdImpl)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoImplements", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.cmdImplements)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoInfo", Eval: "[getcwd(), expand('%:p')]"}, c.cmdInfo)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoIferr", Eval: "expand('%:p')"}, c.cmdIferr)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoKeyify", Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdKeyify)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoLogClear"}, c.cmdLogClear)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoLogOpen"}, c.cmdLogOpen)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Golint", NArgs: "?", Eval: "expand('%:p')", Complete: "customlist,GoLintCompletion"}, c.cmdLint)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gometalinter", Eval: "getcwd()"}, c.cmdMetalinter)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoModDownload", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdModDownload)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoModTidy", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdModTidy)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoModVerify", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdModVerify)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoOutline", Eval: "expand('%:p')"}, c.cmdOutline)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoPackagesRefresh", Eval: "expand('%:p:h')"}, c.cmdPackagesRefresh)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoProfile", NArgs: "1", Bang: true, Eval: "expand('%:p:h')", Complete: "customlist,GoProfileCompletion"}, c.cmdProfile)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoProfileReport", Bang: true}, c.cmdProfileReport)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoReferrers", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.cmdReferrers)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoReferrersJump"}, c.funcReferrersJump)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoRemoveTags", NArgs: "+", Range: ".", Eval: "[expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdRemoveTags)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gorename", NArgs: "?", Bang: true, Eval: "[getcwd(), expand('%:p'), expand('<cword>')]"}, c.cmdRename)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gorun", NArgs: "*", Eval: "expand('%:p')"}, c.cmdRun)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GorunLast", Eval: "expand('%:p')"}, c.cmdRunLast)
-	p.HandleCommand(&plugin.CommandOptions{Name: "Gotest", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdTest)
+	p.HandleCommand(&plugin.CommandOptions{Name: "Gotest", NArgs: "*", Eval: "expand('%:p:h')", Complete: "customlist,GoPackagesCompletion"}, c.cmdTest)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestCompile", Eval: "expand('%:p:h')"}, c.cmdTestCompile)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestCoverageToggle", Eval: "[getcwd(), expand('%:p'), b:changedtick]"}, c.cmdTestCoverageToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestRace", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdTestRace)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestReport", NArgs: "*", Eval: "expand('%:p:h')", Complete: "customlist,GoPackagesCompletion"}, c.cmdTestReport)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoTestReportJump"}, c.funcTestReportJump)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoStaticcheck", Eval: "[getcwd(), expand('%:p')]"}, c.cmdStaticcheck)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoStop"}, c.cmdStop)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoStatus"}, c.funcStatus)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoSymbols", NArgs: "?", Bang: true, Eval: "[getcwd(), expand('%:p:h')]"}, c.cmdSymbols)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoSwitchTest", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdSwitchTest)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTools"}, c.cmdTools)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoToolsInstall", NArgs: "*", Complete: "customlist,GoToolsCompletion"}, c.cmdToolsInstall)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoToolsUpdate", NArgs: "*", Complete: "customlist,GoToolsCompletion"}, c.cmdToolsUpdate)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoVendorAdd", NArgs: "+", Eval: "expand('%:p:h')"}, c.cmdVendorAdd)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoVendorList", Eval: "expand('%:p:h')"}, c.cmdVendorList)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoVendorUpdate", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdVendorUpdate)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoVetAutosaveToggle"}, c.cmdVetAutosaveToggle)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Govet", NArgs: "*", Eval: "[getcwd(), expand('%:p')]", Complete: "customlist,GoVetCompletion"}, c.cmdVet)
 
 	// Commnad completion
-	p.HandleFunction(&plugin.FunctionOptions{Name: "GoLintCompletion", Eval: "getcwd()"}, c.cmdLintComplete) // list the file, directory and go packages
-	p.HandleFunction(&plugin.FunctionOptions{Name: "GoVetCompletion", Eval: "getcwd()"}, c.cmdVetComplete)   // flag for go tool vet
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoAlternateMockCompletion", Eval: "expand('%:p:h')"}, c.cmdAlternateMockComplete) // interfaces of the current package
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoDocCompletion", Eval: "getcwd()"}, c.cmdDocComplete)                            // importable packages
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoGuruCompletion"}, c.cmdGuruComplete)                                            // guru query modes
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoLintCompletion", Eval: "getcwd()"}, c.cmdLintComplete)                          // list the file, directory and go packages
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoPackagesCompletion", Eval: "getcwd()"}, c.cmdPackagesComplete)                  // packages of the project
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoProfileCompletion"}, c.cmdProfileComplete)                                      // profile kinds
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoToolsCompletion"}, c.cmdToolsComplete)                                          // tools of g:go#tools#packages
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoVetCompletion", Eval: "getcwd()"}, c.cmdVetComplete)                            // flag for go tool vet and the go packages
+
+	// check the external tools in the background
+	go checkTools()
 
 	// for debug
-	p.HandleCommand(&plugin.CommandOptions{Name: "GoByteOffset", Range: "%", Eval: "expand('%:p')"}, c.cmdByteOffset)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoByteOffset", Range: ".", Eval: "[expand('%:p'), getpos(\"'<\"), getpos(\"'>\")]"}, c.cmdByteOffset)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoBuffers"}, c.cmdBuffers)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoWindows"}, c.cmdWindows)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoTabpages"}, c.cmdTabpagas)
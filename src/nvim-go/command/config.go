@@ -0,0 +1,90 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+func (c *Command) cmdConfigDump() {
+	go func() {
+		if err := c.ConfigDump(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// ConfigDump dumps the effective config variables into the scratch buffer.
+func (c *Command) ConfigDump() error {
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+			nvimutil.BufOptionFiletype:  "vim",
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create("__GoConfigDump__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return buf.SetBufferLines(0, -1, true, bytes.TrimSuffix(config.Dump(config.Current()), []byte{'\n'}))
+}
+
+func (c *Command) cmdConfigReload() {
+	go func() {
+		if err := c.ConfigReload(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// ConfigReload reloads the config variables, and echoes the changed variables.
+// Fires the "User GoConfigChanged" autocmd if any variables are changed.
+// The invalid variables are reported after the reload, because the other
+// variables are still reloaded.
+//
+// The in-flight commands keep reading the previous Config, because the
+// accessors read the whole Config which swapped atomically.
+func (c *Command) ConfigReload() error {
+	prev := config.Current()
+	cfg, verr := config.Load(c.Nvim)
+	if cfg == nil {
+		return verr
+	}
+
+	diff := config.Diff(prev, cfg)
+	if len(diff) == 0 {
+		if err := nvimutil.EchoSuccess(c.Nvim, "GoConfigReload", "no changes"); err != nil {
+			return err
+		}
+		return verr
+	}
+
+	// the cached program was loaded with the previous guru config and build tags
+	if !reflect.DeepEqual(prev.Guru, cfg.Guru) || !reflect.DeepEqual(prev.Build.Tags, cfg.Build.Tags) {
+		c.InvalidateGuruCache()
+	}
+
+	if err := c.Nvim.Command("if exists('#User#GoConfigChanged') | doautocmd <nomodeline> User GoConfigChanged | endif"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := nvimutil.EchoSuccess(c.Nvim, "GoConfigReload", fmt.Sprintf("%d changed: %s", len(diff), strings.Join(diff, ", "))); err != nil {
+		return err
+	}
+	return verr
+}
@@ -6,9 +6,9 @@ package command
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -31,102 +31,145 @@ type cmdCoverEval struct {
 }
 
 func (c *Command) cmdCover(eval *cmdCoverEval) {
-	go func() {
-		err := c.cover(eval)
-
-		switch e := err.(type) {
-		case error:
-			nvimutil.ErrorWrap(c.Nvim, e)
-		case []*nvim.QuickfixError:
-			c.errs.Store("Cover", e)
-			errlist := make(map[string][]*nvim.QuickfixError)
-			c.errs.Range(func(ki, vi interface{}) bool {
-				k, v := ki.(string), vi.([]*nvim.QuickfixError)
-				errlist[k] = append(errlist[k], v...)
-				return true
-			})
-			nvimutil.ErrorList(c.Nvim, errlist, true)
-		}
-	}()
+	go func() { c.reportCover(eval.Cwd, c.cover(eval)) }()
+}
+
+// reportCover reports the result of the cover commands, which is the error or
+// the errlist of the tests build. The cwd is the Neovim current directory of
+// the command.
+func (c *Command) reportCover(cwd string, result interface{}) {
+	switch e := result.(type) {
+	case error:
+		nvimutil.ErrorWrap(c.Nvim, e)
+	case []*nvim.QuickfixError:
+		c.errs.Store("Cover", e)
+		errlist := make(map[string][]*nvim.QuickfixError)
+		c.errs.Range(func(ki, vi interface{}) bool {
+			k, v := ki.(string), vi.([]*nvim.QuickfixError)
+			errlist[k] = append(errlist[k], v...)
+			return true
+		})
+		nvimutil.ErrorList(c.Nvim, cwd, errlist, true)
+	}
 }
 
 // cover run the go tool cover command and highlight current buffer based cover
 // profile result.
 func (c *Command) cover(eval *cmdCoverEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoCover")
+	c.ctx.SetContext(filepath.Dir(eval.File))
 
-	coverFile, err := ioutil.TempFile(os.TempDir(), "nvim-go-cover")
+	ctx, done := c.startOp("GoCover")
+	defer done()
+
+	profile, errlist, err := c.runCover(ctx, filepath.Dir(eval.File))
+	if err != nil {
+		return err
+	}
+	if errlist != nil {
+		return errlist
+	}
+
+	b, err := c.Nvim.CurrentBuffer()
 	if err != nil {
 		return errors.WithStack(err)
 	}
+
+	var res int // for ignore the msgpack decode errror. not used
+	batch := c.Nvim.NewBatch()
+	for line, hl := range coverLines(profile, eval.File) {
+		batch.AddBufferHighlight(b, 0, hl, line-1, 0, -1, &res) // nvim_buf_add_highlight line started by 0
+	}
+
+	return errors.WithStack(batch.Execute())
+}
+
+// runCover runs the package tests of dir with the coverage profile. It returns
+// the errlist if the tests failed.
+func (c *Command) runCover(ctx context.Context, dir string) ([]*cover.Profile, []*nvim.QuickfixError, error) {
+	coverFile, err := ioutil.TempFile(os.TempDir(), "nvim-go-cover")
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
 	defer os.Remove(coverFile.Name())
 
-	cmd := exec.Command("go", strings.Fields(fmt.Sprintf("test -cover -covermode=%s -coverprofile=%s .", config.CoverMode, coverFile.Name()))...)
-	if len(config.CoverFlags) > 0 {
-		cmd.Args = append(cmd.Args, config.CoverFlags...)
+	cmd := exec.CommandContext(ctx, "go", strings.Fields(fmt.Sprintf("test -cover -covermode=%s -coverprofile=%s .", config.CoverMode(), coverFile.Name()))...)
+	if len(config.CoverFlags()) > 0 {
+		cmd.Args = append(cmd.Args, config.CoverFlags()...)
 	}
-	cmd.Dir = filepath.Dir(eval.File)
+	cmd.Dir = dir
+	cmd.Env = c.ctx.Build.Environ()
 
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
 	if coverErr := cmd.Run(); coverErr != nil && coverErr.(*exec.ExitError) != nil {
-		errlist, err := nvimutil.ParseError(stdout.Bytes(), filepath.Dir(eval.File), &c.ctx.Build, nil)
+		errlist, err := nvimutil.ParseError(stdout.Bytes(), dir, &c.ctx.Build, nil)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
-		return errlist
+		return nil, errlist, nil
 	}
 	delete(c.ctx.Errlist, "Cover")
 
 	profile, err := cover.ParseProfiles(coverFile.Name())
 	if err != nil {
-		return errors.WithStack(err)
-	}
-
-	b, err := c.Nvim.CurrentBuffer()
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	buf, err := c.Nvim.BufferLines(b, 0, -1, true)
-	if err != nil {
-		return errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
+	return profile, nil, nil
+}
 
-	highlighted := make(map[int]bool)
-	var res int // for ignore the msgpack decode errror. not used
-	batch := c.Nvim.NewBatch()
+// coverLines returns the cover highlight group of each line of file, which
+// is the 1-based line number. The first block of the line wins.
+func coverLines(profile []*cover.Profile, file string) map[int]string {
+	lines := make(map[int]string)
 	for _, prof := range profile {
-		if filepath.Base(prof.FileName) == filepath.Base(eval.File) {
+		if filepath.Base(prof.FileName) != filepath.Base(file) {
+			continue
+		}
 
-			if config.DebugEnable {
-				log.Printf("prof.Blocks:\n%+v\n", spew.Sdump(prof.Blocks))
-				log.Printf("prof.Boundaries():\n%+v\n", spew.Sdump(prof.Boundaries(nvimutil.ToByteSlice(buf))))
-			}
-			for _, block := range prof.Blocks {
-				for line := block.StartLine - 1; line <= block.EndLine-1; line++ { // nvim_buf_add_highlight line started by 0
-					// not highlighting the last RBRACE of the function
-					if line == block.EndLine-1 && block.EndCol == 2 {
-						break
-					}
-
-					var hl string
-					switch {
-					case block.Count == 0:
-						hl = "GoCoverMiss"
-					case block.Count-block.NumStmt == 0:
-						hl = "GoCoverPartial"
-					default:
-						hl = "GoCoverHit"
-					}
-					if !highlighted[line] {
-						batch.AddBufferHighlight(b, 0, hl, line, 0, -1, &res)
-						highlighted[line] = true
-					}
+		if config.DebugEnable() {
+			logger := nvimutil.NewLogger("cover")
+			logger.Debugf("prof.Blocks:\n%s", spew.Sdump(prof.Blocks))
+		}
+		for _, block := range prof.Blocks {
+			for line := block.StartLine; line <= block.EndLine; line++ {
+				// not highlighting the last RBRACE of the function
+				if line == block.EndLine && block.EndCol == 2 {
+					break
+				}
+
+				var hl string
+				switch {
+				case block.Count == 0:
+					hl = "GoCoverMiss"
+				case block.Count-block.NumStmt == 0:
+					hl = "GoCoverPartial"
+				default:
+					hl = "GoCoverHit"
+				}
+				if _, ok := lines[line]; !ok {
+					lines[line] = hl
 				}
 			}
 		}
 	}
+	return lines
+}
 
-	return errors.WithStack(batch.Execute())
+// coverPercent returns the percentage of the covered statements of profile.
+func coverPercent(profile []*cover.Profile) float64 {
+	var total, covered int
+	for _, prof := range profile {
+		for _, block := range prof.Blocks {
+			total += block.NumStmt
+			if block.Count > 0 {
+				covered += block.NumStmt
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
 }
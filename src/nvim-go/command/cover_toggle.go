@@ -0,0 +1,170 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const pkgTestCoverageToggle = "GoTestCoverageToggle"
+
+// coverSignGroup is the sign group of the coverage signs.
+const coverSignGroup = "nvim-go-cover"
+
+// coverSigns is the sign name of each cover highlight group.
+var coverSigns = map[string]string{
+	"GoCoverHit":     "go_cover_hit",
+	"GoCoverMiss":    "go_cover_miss",
+	"GoCoverPartial": "go_cover_partial",
+}
+
+// cmdTestCoverageToggleEval represents a GoTestCoverageToggle command Eval args.
+type cmdTestCoverageToggleEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+	Tick int // b:changedtick
+}
+
+// coverAction represents the action of GoTestCoverageToggle.
+type coverAction int
+
+const (
+	// coverRun runs the tests, and shows the coverage.
+	coverRun coverAction = iota
+	// coverShow shows the cached coverage without running the tests.
+	coverShow
+	// coverClear clears the shown coverage.
+	coverClear
+)
+
+// coverState represents the GoTestCoverageToggle state of a file.
+type coverState struct {
+	shown  bool
+	cached bool
+	tick   int            // b:changedtick of the buffer when cached
+	lines  map[int]string // the cover highlight group of each line
+	// percent is the coverage of the package.
+	percent float64
+}
+
+// toggle returns the next action of the buffer which changedtick is tick.
+// The coverage is shown by the coverRun action after the store.
+func (s *coverState) toggle(tick int) coverAction {
+	switch {
+	case s.shown:
+		s.shown = false
+		return coverClear
+	case s.cached && s.tick == tick:
+		s.shown = true
+		return coverShow
+	}
+	return coverRun
+}
+
+// store caches the coverage of the buffer which changedtick is tick, and
+// marks it shown.
+func (s *coverState) store(tick int, lines map[int]string, percent float64) {
+	s.shown = true
+	s.cached = true
+	s.tick = tick
+	s.lines = lines
+	s.percent = percent
+}
+
+// coverageState represents the coverage state of each file.
+type coverageState struct {
+	mu    sync.Mutex
+	files map[string]*coverState
+}
+
+// state returns the coverage state of file.
+func (s *coverageState) state(file string) *coverState {
+	if s.files == nil {
+		s.files = make(map[string]*coverState)
+	}
+	st, ok := s.files[file]
+	if !ok {
+		st = new(coverState)
+		s.files[file] = st
+	}
+	return st
+}
+
+func (c *Command) cmdTestCoverageToggle(eval *cmdTestCoverageToggleEval) {
+	go func() { c.reportCover(eval.Cwd, c.TestCoverageToggle(eval)) }()
+}
+
+// TestCoverageToggle runs the package tests with the coverage, and places
+// the coverage signs to the current file. The second invocation clears the
+// signs. The coverage is cached, so toggling back doesn't run the tests again
+// if the buffer is unchanged.
+func (c *Command) TestCoverageToggle(eval *cmdTestCoverageToggleEval) interface{} {
+	defer nvimutil.Profile(time.Now(), pkgTestCoverageToggle)
+
+	c.coverage.mu.Lock()
+	st := c.coverage.state(eval.File)
+	action := st.toggle(eval.Tick)
+	lines, percent := st.lines, st.percent
+	c.coverage.mu.Unlock()
+
+	switch action {
+	case coverClear:
+		return c.clearCoverSigns(eval.File)
+	case coverRun:
+		dir := filepath.Dir(eval.File)
+		c.ctx.SetContext(dir)
+		ctx, done := c.startOp(pkgTestCoverageToggle)
+		defer done()
+
+		profile, errlist, err := c.runCover(ctx, dir)
+		if err != nil {
+			return err
+		}
+		if errlist != nil {
+			return errlist
+		}
+		lines, percent = coverLines(profile, eval.File), coverPercent(profile)
+
+		c.coverage.mu.Lock()
+		st.store(eval.Tick, lines, percent)
+		c.coverage.mu.Unlock()
+	}
+
+	if err := c.placeCoverSigns(eval.File, lines); err != nil {
+		return err
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgTestCoverageToggle, fmt.Sprintf("coverage: %.1f%% of statements", percent))
+}
+
+// placeCoverSigns places the coverage signs of lines to file.
+func (c *Command) placeCoverSigns(file string, lines map[int]string) error {
+	for hl, name := range coverSigns {
+		if _, err := nvimutil.NewSign(c.Nvim, name, "▎", hl, ""); err != nil { // ▎ LEFT ONE QUARTER BLOCK
+			return err
+		}
+	}
+
+	batch := c.Nvim.NewBatch()
+	batch.Call("sign_unplace", nil, coverSignGroup, map[string]interface{}{"buffer": file})
+	for line, hl := range lines {
+		batch.Call("sign_place", nil, 0, coverSignGroup, coverSigns[hl], file, map[string]interface{}{"lnum": line})
+	}
+	return errors.WithStack(batch.Execute())
+}
+
+// clearCoverSigns clears the coverage signs of file.
+func (c *Command) clearCoverSigns(file string) error {
+	if err := c.Nvim.Call("sign_unplace", nil, coverSignGroup, map[string]interface{}{"buffer": file}); err != nil {
+		return errors.WithStack(err)
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgTestCoverageToggle, "cleared")
+}
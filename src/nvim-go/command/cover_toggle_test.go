@@ -0,0 +1,45 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import "testing"
+
+func TestCoverStateToggle(t *testing.T) {
+	lines := map[int]string{3: "GoCoverHit"}
+
+	var st coverState
+	steps := []struct {
+		name  string
+		tick  int
+		store bool // the tests run and the coverage is stored
+		want  coverAction
+	}{
+		{name: "first toggle runs the tests", tick: 1, store: true, want: coverRun},
+		{name: "second toggle clears", tick: 1, want: coverClear},
+		{name: "unchanged buffer shows the cache", tick: 1, want: coverShow},
+		{name: "clear the cache", tick: 1, want: coverClear},
+		{name: "changed buffer runs the tests again", tick: 5, store: true, want: coverRun},
+		{name: "clear the new coverage", tick: 5, want: coverClear},
+		{name: "failed tests are not cached", tick: 8, want: coverRun},
+		{name: "retry after the failure", tick: 8, store: true, want: coverRun},
+		{name: "clear after the retry", tick: 8, want: coverClear},
+		{name: "shows the retried cache", tick: 8, want: coverShow},
+	}
+	for _, tt := range steps {
+		got := st.toggle(tt.tick)
+		if got != tt.want {
+			t.Fatalf("%q. toggle(%d) = %v, want %v", tt.name, tt.tick, got, tt.want)
+		}
+		if tt.store {
+			st.store(tt.tick, lines, 50)
+		}
+		if wantShown := got != coverClear && (got == coverShow || tt.store); st.shown != wantShown {
+			t.Fatalf("%q. shown = %v, want %v", tt.name, st.shown, wantShown)
+		}
+	}
+	if st.percent != 50 || st.lines[3] != "GoCoverHit" {
+		t.Errorf("cached coverage = %v%% %v, want 50%% %v", st.percent, st.lines, lines)
+	}
+}
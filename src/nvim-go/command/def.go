@@ -0,0 +1,323 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/build"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/internal/pathutil"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/buildutil"
+)
+
+const pkgDef = "GoDef"
+
+type cmdDefEval struct {
+	Cwd      string `msgpack:",array"`
+	File     string
+	Modified int
+	Offset   int
+}
+
+func (c *Command) cmdDef(eval *cmdDefEval) {
+	go func() {
+		if err := c.Def(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// defResolver resolves the definition position of the eval cursor offset.
+// The src is the unsaved buffer contents, or nil if the buffer is not modified.
+// The env is the environment variables of the external tool.
+type defResolver func(ctx context.Context, eval *cmdDefEval, src []byte, env []string) (string, error)
+
+var defResolvers = map[string]defResolver{
+	"gopls": defGopls,
+	"guru":  defGuru,
+	"godef": defGodef,
+}
+
+// Def jumps to the definition of the current cursor identifier.
+// It tries each config.DefTool() in order until one returns a position.
+func (c *Command) Def(eval *cmdDefEval) error {
+	defer nvimutil.Profile(time.Now(), pkgDef)
+
+	var src []byte
+	if eval.Modified != 0 {
+		buf, err := c.Nvim.BufferLines(nvim.Buffer(c.ctx.BufNr), 0, -1, true)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		src = bytes.Join(buf, []byte{'\n'})
+	}
+
+	ctx, done := c.startOp(pkgDef)
+	defer done()
+
+	var errs []string
+	for _, tool := range config.DefTool() {
+		resolve, ok := defResolvers[tool]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: unknown tool", tool))
+			continue
+		}
+
+		out, err := resolve(ctx, eval, src, c.ctx.Build.Environ())
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", tool, err))
+			continue
+		}
+		pos, err := parseDefPos(out)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", tool, err))
+			continue
+		}
+
+		if config.DefDebug() {
+			nvimutil.Info(c.Nvim, pkgDef, "resolved by %s", tool)
+		}
+		return c.jumpDefinition(eval.Cwd, eval.File, pos)
+	}
+
+	return errors.Errorf("couldn't resolve the definition:\n\t%s", strings.Join(errs, "\n\t"))
+}
+
+// jumpDefinition jumps to the pos definition position from the file.
+// The pos is a "file:line:col" form.
+func (c *Command) jumpDefinition(cwd, file, pos string) error {
+	w := nvim.Window(c.ctx.WinID)
+
+	cursor, err := c.Nvim.WindowCursor(w)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.defStack.push(defPos{win: w, file: file, line: cursor[0], col: cursor[1]})
+
+	batch := c.Nvim.NewBatch()
+
+	fname, line, col := nvimutil.SplitPos(pos, cwd)
+
+	batch.Command("normal! m'")
+	sameFile := pathutil.Abs(cwd, fname) == file
+	if cmd := guruDefinitionCmd(config.GuruDefinitionMode(), sameFile, pathutil.Rel(cwd, fname)); cmd != "" {
+		batch.Command(cmd)
+	}
+	// the split or tab mode opens the new window, so moves the cursor of the
+	// current window instead of w
+	batch.SetWindowCursor(0, [2]int{line, col - 1})
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return c.Nvim.Command(`lclose | normal! zz`)
+}
+
+// defPos represents a cursor position of before the definition jump.
+type defPos struct {
+	// win is the window of the jump source. The jump opens the other window
+	// if config.GuruDefinitionMode() is the split or tab.
+	win  nvim.Window
+	file string
+	line int
+	col  int // 0-based byte column
+}
+
+// defStack is a jump history stack of GoDef.
+type defStack struct {
+	mu  sync.Mutex
+	pos []defPos
+}
+
+func (s *defStack) push(p defPos) {
+	s.mu.Lock()
+	s.pos = append(s.pos, p)
+	s.mu.Unlock()
+}
+
+// pop pops the last position from s. The second return value reports whether s was non-empty.
+func (s *defStack) pop() (defPos, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pos) == 0 {
+		return defPos{}, false
+	}
+	p := s.pos[len(s.pos)-1]
+	s.pos = s.pos[:len(s.pos)-1]
+	return p, true
+}
+
+func (s *defStack) clear() {
+	s.mu.Lock()
+	s.pos = nil
+	s.mu.Unlock()
+}
+
+type cmdDefStackPopEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+func (c *Command) cmdDefStackPop(eval *cmdDefStackPopEval) {
+	go func() {
+		if err := c.DefStackPop(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// DefStackPop returns to the previous location of before the GoDef jump.
+// If the jump opened the split or tab window, returns to the jump source
+// window while it is still open, otherwise returns in the current window.
+func (c *Command) DefStackPop(eval *cmdDefStackPopEval) error {
+	p, ok := c.defStack.pop()
+	if !ok {
+		return nvimutil.EchohlAfter(c.Nvim, pkgDef, "WarningMsg", "jump stack is empty")
+	}
+
+	w, file := nvim.Window(c.ctx.WinID), eval.File
+	if p.win != 0 && p.win != w {
+		valid, err := c.Nvim.IsWindowValid(p.win)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if valid {
+			if err := c.Nvim.SetCurrentWindow(p.win); err != nil {
+				return errors.WithStack(err)
+			}
+			buf, err := c.Nvim.WindowBuffer(p.win)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if file, err = c.Nvim.BufferName(buf); err != nil {
+				return errors.WithStack(err)
+			}
+			w = p.win
+		}
+	}
+
+	batch := c.Nvim.NewBatch()
+	if p.file != file {
+		// the file may contain the special characters of Ex commands, such as space
+		var name string
+		if err := c.Nvim.Call("fnameescape", &name, pathutil.Rel(eval.Cwd, p.file)); err != nil {
+			return errors.WithStack(err)
+		}
+		batch.Command(fmt.Sprintf("keepjumps edit %s", name))
+	}
+	batch.SetWindowCursor(w, [2]int{p.line, p.col})
+	batch.Command("normal! zz")
+
+	return errors.WithStack(batch.Execute())
+}
+
+func (c *Command) cmdDefStackClear() {
+	c.DefStackClear()
+}
+
+// DefStackClear clears the GoDef jump stack.
+func (c *Command) DefStackClear() {
+	c.defStack.clear()
+}
+
+var defPosRe = regexp.MustCompile(`^(.+?):(\d+):(\d+)`)
+
+// parseDefPos normalizes the output of definition tools to "file:line:col" form.
+func parseDefPos(out string) (string, error) {
+	out = strings.TrimSpace(out)
+	if i := strings.IndexByte(out, '\n'); i >= 0 {
+		out = out[:i]
+	}
+
+	m := defPosRe.FindStringSubmatch(out)
+	if m == nil {
+		return "", errors.Errorf("invalid definition position: %q", out)
+	}
+	line, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+
+	return fmt.Sprintf("%s:%d:%d", m[1], line, col), nil
+}
+
+// defGopls resolves the definition with "gopls definition" command.
+func defGopls(ctx context.Context, eval *cmdDefEval, src []byte, env []string) (string, error) {
+	if src != nil {
+		return "", errors.New("unsupported the modified buffer")
+	}
+
+	bin, err := tools.Require(pkgDef, "gopls")
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, bin, "definition", fmt.Sprintf("%s:#%d", eval.File, eval.Offset))
+	cmd.Dir = eval.Cwd
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Errorf("%v: %s", err, bytes.TrimSpace(out))
+	}
+
+	return string(out), nil
+}
+
+// defGuru resolves the definition with the internal guru package.
+func defGuru(ctx context.Context, eval *cmdDefEval, src []byte, env []string) (string, error) {
+	ctxt := build.Default // copy
+	guruContext := &ctxt
+	if src != nil {
+		guruContext = buildutil.OverlayContext(guruContext, map[string][]byte{eval.File: src})
+	}
+
+	obj, err := Definition(&guru.Query{
+		Pos:   fmt.Sprintf("%s:#%d", eval.File, eval.Offset),
+		Build: guruContext,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return obj.ObjPos, nil
+}
+
+// defGodef resolves the definition with godef command.
+func defGodef(ctx context.Context, eval *cmdDefEval, src []byte, env []string) (string, error) {
+	args := []string{"-f", eval.File, "-o", strconv.Itoa(eval.Offset)}
+	if src != nil {
+		args = append(args, "-i")
+	}
+
+	bin, err := tools.Require(pkgDef, "godef")
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = eval.Cwd
+	cmd.Env = env
+	if src != nil {
+		cmd.Stdin = bytes.NewReader(src)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Errorf("%v: %s", err, bytes.TrimSpace(out))
+	}
+
+	return string(out), nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"nvim-go/config"
+	"nvim-go/ctx"
+	"nvim-go/nvimutil"
+)
+
+func TestParseDefPos(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "gopls",
+			out:     "/go/src/foo.org/foo/foo.go:12:6-9: defined here as func foo.Foo()\n",
+			want:    "/go/src/foo.org/foo/foo.go:12:6",
+			wantErr: false,
+		},
+		{
+			name:    "guru",
+			out:     "/go/src/foo.org/foo/foo.go:12:6",
+			want:    "/go/src/foo.org/foo/foo.go:12:6",
+			wantErr: false,
+		},
+		{
+			name:    "godef",
+			out:     "/go/src/foo.org/foo/foo.go:12:6\n",
+			want:    "/go/src/foo.org/foo/foo.go:12:6",
+			wantErr: false,
+		},
+		{
+			name:    "invalid output",
+			out:     "godef: no identifier found\n",
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "empty output",
+			out:     "",
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseDefPos(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%q. parseDefPos(%v) error = %v, wantErr %v", tt.name, tt.out, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("%q. parseDefPos(%v) = %v, want %v", tt.name, tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefStack(t *testing.T) {
+	var s defStack
+
+	if _, ok := s.pop(); ok {
+		t.Errorf("defStack.pop() on empty stack = _, %v, want false", ok)
+	}
+
+	first := defPos{win: 1000, file: "foo.go", line: 1, col: 2}
+	second := defPos{win: 1001, file: "bar.go", line: 3, col: 4}
+	s.push(first)
+	s.push(second)
+
+	for _, want := range []defPos{second, first} {
+		got, ok := s.pop()
+		if !ok || got != want {
+			t.Errorf("defStack.pop() = %v, %v, want %v, true", got, ok, want)
+		}
+	}
+
+	s.push(first)
+	s.clear()
+	if _, ok := s.pop(); ok {
+		t.Errorf("defStack.pop() after clear = _, %v, want false", ok)
+	}
+}
+
+func TestCommand_jumpDefinition(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"a.go": "package a\n\nfunc A() { B() }\n",
+		"b.go": "package a\n\n// B does nothing.\nfunc B() {}\n",
+	})
+	defer cleanup()
+	a, b := filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")
+
+	for _, mode := range []string{"edit", "split", "vsplit", "tab"} {
+		func() {
+			defer config.Set(config.Update(func(cfg *config.Config) { cfg.Guru.DefMode = mode }))
+
+			n := nvimutil.TestNvim(t, a)
+			src, err := n.CurrentWindow()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.SetWindowCursor(src, [2]int{3, 11}); err != nil {
+				t.Fatal(err)
+			}
+			c := NewCommand(n, ctx.NewContext())
+			c.ctx.WinID = int(src)
+
+			if err := c.jumpDefinition(dir, a, b+":4:6"); err != nil {
+				t.Errorf("%q. jumpDefinition() error = %v", mode, err)
+				return
+			}
+
+			w, err := n.CurrentWindow()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opened := w != src; opened != (mode != "edit") {
+				t.Errorf("%q. jumpDefinition() opened the new window = %v", mode, opened)
+			}
+			buf, err := n.WindowBuffer(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name, err := n.BufferName(buf); err != nil || name != b {
+				t.Errorf("%q. jumpDefinition() current buffer = %v, %v, want %v", mode, name, err, b)
+			}
+			if got, err := n.WindowCursor(w); err != nil || got != [2]int{4, 5} {
+				t.Errorf("%q. jumpDefinition() cursor = %v, %v, want [4 5]", mode, got, err)
+			}
+			if mode == "edit" {
+				return
+			}
+			if got, err := n.WindowCursor(src); err != nil || got != [2]int{3, 11} {
+				t.Errorf("%q. jumpDefinition() source window cursor = %v, %v, want [3 11]", mode, got, err)
+			}
+		}()
+	}
+}
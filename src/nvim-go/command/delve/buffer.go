@@ -6,10 +6,14 @@ package delve
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"nvim-go/config"
 	"nvim-go/nvimutil"
 
+	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
 )
 
@@ -20,54 +24,192 @@ const (
 	Context nvimutil.BufferName = "context"
 	// Threads define threads buffer name.
 	Threads nvimutil.BufferName = "thread"
+	// Breakpoints define breakpoint list buffer name.
+	Breakpoints nvimutil.BufferName = "breakpoint"
+	// Disassemble define disassemble buffer name.
+	Disassemble nvimutil.BufferName = "disassemble"
 )
 
 // openDebugBuffer opens the buffers that prints the debug information.
+//
+// The buffers are created first by the config.DelveLayout with the absolute
+// split sizes which based by the source window dimensions, and then all of the
+// buffer and window options are applied by a single batch to reduce the
+// round-trips.
 func (d *Delve) openDebugBuffer() error {
-	batch := d.Nvim.NewBatch()
+	defer nvimutil.Profile(time.Now(), "DlvOpenDebugBuffer")
 
+	// the window 0 is the current window
+	var height, width int
+	batch := d.Nvim.NewBatch()
 	batch.CurrentBuffer(&d.cb)
 	batch.CurrentWindow(&d.cw)
-	err := batch.Execute()
-	if err != nil {
+	batch.WindowHeight(0, &height)
+	batch.WindowWidth(0, &width)
+	if err := batch.Execute(); err != nil {
 		return errors.WithStack(err)
 	}
 
-	var height, width int
-	batch.WindowHeight(d.cw, &height)
-	batch.WindowWidth(d.cw, &width)
-	err = batch.Execute()
+	panels, err := parseLayout(config.DelveLayout())
+	if err != nil {
+		return err
+	}
+	d.buffers, err = createBuffers(d.Nvim, panels, width, height)
 	if err != nil {
+		return err
+	}
+
+	option := d.setBufferOption()
+	for _, p := range panels {
+		d.buffers[p.name].SetOptions(batch, option)
+	}
+	for _, name := range []nvimutil.BufferName{Threads, Breakpoints} {
+		if buf, ok := d.buffers[name]; ok {
+			batch.SetWindowOption(buf.Window, nvimutil.WinOptionWinfixheight, true)
+		}
+	}
+
+	d.buffers[Terminal].SetOptions(batch, map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferMapping: {
+			"i": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvStdin')<CR>", config.ChannelID()),
+		},
+	})
+	if buf, ok := d.buffers[Breakpoints]; ok {
+		buf.SetOptions(batch, map[nvimutil.NvimOption]map[string]interface{}{
+			nvimutil.BufferMapping: {
+				"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvJumpBreakpoint', line('.'))<CR>", config.ChannelID()),
+			},
+		})
+	}
+	batch.SetCurrentWindow(d.cw)
+	if err := batch.Execute(); err != nil {
 		return errors.WithStack(err)
 	}
 
-	go func() {
-		defer d.Nvim.SetCurrentWindow(d.cw)
+	d.pcSign, err = nvimutil.NewSign(d.Nvim, "delve_pc", signSymbol(config.DelvePCSymbol(), nvimutil.DefaultProgramCounterSymbol), "delvePCSign", "delvePCLine") // *nvim.Sign
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
-		option := d.setBufferOption()
-		d.buffers = make(map[nvimutil.BufferName]*nvimutil.Buffer)
-		nnoremap := make(map[string]string)
+	return nil
+}
 
-		d.buffers[Terminal] = nvimutil.NewBuffer(d.Nvim)
-		d.buffers[Terminal].Create(string(Terminal), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d vsplit", (width*2/5)), option)
-		nnoremap["i"] = fmt.Sprintf(":<C-u>call rpcrequest(%d, 'DlvStdin')<CR>", config.ChannelID)
-		d.buffers[Terminal].SetLocalMapping(nvimutil.NoremapNormal, nnoremap)
+// panel represents a debug buffer of the layout.
+type panel struct {
+	name     nvimutil.BufferName
+	vertical bool
+	// the size is num/den of the source window, or num lines or columns if den is 0.
+	num, den int
+}
 
-		d.buffers[Context] = nvimutil.NewBuffer(d.Nvim)
-		d.buffers[Context].Create(string(Context), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*2/3)), option)
+// parseLayout parses the g:go#delve#layout spec. Each spec is the
+// "name:split:size" form such as "context:split:2/3", and the panels are
+// split from the previous one in order. The split is either "split" or
+// "vsplit", and the size is the fraction or the percentage of the source
+// window such as "2/5" and "40%", or the number of lines or columns. The
+// terminal panel is required since it shows the dlv output.
+func parseLayout(spec []string) ([]panel, error) {
+	var (
+		panels   []panel
+		seen     = make(map[nvimutil.BufferName]bool)
+		terminal bool
+	)
+	for _, s := range spec {
+		fields := strings.Split(s, ":")
+		if len(fields) != 3 {
+			return nil, errors.Errorf("invalid g:go#delve#layout %q, must be the name:split:size form", s)
+		}
+
+		p := panel{name: nvimutil.BufferName(fields[0])}
+		switch p.name {
+		case Terminal, Context, Threads, Breakpoints:
+		default:
+			return nil, errors.Errorf("invalid g:go#delve#layout %q, unknown panel %s", s, fields[0])
+		}
+		if seen[p.name] {
+			return nil, errors.Errorf("invalid g:go#delve#layout %q, duplicated panel %s", s, fields[0])
+		}
+		seen[p.name] = true
+		terminal = terminal || p.name == Terminal
+
+		switch fields[1] {
+		case "split":
+		case "vsplit":
+			p.vertical = true
+		default:
+			return nil, errors.Errorf("invalid g:go#delve#layout %q, the split must be split or vsplit", s)
+		}
+
+		var err error
+		size := fields[2]
+		switch {
+		case strings.HasSuffix(size, "%"):
+			p.den = 100
+			p.num, err = strconv.Atoi(strings.TrimSuffix(size, "%"))
+		case strings.Contains(size, "/"):
+			i := strings.Index(size, "/")
+			if p.num, err = strconv.Atoi(size[:i]); err == nil {
+				p.den, err = strconv.Atoi(size[i+1:])
+			}
+		default:
+			p.num, err = strconv.Atoi(size)
+		}
+		if err != nil || p.num <= 0 || p.den < 0 || (p.den > 0 && p.num > p.den) {
+			return nil, errors.Errorf("invalid g:go#delve#layout %q, invalid size %s", s, size)
+		}
+
+		panels = append(panels, p)
+	}
+	if !terminal {
+		return nil, errors.New("invalid g:go#delve#layout, the terminal panel is required")
+	}
+	return panels, nil
+}
 
-		d.buffers[Threads] = nvimutil.NewBuffer(d.Nvim)
-		d.buffers[Threads].Create(string(Threads), nvimutil.FiletypeDelve, fmt.Sprintf("silent belowright %d split", (height*1/5)), option)
-		d.Nvim.SetWindowOption(d.buffers[Threads].Window, "winfixheight", true)
+// mode returns the split command of p based on the source window dimensions.
+func (p panel) mode(width, height int) string {
+	split, size := "split", height
+	if p.vertical {
+		split, size = "vsplit", width
+	}
+	if p.den > 0 {
+		size = size * p.num / p.den
+	} else {
+		size = p.num
+	}
+	return fmt.Sprintf("silent belowright %d %s", size, split)
+}
 
-	}()
+// newBuffer creates the name buffer by the mode split command. It's replaced by the tests.
+var newBuffer = func(n *nvim.Nvim, name nvimutil.BufferName, mode string) (*nvimutil.Buffer, error) {
+	buf := nvimutil.NewBuffer(n)
+	buf.Reuse = true
+	if _, err := buf.Create(string(name), nvimutil.FiletypeDelve, mode, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf, nil
+}
 
-	d.pcSign, err = nvimutil.NewSign(d.Nvim, "delve_pc", nvimutil.ProgramCounterSymbol, "delvePCSign", "delvePCLine") // *nvim.Sign
-	if err != nil {
-		return errors.WithStack(err)
+// createBuffers creates the buffers of panels in order, with the absolute
+// split sizes based on the source window width and height.
+func createBuffers(n *nvim.Nvim, panels []panel, width, height int) (map[nvimutil.BufferName]*nvimutil.Buffer, error) {
+	buffers := make(map[nvimutil.BufferName]*nvimutil.Buffer)
+	for _, p := range panels {
+		buf, err := newBuffer(n, p.name, p.mode(width, height))
+		if err != nil {
+			return nil, err
+		}
+		buffers[p.name] = buf
 	}
+	return buffers, nil
+}
 
-	return batch.Execute()
+// signSymbol returns the sign symbol, or fallback if symbol is unset.
+func signSymbol(symbol, fallback string) string {
+	if symbol == "" {
+		return fallback
+	}
+	return symbol
 }
 
 // setBufferOption sets the delve buffer options.
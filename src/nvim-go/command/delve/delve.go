@@ -7,14 +7,15 @@ package delve
 import (
 	"bytes"
 	"fmt"
-	"go/build"
-	"io/ioutil"
-	"os"
+	"net"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"nvim-go/config"
 	"nvim-go/ctx"
 	"nvim-go/internal/pathutil"
 	"nvim-go/nvimutil"
@@ -28,6 +29,9 @@ import (
 
 const defaultAddr = "localhost:41222" // d:4 l:12 v:22
 
+// logger writes the delve debug messages to the GoLog buffer.
+var logger = nvimutil.NewLogger("delve")
+
 // Delve represents a delve client.
 type Delve struct {
 	Nvim *nvim.Nvim
@@ -35,13 +39,23 @@ type Delve struct {
 	ctx *ctx.Context
 
 	server     *exec.Cmd
+	exitMu     sync.Mutex
+	exited     <-chan struct{} // closed when the server exits, guarded by exitMu
+	addr       string
+	backend    string // the backend of the server, such as "rr"
 	client     *delverpc2.RPCClient
 	term       *delveterm.Term
 	debugger   *delveterm.Commands
 	processPid int
+	remote     bool // connected to the already running server by DlvAttachRemote
 	serverOut  bytes.Buffer
 	serverErr  bytes.Buffer
 
+	// running is true while the execution command such as the continue runs
+	// the debuggee, which the delve server blocks the other calls.
+	runMu   sync.Mutex
+	running bool
+
 	channelID int
 
 	Locals []delveapi.Variable
@@ -59,8 +73,18 @@ type BufferContext struct {
 
 // SignContext represents a breakpoint and program counter sign.
 type SignContext struct {
-	bpSign map[int]*nvimutil.Sign // map[breakPoint.id]*nvim.Sign
-	pcSign *nvimutil.Sign
+	bpSign  map[int]*nvimutil.Sign // map[breakPoint.id]*nvim.Sign
+	pcSign  *nvimutil.Sign
+	asmSign *nvimutil.Sign // program counter sign of the disassemble buffer
+
+	// bpList is the breakpoints which displayed in the breakpoint list buffer.
+	// bpMu guards the bpList and the pending.
+	bpMu   sync.Mutex
+	bpList []*delveapi.Breakpoint
+
+	// pending is the queued breakpoints which created on the next debug session.
+	pending     []bpLocation
+	pendingSign *nvimutil.Sign
 }
 
 // NewDelve represents a delve client interface.
@@ -74,23 +98,44 @@ func NewDelve(v *nvim.Nvim, ctx *ctx.Context) *Delve {
 // init setup the delve client. Separate the NewDelveClient() function.
 // caused by neovim-go can't call the rpc2.NewClient?
 func (d *Delve) init(v *nvim.Nvim, addr string) error {
-	if !strings.Contains(addr, ":") {
-		addr = "localhost:" + addr
-	}
+	addr = delveAddr(addr)
+	d.addr = addr
 	d.client = delverpc2.NewClient(addr)           // *rpc2.RPCClient
 	d.term = delveterm.New(d.client, nil)          // *terminal.Term
 	d.debugger = delveterm.DebugCommands(d.client) // *terminal.Commands
-	d.processPid = d.client.ProcessPid()           // int
+	err := d.call(func() error {
+		d.processPid = d.client.ProcessPid() // int
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
 	if d.processPid == 0 {
 		return errors.New("Cannot setup delve server")
 	}
 	// avoid setup logs by assigning after server starts up
-	d.server.Stdout = &d.serverOut
-	d.server.Stderr = &d.serverErr
+	if d.server != nil {
+		d.server.Stdout = &d.serverOut
+		d.server.Stderr = &d.serverErr
+	}
 
 	return nil
 }
 
+// setRunning sets whether the execution command runs the debuggee.
+func (d *Delve) setRunning(running bool) {
+	d.runMu.Lock()
+	d.running = running
+	d.runMu.Unlock()
+}
+
+// isRunning reports whether the execution command runs the debuggee.
+func (d *Delve) isRunning() bool {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+	return d.running
+}
+
 // ----------------------------------------------------------------------------
 // start
 
@@ -101,24 +146,47 @@ type delveEval struct {
 }
 
 func (d *Delve) waitServer(addr string) error {
-	d.dialServer(d.Nvim, defaultAddr)
+	if err := d.dialServer(d.Nvim, delveAddr(addr), config.DelveConnectTimeout()); err != nil {
+		return err
+	}
 
 	if err := d.init(d.Nvim, addr); err != nil {
 		return errors.WithStack(err)
 	}
 
 	// TODO(zchee): check whether the exists terminal buffer created by d.createDebugBuffer()
-	return d.printTerminal("", []byte("Type 'help' for list of commands."))
+	if err := d.printTerminal("", []byte("Type 'help' for list of commands.")); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return d.createPending(d.Nvim)
 }
 
 // start starts the dlv debugging.
 func (d *Delve) start(cmd string, cfg Config, eval *delveEval) error {
+	// the server is started by us, so the detach kills the debuggee
+	d.remote = false
 	if err := d.startServer(cmd, cfg); err != nil {
 		return errors.WithStack(err)
 	}
-	defer d.waitServer(cfg.addr)
+	d.backend = cfg.backend
+	if cmd == "replay" {
+		d.backend = "rr"
+	}
+	if err := d.openDebugBuffer(); err != nil {
+		return errors.WithStack(err)
+	}
 
-	return d.openDebugBuffer()
+	return d.waitServer(cfg.addr)
+}
+
+// startAsync starts the dlv debugging in the goroutine, and reports the error.
+func (d *Delve) startAsync(cmd string, cfg Config, eval *delveEval) {
+	go func() {
+		if err := d.start(cmd, cfg, eval); err != nil {
+			nvimutil.ErrorWrap(d.Nvim, err)
+		}
+	}()
 }
 
 // ----------------------------------------------------------------------------
@@ -134,7 +202,7 @@ func (d *Delve) cmdAttach(v *nvim.Nvim, args []string, eval *delveEval) {
 		pid:   int(pid),
 		flags: args[1:],
 	}
-	go d.start("attach", cfg, eval)
+	d.startAsync("attach", cfg, eval)
 }
 
 // ----------------------------------------------------------------------------
@@ -143,15 +211,43 @@ func (d *Delve) cmdAttach(v *nvim.Nvim, args []string, eval *delveEval) {
 // cmdConnect connect to dlv headless server.
 // This command useful for debug the Google Application Engine for Go.
 func (d *Delve) cmdConnect(v *nvim.Nvim, args []string, eval *delveEval) {
-	addr := args[0]
-	if !strings.Contains(addr, ":") {
-		addr = "localhost:" + addr
-	}
 	cfg := Config{
-		addr:  addr,
+		addr:  delveAddr(args[0]),
 		flags: args[1:],
 	}
-	go d.start("connect", cfg, eval)
+	d.startAsync("connect", cfg, eval)
+}
+
+// ----------------------------------------------------------------------------
+// attach remote
+
+// cmdAttachRemote connects to the already running dlv headless server, such
+// as the server in the remote host or the Docker container.
+func (d *Delve) cmdAttachRemote(v *nvim.Nvim, args []string, eval *delveEval) {
+	go func() {
+		if err := d.attachRemote(v, args[0]); err != nil {
+			nvimutil.ErrorWrap(v, err)
+		}
+	}()
+}
+
+// attachRemote connects to the dlv headless server on addr without spawning
+// the server process.
+func (d *Delve) attachRemote(v *nvim.Nvim, addr string) error {
+	addr = delveAddr(addr)
+
+	conn, err := net.DialTimeout("tcp", addr, config.DelveConnectTimeout())
+	if err != nil {
+		return errors.Errorf("nothing is listening on %s: %v", addr, err)
+	}
+	conn.Close()
+
+	d.remote = true
+	if err := d.openDebugBuffer(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return d.waitServer(addr)
 }
 
 // ----------------------------------------------------------------------------
@@ -159,19 +255,33 @@ func (d *Delve) cmdConnect(v *nvim.Nvim, args []string, eval *delveEval) {
 
 func (d *Delve) findRootDir(dir string) string {
 	rootDir := pathutil.FindVCSRoot(dir)
-	srcPath := filepath.Join(build.Default.GOPATH, "src") + string(filepath.Separator)
-	return filepath.Clean(strings.TrimPrefix(rootDir, srcPath))
+	pkgID, err := pathutil.PackageID(rootDir)
+	if err != nil {
+		return rootDir
+	}
+	return pkgID
 }
 
 // cmdDebug setup the debugging.
 // TODO(zchee): If failed debug(build), even create each buffers.
 func (d *Delve) cmdDebug(v *nvim.Nvim, args []string, eval *delveEval) {
-	cfg := Config{
-		path:  d.findRootDir(eval.Dir),
-		addr:  defaultAddr,
-		flags: args,
+	cfg := debugConfig(d.findRootDir(eval.Dir), args)
+	if err := checkBackend(cfg.backend); err != nil {
+		nvimutil.ErrorWrap(v, err)
+		return
+	}
+	d.startAsync("debug", cfg, eval)
+}
+
+// debugConfig returns the server Config of the debug command for the path
+// package with the config.DelveBackend.
+func debugConfig(path string, args []string) Config {
+	return Config{
+		path:    path,
+		addr:    defaultAddr,
+		flags:   args,
+		backend: config.DelveBackend(),
 	}
-	go d.start("debug", cfg, eval)
 }
 
 // ----------------------------------------------------------------------------
@@ -228,30 +338,228 @@ func (d *Delve) breakpoint(v *nvim.Nvim, args []string, eval *breakpointEval) er
 		nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
 
+	if err := d.createBreakpoint(v, bpInfo, filepath.Dir(eval.File)); err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	return nil
+}
+
+// createBreakpoint creates the bpInfo breakpoint, and places the sign.
+func (d *Delve) createBreakpoint(v *nvim.Nvim, bpInfo *delveapi.Breakpoint, dir string) error {
 	if d.bpSign == nil {
 		d.bpSign = make(map[int]*nvimutil.Sign)
 	}
 
-	bp, err := d.client.CreateBreakpoint(bpInfo) // *delveapi.Breakpoint
+	var bp *delveapi.Breakpoint
+	err := d.call(func() (err error) {
+		bp, err = d.client.CreateBreakpoint(bpInfo)
+		return err
+	})
 	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return errors.WithStack(err)
 	}
 
-	d.bpSign[bp.ID], err = nvimutil.NewSign(v, "delve_bp", nvimutil.BreakpointSymbol, "delveBreakpointSign", "") // *nvim.Sign
+	d.bpSign[bp.ID], err = nvimutil.NewSign(v, "delve_bp", signSymbol(config.DelveBreakpointSymbol(), nvimutil.DefaultBreakpointSymbol), "delveBreakpointSign", "") // *nvim.Sign
 	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return errors.WithStack(err)
 	}
 	d.bpSign[bp.ID].Place(v, bp.ID, bp.Line, bp.File, false)
+	d.updateBreakpoints(v, dir)
 
-	filename := pathutil.ShortFilePath(bp.File, filepath.Dir(eval.File))
+	filename := pathutil.ShortFilePath(bp.File, dir)
 	msg := fmt.Sprintf("Breakpoint %d set at %#v for %s() %s:%d", bp.ID, bp.Addr, bp.FunctionName, filename, bp.Line)
-	if err := d.printTerminal("break "+bp.FunctionName, nvimutil.StrToByteSlice(msg)); err != nil {
+	return errors.WithStack(d.printTerminal("break "+bp.FunctionName, nvimutil.StrToByteSlice(msg)))
+}
+
+// updateBreakpoints prints the breakpoints list in the background, and
+// reports the error.
+func (d *Delve) updateBreakpoints(v *nvim.Nvim, dir string) {
+	go func() {
+		if err := d.printBreakpoints(dir); err != nil {
+			d.handleError(v, err)
+		}
+	}()
+}
+
+// jumpBreakpoint jumps to the source location of the breakpoint which
+// displayed at the line of the breakpoint list buffer.
+func (d *Delve) jumpBreakpoint(v *nvim.Nvim, line int) error {
+	d.bpMu.Lock()
+	idx := line - 2 // header line
+	if idx < 0 || idx >= len(d.bpList) {
+		d.bpMu.Unlock()
+		return nil
+	}
+	bp := d.bpList[idx]
+	d.bpMu.Unlock()
+
+	// the file may contain the special characters of Ex commands, such as space
+	var file string
+	if err := v.Call("fnameescape", &file, bp.File); err != nil {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+	batch := v.NewBatch()
+	batch.SetCurrentWindow(d.cw)
+	batch.Command(fmt.Sprintf("keepjumps edit %s", file))
+	batch.SetWindowCursor(d.cw, [2]int{bp.Line, 0})
+	batch.Command("normal! zz")
+	if err := batch.Execute(); err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
 
 	return nil
 }
 
+// ----------------------------------------------------------------------------
+// toggle breakpoint
+
+// toggleBreakpointEval represent a toggle breakpoint commands Eval args.
+type toggleBreakpointEval struct {
+	File string `msgpack:",array"`
+	Line int
+}
+
+func (d *Delve) cmdToggleBreakpoint(v *nvim.Nvim, eval *toggleBreakpointEval) {
+	go func() {
+		if err := d.toggleBreakpoint(v, eval); err != nil {
+			nvimutil.ErrorWrap(v, err)
+		}
+	}()
+}
+
+// bpLocation represents a file and line location of the breakpoint.
+type bpLocation struct {
+	file string
+	line int
+}
+
+// toggleLocation removes loc from locs if exists, otherwise appends it.
+// The added reports whether loc is appended.
+func toggleLocation(locs []bpLocation, loc bpLocation) (_ []bpLocation, added bool) {
+	for i, l := range locs {
+		if l == loc {
+			return append(locs[:i:i], locs[i+1:]...), false
+		}
+	}
+	return append(locs, loc), true
+}
+
+// findBreakpoint returns the breakpoint which at the file and line, or nil.
+func findBreakpoint(bps []*delveapi.Breakpoint, file string, line int) *delveapi.Breakpoint {
+	for _, bp := range bps {
+		if bp.File == file && bp.Line == line {
+			return bp
+		}
+	}
+	return nil
+}
+
+// pendingSignID returns the sign ID of the queued breakpoint at line.
+// It is offset so as not to conflict with the delve breakpoint IDs.
+func pendingSignID(line int) int { return 1000000 + line }
+
+// toggleBreakpoint clears the breakpoint on the cursor line if it exists,
+// otherwise creates it.
+// If the debug session is not active, it queues the breakpoint to be created
+// on the next debug session.
+func (d *Delve) toggleBreakpoint(v *nvim.Nvim, eval *toggleBreakpointEval) error {
+	loc := bpLocation{file: eval.File, line: eval.Line}
+
+	if d.client == nil || d.processPid == 0 {
+		return d.togglePending(v, loc)
+	}
+
+	var bps []*delveapi.Breakpoint
+	err := d.call(func() (err error) {
+		bps, err = d.client.ListBreakpoints()
+		return err
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bp := findBreakpoint(bps, loc.file, loc.line)
+	if bp == nil {
+		return d.createBreakpoint(v, &delveapi.Breakpoint{File: loc.file, Line: loc.line}, filepath.Dir(loc.file))
+	}
+
+	err = d.call(func() error {
+		_, err := d.client.ClearBreakpoint(bp.ID)
+		return err
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if sign, ok := d.bpSign[bp.ID]; ok {
+		sign.Unplace(v, bp.ID, bp.File)
+		delete(d.bpSign, bp.ID)
+	}
+	d.updateBreakpoints(v, filepath.Dir(loc.file))
+
+	filename := pathutil.ShortFilePath(bp.File, filepath.Dir(loc.file))
+	msg := fmt.Sprintf("Breakpoint %d cleared at %#v for %s() %s:%d", bp.ID, bp.Addr, bp.FunctionName, filename, bp.Line)
+	return errors.WithStack(d.printTerminal(fmt.Sprintf("clear %d", bp.ID), nvimutil.StrToByteSlice(msg)))
+}
+
+// togglePending toggles the queued breakpoint at loc, and updates the sign.
+func (d *Delve) togglePending(v *nvim.Nvim, loc bpLocation) error {
+	if d.pendingSign == nil {
+		sign, err := nvimutil.NewSign(v, "delve_bp_pending", signSymbol(config.DelveBreakpointSymbol(), nvimutil.DefaultBreakpointSymbol), "delveBreakpointSign", "")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		d.pendingSign = sign
+	}
+
+	d.bpMu.Lock()
+	var added bool
+	d.pending, added = toggleLocation(d.pending, loc)
+	d.bpMu.Unlock()
+	if !added {
+		if err := d.pendingSign.Unplace(v, pendingSignID(loc.line), loc.file); err != nil {
+			return errors.WithStack(err)
+		}
+		return nvimutil.Info(v, "Delve", "breakpoint unqueued at %s:%d", filepath.Base(loc.file), loc.line)
+	}
+
+	if err := d.pendingSign.Place(v, pendingSignID(loc.line), loc.line, loc.file, false); err != nil {
+		return errors.WithStack(err)
+	}
+	return nvimutil.Info(v, "Delve", "breakpoint queued at %s:%d", filepath.Base(loc.file), loc.line)
+}
+
+// createPending creates the queued breakpoints on the started debug session.
+// The failed breakpoints remain queued, and the errors of them are returned.
+func (d *Delve) createPending(v *nvim.Nvim) error {
+	d.bpMu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.bpMu.Unlock()
+
+	var (
+		failed []bpLocation
+		msgs   []string
+	)
+	for _, loc := range pending {
+		if err := d.createBreakpoint(v, &delveapi.Breakpoint{File: loc.file, Line: loc.line}, filepath.Dir(loc.file)); err != nil {
+			failed = append(failed, loc)
+			msgs = append(msgs, fmt.Sprintf("%s:%d: %v", filepath.Base(loc.file), loc.line, err))
+			continue
+		}
+		if d.pendingSign != nil {
+			d.pendingSign.Unplace(v, pendingSignID(loc.line), loc.file)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	d.bpMu.Lock()
+	d.pending = append(failed, d.pending...)
+	d.bpMu.Unlock()
+	return errors.Errorf("couldn't create the queued breakpoints: %s", strings.Join(msgs, "; "))
+}
+
 // ----------------------------------------------------------------------------
 // continue
 
@@ -268,38 +576,21 @@ func (d *Delve) cmdContinue(v *nvim.Nvim, args []string, eval *continueEval) {
 // sign marker to current stopping position.
 // Note that 'continue' name is reverved Go language spec.
 func (d *Delve) cont(v *nvim.Nvim, args []string, eval *continueEval) error {
-	stateCh := d.client.Continue()
-	state := <-stateCh
+	d.setRunning(true)
+	state, err := waitState(d.client.Continue(), d.exitedChan())
+	d.setRunning(false)
+	if err != nil {
+		return d.handleError(v, err)
+	}
 	if err := d.printServerStderr(); err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
-	if state == nil || state.Exited {
+	if state.Exited {
 		return nvimutil.ErrorWrap(v, errors.WithStack(state.Err))
 	}
 
 	cThread := state.CurrentThread
-
-	go func() {
-		goroutines, err := d.client.ListGoroutines()
-		if err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
-		}
-		d.printContext(eval.Dir, cThread, goroutines)
-	}()
-
-	go d.pcSign.Place(v, cThread.ID, cThread.Line, cThread.File, true)
-
-	go func() {
-		if err := v.SetWindowCursor(d.cw, [2]int{cThread.Line, 0}); err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
-		}
-		if err := v.Command("silent normal zz"); err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
-		}
-	}()
+	d.showThread(v, eval.Dir, cThread)
 
 	var msg []byte
 	if hitCount, ok := cThread.Breakpoint.HitCount[strconv.Itoa(cThread.GoroutineID)]; ok {
@@ -324,6 +615,38 @@ func (d *Delve) cont(v *nvim.Nvim, args []string, eval *continueEval) error {
 	return d.printTerminal("continue", msg)
 }
 
+// showThread updates the context and breakpoint buffers, and moves the
+// program counter sign and the cursor to the stopped position of cThread in
+// the background.
+func (d *Delve) showThread(v *nvim.Nvim, dir string, cThread *delveapi.Thread) {
+	go func() {
+		var goroutines []*delveapi.Goroutine
+		err := d.call(func() (err error) {
+			goroutines, err = d.client.ListGoroutines()
+			return err
+		})
+		if err != nil {
+			d.handleError(v, err)
+			return
+		}
+		d.printContext(dir, cThread, goroutines)
+	}()
+
+	d.updateBreakpoints(v, dir)
+	go d.pcSign.Update(v, cThread.ID, cThread.Line, cThread.File)
+
+	go func() {
+		if err := v.SetWindowCursor(d.cw, [2]int{cThread.Line, 0}); err != nil {
+			nvimutil.ErrorWrap(v, errors.WithStack(err))
+			return
+		}
+		if err := v.Command("silent normal zz"); err != nil {
+			nvimutil.ErrorWrap(v, errors.WithStack(err))
+			return
+		}
+	}()
+}
+
 // ----------------------------------------------------------------------------
 // next
 
@@ -339,7 +662,16 @@ func (d *Delve) cmdNext(v *nvim.Nvim, eval *nextEval) {
 // next sends the 'next' signals to the delve headless server, and update sign
 // marker to current stopping position.
 func (d *Delve) next(v *nvim.Nvim, eval *nextEval) error {
-	state, err := d.client.Next()
+	var state *delveapi.DebuggerState
+	d.setRunning(true)
+	err := d.call(func() (err error) {
+		state, err = d.client.Next()
+		return err
+	})
+	d.setRunning(false)
+	if isServerDown(err) {
+		return d.handleError(v, err)
+	}
 	// prints server stderr before the prints the error messages
 	if err := d.printServerStderr(); err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
@@ -350,28 +682,7 @@ func (d *Delve) next(v *nvim.Nvim, eval *nextEval) error {
 	}
 
 	cThread := state.CurrentThread
-
-	go func() {
-		goroutines, err := d.client.ListGoroutines()
-		if err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
-		}
-		d.printContext(eval.Dir, cThread, goroutines)
-	}()
-
-	go d.pcSign.Place(v, cThread.ID, cThread.Line, cThread.File, true)
-
-	go func() {
-		if err := v.SetWindowCursor(d.cw, [2]int{cThread.Line, 0}); err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
-		}
-		if err := v.Command("silent normal zz"); err != nil {
-			nvimutil.ErrorWrap(v, errors.WithStack(err))
-			return
-		}
-	}()
+	d.showThread(v, eval.Dir, cThread)
 
 	msg := []byte(
 		fmt.Sprintf("> %s() %s:%d goroutine(%d) (PC: %d)",
@@ -391,14 +702,24 @@ func (d *Delve) cmdRestart(v *nvim.Nvim) {
 }
 
 func (d *Delve) restart(v *nvim.Nvim) error {
-	discarded, err := d.client.Restart()
+	var discarded []delveapi.DiscardedBreakpoint
+	err := d.call(func() (err error) {
+		discarded, err = d.client.Restart()
+		return err
+	})
 	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return d.handleError(v, err)
 	}
 
 	var buf bytes.Buffer
 
-	d.processPid = d.client.ProcessPid()
+	err = d.call(func() error {
+		d.processPid = d.client.ProcessPid()
+		return nil
+	})
+	if err != nil {
+		return d.handleError(v, err)
+	}
 	buf.WriteString(fmt.Sprintf("Process restarted with PID %d\n", d.processPid))
 
 	for i := range discarded {
@@ -416,11 +737,15 @@ func (d *Delve) cmdState(v *nvim.Nvim) {
 }
 
 func (d *Delve) state(v *nvim.Nvim) error {
-	state, err := d.client.GetState()
+	var state *delveapi.DebuggerState
+	err := d.call(func() (err error) {
+		state, err = d.client.GetState()
+		return err
+	})
 	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		return d.handleError(v, err)
 	}
-	printDebug("state: %+v\n", state)
+	printDebug("state", state)
 	return nil
 }
 
@@ -444,42 +769,285 @@ func (d *Delve) stdin(v *nvim.Nvim) error {
 		return nil
 	}
 
-	// Create the connected pair of *os.Files and replace os.Stdout.
-	// delve terminal package return to stdout only.
-	r, w, _ := os.Pipe() // *os.File
-	saveStdout := os.Stdout
-	os.Stdout = w
+	return d.command(v, stdin.(string))
+}
+
+func (d *Delve) cmdCommand(v *nvim.Nvim, args []string) {
+	go d.command(v, strings.Join(args, " "))
+}
 
-	cmd := strings.SplitN(stdin.(string), " ", 2)
-	var args string
-	if len(cmd) == 2 {
-		args = cmd[1]
+// command runs the line command such as "print i" on the internal delve
+// terminal, and prints the output to the terminal buffer.
+func (d *Delve) command(v *nvim.Nvim, line string) error {
+	if d.debugger == nil {
+		return nvimutil.ErrorWrap(v, errors.New("delve is not running"))
 	}
 
-	err = d.debugger.Call(cmd[0]+args, d.term)
+	// the line may be the execution command such as the step
+	d.setRunning(true)
+	// the execution command waits for the breakpoint, so it doesn't time out
+	out, err := captureStdout(func() error {
+		return callTimeout(func() error {
+			return d.debugger.Call(line, d.term)
+		}, d.exitedChan(), 0)
+	})
+	d.setRunning(false)
 	if err != nil {
 		return nvimutil.ErrorWrap(v, errors.WithStack(err))
 	}
 
-	// Close the w file and restore os.Stdout to original.
-	w.Close()
-	os.Stdout = saveStdout
+	return d.printTerminal(line, out)
+}
+
+// delveCommands is the commands of the internal delve terminal.
+var delveCommands = []string{
+	"args", "break", "breakpoints", "clear", "clearall", "condition", "continue",
+	"disassemble", "exit", "frame", "funcs", "goroutine", "goroutines", "help",
+	"list", "locals", "next", "on", "print", "regs", "restart", "set", "source",
+	"sources", "stack", "step", "step-instruction", "stepout", "thread",
+	"threads", "trace", "types", "vars",
+}
+
+// commandCompletion completes the delve commands of DlvCommand, and the
+// variable names in the current scope for the print and set commands.
+func (d *Delve) commandCompletion(v *nvim.Nvim, a *nvim.CommandCompletionArgs) ([]string, error) {
+	return completeCommand(a, d.scopeVariables), nil
+}
+
+// completeCommand returns the completion candidates of the a args. The
+// variables returns the variable names in the current scope.
+func completeCommand(a *nvim.CommandCompletionArgs, variables func() []string) []string {
+	line := a.CmdLine
+	if pos := a.CursorPos(); pos > 0 && pos < len(line) {
+		line = line[:pos]
+	}
+	// the first field is the DlvCommand itself
+	args := strings.Fields(line)[1:]
+	if a.ArgLead != "" && len(args) > 0 {
+		args = args[:len(args)-1]
+	}
+
+	var candidates []string
+	switch {
+	case len(args) == 0:
+		candidates = delveCommands
+	case len(args) == 1 && (args[0] == "print" || args[0] == "p" || args[0] == "set"):
+		candidates = variables()
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, a.ArgLead) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// scopeVariables returns the local variable and the function argument names
+// in the current frame. Returns nil without any call if the process is not
+// stopped, because the completion blocks the Neovim.
+func (d *Delve) scopeVariables() []string {
+	if d.client == nil || d.processPid == 0 || d.isRunning() {
+		return nil
+	}
 
-	// Read all the lines of r file.
-	out, err := ioutil.ReadAll(r)
+	var names []string
+	err := d.call(func() error {
+		state, err := d.client.GetState()
+		if err != nil || state.Exited || state.CurrentThread == nil {
+			return err
+		}
+		scope := delveapi.EvalScope{GoroutineID: state.CurrentThread.GoroutineID}
+		locals, err := d.client.ListLocalVariables(scope, delveapi.LoadConfig{})
+		if err != nil {
+			return err
+		}
+		args, err := d.client.ListFunctionArgs(scope, delveapi.LoadConfig{})
+		if err != nil {
+			return err
+		}
+		for _, v := range append(locals, args...) {
+			names = append(names, v.Name)
+		}
+		return nil
+	})
 	if err != nil {
-		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+		logger.Printf("couldn't list the variables: %v", err)
+		return nil
 	}
+	sort.Strings(names)
+	return names
+}
+
+// ----------------------------------------------------------------------------
+// disassemble
 
-	return d.printTerminal(stdin.(string), out)
+func (d *Delve) cmdDisassemble(v *nvim.Nvim, args []string, eval *disassembleEval) {
+	go func() {
+		if err := d.disassemble(v, args, eval); err != nil {
+			nvimutil.ErrorWrap(v, err)
+		}
+	}()
+}
+
+// disassembleEval represent a disassemble commands Eval args.
+type disassembleEval struct {
+	Dir string `msgpack:",array"`
+}
+
+// disassemble disassembles the function containing the current PC into the
+// disassemble buffer, and places the sign on the current instruction.
+// The args[0] is the assembly flavor, the default is config.DelveAsmFlavor().
+func (d *Delve) disassemble(v *nvim.Nvim, args []string, eval *disassembleEval) error {
+	if d.client == nil {
+		return errors.New("delve is not running")
+	}
+
+	flavorName := config.DelveAsmFlavor()
+	if len(args) > 0 {
+		flavorName = args[0]
+	}
+	flavor, err := asmFlavor(flavorName)
+	if err != nil {
+		return err
+	}
+
+	var state *delveapi.DebuggerState
+	err = d.call(func() (err error) {
+		state, err = d.client.GetState()
+		return err
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if state.Exited || state.CurrentThread == nil {
+		return nvimutil.EchohlAfter(v, "DlvDisassemble", "WarningMsg", "process is not stopped")
+	}
+
+	cThread := state.CurrentThread
+	scope := delveapi.EvalScope{GoroutineID: cThread.GoroutineID}
+	var insts delveapi.AsmInstructions
+	err = d.call(func() (err error) {
+		insts, err = d.client.DisassemblePC(scope, cThread.PC, flavor)
+		return err
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines, pcLine := formatDisassemble(insts, eval.Dir)
+	return d.printDisassemble(v, lines, pcLine)
+}
+
+// asmFlavor converts the name to the delve assembly flavor.
+func asmFlavor(name string) (delveapi.AssemblyFlavour, error) {
+	switch strings.ToLower(name) {
+	case "", "gnu":
+		return delveapi.GNUFlavour, nil
+	case "intel":
+		return delveapi.IntelFlavour, nil
+	default:
+		return 0, errors.Errorf("unknown assembly flavor: %s", name)
+	}
+}
+
+// formatDisassemble formats the instructions to the disassemble buffer lines,
+// and returns the 1-based line number of the current PC instruction, or 0 if
+// not found.
+func formatDisassemble(insts delveapi.AsmInstructions, cwd string) ([][]byte, int) {
+	var (
+		lines    [][]byte
+		pcLine   int
+		lastFile string
+		lastLine int
+	)
+	for _, inst := range insts {
+		if inst.Loc.File != lastFile || inst.Loc.Line != lastLine {
+			lines = append(lines, []byte(fmt.Sprintf("%s:%d", pathutil.ShortFilePath(inst.Loc.File, cwd), inst.Loc.Line)))
+			lastFile, lastLine = inst.Loc.File, inst.Loc.Line
+		}
+
+		var mark string
+		switch {
+		case inst.AtPC:
+			mark = "=>"
+		case inst.Breakpoint:
+			mark = "*"
+		}
+		text := inst.Text
+		if inst.DestLoc != nil && inst.DestLoc.Function != nil {
+			text += " " + inst.DestLoc.Function.Name
+		}
+		lines = append(lines, []byte(fmt.Sprintf("%2s\t%#x\t%s", mark, inst.Loc.PC, text)))
+		if inst.AtPC {
+			pcLine = len(lines)
+		}
+	}
+
+	return lines, pcLine
+}
+
+// printDisassemble prints the disassembled lines to the disassemble buffer,
+// and jumps the cursor to the pcLine instruction.
+func (d *Delve) printDisassemble(v *nvim.Nvim, lines [][]byte, pcLine int) error {
+	buf, ok := d.buffers[Disassemble]
+	if !ok {
+		option := d.setBufferOption()
+		option[nvimutil.BufferOption][nvimutil.BufOptionFiletype] = nvimutil.FiletypeAsm
+		buf = nvimutil.NewBuffer(v)
+		buf.Reuse = true
+		if _, err := buf.Create(string(Disassemble), nvimutil.FiletypeAsm, "silent belowright vsplit", option); err != nil {
+			return errors.WithStack(err)
+		}
+		if d.buffers == nil {
+			d.buffers = make(map[nvimutil.BufferName]*nvimutil.Buffer)
+		}
+		d.buffers[Disassemble] = buf
+		defer v.SetCurrentWindow(d.cw)
+	}
+
+	v.SetBufferOption(buf.Buffer(), nvimutil.BufOptionModifiable, true)
+	defer v.SetBufferOption(buf.Buffer(), nvimutil.BufOptionModifiable, false)
+	if err := v.SetBufferLines(buf.Buffer(), 0, -1, true, lines); err != nil {
+		return errors.WithStack(err)
+	}
+	if pcLine == 0 {
+		return nil
+	}
+
+	if d.asmSign == nil {
+		sign, err := nvimutil.NewSign(v, "delve_asm_pc", signSymbol(config.DelvePCSymbol(), nvimutil.DefaultProgramCounterSymbol), "delvePCSign", "delvePCLine")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		d.asmSign = sign
+	}
+	if err := d.asmSign.Update(v, int(buf.Buffer()), pcLine, string(Disassemble)); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return v.SetWindowCursor(buf.Window, [2]int{pcLine, 0})
 }
 
 // ----------------------------------------------------------------------------
 // command-line completion
 
+// asmFlavorCompletion returns the assembly flavors of DlvDisassemble.
+func (d *Delve) asmFlavorCompletion(v *nvim.Nvim) ([]string, error) {
+	return []string{"gnu", "intel"}, nil
+}
+
 // FunctionsCompletion return the debug target functions with filtering "main".
 func (d *Delve) FunctionsCompletion(v *nvim.Nvim) ([]string, error) {
-	funcs, err := d.client.ListFunctions("main")
+	if d.client == nil {
+		return []string{}, nil
+	}
+	var funcs []string
+	err := d.call(func() (err error) {
+		funcs, err = d.client.ListFunctions("main")
+		return err
+	})
 	if err != nil {
 		return []string{}, errors.WithStack(err)
 	}
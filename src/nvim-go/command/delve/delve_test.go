@@ -0,0 +1,364 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestToggleLocation(t *testing.T) {
+	foo10 := bpLocation{file: "/go/src/foo/foo.go", line: 10}
+	foo20 := bpLocation{file: "/go/src/foo/foo.go", line: 20}
+	bar10 := bpLocation{file: "/go/src/foo/bar.go", line: 10}
+
+	tests := []struct {
+		name      string
+		locs      []bpLocation
+		loc       bpLocation
+		want      []bpLocation
+		wantAdded bool
+	}{
+		{
+			name:      "toggle on empty",
+			locs:      nil,
+			loc:       foo10,
+			want:      []bpLocation{foo10},
+			wantAdded: true,
+		},
+		{
+			name:      "toggle on other line",
+			locs:      []bpLocation{foo10, bar10},
+			loc:       foo20,
+			want:      []bpLocation{foo10, bar10, foo20},
+			wantAdded: true,
+		},
+		{
+			name:      "toggle off",
+			locs:      []bpLocation{foo10, foo20, bar10},
+			loc:       foo20,
+			want:      []bpLocation{foo10, bar10},
+			wantAdded: false,
+		},
+		{
+			name:      "toggle off last",
+			locs:      []bpLocation{foo10},
+			loc:       foo10,
+			want:      []bpLocation{},
+			wantAdded: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, added := toggleLocation(tt.locs, tt.loc)
+			if !reflect.DeepEqual(got, tt.want) || added != tt.wantAdded {
+				t.Errorf("%q. toggleLocation(%v, %v) = %v, %v, want %v, %v", tt.name, tt.locs, tt.loc, got, added, tt.want, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func TestFindBreakpoint(t *testing.T) {
+	bps := []*delveapi.Breakpoint{
+		{ID: -1, FunctionName: "runtime.startpanic"},
+		{ID: 1, File: "/go/src/foo/foo.go", Line: 10},
+		{ID: 2, File: "/go/src/foo/bar.go", Line: 10},
+	}
+
+	tests := []struct {
+		name   string
+		file   string
+		line   int
+		wantID int // 0 is not found
+	}{
+		{name: "toggle off existing", file: "/go/src/foo/foo.go", line: 10, wantID: 1},
+		{name: "same line other file", file: "/go/src/foo/bar.go", line: 10, wantID: 2},
+		{name: "toggle on new line", file: "/go/src/foo/foo.go", line: 11, wantID: 0},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotID int
+			if bp := findBreakpoint(bps, tt.file, tt.line); bp != nil {
+				gotID = bp.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("%q. findBreakpoint(%v, %v) = %v, want %v", tt.name, tt.file, tt.line, gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestFormatBreakpoints(t *testing.T) {
+	bps := userBreakpoints([]*delveapi.Breakpoint{
+		{ID: 2, FunctionName: "main.foo", File: "/go/src/foo/foo.go", Line: 20, Cond: "i > 1", TotalHitCount: 3, HitCount: map[string]uint64{"1": 2, "18": 1}},
+		{ID: -1, FunctionName: "runtime.startpanic"},
+		{ID: 1, FunctionName: "main.main", File: "/go/src/foo/main.go", Line: 10},
+	})
+
+	want := [][]byte{
+		[]byte("Breakpoints"),
+		[]byte("\t1\tmain.main()\t./main.go:10\thits: 0"),
+		[]byte("\t2\tmain.foo()\t./foo.go:20\tcond: i > 1\thits: 3 (goroutine(1):2 goroutine(18):1)"),
+	}
+	if got := formatBreakpoints(bps, "/go/src/foo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("formatBreakpoints() = %q, want %q", got, want)
+	}
+}
+
+func TestCreateBuffers(t *testing.T) {
+	saved := newBuffer
+	defer func() { newBuffer = saved }()
+	var modes []string
+	newBuffer = func(n *nvim.Nvim, name nvimutil.BufferName, mode string) (*nvimutil.Buffer, error) {
+		modes = append(modes, string(name)+": "+mode)
+		return &nvimutil.Buffer{Name: string(name)}, nil
+	}
+
+	tests := []struct {
+		name   string
+		layout []string
+		want   []string
+	}{
+		{
+			name:   "default",
+			layout: config.Default().Delve.Layout,
+			want: []string{
+				"terminal: silent belowright 80 vsplit",
+				"context: silent belowright 40 split",
+				"thread: silent belowright 12 split",
+				"breakpoint: silent belowright 12 split",
+			},
+		},
+		{
+			// with the source buffer, the minimal layout has exactly two buffers
+			name:   "source and terminal",
+			layout: []string{"terminal:split:25%"},
+			want:   []string{"terminal: silent belowright 15 split"},
+		},
+		{
+			name:   "horizontal",
+			layout: []string{"context:vsplit:50%", "terminal:split:10"},
+			want: []string{
+				"context: silent belowright 100 vsplit",
+				"terminal: silent belowright 10 split",
+			},
+		},
+	}
+	for _, tt := range tests {
+		modes = nil
+		panels, err := parseLayout(tt.layout)
+		if err != nil {
+			t.Fatalf("%q. parseLayout(%v) error = %v", tt.name, tt.layout, err)
+		}
+		buffers, err := createBuffers(nil, panels, 200, 60)
+		if err != nil {
+			t.Fatalf("%q. createBuffers(%v) error = %v", tt.name, tt.layout, err)
+		}
+		if !reflect.DeepEqual(modes, tt.want) || len(buffers) != len(tt.want) {
+			t.Errorf("%q. createBuffers(%v) = %v, %d buffers, want %v", tt.name, tt.layout, modes, len(buffers), tt.want)
+		}
+	}
+}
+
+func TestParseLayoutError(t *testing.T) {
+	tests := [][]string{
+		{"context:split:2/3"},                        // no terminal
+		{"terminal:split"},                           // no size
+		{"terminal:split:1/3", "locals:split:1/3"},   // unknown panel
+		{"terminal:split:1/3", "terminal:split:1/3"}, // duplicated
+		{"terminal:hsplit:1/3"},                      // unknown split
+		{"terminal:split:3/2"},                       // larger than the source
+		{"terminal:split:0"},
+	}
+	for _, layout := range tests {
+		if _, err := parseLayout(layout); err == nil {
+			t.Errorf("parseLayout(%v) error = nil, want the invalid layout error", layout)
+		}
+	}
+}
+
+func TestCompleteCommand(t *testing.T) {
+	if !sort.StringsAreSorted(delveCommands) {
+		t.Errorf("delveCommands = %v, want sorted", delveCommands)
+	}
+	variables := func() []string { return []string{"err", "i", "items"} }
+
+	tests := []struct {
+		cmdline string
+		lead    string
+		want    []string
+	}{
+		{cmdline: "DlvCommand ", want: delveCommands},
+		{cmdline: "DlvCommand go", lead: "go", want: []string{"goroutine", "goroutines"}},
+		{cmdline: "DlvCommand st", lead: "st", want: []string{"stack", "step", "step-instruction", "stepout"}},
+		{cmdline: "DlvCommand print i", lead: "i", want: []string{"i", "items"}},
+		{cmdline: "DlvCommand p ", want: []string{"err", "i", "items"}},
+		{cmdline: "DlvCommand set e", lead: "e", want: []string{"err"}},
+		{cmdline: "DlvCommand break ", want: nil},
+		{cmdline: "DlvCommand print i ", want: nil},
+	}
+	for _, tt := range tests {
+		a := &nvim.CommandCompletionArgs{ArgLead: tt.lead, CmdLine: tt.cmdline, CursorPosString: strconv.Itoa(len(tt.cmdline))}
+		if got := completeCommand(a, variables); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completeCommand(%q) = %v, want %v", tt.cmdline, got, tt.want)
+		}
+	}
+
+	// no completion of the variables if the process is not stopped
+	a := &nvim.CommandCompletionArgs{CmdLine: "DlvCommand print "}
+	if got := completeCommand(a, func() []string { return nil }); got != nil {
+		t.Errorf("completeCommand(%q) not stopped = %v, want nil", a.CmdLine, got)
+	}
+}
+
+func TestCheckBackend(t *testing.T) {
+	for _, backend := range []string{"", "default", "rr"} {
+		var want error
+		if backend == "rr" && runtime.GOOS != "linux" {
+			want = errNotLinux
+		}
+		if got := checkBackend(backend); got != want {
+			t.Errorf("checkBackend(%q) on %s = %v, want %v", backend, runtime.GOOS, got, want)
+		}
+	}
+}
+
+func TestServerArgs(t *testing.T) {
+	defer config.Set(config.Update(func(cfg *config.Config) { cfg.Delve.Backend = "rr" }))
+
+	got := serverArgs("debug", debugConfig("github.com/foo/bar", []string{"--build-flags=-race"}))
+	want := []string{"debug", "github.com/foo/bar", "--headless", "--listen=" + defaultAddr, "--accept-multiclient", "--api-version=2", "--log", "--backend=rr", "--build-flags=-race"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("serverArgs(debug) with the rr backend = %v, want %v", got, want)
+	}
+
+	config.Update(func(cfg *config.Config) { cfg.Delve.Backend = "default" })
+	got = serverArgs("debug", debugConfig("github.com/foo/bar", nil))
+	for _, arg := range got {
+		if strings.HasPrefix(arg, "--backend") {
+			t.Errorf("serverArgs(debug) with the default backend = %v, want no --backend flag", got)
+		}
+	}
+
+	got = serverArgs("replay", Config{path: "/tmp/rr/foo-0", addr: defaultAddr})
+	if len(got) < 2 || got[0] != "replay" || got[1] != "/tmp/rr/foo-0" {
+		t.Errorf("serverArgs(replay) = %v, want the trace directory", got)
+	}
+	if got := serverArgs("exec", Config{}); got != nil {
+		t.Errorf("serverArgs(exec) = %v, want nil", got)
+	}
+}
+
+func TestFormatDisassemble(t *testing.T) {
+	// asm returns the main.main instructions in the flavor text, such as the
+	// "go tool objdump" output of
+	//
+	// 	main.go:5	0x1050e20	mov %fs:0xfffffff8,%rcx
+	// 	main.go:5	0x1050e29	cmp 0x10(%rcx),%rsp
+	// 	main.go:6	0x1050e2d	callq main.foo(SB)
+	asm := func(text ...string) delveapi.AsmInstructions {
+		foo := &delveapi.Location{PC: 0x1050e00, Function: &delveapi.Function{Name: "main.foo"}}
+		return delveapi.AsmInstructions{
+			{Loc: delveapi.Location{PC: 0x1050e20, File: "/go/src/foo/main.go", Line: 5}, Text: text[0]},
+			{Loc: delveapi.Location{PC: 0x1050e29, File: "/go/src/foo/main.go", Line: 5}, Text: text[1], Breakpoint: true},
+			{Loc: delveapi.Location{PC: 0x1050e2d, File: "/go/src/foo/main.go", Line: 6}, Text: text[2], DestLoc: foo, AtPC: true},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		flavor     string
+		insts      delveapi.AsmInstructions
+		wantFlavor delveapi.AssemblyFlavour
+		wantErr    bool
+		want       []string
+		wantPCLine int
+	}{
+		{
+			name:       "gnu",
+			flavor:     "gnu",
+			insts:      asm("mov %fs:0xfffffff8,%rcx", "cmp 0x10(%rcx),%rsp", "callq 0x1050e00"),
+			wantFlavor: delveapi.GNUFlavour,
+			want: []string{
+				"./main.go:5",
+				"  \t0x1050e20\tmov %fs:0xfffffff8,%rcx",
+				" *\t0x1050e29\tcmp 0x10(%rcx),%rsp",
+				"./main.go:6",
+				"=>\t0x1050e2d\tcallq 0x1050e00 main.foo",
+			},
+			wantPCLine: 5,
+		},
+		{
+			name:       "intel",
+			flavor:     "Intel",
+			insts:      asm("mov rcx, qword ptr fs:[0xfffffff8]", "cmp rsp, qword ptr [rcx+0x10]", "call 0x1050e00"),
+			wantFlavor: delveapi.IntelFlavour,
+			want: []string{
+				"./main.go:5",
+				"  \t0x1050e20\tmov rcx, qword ptr fs:[0xfffffff8]",
+				" *\t0x1050e29\tcmp rsp, qword ptr [rcx+0x10]",
+				"./main.go:6",
+				"=>\t0x1050e2d\tcall 0x1050e00 main.foo",
+			},
+			wantPCLine: 5,
+		},
+		{
+			name:       "default",
+			flavor:     "",
+			insts:      asm("mov %fs:0xfffffff8,%rcx", "cmp 0x10(%rcx),%rsp", "callq 0x1050e00")[:2],
+			wantFlavor: delveapi.GNUFlavour,
+			want: []string{
+				"./main.go:5",
+				"  \t0x1050e20\tmov %fs:0xfffffff8,%rcx",
+				" *\t0x1050e29\tcmp 0x10(%rcx),%rsp",
+			},
+			wantPCLine: 0,
+		},
+		{
+			name:    "unknown flavor",
+			flavor:  "plan9",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		flavor, err := asmFlavor(tt.flavor)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. asmFlavor(%q) error = %v, wantErr %v", tt.name, tt.flavor, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if flavor != tt.wantFlavor {
+			t.Errorf("%q. asmFlavor(%q) = %v, want %v", tt.name, tt.flavor, flavor, tt.wantFlavor)
+		}
+
+		lines, pcLine := formatDisassemble(tt.insts, "/go/src/foo")
+		got := make([]string, len(lines))
+		for i, l := range lines {
+			got[i] = string(l)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. formatDisassemble() = %q, want %q", tt.name, got, tt.want)
+		}
+		if pcLine != tt.wantPCLine {
+			t.Errorf("%q. formatDisassemble() pcLine = %d, want %d", tt.name, pcLine, tt.wantPCLine)
+		}
+	}
+}
@@ -5,7 +5,6 @@
 package delve
 
 import (
-	"log"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -18,25 +17,67 @@ func (d *Delve) cmdDetach(v *nvim.Nvim) {
 
 func (d *Delve) detach(v *nvim.Nvim) error {
 	defer d.kill()
-	if d.processPid != 0 {
-		err := d.client.Detach(true)
+	defer d.clearSigns(v)
+	if d.client != nil && d.processPid != 0 {
+		// don't kill the process of the remote server which is not started by us
+		err := d.call(func() error { return d.client.Detach(!d.remote) })
 		if err != nil {
 			return nvimutil.ErrorWrap(d.Nvim, errors.WithStack(err))
 		}
-		log.Printf("Detached delve client\n")
+		logger.Printf("detached delve client")
 	}
 
 	return nil
 }
 
-func (d *Delve) kill() error {
-	if d.server != nil {
-		err := d.server.Process.Kill()
-		if err != nil {
-			return errors.WithStack(err)
+func (d *Delve) cmdKill(v *nvim.Nvim) {
+	go func() {
+		d.clearSigns(v)
+		if err := d.kill(); err != nil {
+			nvimutil.ErrorWrap(v, err)
 		}
-		log.Printf("Killed delve server\n")
+	}()
+}
+
+// clearSigns unplaces the all of placed breakpoint and program counter signs,
+// and resets the signs of the session. The debug session doesn't change the
+// options of the source window, so there is nothing to restore.
+func (d *Delve) clearSigns(v *nvim.Nvim) {
+	if d.pcSign != nil {
+		d.pcSign.Clear(v)
+	}
+	if d.asmSign != nil {
+		d.asmSign.Clear(v)
+	}
+	for _, sign := range d.bpSign {
+		sign.Clear(v)
+	}
+
+	d.pcSign = nil
+	d.asmSign = nil
+	d.bpSign = nil
+	d.bpMu.Lock()
+	d.bpList = nil
+	d.bpMu.Unlock()
+}
+
+// kill kills the delve server which started by us, and ends the session. It
+// doesn't use the client, so it works even if the session has no client.
+func (d *Delve) kill() error {
+	d.processPid = 0
+	d.setExited(nil)
+	d.remote = false
+	d.backend = ""
+	if d.server == nil {
+		return nil
+	}
+
+	server := d.server
+	d.server = nil
+	if err := server.Process.Kill(); err != nil {
+		return errors.WithStack(err)
 	}
+	logger.Printf("killed delve server")
 
 	return nil
 }
@@ -0,0 +1,67 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nvim-go/nvimutil"
+)
+
+func TestDetachClearSigns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-delve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(file, []byte("package main\n\nfunc main() {\n\tprintln()\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n := nvimutil.TestNvim(t, file)
+	// the session without the client, such as the server failed to start
+	d := NewDelve(n, nil)
+	d.pcSign, err = nvimutil.NewSign(n, "delve_pc", nvimutil.DefaultProgramCounterSymbol, "delvePCSign", "delvePCLine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.pcSign.Update(n, 1, 4, file); err != nil {
+		t.Fatal(err)
+	}
+	bp, err := nvimutil.NewSign(n, "delve_bp", nvimutil.DefaultBreakpointSymbol, "delveBreakpointSign", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bp.Place(n, 2, 3, file, false); err != nil {
+		t.Fatal(err)
+	}
+	d.bpSign = map[int]*nvimutil.Sign{2: bp}
+
+	if err := d.detach(n); err != nil {
+		t.Fatalf("detach() error = %v", err)
+	}
+
+	var placed []struct {
+		Signs []struct {
+			ID int `msgpack:"id"`
+		} `msgpack:"signs"`
+	}
+	if err := n.Call("sign_getplaced", &placed, file); err != nil {
+		t.Fatal(err)
+	}
+	if len(placed) != 1 || len(placed[0].Signs) != 0 {
+		t.Errorf("sign_getplaced(%q) after detach = %v, want no signs", file, placed)
+	}
+	if d.pcSign != nil || d.bpSign != nil {
+		t.Errorf("signs after detach = %v, %v, want reset", d.pcSign, d.bpSign)
+	}
+	if err := d.kill(); err != nil {
+		t.Errorf("kill() without the session error = %v", err)
+	}
+}
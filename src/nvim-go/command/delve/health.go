@@ -0,0 +1,127 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+	"os/exec"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errServerExited is the error of the dlv server process exited during the session.
+	errServerExited = errors.New("dlv server exited")
+	// errNoResponse is the error of the dlv server doesn't respond within the config.DelveRPCTimeout.
+	errNoResponse = errors.New("dlv server is not responding")
+)
+
+// watchServer closes the returned channel when the started dlv server exits.
+func watchServer(server *exec.Cmd) <-chan struct{} {
+	exited := make(chan struct{})
+	go func() {
+		server.Wait()
+		close(exited)
+	}()
+	return exited
+}
+
+// call runs the blocking client call fn with the config.DelveRPCTimeout.
+func (d *Delve) call(fn func() error) error {
+	return callTimeout(fn, d.exitedChan(), config.DelveRPCTimeout())
+}
+
+// exitedChan returns the channel which is closed when the server exits.
+func (d *Delve) exitedChan() <-chan struct{} {
+	d.exitMu.Lock()
+	defer d.exitMu.Unlock()
+	return d.exited
+}
+
+// setExited sets the channel which is closed when the server exits.
+func (d *Delve) setExited(exited <-chan struct{}) {
+	d.exitMu.Lock()
+	d.exited = exited
+	d.exitMu.Unlock()
+}
+
+// callTimeout runs fn, and returns the error of fn. It returns errServerExited
+// if exited is closed, or errNoResponse if fn doesn't return within timeout.
+// The fn goroutine is abandoned in that case. The zero timeout never expires,
+// such as the call which waits for the breakpoint.
+func callTimeout(fn func() error, exited <-chan struct{}, timeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- fn()
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case err := <-errc:
+		return err
+	case <-exited:
+		return errServerExited
+	case <-expired:
+		return errNoResponse
+	}
+}
+
+// waitState waits for the state of the continue command from ch. It doesn't
+// time out since the continue waits for the breakpoint, but returns
+// errServerExited if exited is closed, and the RPC error of the state.
+func waitState(ch <-chan *delveapi.DebuggerState, exited <-chan struct{}) (*delveapi.DebuggerState, error) {
+	select {
+	case state, ok := <-ch:
+		if !ok || state == nil {
+			return nil, rpc.ErrShutdown
+		}
+		if state.Err != nil && !state.Exited {
+			return nil, state.Err
+		}
+		return state, nil
+	case <-exited:
+		return nil, errServerExited
+	}
+}
+
+// isServerDown reports whether the err is the lost connection to the dlv server.
+func isServerDown(err error) bool {
+	switch errors.Cause(err) {
+	case errServerExited, errNoResponse, rpc.ErrShutdown, io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+	_, ok := errors.Cause(err).(*net.OpError)
+	return ok
+}
+
+// handleError reports the err of the client call. If the dlv server is down,
+// it also tears down the session so that the signs and the server don't remain.
+func (d *Delve) handleError(v *nvim.Nvim, err error) error {
+	if !isServerDown(err) {
+		return nvimutil.ErrorWrap(v, errors.WithStack(err))
+	}
+
+	logger.Printf("delve server is down: %v", err)
+	if _, ok := d.buffers[Terminal]; ok {
+		d.printTerminal("", []byte("delve server is down: "+err.Error()))
+	}
+	d.clearSigns(v)
+	d.kill()
+
+	return nvimutil.ErrorWrap(v, errors.Wrap(err, "delve server is down"))
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	delverpc2 "github.com/derekparker/delve/service/rpc2"
+)
+
+func TestWaitStateClosedConn(t *testing.T) {
+	// the dlv server which crashes as soon as the client connects
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	client := delverpc2.NewClient(ln.Addr().String())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := waitState(client.Continue(), nil)
+		errc <- err
+	}()
+
+	select {
+	case err := <-errc:
+		if err == nil || !isServerDown(err) {
+			t.Errorf("waitState() error = %v, want the lost connection error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitState() blocks on the closed connection")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	exited := make(chan struct{})
+	close(exited)
+
+	tests := []struct {
+		name   string
+		fn     func() error
+		exited <-chan struct{}
+		want   error
+	}{
+		{name: "return", fn: func() error { return nil }, want: nil},
+		{name: "no response", fn: func() error { <-block; return nil }, want: errNoResponse},
+		{name: "server exited", fn: func() error { <-block; return nil }, exited: exited, want: errServerExited},
+	}
+	for _, tt := range tests {
+		if err := callTimeout(tt.fn, tt.exited, 10*time.Millisecond); err != tt.want {
+			t.Errorf("%q. callTimeout() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -8,10 +8,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"nvim-go/internal/pathutil"
 	"nvim-go/nvimutil"
 	"sort"
+	"strings"
 
 	delveapi "github.com/derekparker/delve/service/api"
 	"github.com/neovim/go-client/nvim"
@@ -24,18 +24,18 @@ func (d *Delve) printTerminal(cmd string, message []byte) error {
 	d.Nvim.SetBufferOption(d.buffers[Terminal].Buffer(), "modifiable", true)
 	defer d.Nvim.SetBufferOption(d.buffers[Terminal].Buffer(), "modifiable", false)
 
-	lcount, err := d.Nvim.BufferLineCount(d.buffers[Terminal].Buffer())
+	lcount, err := nvimutil.LineCount(d.Nvim, d.buffers[Terminal].Buffer())
 	if err != nil {
-		return errors.WithStack(err)
-	}
-	if lcount == 1 {
-		lcount = 0
+		return err
 	}
 
 	var msg []byte
 	if cmd != "" {
 		msg = []byte("(dlv) " + cmd + "\n")
-		lcount--
+		// overwrite the last "(dlv)" prompt line
+		if lcount > 0 {
+			lcount--
+		}
 	}
 	msg = append(msg, bytes.TrimSpace(message)...)
 	if len(message) != 0 {
@@ -69,6 +69,9 @@ func (d *Delve) printServerStderr() error {
 // context
 
 func (d *Delve) printContext(cwd string, cThread *delveapi.Thread, goroutines []*delveapi.Goroutine) error {
+	if _, ok := d.buffers[Context]; !ok {
+		return nil
+	}
 	d.Nvim.SetBufferOption(d.buffers[Context].Buffer(), "modifiable", true)
 	defer d.Nvim.SetBufferOption(d.buffers[Context].Buffer(), "modifiable", false)
 
@@ -120,7 +123,11 @@ func (d *Delve) printStacktrace(cwd string, currentFunc *delveapi.Function, goro
 
 		// Appends the stacktrace from each threads goroutine if valid goroutine ID.
 		if g.ID != 0 {
-			stacks, err := d.client.Stacktrace(g.ID, goroutineDepth, &delveapi.LoadConfig{FollowPointers: true}) // []delveapi.Stackframe
+			var stacks []delveapi.Stackframe
+			err := d.call(func() (err error) {
+				stacks, err = d.client.Stacktrace(g.ID, goroutineDepth, &delveapi.LoadConfig{FollowPointers: true})
+				return err
+			})
 			if err != nil {
 				return end, errors.WithStack(err)
 			}
@@ -186,6 +193,9 @@ func (d *Delve) printLocals(cwd string, locals []delveapi.Variable, stackHeight
 }
 
 func (d *Delve) printThread(v *nvim.Nvim, cwd string, threads []*delveapi.Thread) error {
+	if _, ok := d.buffers[Context]; !ok {
+		return nil
+	}
 	v.SetBufferOption(d.buffers[Context].Buffer(), "modifiable", true)
 	defer v.SetBufferOption(d.buffers[Context].Buffer(), "modifiable", false)
 
@@ -196,15 +206,96 @@ func (d *Delve) printThread(v *nvim.Nvim, cwd string, threads []*delveapi.Thread
 	return nil
 }
 
+// ----------------------------------------------------------------------------
+// breakpoints
+
+// byBreakpointID sorts the []*delveapi.Breakpoint slice by breakpoint ID.
+type byBreakpointID []*delveapi.Breakpoint
+
+func (a byBreakpointID) Len() int           { return len(a) }
+func (a byBreakpointID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byBreakpointID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+
+// printBreakpoints prints the breakpoints list with the hit counts to the
+// breakpoint buffer. Only the changed lines are updated.
+func (d *Delve) printBreakpoints(cwd string) error {
+	buf, ok := d.buffers[Breakpoints]
+	if !ok {
+		return nil
+	}
+
+	var bps []*delveapi.Breakpoint
+	err := d.call(func() (err error) {
+		bps, err = d.client.ListBreakpoints()
+		return err
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bps = userBreakpoints(bps)
+
+	d.bpMu.Lock()
+	d.bpList = bps
+	d.bpMu.Unlock()
+
+	d.Nvim.SetBufferOption(buf.Buffer(), "modifiable", true)
+	defer d.Nvim.SetBufferOption(buf.Buffer(), "modifiable", false)
+
+	return buf.WriteDiff(formatBreakpoints(bps, cwd))
+}
+
+// userBreakpoints returns the breakpoints sorted by ID, without the internal
+// breakpoints such as the unrecovered-panic that has a negative ID.
+func userBreakpoints(bps []*delveapi.Breakpoint) []*delveapi.Breakpoint {
+	var user []*delveapi.Breakpoint
+	for _, bp := range bps {
+		if bp.ID > 0 {
+			user = append(user, bp)
+		}
+	}
+	sort.Sort(byBreakpointID(user))
+	return user
+}
+
+// formatBreakpoints formats the breakpoint buffer lines of bps.
+func formatBreakpoints(bps []*delveapi.Breakpoint, cwd string) [][]byte {
+	lines := [][]byte{[]byte("Breakpoints")}
+	for _, bp := range bps {
+		line := fmt.Sprintf("\t%d\t%s()\t%s:%d", bp.ID, bp.FunctionName, pathutil.ShortFilePath(bp.File, cwd), bp.Line)
+		if bp.Cond != "" {
+			line += fmt.Sprintf("\tcond: %s", bp.Cond)
+		}
+		line += fmt.Sprintf("\thits: %d", bp.TotalHitCount)
+
+		if len(bp.HitCount) > 0 {
+			gids := make([]string, 0, len(bp.HitCount))
+			for gid := range bp.HitCount {
+				gids = append(gids, gid)
+			}
+			sort.Strings(gids)
+			hits := make([]string, len(gids))
+			for i, gid := range gids {
+				hits[i] = fmt.Sprintf("goroutine(%s):%d", gid, bp.HitCount[gid])
+			}
+			line += " (" + strings.Join(hits, " ") + ")"
+		}
+		lines = append(lines, []byte(line))
+	}
+
+	return lines
+}
+
 // ----------------------------------------------------------------------------
 // for debugging
 
+// printDebug logs the prefix and the JSON dump of data to the GoLog buffer.
 func printDebug(prefix string, data interface{}) error {
 	d, err := json.MarshalIndent(data, "", "    ")
 	if err != nil {
-		log.Printf("PrintDebug: %s\n%s", prefix, data)
+		logger.Debugf("%s\n%+v", prefix, data)
+		return nil
 	}
-	log.Printf("PrintDebug: %s\n%s", prefix, d)
+	logger.Debugf("%s\n%s", prefix, d)
 
 	return nil
 }
@@ -18,9 +18,18 @@ func Register(p *plugin.Plugin, ctx *ctx.Context) {
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvDebug", NArgs: "*", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdDebug)
 	// Connect connect to a headless debug server.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvConnect", NArgs: "*", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdConnect)
+	// AttachRemote connect to an already running headless debug server without starting it.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvAttachRemote", NArgs: "1", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdAttachRemote)
+
+	// Record compile and begin debugging program with recording by the rr backend.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvRecord", NArgs: "*", Eval: "[getcwd(), expand('%:p:h')]"}, d.cmdRecord)
+	// Replay replays the trace directory which recorded by rr.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvReplay", NArgs: "+", Eval: "[getcwd(), expand('%:p:h')]", Complete: "dir"}, d.cmdReplay)
 
 	// Breakpoint sets a breakpoint.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvBreakpoint", NArgs: "*", Eval: "[expand('%:p')]", Complete: "customlist,FunctionsCompletion"}, d.cmdBreakpoint)
+	// ToggleBreakpoint sets or clears a breakpoint on the cursor line.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvToggleBreakpoint", Eval: "[expand('%:p'), line('.')]"}, d.cmdToggleBreakpoint)
 
 	// Stepping execution control
 	// Continue run until breakpoint or program termination.
@@ -28,19 +37,38 @@ func Register(p *plugin.Plugin, ctx *ctx.Context) {
 	// Next step over to next source line.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvNext", Eval: "[expand('%:p:h')]"}, d.cmdNext)
 
+	// Set sets the variable to the value in the current scope.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvSet", NArgs: "1", Eval: "[expand('%:p:h')]"}, d.cmdSet)
+
+	// Reverse execution control of the rr backend
+	// Rewind run backwards until breakpoint or start of recorded history.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvRewind", Eval: "[expand('%:p:h')]"}, d.cmdRewind)
+	// ReverseNext step backwards to previous source line.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvReverseNext", Eval: "[expand('%:p:h')]"}, d.cmdReverseNext)
+
+	// Disassemble disassembler for the current function.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvDisassemble", NArgs: "?", Eval: "[expand('%:p:h')]", Complete: "customlist,DlvAsmFlavorCompletion"}, d.cmdDisassemble)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DlvAsmFlavorCompletion"}, d.asmFlavorCompletion)
+
 	// restart restart the process.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvRestart"}, d.cmdRestart) // Restart process.
 
 	// stdin interactive mode
 	// TODO(zchee): Support contextual command completion
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvStdin"}, d.cmdStdin)
+	// Command runs the delve terminal command such as "print i".
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvCommand", NArgs: "+", Complete: "customlist,DlvCommandCompletion"}, d.cmdCommand)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DlvCommandCompletion"}, d.commandCompletion)
 	// RPC export
 	p.Handle("DlvStdin", d.stdin)
+	p.Handle("DlvJumpBreakpoint", d.jumpBreakpoint)
 	// FunctionsCompletion list of functions for command completion.
 	p.HandleFunction(&plugin.FunctionOptions{Name: "FunctionsCompletion"}, d.FunctionsCompletion)
 
 	// detach exit the debugger.
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvDetach"}, d.cmdDetach)
+	// kill kills the debug server without detaching the client.
+	p.HandleCommand(&plugin.CommandOptions{Name: "DlvKill"}, d.cmdKill)
 
 	// State (WIP: for debug)
 	p.HandleCommand(&plugin.CommandOptions{Name: "DlvState"}, d.cmdState)
@@ -0,0 +1,151 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"fmt"
+	"net/rpc/jsonrpc"
+	"path/filepath"
+	"runtime"
+
+	"nvim-go/config"
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	delverpc2 "github.com/derekparker/delve/service/rpc2"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// The reverse execution commands of the rr backend. The vendored delve API
+// doesn't define them.
+const (
+	rewind      = "rewind"
+	reverseNext = "reverseNext"
+)
+
+// errNotLinux is the error of the rr backend on the other than Linux.
+var errNotLinux = errors.New("the rr backend is only available on Linux")
+
+// checkBackend returns errNotLinux if the backend is rr on the other than Linux.
+func checkBackend(backend string) error {
+	if backend == "rr" && runtime.GOOS != "linux" {
+		return errNotLinux
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// record
+
+// cmdRecord records the execution of the program by the rr backend, and
+// begins debugging it.
+func (d *Delve) cmdRecord(v *nvim.Nvim, args []string, eval *delveEval) {
+	cfg := debugConfig(d.findRootDir(eval.Dir), args)
+	cfg.backend = "rr"
+	if err := checkBackend(cfg.backend); err != nil {
+		nvimutil.ErrorWrap(v, err)
+		return
+	}
+	d.startAsync("debug", cfg, eval)
+}
+
+// ----------------------------------------------------------------------------
+// replay
+
+// cmdReplay replays the trace directory which recorded by rr. The relative
+// trace directory is based on the current working directory.
+func (d *Delve) cmdReplay(v *nvim.Nvim, args []string, eval *delveEval) {
+	if err := checkBackend("rr"); err != nil {
+		nvimutil.ErrorWrap(v, err)
+		return
+	}
+	trace := args[0]
+	if !filepath.IsAbs(trace) {
+		trace = filepath.Join(eval.Cwd, trace)
+	}
+	cfg := Config{
+		path:  trace,
+		addr:  defaultAddr,
+		flags: args[1:],
+	}
+	d.startAsync("replay", cfg, eval)
+}
+
+// ----------------------------------------------------------------------------
+// reverse execution
+
+// reverseEval represent a reverse execution commands Eval args.
+type reverseEval struct {
+	Dir string `msgpack:",array"`
+}
+
+func (d *Delve) cmdRewind(v *nvim.Nvim, eval *reverseEval) {
+	go d.reverse(v, rewind, eval)
+}
+
+func (d *Delve) cmdReverseNext(v *nvim.Nvim, eval *reverseEval) {
+	go d.reverse(v, reverseNext, eval)
+}
+
+// reverse runs the name reverse execution command, and updates the sign
+// marker to the stopped position.
+func (d *Delve) reverse(v *nvim.Nvim, name string, eval *reverseEval) error {
+	if d.client == nil || d.processPid == 0 {
+		return nvimutil.ErrorWrap(v, errors.New("delve is not running"))
+	}
+	if d.backend != "rr" {
+		return nvimutil.ErrorWrap(v, errors.Errorf("%s requires the rr backend, use DlvRecord or DlvReplay", name))
+	}
+
+	// the rewind runs back to the previous breakpoint like the continue, so it doesn't time out
+	timeout := config.DelveRPCTimeout()
+	if name == rewind {
+		timeout = 0
+	}
+	var state *delveapi.DebuggerState
+	d.setRunning(true)
+	err := callTimeout(func() (err error) {
+		state, err = reverseCommand(d.addr, name)
+		return err
+	}, d.exitedChan(), timeout)
+	d.setRunning(false)
+	if err != nil {
+		return d.handleError(v, err)
+	}
+	if state.Exited || state.CurrentThread == nil {
+		return nvimutil.ErrorWrap(v, errors.Errorf("%s: process is not stopped", name))
+	}
+
+	cThread := state.CurrentThread
+	d.showThread(v, eval.Dir, cThread)
+
+	msg := []byte(
+		fmt.Sprintf("> %s() %s:%d goroutine(%d) (PC: %#v)",
+			cThread.Function.Name,
+			pathutil.ShortFilePath(cThread.File, eval.Dir),
+			cThread.Line,
+			cThread.GoroutineID,
+			cThread.PC))
+	return d.printTerminal(name, msg)
+}
+
+// reverseCommand calls the name command of the dlv server on addr. The
+// vendored client doesn't have the reverse execution APIs, so it calls the
+// Command method of the server directly.
+func reverseCommand(addr, name string) (*delveapi.DebuggerState, error) {
+	client, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer client.Close()
+
+	var out delverpc2.CommandOut
+	if err := client.Call("RPCServer.Command", delveapi.DebuggerCommand{Name: name}, &out); err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
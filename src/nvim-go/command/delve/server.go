@@ -7,7 +7,10 @@ package delve
 import (
 	"net"
 	"os/exec"
+	"strings"
+	"time"
 
+	"nvim-go/internal/tools"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -16,28 +19,50 @@ import (
 
 // Config represents a delve headless servers config.
 type Config struct {
-	addr  string
-	flags []string
-	path  string
-	pid   int
+	addr    string
+	flags   []string
+	path    string
+	pid     int
+	backend string // the --backend flag value, such as "rr"
 }
 
 // startServer starts the delve headless server and replace server Stdout & Stderr.
 func (d *Delve) startServer(cmd string, cfg Config) error {
-	dlv, err := exec.LookPath("dlv")
+	dlv, err := tools.Require("Dlv"+strings.ToUpper(cmd[:1])+cmd[1:], "dlv")
 	if err != nil {
+		return err
+	}
+
+	args := serverArgs(cmd, cfg)
+	if args == nil {
+		return errors.Errorf("dlv %s is not supported", cmd)
+	}
+	d.server = exec.Command(dlv, args...)
+
+	if err := d.server.Start(); err != nil {
+		err = errors.New(d.serverOut.String())
+		d.serverOut.Reset()
 		return errors.WithStack(err)
 	}
+	d.setExited(watchServer(d.server))
+
+	return nil
+}
 
+// serverArgs returns the dlv arguments of the cmd command, or nil if cmd is
+// not supported.
+func serverArgs(cmd string, cfg Config) []string {
+	var args []string
 	switch cmd {
 	case "attach":
 		// TODO(zchee): implements
 	case "connect":
 		// connect command must be addr to the second argument
-		d.server = exec.Command(dlv, cmd, cfg.addr, "--log")
-	case "debug":
-		// debug command must be package path to the second argument, and need "--accept-multiclient" flag
-		d.server = exec.Command(dlv, cmd, cfg.path, "--headless", "--listen="+cfg.addr, "--accept-multiclient", "--api-version=2", "--log")
+		args = []string{cmd, cfg.addr, "--log"}
+	case "debug", "replay":
+		// debug command must be package path, and replay command must be the
+		// trace directory to the second argument, and need "--accept-multiclient" flag
+		args = []string{cmd, cfg.path, "--headless", "--listen=" + cfg.addr, "--accept-multiclient", "--api-version=2", "--log"}
 	case "exec":
 		// TODO(zchee): implements
 	case "test":
@@ -45,33 +70,42 @@ func (d *Delve) startServer(cmd string, cfg Config) error {
 	case "trace":
 		// TODO(zchee): implements
 	}
+	if args == nil {
+		return nil
+	}
+	if cfg.backend != "" && cfg.backend != "default" {
+		args = append(args, "--backend="+cfg.backend)
+	}
 	// append other flags such as build flags
-	d.server.Args = append(d.server.Args, cfg.flags...)
+	return append(args, cfg.flags...)
+}
 
-	if err := d.server.Start(); err != nil {
-		err = errors.New(d.serverOut.String())
-		d.serverOut.Reset()
-		return errors.WithStack(err)
+// delveAddr returns the addr with "localhost" host if addr is port only.
+func delveAddr(addr string) string {
+	if !strings.Contains(addr, ":") {
+		return "localhost:" + addr
 	}
-
-	return nil
+	return addr
 }
 
-// dialServer dial the dlv launch the headless server.
-// `net.Dial` is better way?
-// http://stackoverflow.com/a/30838807/5228839
-func (d *Delve) dialServer(v *nvim.Nvim, addr string) error {
-	defer nvimutil.EchohlAfter(v, "Delve", nvimutil.ProgressColor, "Ready")
+// dialInterval is the interval of polling the dlv headless server.
+const dialInterval = 100 * time.Millisecond
+
+// dialServer waits for the dlv headless server listening on addr, and returns
+// the error if the server is not up until the timeout.
+func (d *Delve) dialServer(v *nvim.Nvim, addr string, timeout time.Duration) error {
 	nvimutil.EchoProgress(v, "Delve", "Wait for running dlv server")
 
+	deadline := time.Now().Add(timeout)
 	for {
-		conn, err := net.Dial("tcp", addr)
-		if err != nil {
-			continue
+		conn, err := net.DialTimeout("tcp", addr, dialInterval)
+		if err == nil {
+			conn.Close()
+			return nvimutil.EchohlAfter(v, "Delve", nvimutil.ProgressColor, "Ready")
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("couldn't connect to the dlv server on %s within %s: %v", addr, timeout, err)
 		}
-		defer conn.Close()
-		break
+		time.Sleep(dialInterval)
 	}
-
-	return nil
 }
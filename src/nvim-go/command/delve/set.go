@@ -0,0 +1,164 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"go/ast"
+	"go/parser"
+	"strings"
+
+	"nvim-go/nvimutil"
+
+	delveapi "github.com/derekparker/delve/service/api"
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// setEval represent a DlvSet command Eval args.
+type setEval struct {
+	Dir string `msgpack:",array"`
+}
+
+func (d *Delve) cmdSet(v *nvim.Nvim, args []string, eval *setEval) {
+	go d.set(v, args[0], eval)
+}
+
+// variableSetter represents the delve client which sets the variable.
+type variableSetter interface {
+	SetVariable(scope delveapi.EvalScope, symbol, value string) error
+}
+
+// set sets the variable of the "{expr} = {value}" arg in the current frame
+// of the stopped goroutine, and refreshes the local variables.
+func (d *Delve) set(v *nvim.Nvim, arg string, eval *setEval) error {
+	if d.client == nil || d.processPid == 0 {
+		return nvimutil.ErrorWrap(v, errors.New("delve is not running"))
+	}
+	expr, value, err := parseSet(arg)
+	if err != nil {
+		return nvimutil.ErrorWrap(v, err)
+	}
+
+	var state *delveapi.DebuggerState
+	err = d.call(func() (err error) {
+		state, err = d.client.GetState()
+		return err
+	})
+	if err != nil {
+		return d.handleError(v, err)
+	}
+	if state.Exited || state.CurrentThread == nil {
+		return nvimutil.EchohlAfter(v, "DlvSet", "WarningMsg", "process is not stopped")
+	}
+	cThread := state.CurrentThread
+
+	err = d.call(func() error {
+		return setVariable(d.client, cThread.GoroutineID, expr, value)
+	})
+	if err != nil {
+		return d.handleError(v, err)
+	}
+
+	go func() {
+		var goroutines []*delveapi.Goroutine
+		err := d.call(func() (err error) {
+			goroutines, err = d.client.ListGoroutines()
+			return err
+		})
+		if err != nil {
+			d.handleError(v, err)
+			return
+		}
+		d.printContext(eval.Dir, cThread, goroutines)
+	}()
+
+	return d.printTerminal("set "+expr+" = "+value, nil)
+}
+
+// setVariable sets the expr variable to value in the current frame of the
+// goroutineID goroutine.
+func setVariable(c variableSetter, goroutineID int, expr, value string) error {
+	scope := delveapi.EvalScope{GoroutineID: goroutineID}
+	return errors.WithStack(c.SetVariable(scope, expr, value))
+}
+
+// parseSet parses the "{expr} = {value}" arg of DlvSet. The expr must be the
+// variable, such as the struct field "s.f" and the slice element "s[1]".
+// The arg is split on the first "=" outside the quotes and the brackets, and
+// the whitespaces in the value are kept as is.
+func parseSet(arg string) (expr, value string, err error) {
+	i := assignIndex(arg)
+	if i < 0 {
+		return "", "", errors.New("usage: DlvSet {expr} = {value}")
+	}
+	expr, value = strings.TrimSpace(arg[:i]), strings.TrimSpace(arg[i+1:])
+	if expr == "" || value == "" {
+		return "", "", errors.New("usage: DlvSet {expr} = {value}")
+	}
+
+	x, err := parser.ParseExpr(expr)
+	if err != nil {
+		return "", "", errors.Errorf("invalid expression %q: %v", expr, err)
+	}
+	if !isVariable(x) {
+		return "", "", errors.Errorf("cannot assign to %s", expr)
+	}
+	if _, err := parser.ParseExpr(value); err != nil {
+		return "", "", errors.Errorf("invalid value %q: %v", value, err)
+	}
+
+	return expr, value, nil
+}
+
+// assignIndex returns the index of the first "=" in s which is not in the
+// quotes nor the brackets, or -1 if s has no such "=".
+func assignIndex(s string) int {
+	var (
+		quote byte
+		depth int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			switch {
+			case c == '\\' && quote != '`':
+				i++ // skip the escaped character
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '=':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isVariable reports whether the x is the assignable variable expression.
+func isVariable(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x.Name != "_"
+	case *ast.SelectorExpr:
+		return isVariable(x.X)
+	case *ast.IndexExpr:
+		return isVariable(x.X)
+	case *ast.StarExpr:
+		return isVariable(x.X)
+	case *ast.ParenExpr:
+		return isVariable(x.X)
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	delveapi "github.com/derekparker/delve/service/api"
+)
+
+// fakeSetter is a mock of the delve client which records the SetVariable call.
+type fakeSetter struct {
+	scope         delveapi.EvalScope
+	symbol, value string
+	err           error
+}
+
+func (f *fakeSetter) SetVariable(scope delveapi.EvalScope, symbol, value string) error {
+	f.scope, f.symbol, f.value = scope, symbol, value
+	return f.err
+}
+
+func TestSetVariable(t *testing.T) {
+	tests := []struct {
+		args       string
+		wantSymbol string
+		wantValue  string
+	}{
+		{args: "i = 10", wantSymbol: "i", wantValue: "10"},
+		{args: "t.Name = \"a = b\"", wantSymbol: "t.Name", wantValue: "\"a = b\""},
+		{args: "s[2]=3", wantSymbol: "s[2]", wantValue: "3"},
+		{args: "p.m[\"k\"].f = true", wantSymbol: "p.m[\"k\"].f", wantValue: "true"},
+		{args: "*p = -1", wantSymbol: "*p", wantValue: "-1"},
+		{args: "m[\"a=b\"] = 1", wantSymbol: "m[\"a=b\"]", wantValue: "1"},
+		{args: "m[k == 1] = 2", wantSymbol: "m[k == 1]", wantValue: "2"},
+		{args: "s = \"a  \\\"=\\\"  b\"", wantSymbol: "s", wantValue: "\"a  \\\"=\\\"  b\""},
+		{args: "b = i == 1", wantSymbol: "b", wantValue: "i == 1"},
+	}
+	for _, tt := range tests {
+		expr, value, err := parseSet(tt.args)
+		if err != nil {
+			t.Errorf("parseSet(%q) error = %v", tt.args, err)
+			continue
+		}
+		c := new(fakeSetter)
+		if err := setVariable(c, 18, expr, value); err != nil {
+			t.Errorf("setVariable(%q) error = %v", tt.args, err)
+		}
+		if c.scope != (delveapi.EvalScope{GoroutineID: 18}) || c.symbol != tt.wantSymbol || c.value != tt.wantValue {
+			t.Errorf("setVariable(%q) called SetVariable(%+v, %q, %q), want ({GoroutineID:18}, %q, %q)", tt.args, c.scope, c.symbol, c.value, tt.wantSymbol, tt.wantValue)
+		}
+	}
+
+	c := &fakeSetter{err: errors.New("could not find symbol value for j")}
+	if err := setVariable(c, 1, "j", "1"); err == nil || !strings.Contains(err.Error(), "could not find symbol") {
+		t.Errorf("setVariable(j) error = %v, want the delve error", err)
+	}
+}
+
+func TestParseSetError(t *testing.T) {
+	tests := []string{
+		"i",
+		"= 1",
+		"i =",
+		"1 = 2",
+		"f() = 2",
+		"_ = 2",
+		"s[ = 1",
+		"i = )",
+		"s[\"=\"]",
+	}
+	for _, args := range tests {
+		if _, _, err := parseSet(args); err == nil {
+			t.Errorf("parseSet(%q) error = nil, want the invalid args error", args)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"io/ioutil"
+	"os"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+// captureStdout calls f with os.Stdout replaced by the pipe, and returns the
+// output written to it. The delve terminal package prints to os.Stdout only.
+//
+// os.Stdout is always restored and the pipe is closed even if f returns the
+// error or panics. The pipe is read in the goroutine, so f doesn't block when
+// the output exceeds the pipe buffer.
+//
+// The swap is serialized by the nvimutil.StdioMu with the other commands which
+// swap os.Stdout. The commands such as GoBuild and GoTest write the output to
+// their own exec.Cmd pipes and buffers, not to os.Stdout, so they are not
+// captured.
+func captureStdout(f func() error) ([]byte, error) {
+	nvimutil.StdioMu.Lock()
+	defer nvimutil.StdioMu.Unlock()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	outCh := make(chan []byte, 1)
+	go func() {
+		out, _ := ioutil.ReadAll(r)
+		r.Close()
+		outCh <- out
+	}()
+
+	saveStdout := os.Stdout
+	os.Stdout = w
+	func() {
+		defer func() {
+			os.Stdout = saveStdout
+			w.Close()
+		}()
+		err = f()
+	}()
+
+	return <-outCh, err
+}
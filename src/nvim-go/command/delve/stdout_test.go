@@ -0,0 +1,153 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delve
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nvim-go/nvimutil"
+)
+
+func TestCaptureStdout(t *testing.T) {
+	saveStdout := os.Stdout
+	large := bytes.Repeat([]byte("x"), 1<<20) // exceeds the pipe buffer
+
+	tests := []struct {
+		name    string
+		f       func() error
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "success",
+			f: func() error {
+				fmt.Print("(dlv) next")
+				return nil
+			},
+			want:    []byte("(dlv) next"),
+			wantErr: false,
+		},
+		{
+			name: "failing debugger call",
+			f: func() error {
+				fmt.Print("partial output")
+				return errors.New("Command failed: not running")
+			},
+			want:    []byte("partial output"),
+			wantErr: true,
+		},
+		{
+			name: "large output",
+			f: func() error {
+				_, err := os.Stdout.Write(large)
+				return err
+			},
+			want:    large,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		got, err := captureStdout(tt.f)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. captureStdout() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%q. captureStdout() = %d bytes, want %d bytes", tt.name, len(got), len(tt.want))
+		}
+		if os.Stdout != saveStdout {
+			t.Fatalf("%q. captureStdout() did not restore os.Stdout", tt.name)
+		}
+	}
+}
+
+// swapStdout swaps os.Stdout under the nvimutil.StdioMu like the GoGenerateTest
+// and the GoRename, and returns the output written by f.
+func swapStdout(f func()) ([]byte, error) {
+	nvimutil.StdioMu.Lock()
+	defer nvimutil.StdioMu.Unlock()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	saveStdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = saveStdout
+	w.Close()
+
+	out, err := ioutil.ReadAll(r)
+	r.Close()
+	return out, err
+}
+
+func TestCaptureStdout_Concurrent(t *testing.T) {
+	const n = 20
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		got = make(map[string]string) // the output of each capture
+	)
+	capture := func(want string, swap func(string) ([]byte, error)) {
+		defer wg.Done()
+		out, err := swap(want)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		got[want] = string(out)
+		mu.Unlock()
+	}
+	// write each byte separately to interleave with the other goroutines
+	writeBytes := func(s string) {
+		for _, c := range s {
+			fmt.Print(string(c))
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+
+		// delve terminal command
+		go capture(fmt.Sprintf("dlv-%d\n", i), func(want string) ([]byte, error) {
+			return captureStdout(func() error {
+				writeBytes(want)
+				return nil
+			})
+		})
+
+		// the other command which swaps os.Stdout, such as the GoGenerateTest
+		go capture(fmt.Sprintf("gen-%d\n", i), func(want string) ([]byte, error) {
+			return swapStdout(func() { writeBytes(want) })
+		})
+	}
+	wg.Wait()
+
+	for want, out := range got {
+		if out != want {
+			t.Errorf("capture of %q = %q, want exactly its own output", want, out)
+		}
+		// none of the other captures saw this output
+		for other, otherOut := range got {
+			if other != want && strings.Contains(otherOut, want) {
+				t.Errorf("capture of %q = %q, contains the output of %q", other, otherOut, want)
+			}
+		}
+	}
+	if len(got) != n*2 {
+		t.Errorf("captured %d outputs, want %d", len(got), n*2)
+	}
+}
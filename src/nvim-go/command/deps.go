@@ -0,0 +1,377 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgDeps = "GoDeps"
+
+// depsBufferName is the buffer name of the GoDeps sidebar.
+const depsBufferName = "__GoDeps__"
+
+// depsNamespace is the namespace name of the GoDeps highlights.
+const depsNamespace = "nvim-go-deps"
+
+// depKind represents the kind of the dependency package.
+type depKind int
+
+const (
+	// depStdlib is the standard library package.
+	depStdlib depKind = iota
+	// depThirdParty is the package out of the project.
+	depThirdParty
+	// depInternal is the package of the project.
+	depInternal
+)
+
+// depHighlights is the highlight group of each depKind.
+var depHighlights = map[depKind]string{
+	depStdlib:     "GoDepsStdlib",
+	depThirdParty: "GoDepsThirdParty",
+	depInternal:   "GoDepsInternal",
+}
+
+// depPackage represents a package of the "go list -deps -json" result.
+type depPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Imports    []string
+	Standard   bool
+	DepOnly    bool
+	Module     *struct {
+		Path string
+		Main bool
+	}
+
+	kind depKind
+}
+
+// depNode represents a row of the GoDeps tree.
+type depNode struct {
+	pkg      *depPackage
+	depth    int
+	expanded bool
+	// children is nil until the node is expanded.
+	children []*depNode
+}
+
+// depsTree represents the import graph of the package.
+type depsTree struct {
+	pkgs       map[string]*depPackage
+	root       *depNode
+	hideStdlib bool
+}
+
+// depsState represents the displayed GoDeps sidebar.
+type depsState struct {
+	mu   sync.Mutex
+	win  nvim.Window
+	tree *depsTree
+	// rows is the nodes of each row in the sidebar.
+	rows []*depNode
+}
+
+func (c *Command) cmdDeps(dir string) {
+	go func() {
+		if err := c.Deps(dir); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) funcDepsToggle(row int) {
+	go func() {
+		if err := c.DepsToggle(row); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) funcDepsJump(row int) {
+	go func() {
+		if err := c.DepsJump(row); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Deps toggles the sidebar which shows the import graph of the dir package as
+// the tree. The <CR> in the sidebar expands or collapses the imports of the
+// package, and the gf jumps to the package source.
+func (c *Command) Deps(dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgDeps)
+	c.ctx.SetContext(dir)
+
+	c.deps.mu.Lock()
+	defer c.deps.mu.Unlock()
+
+	if c.deps.win != 0 {
+		valid, err := c.Nvim.IsWindowValid(c.deps.win)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if valid {
+			var winnr int
+			if err := c.Nvim.Call("win_id2win", &winnr, c.deps.win); err != nil {
+				return errors.WithStack(err)
+			}
+			c.deps.win = 0
+			return errors.WithStack(c.Nvim.Command(fmt.Sprintf("%dclose", winnr)))
+		}
+	}
+
+	ctx, done := c.startOp(pkgDeps)
+	out, err := goList(ctx, dir, c.ctx.Build.Env, "-deps", ".")
+	done()
+	if err != nil {
+		return err
+	}
+	tree, err := parseDeps(bytes.NewReader(out), config.DepsHideStdlib())
+	if err != nil {
+		return err
+	}
+
+	srcWin, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+		nvimutil.BufferVar: {
+			"go_deps_win": int(srcWin),
+		},
+		nvimutil.WindowOption: {
+			"number":      false,
+			"winfixwidth": true,
+		},
+		nvimutil.BufferMapping: {
+			"<CR>": ":<C-u>call GoDepsToggle(line('.'))<CR>",
+			"gf":   ":<C-u>call GoDepsJump(line('.'))<CR>",
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	buf.Width = 50
+	if _, err := buf.Create(depsBufferName, "", "vertical botright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	c.deps.win = buf.Window
+	c.deps.tree = tree
+	if err := c.renderDeps(); err != nil {
+		return err
+	}
+
+	// back to the source window
+	return errors.WithStack(c.Nvim.SetCurrentWindow(srcWin))
+}
+
+// DepsToggle expands or collapses the imports of the package at the 1-based
+// row of the GoDeps sidebar.
+func (c *Command) DepsToggle(row int) error {
+	c.deps.mu.Lock()
+	defer c.deps.mu.Unlock()
+
+	if row < 1 || row > len(c.deps.rows) {
+		return nil
+	}
+	c.deps.tree.toggle(c.deps.rows[row-1])
+	return c.renderDeps()
+}
+
+// DepsJump opens the source of the package at the 1-based row of the GoDeps
+// sidebar in the source window.
+func (c *Command) DepsJump(row int) error {
+	c.deps.mu.Lock()
+	defer c.deps.mu.Unlock()
+
+	if row < 1 || row > len(c.deps.rows) {
+		return nil
+	}
+	pkg := c.deps.rows[row-1].pkg
+	if pkg.Dir == "" {
+		return errors.Errorf("%s: package not found", pkg.ImportPath)
+	}
+	path := pkg.Dir
+	if len(pkg.GoFiles) > 0 {
+		path = filepath.Join(pkg.Dir, pkg.GoFiles[0])
+	}
+
+	var srcWin int
+	if err := c.Nvim.Eval("b:go_deps_win", &srcWin); err != nil {
+		return errors.WithStack(err)
+	}
+	// the path may contain the special characters of Ex commands, such as space
+	if err := c.Nvim.Call("fnameescape", &path, path); err != nil {
+		return errors.WithStack(err)
+	}
+	batch := c.Nvim.NewBatch()
+	batch.Call("win_gotoid", nil, srcWin)
+	batch.Command("edit " + path)
+	return errors.WithStack(batch.Execute())
+}
+
+// renderDeps writes the current tree to the GoDeps sidebar, and highlights
+// each package by the kind.
+func (c *Command) renderDeps() error {
+	lines, rows := c.deps.tree.render()
+	c.deps.rows = rows
+
+	var ns int
+	if err := c.Nvim.Call("nvim_create_namespace", &ns, depsNamespace); err != nil {
+		return errors.WithStack(err)
+	}
+	b, err := c.Nvim.WindowBuffer(c.deps.win)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	batch := c.Nvim.NewBatch()
+	batch.Command("highlight default link GoDepsStdlib Comment")
+	batch.Command("highlight default link GoDepsThirdParty Type")
+	batch.Command("highlight default link GoDepsInternal Function")
+	batch.SetBufferOption(b, "modifiable", true)
+	batch.SetBufferLines(b, 0, -1, true, lines)
+	batch.SetBufferOption(b, "modifiable", false)
+	batch.Call("nvim_buf_clear_namespace", nil, b, ns, 0, -1)
+	for i, n := range rows {
+		col := len(lines[i]) - len(n.pkg.ImportPath)
+		batch.Call("nvim_buf_add_highlight", nil, b, ns, depHighlights[n.pkg.kind], i, col, -1)
+	}
+	return errors.WithStack(batch.Execute())
+}
+
+// parseDeps parses the "go list -deps -json" output r to the tree which root
+// is the listed package. The root is expanded. The standard library packages
+// are excluded from the tree if hideStdlib is true.
+func parseDeps(r io.Reader, hideStdlib bool) (*depsTree, error) {
+	t := &depsTree{
+		pkgs:       make(map[string]*depPackage),
+		hideStdlib: hideStdlib,
+	}
+
+	var root *depPackage
+	dec := json.NewDecoder(r)
+	for {
+		pkg := new(depPackage)
+		if err := dec.Decode(pkg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.WithStack(err)
+		}
+		t.pkgs[pkg.ImportPath] = pkg
+		if !pkg.DepOnly {
+			root = pkg
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no package found")
+	}
+
+	// the project is the main module, or the root package in the GOPATH mode
+	project := root.ImportPath
+	if root.Module != nil {
+		project = root.Module.Path
+	}
+	for _, pkg := range t.pkgs {
+		switch {
+		case pkg.Standard:
+			pkg.kind = depStdlib
+		case pkg.ImportPath == project || strings.HasPrefix(pkg.ImportPath, project+"/"):
+			pkg.kind = depInternal
+		default:
+			pkg.kind = depThirdParty
+		}
+	}
+
+	t.root = &depNode{pkg: root}
+	t.toggle(t.root)
+
+	return t, nil
+}
+
+// toggle expands or collapses the n node.
+func (t *depsTree) toggle(n *depNode) {
+	if n.expanded {
+		n.expanded = false
+		return
+	}
+	if n.children == nil {
+		n.children = []*depNode{}
+		for _, path := range n.pkg.Imports {
+			pkg, ok := t.pkgs[path]
+			if !ok || (t.hideStdlib && pkg.Standard) {
+				continue
+			}
+			n.children = append(n.children, &depNode{pkg: pkg, depth: n.depth + 1})
+		}
+	}
+	n.expanded = true
+}
+
+// hasImports reports whether the n node has the imports to show.
+func (t *depsTree) hasImports(n *depNode) bool {
+	if n.children != nil {
+		return len(n.children) > 0
+	}
+	for _, path := range n.pkg.Imports {
+		if pkg, ok := t.pkgs[path]; ok && !(t.hideStdlib && pkg.Standard) {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns the lines of the visible nodes, and the nodes of each line.
+// Each line is the indented "+ path" form, the "-" is the expanded node and
+// the blank is the node which has no imports.
+func (t *depsTree) render() ([][]byte, []*depNode) {
+	var (
+		lines [][]byte
+		rows  []*depNode
+		walk  func(n *depNode)
+	)
+	walk = func(n *depNode) {
+		mark := " "
+		switch {
+		case n.expanded:
+			mark = "-"
+		case t.hasImports(n):
+			mark = "+"
+		}
+		lines = append(lines, []byte(strings.Repeat("  ", n.depth)+mark+" "+n.pkg.ImportPath))
+		rows = append(rows, n)
+		if n.expanded {
+			for _, child := range n.children {
+				walk(child)
+			}
+		}
+	}
+	walk(t.root)
+
+	return lines, rows
+}
@@ -0,0 +1,122 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDeps(t *testing.T) {
+	// the "go list -deps -json" output, the listed package is the last
+	out := `{
+	"ImportPath": "errors",
+	"Standard": true,
+	"DepOnly": true
+}
+{
+	"ImportPath": "fmt",
+	"Imports": ["errors"],
+	"Standard": true,
+	"DepOnly": true
+}
+{
+	"ImportPath": "github.com/pkg/errors",
+	"Imports": ["fmt"],
+	"DepOnly": true,
+	"Module": {"Path": "github.com/pkg/errors"}
+}
+{
+	"ImportPath": "example.com/foo/internal/bar",
+	"Imports": ["github.com/pkg/errors"],
+	"DepOnly": true,
+	"Module": {"Path": "example.com/foo", "Main": true}
+}
+{
+	"ImportPath": "example.com/foo",
+	"Imports": ["example.com/foo/internal/bar", "fmt", "github.com/pkg/errors"],
+	"Module": {"Path": "example.com/foo", "Main": true}
+}
+`
+
+	tests := []struct {
+		name       string
+		hideStdlib bool
+		expand     []int // the 0-based rows to toggle in order
+		want       []string
+		wantKinds  []depKind
+	}{
+		{
+			name: "root",
+			want: []string{
+				"- example.com/foo",
+				"  + example.com/foo/internal/bar",
+				"  + fmt",
+				"  + github.com/pkg/errors",
+			},
+			wantKinds: []depKind{depInternal, depInternal, depStdlib, depThirdParty},
+		},
+		{
+			name:   "expand",
+			expand: []int{1, 2},
+			want: []string{
+				"- example.com/foo",
+				"  - example.com/foo/internal/bar",
+				"    - github.com/pkg/errors",
+				"      + fmt",
+				"  + fmt",
+				"  + github.com/pkg/errors",
+			},
+			wantKinds: []depKind{depInternal, depInternal, depThirdParty, depStdlib, depStdlib, depThirdParty},
+		},
+		{
+			name:   "collapse",
+			expand: []int{1, 1},
+			want: []string{
+				"- example.com/foo",
+				"  + example.com/foo/internal/bar",
+				"  + fmt",
+				"  + github.com/pkg/errors",
+			},
+			wantKinds: []depKind{depInternal, depInternal, depStdlib, depThirdParty},
+		},
+		{
+			name:       "hide stdlib",
+			hideStdlib: true,
+			expand:     []int{2},
+			want: []string{
+				"- example.com/foo",
+				"  + example.com/foo/internal/bar",
+				"  - github.com/pkg/errors",
+			},
+			wantKinds: []depKind{depInternal, depInternal, depThirdParty},
+		},
+	}
+	for _, tt := range tests {
+		tree, err := parseDeps(strings.NewReader(out), tt.hideStdlib)
+		if err != nil {
+			t.Fatalf("%q. parseDeps() error = %v", tt.name, err)
+		}
+		for _, row := range tt.expand {
+			_, rows := tree.render()
+			tree.toggle(rows[row])
+		}
+
+		lines, rows := tree.render()
+		var got []string
+		var gotKinds []depKind
+		for i := range lines {
+			got = append(got, string(lines[i]))
+			gotKinds = append(gotKinds, rows[i].pkg.kind)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. render() = %q, want %q", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(gotKinds, tt.wantKinds) {
+			t.Errorf("%q. render() kinds = %v, want %v", tt.name, gotKinds, tt.wantKinds)
+		}
+	}
+}
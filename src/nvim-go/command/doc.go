@@ -0,0 +1,229 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgDoc = "GoDoc"
+
+type cmdDocEval struct {
+	Cwd    string `msgpack:",array"`
+	File   string
+	Offset int
+}
+
+func (c *Command) cmdDoc(args []string, eval *cmdDocEval) {
+	go func() {
+		if err := c.Doc(args, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Doc shows the documentation of the symbol under the cursor in the scratch
+// buffer. If args is given, shows the "go doc args[0]" result instead, such
+// as the package or "pkg.Symbol".
+func (c *Command) Doc(args []string, eval *cmdDocEval) error {
+	defer nvimutil.Profile(time.Now(), pkgDoc)
+	dir := filepath.Dir(eval.File)
+	c.ctx.SetContext(dir)
+
+	var doc []byte
+	if len(args) > 0 {
+		ctx, done := c.startOp(pkgDoc)
+		defer done()
+
+		cmd := goDocCmd(ctx, dir, args[0])
+		cmd.Env = c.ctx.Build.Environ()
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return errors.Errorf("go doc %s: %s", args[0], bytes.TrimSpace(out))
+		}
+		doc = out
+	} else {
+		_, _, src, err := c.bufferSource()
+		if err != nil {
+			return err
+		}
+		f, err := loadTypedFile(eval.File, src)
+		if err != nil {
+			return err
+		}
+		doc, err = symbolDoc(f, eval.Offset)
+		if err != nil {
+			return err
+		}
+	}
+
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create("__GoDoc__", "go", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return buf.SetBufferLines(0, -1, true, bytes.TrimSuffix(doc, []byte{'\n'}))
+}
+
+// goDocCmd returns the "go doc query" command which runs in dir.
+func goDocCmd(ctx context.Context, dir, query string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "go", "doc", query)
+	cmd.Dir = dir
+	return cmd
+}
+
+// symbolDoc returns the documentation of the symbol at the offset in f. It's
+// the import path, the doc comment and the declaration of the symbol.
+func symbolDoc(f *typedFile, offset int) ([]byte, error) {
+	node := f.enclosingNode(offset, func(n ast.Node) bool {
+		_, ok := n.(*ast.Ident)
+		return ok
+	})
+	if node == nil {
+		return nil, errors.New("no identifier under the cursor")
+	}
+	obj := f.info.ObjectOf(node.(*ast.Ident))
+	if obj == nil {
+		return nil, errors.Errorf("couldn't resolve the %s", node.(*ast.Ident).Name)
+	}
+
+	var buf bytes.Buffer
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		pkg := pkgName.Imported()
+		fmt.Fprintf(&buf, "package %s // import %q\n", pkg.Name(), pkg.Path())
+		if info := f.prog.Package(pkg.Path()); info != nil {
+			for _, file := range info.Files {
+				if file.Doc != nil {
+					buf.WriteByte('\n')
+					writeDocComment(&buf, file.Doc)
+					break
+				}
+			}
+		}
+		return buf.Bytes(), nil
+	}
+	if obj.Pkg() == nil {
+		return nil, errors.Errorf("%s is the builtin", obj.Name())
+	}
+	fmt.Fprintf(&buf, "package %s // import %q\n\n", obj.Pkg().Name(), obj.Pkg().Path())
+
+	doc, decl := f.declDoc(obj)
+	writeDocComment(&buf, doc)
+	buf.WriteString(decl)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// declDoc returns the doc comment and the declaration text of obj. The
+// declaration is the object string if the declaration is not found.
+func (f *typedFile) declDoc(obj types.Object) (*ast.CommentGroup, string) {
+	qualifier := types.RelativeTo(obj.Pkg())
+	_, path, _ := f.prog.PathEnclosingInterval(obj.Pos(), obj.Pos())
+	for i, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return n.Doc, nodeString(f.fset, &ast.FuncDecl{Recv: n.Recv, Name: n.Name, Type: n.Type})
+		case *ast.Field:
+			doc := n.Doc
+			if doc == nil {
+				doc = n.Comment
+			}
+			return doc, types.ObjectString(obj, qualifier)
+		case *ast.TypeSpec, *ast.ValueSpec:
+			gen, _ := path[i+1].(*ast.GenDecl)
+			var doc *ast.CommentGroup
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				doc = spec.Doc
+			} else {
+				doc = n.(*ast.ValueSpec).Doc
+			}
+			if doc == nil && gen != nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			tok := token.TYPE
+			if gen != nil {
+				tok = gen.Tok
+			}
+			return doc, tok.String() + " " + nodeString(f.fset, n)
+		}
+	}
+	return nil, types.ObjectString(obj, qualifier)
+}
+
+// nodeString returns the formatted source of node.
+func nodeString(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
+
+// writeDocComment writes the doc comment text to buf with the "//" prefix.
+func writeDocComment(buf *bytes.Buffer, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(doc.Text(), "\n"), "\n") {
+		if line == "" {
+			buf.WriteString("//\n")
+			continue
+		}
+		buf.WriteString("// " + line + "\n")
+	}
+}
+
+// cmdDocComplete returns the importable packages which has the ArgLead prefix.
+// The packages are the cached standard library, and the project packages of
+// cwd with the dependencies.
+func (c *Command) cmdDocComplete(a *nvim.CommandCompletionArgs, cwd string) ([]string, error) {
+	c.ctx.SetContext(cwd)
+
+	std, err := c.StdPackages()
+	if err != nil {
+		return nil, err
+	}
+	deps, err := c.DepPackages(cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, pkgs := range [][]*GoPackage{std, deps} {
+		for _, pkg := range pkgs {
+			if !seen[pkg.ImportPath] {
+				seen[pkg.ImportPath] = true
+				paths = append(paths, pkg.ImportPath)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	return filterPrefix(paths, a.ArgLead), nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nvim-go/ctx"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestSymbolDoc(t *testing.T) {
+	src := `// Package foo is the test package.
+package foo
+
+import "io"
+
+// Reader reads the data.
+type Reader struct {
+	// Name is the reader name.
+	Name string
+}
+
+// Open opens the named reader.
+func Open(name string) *Reader {
+	var r Reader
+	io.ReadFull(nil, nil)
+	return &r
+}
+`
+	tests := []struct {
+		name    string
+		ident   string // the identifier under the cursor
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "stdlib function",
+			ident: "ReadFull(",
+			want: `package io // import "io"
+
+// ReadFull reads exactly len(buf) bytes from r into buf.`,
+		},
+		{
+			name:  "stdlib package",
+			ident: "io.ReadFull",
+			want:  `package io // import "io"`,
+		},
+		{
+			name:  "local type",
+			ident: "Reader\n",
+			want: `package foo // import "foo"
+
+// Reader reads the data.
+type Reader struct {
+	// Name is the reader name.
+	Name string
+}
+`,
+		},
+		{
+			name:  "local function",
+			ident: "Open(",
+			want: `package foo // import "foo"
+
+// Open opens the named reader.
+func Open(name string) *Reader
+`,
+		},
+		{
+			name:  "local field",
+			ident: "Name string",
+			want: `package foo // import "foo"
+
+// Name is the reader name.
+field Name string
+`,
+		},
+		{
+			name:    "no identifier",
+			ident:   "(name",
+			wantErr: true,
+		},
+	}
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": src})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+
+	f, err := loadTypedFile(fname, []byte(src))
+	if err != nil {
+		t.Fatalf("loadTypedFile(%v) error = %v", fname, err)
+	}
+	for _, tt := range tests {
+		offset := strings.Index(src, tt.ident)
+		got, err := symbolDoc(f, offset)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. symbolDoc(%v) error = %v, wantErr %v", tt.name, offset, err, tt.wantErr)
+			continue
+		}
+		if !strings.HasPrefix(string(got), tt.want) {
+			t.Errorf("%q. symbolDoc(%v) = %s, want %s", tt.name, offset, got, tt.want)
+		}
+	}
+}
+
+func TestCommand_cmdDocComplete(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"go.mod": "module example.com/foo\n",
+		"foo.go": "package foo\n\nimport _ \"strings\"\n",
+	})
+	defer cleanup()
+
+	c := NewCommand(nil, ctx.NewContext())
+
+	tests := []struct {
+		argLead string
+		want    []string
+	}{
+		{argLead: "example.com/", want: []string{"example.com/foo"}},
+		{argLead: "strings", want: []string{"strings"}},
+		{argLead: "net/http/httpt", want: []string{"net/http/httptest", "net/http/httptrace"}},
+	}
+	for _, tt := range tests {
+		got, err := c.cmdDocComplete(&nvim.CommandCompletionArgs{ArgLead: tt.argLead}, dir)
+		if err != nil {
+			t.Fatalf("cmdDocComplete(%q) error = %v", tt.argLead, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cmdDocComplete(%q) = %v, want %v", tt.argLead, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const pkgErrcheck = "GoErrcheck"
+
+// errcheckNamespace is the namespace name of the GoErrcheck highlights.
+const errcheckNamespace = "nvim-go-errcheck"
+
+// CmdErrcheckEval represents a GoErrcheck command Eval args.
+type CmdErrcheckEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+// CmdErrcheckFixEval represents a GoErrcheckFix command Eval args.
+type CmdErrcheckFixEval struct {
+	File string `msgpack:",array"`
+	Line int
+}
+
+func (c *Command) cmdErrcheck(eval *CmdErrcheckEval) {
+	go func() {
+		delete(c.ctx.Errlist, "Errcheck")
+
+		switch e := c.Errcheck(eval).(type) {
+		case error:
+			c.saveError("Errcheck", e)
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.saveError("Errcheck", nil)
+			c.ctx.Errlist["Errcheck"] = e
+			nvimutil.ErrorList(c.Nvim, eval.Cwd, c.ctx.Errlist, true)
+			nvimutil.EchoSuccess(c.Nvim, pkgErrcheck, fmt.Sprintf("%d unchecked errors, GoErrcheckFix inserts the error check of the cursor line", len(e)))
+		case nil:
+			c.saveError("Errcheck", nil)
+			nvimutil.ErrorList(c.Nvim, eval.Cwd, c.ctx.Errlist, true)
+		}
+	}()
+}
+
+// Errcheck runs the errcheck over the current package, and highlights the
+// unchecked calls of the current buffer by the GoErrcheck. The calls which
+// match any of config.ErrcheckIgnore are omitted.
+func (c *Command) Errcheck(eval *CmdErrcheckEval) interface{} {
+	defer nvimutil.Profile(time.Now(), pkgErrcheck)
+	dir := filepath.Dir(eval.File)
+	c.ctx.SetContext(dir)
+
+	ignore, err := compileIgnore(config.ErrcheckIgnore())
+	if err != nil {
+		return err
+	}
+
+	bin, err := tools.Require(pkgErrcheck, "errcheck")
+	if err != nil {
+		return err
+	}
+	ctx, done := c.startOp(pkgErrcheck)
+	defer done()
+
+	cmd := exec.CommandContext(ctx, bin, ".")
+	cmd.Dir = dir
+	cmd.Env = c.ctx.Build.Environ()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// errcheck exits with 1 if found the unchecked errors
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		return errors.Errorf("errcheck: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	errlist, err := parseErrcheck(stdout.Bytes(), dir, ignore)
+	if err != nil {
+		return err
+	}
+	if err := c.highlightErrcheck(eval.File, errlist); err != nil {
+		return err
+	}
+	if len(errlist) == 0 {
+		return nil
+	}
+	return errlist
+}
+
+// highlightErrcheck highlights the unchecked calls of file in the current
+// buffer, from the call to the end of line.
+func (c *Command) highlightErrcheck(file string, errlist []*nvim.QuickfixError) error {
+	var ns int
+	if err := c.Nvim.Call("nvim_create_namespace", &ns, errcheckNamespace); err != nil {
+		return errors.WithStack(err)
+	}
+
+	b := nvim.Buffer(c.ctx.BufNr)
+	batch := c.Nvim.NewBatch()
+	batch.Command("highlight default link GoErrcheck SpellBad")
+	batch.Call("nvim_buf_clear_namespace", nil, b, ns, 0, -1)
+	for _, e := range errlist {
+		if e.FileName == file {
+			batch.Call("nvim_buf_add_highlight", nil, b, ns, "GoErrcheck", e.LNum-1, e.Col-1, -1)
+		}
+	}
+	return errors.WithStack(batch.Execute())
+}
+
+// compileIgnore compiles the config.ErrcheckIgnore patterns.
+func compileIgnore(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "g:go#lint#errcheck#ignore")
+		}
+		res[i] = re
+	}
+	return res, nil
+}
+
+// errcheckLine matches the errcheck output line, such as:
+//  foo.go:12:10:	defer f.Close()
+var errcheckLine = regexp.MustCompile(`^(.+?):(\d+):(\d+):\s*(.*)$`)
+
+// parseErrcheck parses the errcheck output out to the list. The relative file
+// names are based on dir. The call which matches any of ignore is omitted.
+func parseErrcheck(out []byte, dir string, ignore []*regexp.Regexp) ([]*nvim.QuickfixError, error) {
+	var errlist []*nvim.QuickfixError
+
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		m := errcheckLine.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		call := strings.TrimSpace(m[4])
+		ignored := false
+		for _, re := range ignore {
+			if re.MatchString(call) {
+				ignored = true
+				break
+			}
+		}
+		if ignored {
+			continue
+		}
+
+		fname := m[1]
+		if !filepath.IsAbs(fname) {
+			fname = filepath.Join(dir, fname)
+		}
+		lnum, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		errlist = append(errlist, &nvim.QuickfixError{
+			FileName: fname,
+			LNum:     lnum,
+			Col:      col,
+			Text:     "unchecked error: " + call,
+			Type:     "W",
+		})
+	}
+	return errlist, errors.WithStack(s.Err())
+}
+
+func (c *Command) cmdErrcheckFix(eval *CmdErrcheckFixEval) {
+	go func() {
+		if err := c.ErrcheckFix(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// ErrcheckFix assigns the error result of the unchecked call of the cursor
+// line to err, and inserts the 'if err' Go idiom of the GoIferr after it.
+func (c *Command) ErrcheckFix(eval *CmdErrcheckFixEval) error {
+	defer nvimutil.Profile(time.Now(), "GoErrcheckFix")
+
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	out, err := errcheckFixSource(eval.File, src, eval.Line)
+	if err != nil {
+		return err
+	}
+	return c.updateBuffer(b, in, out)
+}
+
+// errcheckFixSource rewrites the call statement of the line of src which
+// discards the error result to check the error, such as:
+//  _, err := w.Write(p)
+//  if err != nil {
+//  	return err
+//  }
+// The err is assigned by "=" if it's already declared in the scope. The rest
+// of src is kept as is.
+func errcheckFixSource(file string, src []byte, line int) ([]byte, error) {
+	f, err := loadTypedFile(file, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var stmt *ast.ExprStmt
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		if stmt != nil {
+			return false
+		}
+		if s, ok := n.(*ast.ExprStmt); ok && f.fset.Position(s.Pos()).Line == line {
+			if _, ok := s.X.(*ast.CallExpr); ok {
+				stmt = s
+				return false
+			}
+		}
+		return true
+	})
+	if stmt == nil {
+		return nil, errors.Errorf("no unchecked call at line %d", line)
+	}
+
+	var results []types.Type
+	switch t := f.info.TypeOf(stmt.X).(type) {
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			results = append(results, t.At(i).Type())
+		}
+	case nil:
+		return nil, errors.Errorf("couldn't resolve the call at line %d", line)
+	default:
+		results = append(results, t)
+	}
+	if len(results) == 0 || !types.Identical(results[len(results)-1], errorType) {
+		return nil, errors.Errorf("the call at line %d doesn't return the error", line)
+	}
+
+	var outerFunc *ast.FuncDecl
+	path, _ := astutil.PathEnclosingInterval(f.file, stmt.Pos(), stmt.End())
+	for _, n := range path {
+		if decl, ok := n.(*ast.FuncDecl); ok {
+			outerFunc = decl
+			break
+		}
+	}
+	if outerFunc == nil {
+		return nil, errors.Errorf("no function encloses the call at line %d", line)
+	}
+
+	lhs := strings.Repeat("_, ", len(results)-1) + "err"
+	tok := ":="
+	if _, obj := f.info.Scopes[f.file].Innermost(stmt.Pos()).LookupParent("err", stmt.Pos()); obj != nil && types.Identical(obj.Type(), errorType) {
+		tok = "="
+	}
+
+	assign := errorAssign{outerFunc: outerFunc, ident: ast.NewIdent("err")}
+	var catch bytes.Buffer
+	if err := format.Node(&catch, f.fset, makeErrorCatchStatement(assign.ident, makeErrorHandleStatement(assign, *f.info))); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	start := f.fset.Position(stmt.Pos()).Offset
+	bol := bytes.LastIndexByte(src[:start], '\n') + 1
+	indent := src[bol:start]
+	// the end of the last line of the statement, which may be split over
+	// several lines
+	end := f.fset.Position(stmt.End()).Offset
+	eol := len(src)
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		eol = end + i
+	}
+
+	var buf bytes.Buffer
+	buf.Write(src[:start])
+	buf.WriteString(lhs + " " + tok + " ")
+	buf.Write(src[start:eol])
+	for _, l := range bytes.Split(catch.Bytes(), []byte{'\n'}) {
+		buf.WriteByte('\n')
+		buf.Write(indent)
+		buf.Write(l)
+	}
+	buf.Write(src[eol:])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestParseErrcheck(t *testing.T) {
+	out := []byte("foo.go:12:10:\tdefer f.Close()\n" +
+		"bar/bar.go:3:2:\tfmt.Println(\"bar\")\n" +
+		"/abs/baz.go:7:3:\tw.Write(p)\n" +
+		"error: failed to check packages\n")
+
+	tests := []struct {
+		name   string
+		ignore []*regexp.Regexp
+		want   []*nvim.QuickfixError
+	}{
+		{
+			name: "all",
+			want: []*nvim.QuickfixError{
+				{FileName: filepath.FromSlash("/go/src/foo/foo.go"), LNum: 12, Col: 10, Text: "unchecked error: defer f.Close()", Type: "W"},
+				{FileName: filepath.FromSlash("/go/src/foo/bar/bar.go"), LNum: 3, Col: 2, Text: "unchecked error: fmt.Println(\"bar\")", Type: "W"},
+				{FileName: "/abs/baz.go", LNum: 7, Col: 3, Text: "unchecked error: w.Write(p)", Type: "W"},
+			},
+		},
+		{
+			name:   "ignore",
+			ignore: []*regexp.Regexp{regexp.MustCompile(`fmt.Print`), regexp.MustCompile(`Close\(\)`)},
+			want: []*nvim.QuickfixError{
+				{FileName: "/abs/baz.go", LNum: 7, Col: 3, Text: "unchecked error: w.Write(p)", Type: "W"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseErrcheck(out, filepath.FromSlash("/go/src/foo"), tt.ignore)
+		if err != nil {
+			t.Errorf("%q. parseErrcheck() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. parseErrcheck() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestErrcheckFix(t *testing.T) {
+	src := `package foo
+
+import "bytes"
+
+func write(b *bytes.Buffer, p []byte) error {
+	b.Write(p)
+	return nil
+}
+
+func reset(b *bytes.Buffer) error {
+	var err error
+	b.WriteString("x") // comment
+	return err
+}
+
+func flush(b *bytes.Buffer, p []byte) error {
+	b.Write(
+		p,
+	)
+	return nil
+}
+`
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": src})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+
+	tests := []struct {
+		name    string
+		line    int
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "tuple",
+			line: 6,
+			want: `package foo
+
+import "bytes"
+
+func write(b *bytes.Buffer, p []byte) error {
+	_, err := b.Write(p)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func reset(b *bytes.Buffer) error {
+	var err error
+	b.WriteString("x") // comment
+	return err
+}
+
+func flush(b *bytes.Buffer, p []byte) error {
+	b.Write(
+		p,
+	)
+	return nil
+}
+`,
+		},
+		{
+			name: "declared err",
+			line: 12,
+			want: `package foo
+
+import "bytes"
+
+func write(b *bytes.Buffer, p []byte) error {
+	b.Write(p)
+	return nil
+}
+
+func reset(b *bytes.Buffer) error {
+	var err error
+	_, err = b.WriteString("x") // comment
+	if err != nil {
+		return err
+	}
+	return err
+}
+
+func flush(b *bytes.Buffer, p []byte) error {
+	b.Write(
+		p,
+	)
+	return nil
+}
+`,
+		},
+		{
+			name: "multi-line call",
+			line: 17,
+			want: `package foo
+
+import "bytes"
+
+func write(b *bytes.Buffer, p []byte) error {
+	b.Write(p)
+	return nil
+}
+
+func reset(b *bytes.Buffer) error {
+	var err error
+	b.WriteString("x") // comment
+	return err
+}
+
+func flush(b *bytes.Buffer, p []byte) error {
+	_, err := b.Write(
+		p,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+		},
+		{
+			name:    "no call",
+			line:    7,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := errcheckFixSource(fname, []byte(src), tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. errcheckFixSource(%d) error = %v, wantErr %v", tt.name, tt.line, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%q. errcheckFixSource(%d) =\n%s\nwant:\n%s", tt.name, tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgErrors = "GoErrors"
+
+// errorEntry represents an error of the command with the occurred time.
+type errorEntry struct {
+	time time.Time
+	err  error
+}
+
+// saveError saves the err of the name command to show it with GoErrors.
+// If err is nil, clears the saved error of the name command, such as the
+// successful re-run of the same command.
+func (c *Command) saveError(name string, err error) {
+	if err == nil {
+		c.errlog.Delete(name)
+		return
+	}
+	c.errlog.Store(name, &errorEntry{time: time.Now(), err: err})
+}
+
+func (c *Command) cmdErrors() {
+	go func() {
+		if err := c.Errors(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Errors dumps all of the stored per-command errors into the scratch buffer.
+func (c *Command) Errors() error {
+	data := c.formatErrors()
+	if len(data) == 0 {
+		return nvimutil.EchoSuccess(c.Nvim, pkgErrors, "no errors")
+	}
+
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create("__GoErrors__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return buf.SetBufferLines(0, -1, true, bytes.TrimSuffix(data, []byte{'\n'}))
+}
+
+// formatErrors formats the stored errors with timestamps, and the stored
+// error lists.
+func (c *Command) formatErrors() []byte {
+	var buf bytes.Buffer
+
+	var names []string
+	entries := make(map[string]*errorEntry)
+	c.errlog.Range(func(ki, vi interface{}) bool {
+		k, v := ki.(string), vi.(*errorEntry)
+		names = append(names, k)
+		entries[k] = v
+		return true
+	})
+	sort.Strings(names)
+	for _, name := range names {
+		e := entries[name]
+		fmt.Fprintf(&buf, "[%s] %s: %v\n", e.time.Format("2006-01-02 15:04:05"), name, e.err)
+	}
+
+	names = names[:0]
+	errlists := make(map[string][]*nvim.QuickfixError)
+	c.errs.Range(func(ki, vi interface{}) bool {
+		k, v := ki.(string), vi.([]*nvim.QuickfixError)
+		names = append(names, k)
+		errlists[k] = v
+		return true
+	})
+	sort.Strings(names)
+	for _, name := range names {
+		for _, e := range errlists[name] {
+			fmt.Fprintf(&buf, "%s: %s:%d:%d: %s\n", name, e.FileName, e.LNum, e.Col, e.Text)
+		}
+	}
+
+	return buf.Bytes()
+}
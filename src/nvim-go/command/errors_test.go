@@ -0,0 +1,35 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"nvim-go/ctx"
+)
+
+func TestCommand_saveError(t *testing.T) {
+	c := NewCommand(nil, ctx.NewContext())
+
+	c.saveError("Build", errors.New("build failed"))
+	c.saveError("Vet", errors.New("vet failed"))
+	got := string(c.formatErrors())
+	for _, want := range []string{"Build: build failed", "Vet: vet failed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatErrors() = %q, want contains %q", got, want)
+		}
+	}
+	if strings.Index(got, "Build:") > strings.Index(got, "Vet:") {
+		t.Errorf("formatErrors() = %q, want sorted by command name", got)
+	}
+
+	c.saveError("Build", nil)
+	got = string(c.formatErrors())
+	if strings.Contains(got, "Build:") {
+		t.Errorf("formatErrors() after successful re-run = %q, want not contains %q", got, "Build:")
+	}
+}
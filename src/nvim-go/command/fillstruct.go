@@ -0,0 +1,134 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const pkgFillStruct = "GoFillStruct"
+
+type cmdFillStructEval struct {
+	File   string `msgpack:",array"`
+	Offset int
+}
+
+func (c *Command) cmdFillStruct(eval *cmdFillStructEval) {
+	go func() {
+		if err := c.FillStruct(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// FillStruct fills the all fields of the empty struct literal under the
+// cursor with the zero values.
+func (c *Command) FillStruct(eval *cmdFillStructEval) error {
+	defer nvimutil.Profile(time.Now(), pkgFillStruct)
+
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	f, err := loadTypedFile(eval.File, src)
+	if err != nil {
+		return err
+	}
+	out, err := fillStruct(f, eval.Offset)
+	if err != nil {
+		return err
+	}
+
+	return c.updateBuffer(b, in, out)
+}
+
+// fillStructDepth is the depth of the nested struct fields which are filled.
+const fillStructDepth = 1
+
+// fillStruct returns the f source which filled the empty struct literal at
+// the offset.
+func fillStruct(f *typedFile, offset int) ([]byte, error) {
+	node := f.enclosingNode(offset, func(n ast.Node) bool {
+		_, ok := n.(*ast.CompositeLit)
+		return ok
+	})
+	if node == nil {
+		return nil, errors.New("no composite literal under the cursor")
+	}
+	lit := node.(*ast.CompositeLit)
+
+	typ := f.info.TypeOf(lit)
+	if typ == nil {
+		return nil, errors.New("couldn't resolve the type of the composite literal")
+	}
+	st, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil, errors.Errorf("%s is not a struct type", typ)
+	}
+	if len(lit.Elts) > 0 {
+		return nil, errors.New("the struct literal is not empty")
+	}
+
+	var typText string
+	if lit.Type != nil {
+		typText = f.text(lit.Type)
+	}
+
+	return f.apply([]textEdit{{
+		start: f.offset(lit.Pos()),
+		end:   f.offset(lit.End()),
+		text:  typText + f.structFields(st, fillStructDepth),
+	}})
+}
+
+// structFields returns the "{field: zero, ...}" text of the st fields. The
+// nested struct fields are filled until the depth.
+func (f *typedFile) structFields(st *types.Struct, depth int) string {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		// the unexported fields of the other package can't be set
+		if !field.Exported() && field.Pkg() != f.pkg {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s: %s,\n", field.Name(), f.zeroValue(field.Type(), depth))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// zeroValue returns the zero value text of typ.
+func (f *typedFile) zeroValue(typ types.Type, depth int) string {
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+		return "nil"
+	case *types.Struct:
+		if depth > 0 {
+			return f.typeString(typ) + f.structFields(u, depth-1)
+		}
+		return f.typeString(typ) + "{}"
+	case *types.Array:
+		return f.typeString(typ) + "{}"
+	default: // pointer, slice, map, chan, func and interface
+		return "nil"
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFillStruct(t *testing.T) {
+	types := `package foo
+
+import "bytes"
+
+type Base struct {
+	ID int
+}
+
+type Inner struct {
+	Name  string
+	Deep  Base
+	flags []string
+}
+
+type T struct {
+	Base
+	Inner Inner
+	Buf   bytes.Buffer
+	Ptr   *Base
+	OK    bool
+}
+`
+	tests := []struct {
+		name    string
+		src     string
+		cursor  string // the cursor is at the first occurrence
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "embedded and nested fields",
+			src:    "package foo\n\nvar _ = T{}\n",
+			cursor: "T{}",
+			want: `package foo
+
+import "bytes"
+
+var _ = T{
+	Base: Base{
+		ID: 0,
+	},
+	Inner: Inner{
+		Name:  "",
+		Deep:  Base{},
+		flags: nil,
+	},
+	Buf: bytes.Buffer{},
+	Ptr: nil,
+	OK:  false,
+}
+`,
+		},
+		{
+			name:   "existing import",
+			src:    "package foo\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint(Inner{})\n",
+			cursor: "Inner{}",
+			want: `package foo
+
+import "fmt"
+
+var _ = fmt.Sprint(Inner{
+	Name: "",
+	Deep: Base{
+		ID: 0,
+	},
+	flags: nil,
+})
+`,
+		},
+		{
+			name:    "not empty",
+			src:     "package foo\n\nvar _ = Base{ID: 1}\n",
+			cursor:  "Base{",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		dir, cleanup := writePackage(t, map[string]string{"types.go": types, "main.go": tt.src})
+
+		fname := filepath.Join(dir, "main.go")
+		f, err := loadTypedFile(fname, []byte(tt.src))
+		if err != nil {
+			cleanup()
+			t.Fatalf("%q. loadTypedFile(%v) error = %v", tt.name, fname, err)
+		}
+		offset := strings.Index(tt.src, tt.cursor) + 1
+		got, err := fillStruct(f, offset)
+		cleanup()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. fillStruct(%v) error = %v, wantErr %v", tt.name, offset, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%q. fillStruct(%v) = %s, want %s", tt.name, offset, got, tt.want)
+		}
+	}
+}
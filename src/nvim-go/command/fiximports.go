@@ -0,0 +1,403 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+const pkgFixImports = "GoFixImports"
+
+// importFix represents the result of the import block repair.
+type importFix struct {
+	src     []byte
+	added   []string // import paths
+	removed []string // import paths
+	// ambiguous is the other candidates of the added import path which
+	// resolved by the package name collision.
+	ambiguous map[string][]string
+	// unresolved is the package names which no candidate found.
+	unresolved []string
+}
+
+func (c *Command) cmdFixImports(file string) {
+	go func() {
+		if err := c.FixImports(file); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// FixImports repairs the import block of the current buffer. The unresolved
+// package names are resolved to the packages of the project, its dependencies
+// and the standard library, and the unused imports are removed. The import of
+// the unknown package name is kept. The collisions of the package
+// name are resolved by the config.ImportPreferences. Only the import region
+// of the buffer is updated.
+func (c *Command) FixImports(file string) error {
+	defer nvimutil.Profile(time.Now(), pkgFixImports)
+	dir := filepath.Dir(file)
+	c.ctx.SetContext(dir)
+
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+
+	std, err := c.StdPackages()
+	if err != nil {
+		return err
+	}
+	pkgs, err := c.Packages(dir)
+	if err != nil {
+		return err
+	}
+	deps, err := c.DepPackages(dir)
+	if err != nil {
+		return err
+	}
+	declared, err := packageDecls(file, src)
+	if err != nil {
+		return err
+	}
+
+	fix, err := fixImports(src, declared, indexPackages(std, pkgs, deps), config.ImportPreferences())
+	if err != nil {
+		return err
+	}
+	if len(fix.added) > 0 || len(fix.removed) > 0 {
+		if err := c.updateBuffer(b, in, fix.src); err != nil {
+			return err
+		}
+	}
+
+	if len(fix.unresolved) > 0 {
+		return nvimutil.EchohlAfter(c.Nvim, pkgFixImports, "WarningMsg", "%s; unresolved: %s", fix.summary(), strings.Join(fix.unresolved, ", "))
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgFixImports, fix.summary())
+}
+
+// summary returns the message of the added and removed imports.
+func (fix *importFix) summary() string {
+	if len(fix.added) == 0 && len(fix.removed) == 0 {
+		return "no changes"
+	}
+	var msgs []string
+	if len(fix.added) > 0 {
+		added := make([]string, len(fix.added))
+		for i, path := range fix.added {
+			added[i] = path
+			if others := fix.ambiguous[path]; len(others) > 0 {
+				added[i] += fmt.Sprintf(" (not %s)", strings.Join(others, ", "))
+			}
+		}
+		msgs = append(msgs, "added: "+strings.Join(added, ", "))
+	}
+	if len(fix.removed) > 0 {
+		msgs = append(msgs, "removed: "+strings.Join(fix.removed, ", "))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// indexPackages returns the importable packages of pkgs by the package name.
+// The internal and vendored packages of the standard library are skipped, and
+// the vendored packages are indexed by the path under the vendor directory.
+func indexPackages(pkgs ...[]*GoPackage) map[string][]*GoPackage {
+	index := make(map[string][]*GoPackage)
+	seen := make(map[string]bool)
+	for _, list := range pkgs {
+		for _, pkg := range list {
+			if pkg.Standard && (isInternalPath(pkg.ImportPath) || strings.HasPrefix(pkg.ImportPath, "vendor/")) {
+				continue
+			}
+			if i := strings.LastIndex(pkg.ImportPath, "/vendor/"); i >= 0 && !pkg.Standard {
+				vendored := *pkg
+				vendored.ImportPath = pkg.ImportPath[i+len("/vendor/"):]
+				pkg = &vendored
+			}
+			if pkg.Name == "" || pkg.Name == "main" || seen[pkg.ImportPath] {
+				continue
+			}
+			seen[pkg.ImportPath] = true
+			index[pkg.Name] = append(index[pkg.Name], pkg)
+		}
+	}
+	return index
+}
+
+// isInternalPath reports whether the path is the internal package.
+func isInternalPath(path string) bool {
+	return path == "internal" || strings.HasPrefix(path, "internal/") ||
+		strings.HasSuffix(path, "/internal") || strings.Contains(path, "/internal/")
+}
+
+// pickImport returns the import path of the candidates of a package name. The
+// first path of prefs in candidates wins, otherwise the standard library, and
+// the shortest path. others is the import paths of the rest candidates.
+func pickImport(candidates []*GoPackage, prefs []string) (path string, others []string) {
+	cands := append([]*GoPackage{}, candidates...)
+	sort.SliceStable(cands, func(i, j int) bool {
+		a, b := cands[i], cands[j]
+		if a.Standard != b.Standard {
+			return a.Standard
+		}
+		if len(a.ImportPath) != len(b.ImportPath) {
+			return len(a.ImportPath) < len(b.ImportPath)
+		}
+		return a.ImportPath < b.ImportPath
+	})
+
+	pick := cands[0].ImportPath
+pref:
+	for _, p := range prefs {
+		for _, c := range cands {
+			if c.ImportPath == p {
+				pick = p
+				break pref
+			}
+		}
+	}
+
+	for _, c := range cands {
+		if c.ImportPath != pick {
+			others = append(others, c.ImportPath)
+		}
+	}
+	return pick, others
+}
+
+// packageDecls returns the top level names which declared in the other files
+// of the file package. The test files are only used by the test file.
+func packageDecls(file string, src []byte) (map[string]bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dir := filepath.Dir(file)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	isTest := strings.HasSuffix(file, "_test.go")
+	declared := make(map[string]bool)
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || filepath.Ext(name) != ".go" || name == filepath.Base(file) {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !isTest {
+			continue
+		}
+		// ignore the broken files
+		other, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil || other.Name.Name != f.Name.Name {
+			continue
+		}
+		for name := range other.Scope.Objects {
+			declared[name] = true
+		}
+	}
+	return declared, nil
+}
+
+// fixImports returns the src which the unused imports are removed, and the
+// unresolved package names are imported from the index. The declared names
+// are not the package names. Only the import region of src is rewritten.
+func fixImports(src []byte, declared map[string]bool, index map[string][]*GoPackage, prefs []string) (*importFix, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fix := &importFix{src: src, ambiguous: make(map[string][]string)}
+
+	// the unresolved identifiers of the selector expressions are the package
+	// name references
+	refs := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				refs[x.Name] = true
+			}
+		}
+		return true
+	})
+
+	names := make(map[string]string) // the package name of the import path
+	for _, pkgs := range index {
+		for _, pkg := range pkgs {
+			names[pkg.ImportPath] = pkg.Name
+		}
+	}
+	imported := make(map[string]bool)
+	var unused []*ast.ImportSpec
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name, known := importName(spec, path, names)
+		switch {
+		case !known:
+			// the package name is unknown, so keep it, and it may provide
+			// any of the guessed names
+			for _, guess := range guessImportNames(path) {
+				imported[guess] = true
+			}
+		case name == "_" || name == "." || path == "C":
+			// not sure if it's used, keep it
+		case refs[name]:
+			imported[name] = true
+		default:
+			unused = append(unused, spec)
+		}
+	}
+	for _, spec := range unused {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		var name string
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		astutil.DeleteNamedImport(fset, f, name, path)
+		fix.removed = append(fix.removed, path)
+	}
+
+	missing := make([]string, 0, len(refs))
+	for name := range refs {
+		if !imported[name] && !declared[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		cands := index[name]
+		if len(cands) == 0 {
+			fix.unresolved = append(fix.unresolved, name)
+			continue
+		}
+		path, others := pickImport(cands, prefs)
+		astutil.AddImport(fset, f, path)
+		fix.added = append(fix.added, path)
+		if len(others) > 0 {
+			fix.ambiguous[path] = others
+		}
+	}
+
+	if len(fix.added) == 0 && len(fix.removed) == 0 {
+		return fix, nil
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// groups the standard library imports apart from the others
+	opts := importsOptions
+	opts.FormatOnly = true
+	formatted, err := imports.Process("", buf.Bytes(), &opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	out, err := spliceImports(src, formatted)
+	if err != nil {
+		return nil, err
+	}
+	fix.src = out
+	return fix, nil
+}
+
+// importName returns the package name of the import spec, and whether the
+// name is known from the explicit name or the names of the listed packages.
+func importName(spec *ast.ImportSpec, path string, names map[string]string) (string, bool) {
+	if spec.Name != nil {
+		return spec.Name.Name, true
+	}
+	name, ok := names[path]
+	return name, ok
+}
+
+// guessImportNames returns the possible package names of the unknown path,
+// which are the path elements without the "go-" prefix, the "-go" suffix and
+// the ".vN" suffix, such as "redis" and "v8" of "github.com/go-redis/redis/v8".
+func guessImportNames(path string) []string {
+	var guesses []string
+	for _, elem := range strings.Split(path, "/") {
+		if i := strings.Index(elem, ".v"); i > 0 {
+			elem = elem[:i] // gopkg.in/yaml.v2
+		}
+		elem = strings.TrimSuffix(strings.TrimPrefix(elem, "go-"), "-go")
+		guesses = append(guesses, strings.Replace(elem, "-", "_", -1))
+	}
+	return guesses
+}
+
+// spliceImports returns src which the import region is replaced with the
+// import region of out. The rest of src is kept as it is even if out is
+// formatted.
+func spliceImports(src, out []byte) ([]byte, error) {
+	start, end, err := importRegion(src)
+	if err != nil {
+		return nil, err
+	}
+	outStart, outEnd, err := importRegion(out)
+	if err != nil {
+		return nil, err
+	}
+
+	region := out[outStart:outEnd]
+	rest := src[end:]
+	switch {
+	case start == end && len(region) > 0:
+		// insert after the package clause
+		region = append([]byte("\n\n"), region...)
+	case len(region) == 0:
+		rest = bytes.TrimLeft(rest, "\n")
+	}
+
+	spliced := append([]byte{}, src[:start]...)
+	spliced = append(spliced, region...)
+	return append(spliced, rest...), nil
+}
+
+// importRegion returns the byte offsets of the import declarations of src.
+// The region is empty at the end of the package clause if src has no imports.
+func importRegion(src []byte) (start, end int, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	start = fset.Position(f.Name.End()).Offset
+	end = start
+	for i, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		if i == 0 {
+			start = fset.Position(gen.Pos()).Offset
+		}
+		end = fset.Position(gen.End()).Offset
+	}
+	return start, end, nil
+}
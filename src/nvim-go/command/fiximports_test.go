@@ -0,0 +1,126 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFixImports(t *testing.T) {
+	index := indexPackages([]*GoPackage{
+		{ImportPath: "errors", Name: "errors", Standard: true},
+		{ImportPath: "fmt", Name: "fmt", Standard: true},
+		{ImportPath: "internal/foo", Name: "foo", Standard: true},
+		{ImportPath: "os", Name: "os", Standard: true},
+	}, []*GoPackage{
+		{ImportPath: "github.com/foo/bar", Name: "bar"},
+		{ImportPath: "github.com/pkg/errors", Name: "errors"},
+		{ImportPath: "example.com/foo/vendor/github.com/quux/bar", Name: "bar"},
+	})
+
+	// the unformatted body is kept as it is, and the imports of the unknown
+	// package name are kept
+	src := `package foo
+
+import (
+	"os"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nsf/termbox-go"
+)
+
+func Foo() error {
+	fmt.Println(bar.Baz,  quux.Qux, local.X, redis.Nil, termbox.Init)
+	return errors.New("foo")
+}
+`
+
+	tests := []struct {
+		name           string
+		prefs          []string
+		want           string
+		wantAdded      []string
+		wantAmbiguous  map[string][]string
+		wantRemoved    []string
+		wantUnresolved []string
+	}{
+		{
+			name: "no preferences",
+			want: `package foo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/foo/bar"
+	"github.com/go-redis/redis/v8"
+	"github.com/nsf/termbox-go"
+)
+
+func Foo() error {
+	fmt.Println(bar.Baz,  quux.Qux, local.X, redis.Nil, termbox.Init)
+	return errors.New("foo")
+}
+`,
+			wantAdded: []string{"github.com/foo/bar", "errors", "fmt"},
+			wantAmbiguous: map[string][]string{
+				"github.com/foo/bar": {"github.com/quux/bar"},
+				"errors":             {"github.com/pkg/errors"},
+			},
+			wantRemoved:    []string{"os"},
+			wantUnresolved: []string{"quux"},
+		},
+		{
+			name:  "preferences",
+			prefs: []string{"github.com/pkg/errors", "github.com/quux/bar"},
+			want: `package foo
+
+import (
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nsf/termbox-go"
+	"github.com/pkg/errors"
+	"github.com/quux/bar"
+)
+
+func Foo() error {
+	fmt.Println(bar.Baz,  quux.Qux, local.X, redis.Nil, termbox.Init)
+	return errors.New("foo")
+}
+`,
+			wantAdded: []string{"github.com/quux/bar", "github.com/pkg/errors", "fmt"},
+			wantAmbiguous: map[string][]string{
+				"github.com/quux/bar":   {"github.com/foo/bar"},
+				"github.com/pkg/errors": {"errors"},
+			},
+			wantRemoved:    []string{"os"},
+			wantUnresolved: []string{"quux"},
+		},
+	}
+	for _, tt := range tests {
+		fix, err := fixImports([]byte(src), map[string]bool{"local": true}, index, tt.prefs)
+		if err != nil {
+			t.Errorf("%q. fixImports() error = %v", tt.name, err)
+			continue
+		}
+		if got := string(fix.src); got != tt.want {
+			t.Errorf("%q. fixImports() src =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(fix.added, tt.wantAdded) {
+			t.Errorf("%q. fixImports() added = %v, want %v", tt.name, fix.added, tt.wantAdded)
+		}
+		if !reflect.DeepEqual(fix.ambiguous, tt.wantAmbiguous) {
+			t.Errorf("%q. fixImports() ambiguous = %v, want %v", tt.name, fix.ambiguous, tt.wantAmbiguous)
+		}
+		if !reflect.DeepEqual(fix.removed, tt.wantRemoved) {
+			t.Errorf("%q. fixImports() removed = %v, want %v", tt.name, fix.removed, tt.wantRemoved)
+		}
+		if !reflect.DeepEqual(fix.unresolved, tt.wantUnresolved) {
+			t.Errorf("%q. fixImports() unresolved = %v, want %v", tt.name, fix.unresolved, tt.wantUnresolved)
+		}
+	}
+}
@@ -6,6 +6,7 @@ package command
 
 import (
 	"bytes"
+	"go/format"
 	"go/scanner"
 	"time"
 
@@ -26,8 +27,16 @@ var importsOptions = imports.Options{
 
 func (c *Command) cmdFmt(dir string) {
 	delete(c.ctx.Errlist, "Fmt")
-	err := c.Fmt(dir)
+	c.reportFmt(c.Fmt(dir))
+}
+
+func (c *Command) cmdFmtSelection(ranges [2]int, dir string) {
+	delete(c.ctx.Errlist, "Fmt")
+	c.reportFmt(c.FmtSelection(ranges, dir))
+}
 
+// reportFmt shows the result of the Fmt or FmtSelection.
+func (c *Command) reportFmt(err interface{}) {
 	switch e := err.(type) {
 	case error:
 		nvimutil.ErrorWrap(c.Nvim, e)
@@ -39,7 +48,7 @@ func (c *Command) cmdFmt(dir string) {
 			errlist[k] = append(errlist[k], v...)
 			return true
 		})
-		nvimutil.ErrorList(c.Nvim, errlist, true)
+		nvimutil.ErrorList(c.Nvim, "", errlist, true)
 	}
 }
 
@@ -53,7 +62,61 @@ func (c *Command) Fmt(dir string) interface{} {
 		return errors.WithStack(err)
 	}
 
-	switch config.FmtMode {
+	return c.format(b, in, nvimutil.ToByteSlice(in))
+}
+
+// FmtSelection formats the ranges lines of the current buffer uses gofmt
+// behavior, and updates only that lines. The lines are formatted as the
+// declaration list or the statement list if they're not the whole file, and
+// keep the indentation of the first line. Falls back to the whole buffer
+// format if the lines can't be parsed by themselves.
+func (c *Command) FmtSelection(ranges [2]int, dir string) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoFmtSelection")
+
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	start, end := ranges[0], ranges[1]
+	if start < 1 {
+		start = 1
+	}
+	if end > len(in) {
+		end = len(in)
+	}
+	if start > end {
+		return nil
+	}
+
+	out, err := formatSelection(in[start-1 : end])
+	if err != nil {
+		nvimutil.EchohlAfter(c.Nvim, "GoFmtSelection", "WarningMsg", "selection is not parseable, formats the whole buffer: %v", err)
+		return c.format(b, in, src)
+	}
+
+	lines := make([][]byte, 0, len(in)-(end-start+1)+len(out))
+	lines = append(lines, in[:start-1]...)
+	lines = append(lines, out...)
+	lines = append(lines, in[end:]...)
+	return errors.WithStack(minUpdate(c.Nvim, b, in, lines))
+}
+
+// formatSelection formats the lines which is a part of the Go source. The
+// leading and trailing space, and the indentation of the first code line are
+// kept.
+func formatSelection(lines [][]byte) ([][]byte, error) {
+	src := append(nvimutil.ToByteSlice(lines), '\n')
+	buf, err := format.Source(src)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return nvimutil.ToBufferLines(bytes.TrimSuffix(buf, []byte{'\n'})), nil
+}
+
+// format formats the src source, and updates the b buffer which has the in
+// lines with minimum changes, then writes the buffer.
+func (c *Command) format(b nvim.Buffer, in [][]byte, src []byte) interface{} {
+	switch config.FmtMode() {
 	case "fmt":
 		importsOptions.FormatOnly = true
 	case "goimports":
@@ -62,7 +125,7 @@ func (c *Command) Fmt(dir string) interface{} {
 		return errors.WithStack(errors.New("invalid value of go#fmt#mode option"))
 	}
 
-	buf, formatErr := imports.Process("", nvimutil.ToByteSlice(in), &importsOptions)
+	buf, formatErr := imports.Process("", src, &importsOptions)
 	if formatErr != nil {
 		bufName, err := c.Nvim.BufferName(b)
 		if err != nil {
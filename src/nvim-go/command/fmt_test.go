@@ -62,7 +62,7 @@ func TestCommands_Fmt(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		config.FmtMode = "goimports"
+		config.Update(func(cfg *config.Config) { cfg.Fmt.Mode = "goimports" })
 
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -140,3 +140,51 @@ func TestMinUpdate(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "function",
+			in: "func add(a,b int) int {\n" +
+				"return a+b\n" +
+				"}",
+			want: "func add(a, b int) int {\n" +
+				"\treturn a + b\n" +
+				"}",
+		},
+		{
+			name: "statement list",
+			in: "\t\tx:=1\n" +
+				"\t\tif x>0 {\n" +
+				"\t\tx++\n" +
+				"\t\t}",
+			want: "\t\tx := 1\n" +
+				"\t\tif x > 0 {\n" +
+				"\t\t\tx++\n" +
+				"\t\t}",
+		},
+		{
+			name:    "unbalanced",
+			in:      "\tif x > 0 {\n\t\tx++",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := formatSelection(bytes.Split([]byte(tt.in), []byte{'\n'}))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. formatSelection() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if s := string(bytes.Join(got, []byte{'\n'})); s != tt.want {
+			t.Errorf("%q. formatSelection() = %q, want %q", tt.name, s, tt.want)
+		}
+	}
+}
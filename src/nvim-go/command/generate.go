@@ -44,10 +44,10 @@ func (c *Command) GenerateTest(args []string, ranges [2]int, bang bool, dir stri
 	opt := &process.Options{
 		WriteOutput:   true,
 		PrintInputs:   true,
-		AllFuncs:      config.GenerateTestAllFuncs,
-		ExclFuncs:     config.GenerateTestExclFuncs,
-		ExportedFuncs: config.GenerateTestExportedFuncs,
-		Subtests:      config.GenerateTestSubTest,
+		AllFuncs:      config.GenerateTestAllFuncs(),
+		ExclFuncs:     config.GenerateTestExclFuncs(),
+		ExportedFuncs: config.GenerateTestExportedFuncs(),
+		Subtests:      config.GenerateTestSubTest(),
 	}
 
 	// Check users used range. range return variable: (1,$)
@@ -83,6 +83,7 @@ func (c *Command) GenerateTest(args []string, ranges [2]int, bang bool, dir stri
 		}
 	}
 
+	nvimutil.StdioMu.Lock()
 	oldStdout := os.Stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
@@ -91,6 +92,16 @@ func (c *Command) GenerateTest(args []string, ranges [2]int, bang bool, dir stri
 
 	w.Close()
 	os.Stdout = oldStdout
+	nvimutil.StdioMu.Unlock()
+
+	// reload the existing test files which gotests updated
+	var testFiles []string
+	for _, f := range args {
+		testFiles = append(testFiles, strings.TrimSuffix(f, filepath.Ext(f))+"_test.go")
+	}
+	if err := nvimutil.ReloadChangedBuffers(c.Nvim, testFiles); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, err)
+	}
 
 	if !bang {
 		var genFuncs string
@@ -0,0 +1,132 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nvim-go/config"
+	"nvim-go/internal/pathutil"
+)
+
+// goFlagsFile is the project local flags file name, which is placed in the
+// project root directory.
+const goFlagsFile = ".go-flags"
+
+// mergeGoFlags merges the cmdFlags command config flags, such as
+// config.BuildFlags, and the goFlags of dir into the explicit command args.
+// The precedence is explicit args > cmdFlags > goFlags.
+func (c *Command) mergeGoFlags(dir string, explicit, cmdFlags []string) []string {
+	return mergeFlags(explicit, append([][]string{cmdFlags}, c.goFlags(dir)...)...)
+}
+
+// goFlags returns the common flags of the go commands for dir in the order of
+// precedence, which are config.GoFlags, the project local .go-flags file and
+// the $GOFLAGS environment variable. Returns nil if the build tool is not go.
+func (c *Command) goFlags(dir string) [][]string {
+	if c.ctx.Build.Tool != "go" {
+		return nil
+	}
+
+	root := c.ctx.Build.ModuleRoot
+	if root == "" {
+		root = pathutil.FindVCSRoot(dir)
+	}
+	return [][]string{
+		config.GoFlags(),
+		readFlagsFile(filepath.Join(root, goFlagsFile)),
+		strings.Fields(os.Getenv("GOFLAGS")),
+	}
+}
+
+// readFlagsFile reads the whitespace separated flags from the fname file.
+// The lines which start with "#" are comments. Returns nil if fname does not
+// exist.
+func readFlagsFile(fname string) []string {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil
+	}
+
+	var flags []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		flags = append(flags, strings.Fields(line)...)
+	}
+	return flags
+}
+
+// mergeFlags merges the layers flags into the explicit command args, and
+// returns the merged args. The layers are in the order of precedence, the
+// flag which is already in the explicit args or the higher layers is skipped.
+// The merged flags are placed before the explicit args, so the args such as
+// the package paths stay last.
+//
+// The layer flag is the "-name=value" or the boolean "-name" form, the
+// following non-flag token is treated as the value of the previous flag.
+// The same flag in the one layer is kept, such as the repeated "-gcflags",
+// but the exact duplicate is dropped.
+func mergeFlags(explicit []string, layers ...[]string) []string {
+	seen := make(map[string]bool)
+	for _, arg := range explicit {
+		if name, ok := flagName(arg); ok {
+			seen[name] = true
+		}
+	}
+
+	var merged []string
+	for _, layer := range layers {
+		var added []string
+		dup := make(map[string]bool)
+		skip := false
+		for _, arg := range layer {
+			name, ok := flagName(arg)
+			if !ok {
+				// the value of the previous flag
+				if !skip && len(added) > 0 {
+					added = append(added, arg)
+				}
+				continue
+			}
+			skip = seen[name] || dup[arg]
+			if skip {
+				continue
+			}
+			dup[arg] = true
+			added = append(added, arg)
+		}
+		// mark after the layer, so the repeated flag in the same layer is kept
+		for _, arg := range added {
+			if name, ok := flagName(arg); ok {
+				seen[name] = true
+			}
+		}
+		merged = append(merged, added...)
+	}
+
+	return append(merged, explicit...)
+}
+
+// flagName returns the name of the arg flag such as "mod" of "-mod=vendor",
+// or false if arg is not a flag.
+func flagName(arg string) (string, bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", false
+	}
+	name := strings.TrimLeft(arg, "-")
+	if i := strings.Index(name, "="); i >= 0 {
+		name = name[:i]
+	}
+	return name, name != ""
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		explicit []string
+		layers   [][]string
+		want     []string
+	}{
+		{
+			name:     "precedence order",
+			explicit: []string{"-run", "TestFoo", "./..."},
+			layers: [][]string{
+				{"-v", "-mod=readonly"},   // command config
+				{"-mod=vendor", "-race"},  // config.GoFlags
+				{"-race", "-tags", "foo"}, // $GOFLAGS
+			},
+			want: []string{"-v", "-mod=readonly", "-race", "-tags", "foo", "-run", "TestFoo", "./..."},
+		},
+		{
+			name:     "explicit args override",
+			explicit: []string{"-mod=mod", "."},
+			layers:   [][]string{{"-mod=vendor", "-v"}, {"-tags", "foo"}},
+			want:     []string{"-v", "-tags", "foo", "-mod=mod", "."},
+		},
+		{
+			name:   "no double append",
+			layers: [][]string{{"-v", "-v", "-gcflags=-N", "-gcflags=-l"}, {"-v"}},
+			want:   []string{"-v", "-gcflags=-N", "-gcflags=-l"},
+		},
+		{
+			name:   "skipped flag value",
+			layers: [][]string{{"-tags", "foo"}, {"-tags", "bar", "-v"}},
+			want:   []string{"-tags", "foo", "-v"},
+		},
+		{
+			name:     "no layers",
+			explicit: []string{"-v"},
+			want:     []string{"-v"},
+		},
+	}
+	for _, tt := range tests {
+		if got := mergeFlags(tt.explicit, tt.layers...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. mergeFlags(%v, %v) = %v, want %v", tt.name, tt.explicit, tt.layers, got, tt.want)
+		}
+	}
+}
+
+func TestReadFlagsFile(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		goFlagsFile: "# the vendored dependencies\n-mod=vendor\n\n-tags foo -v\n",
+	})
+	defer cleanup()
+
+	want := []string{"-mod=vendor", "-tags", "foo", "-v"}
+	if got := readFlagsFile(filepath.Join(dir, goFlagsFile)); !reflect.DeepEqual(got, want) {
+		t.Errorf("readFlagsFile() = %v, want %v", got, want)
+	}
+	if got := readFlagsFile(filepath.Join(dir, "notexist")); got != nil {
+		t.Errorf("readFlagsFile(notexist) = %v, want nil", got)
+	}
+}
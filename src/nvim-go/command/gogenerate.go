@@ -0,0 +1,101 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgGenerate = "GoGenerate"
+
+func (c *Command) cmdGenerate(args []string, bang bool, dir string) {
+	go func() {
+		err := c.Generate(args, bang, dir)
+		c.saveError("Generate", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Generate runs "go generate" for the dir package, or the all packages under
+// dir if bang is true. The args are passed to go generate, such as
+// "-run regexp". The output is streamed into the scratch buffer, and the
+// errors are set to the quickfix list. Reloads the regenerated buffers.
+func (c *Command) Generate(args []string, bang bool, dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgGenerate)
+	c.ctx.SetContext(dir)
+
+	ctx, done := c.startOp(pkgGenerate)
+	defer done()
+
+	w, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	env := append(append([]string{}, c.ctx.Build.Env...), config.GenerateEnv()...)
+	cmd := generateCmd(ctx, dir, args, bang, env)
+	stamps, err := nvimutil.StampFiles(c.Nvim)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	nvimutil.EchoProgress(c.Nvim, pkgGenerate, "go generate")
+	output, runErr := c.runStream(cmd, "__GoGenerate__")
+	var errlist []*nvim.QuickfixError
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return runErr
+		}
+		errlist, err = nvimutil.ParseError(output, dir, &c.ctx.Build, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if len(errlist) > 0 {
+		if err := nvimutil.SetList(c.Nvim, w, nvimutil.Quickfix, "", errlist); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := nvimutil.OpenList(c.Nvim, w, nvimutil.Quickfix, errlist, true); err != nil {
+		return errors.WithStack(err)
+	}
+
+	files := stamps.ChangedFiles()
+	if err := nvimutil.ReloadChangedBuffers(c.Nvim, files); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return errors.New("go generate failed")
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, pkgGenerate, "go generate")
+}
+
+// generateCmd returns the "go generate" command which runs in the dir package
+// directory. The recursive runs for the all packages under dir. The env is
+// the extra environment variables for the build tool and the generators.
+func generateCmd(ctx context.Context, dir string, args []string, recursive bool, env []string) *exec.Cmd {
+	pkg := "."
+	if recursive {
+		pkg = "./..."
+	}
+
+	cmd := exec.CommandContext(ctx, "go", append(append([]string{"generate"}, args...), pkg)...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateCmd(t *testing.T) {
+	tests := []struct {
+		name      string
+		dir       string
+		args      []string
+		recursive bool
+		env       []string
+		wantArgs  []string
+		wantEnv   string // the last environment variable, or empty if inherits
+	}{
+		{
+			name:     "package",
+			dir:      "/go/src/foo",
+			wantArgs: []string{"go", "generate", "."},
+		},
+		{
+			name:      "recursive",
+			dir:       "/go/src/foo",
+			recursive: true,
+			wantArgs:  []string{"go", "generate", "./..."},
+		},
+		{
+			name:     "run regexp and env",
+			dir:      "/go/src/foo",
+			args:     []string{"-run", "stringer"},
+			env:      []string{"FOO=bar"},
+			wantArgs: []string{"go", "generate", "-run", "stringer", "."},
+			wantEnv:  "FOO=bar",
+		},
+	}
+	for _, tt := range tests {
+		cmd := generateCmd(context.Background(), tt.dir, tt.args, tt.recursive, tt.env)
+		if cmd.Dir != tt.dir {
+			t.Errorf("%q. generateCmd().Dir = %v, want %v", tt.name, cmd.Dir, tt.dir)
+		}
+		if args := append([]string{"go"}, cmd.Args[1:]...); !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%q. generateCmd().Args = %v, want %v", tt.name, args, tt.wantArgs)
+		}
+		var gotEnv string
+		if len(cmd.Env) > 0 {
+			gotEnv = cmd.Env[len(cmd.Env)-1]
+		}
+		if gotEnv != tt.wantEnv {
+			t.Errorf("%q. generateCmd().Env last = %v, want %v", tt.name, gotEnv, tt.wantEnv)
+		}
+	}
+}
@@ -10,10 +10,11 @@ package command
 
 import (
 	"bytes"
+	"crypto/sha1"
 	"fmt"
 	"go/build"
 	"go/token"
-	"log"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -36,6 +37,39 @@ type funcGuruEval struct {
 	File     string
 	Modified int
 	Offset   int
+
+	// end is the exclusive end byte offset of the selection, or zero.
+	end int
+}
+
+type cmdFreeVarsEval struct {
+	Cwd      string `msgpack:",array"`
+	File     string
+	Modified int
+	Offset   int
+	Start    []int // getpos("'<")
+	End      []int // getpos("'>")
+}
+
+// cmdFreeVars runs the GoGuru freevars query over the visual selection range.
+func (c *Command) cmdFreeVars(ranges [2]int, eval *cmdFreeVarsEval) {
+	go func() {
+		guruEval := &funcGuruEval{
+			Cwd:      eval.Cwd,
+			File:     eval.File,
+			Modified: eval.Modified,
+			Offset:   eval.Offset,
+		}
+		if start, end, ok := selectionPos(ranges, eval.Start, eval.End); ok {
+			so, eo, err := nvimutil.ByteOffsetRange(c.Nvim, nvim.Buffer(c.ctx.BufNr), start, end)
+			if err != nil {
+				nvimutil.ErrorWrap(c.Nvim, err)
+				return
+			}
+			guruEval.Offset, guruEval.end = so, eo
+		}
+		c.funcGuru([]string{"freevars"}, guruEval)
+	}()
 }
 
 func (c *Command) funcGuru(args []string, eval *funcGuruEval) {
@@ -52,19 +86,23 @@ func (c *Command) funcGuru(args []string, eval *funcGuruEval) {
 			errlist[k] = append(errlist[k], v...)
 			return true
 		})
-		nvimutil.ErrorList(c.Nvim, errlist, true)
+		nvimutil.ErrorList(c.Nvim, eval.Cwd, errlist, true)
 	}
 }
 
 // Guru go source analysis and output result to the quickfix or locationlist.
 func (c *Command) Guru(args []string, eval *funcGuruEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "Guru")
+	c.ctx.SetContext(filepath.Dir(eval.File))
 
 	mode := args[0]
 	if len(args) > 1 {
 		return guruHelp(c.Nvim, mode)
 	}
 
+	_, done := c.startOp("GoGuru " + mode)
+	defer done()
+
 	defer func() (err error) {
 		if r := recover(); r != nil {
 			err = errors.Errorf("guru internal panic.\nMaybe your set 'g:go#guru#reflection' to 1. Please retry with disable it option.\nOriginal panic message:\n\t%v", r.(error))
@@ -73,118 +111,361 @@ func (c *Command) Guru(args []string, eval *funcGuruEval) interface{} {
 		return nil
 	}()
 
-	b := nvim.Buffer(c.ctx.BufNr)
 	w := nvim.Window(c.ctx.WinID)
 	batch := c.Nvim.NewBatch()
 
-	guruContext := &build.Default
-
-	// https://github.com/golang/tools/blob/master/cmd/guru/main.go
-	if eval.Modified != 0 {
-		overlay := make(map[string][]byte)
-		var buf [][]byte
-
-		batch.BufferLines(b, 0, -1, true, &buf)
-		if err := batch.Execute(); err != nil {
-			return errors.WithStack(err)
-		}
-
-		overlay[eval.File] = bytes.Join(buf, []byte{'\n'})
-		guruContext = buildutil.OverlayContext(guruContext, overlay)
+	var loclist []*nvim.QuickfixError
+	query, err := c.guruQuery(eval)
+	if err != nil {
+		return err
 	}
 
-	var loclist []*nvim.QuickfixError
-	query := guru.Query{
-		Pos:        fmt.Sprintf("%s:#%d", eval.File, eval.Offset),
-		Build:      guruContext,
-		Reflection: config.GuruReflection,
+	var keepCursor bool
+	if int64(1) == config.GuruKeepCursor()[mode] {
+		keepCursor = true
 	}
+	jump := guruJumpOf(mode, keepCursor, config.GuruJumpFirst())
+	listType := nvimutil.ListTypeOf("Guru")
 
 	if mode == "definition" {
-		obj, err := Definition(&query)
+		obj, err := Definition(query)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		fname, line, col := nvimutil.SplitPos(obj.ObjPos, eval.Cwd)
-
-		batch.Command("normal! m'")
-		// TODO(zchee): should change nvimutil.SplitPos behavior
-		f := strings.Split(obj.ObjPos, ":")
-		if f[0] != eval.File {
-			batch.Command(fmt.Sprintf("keepjumps edit %s", pathutil.Rel(eval.Cwd, fname)))
+		if jump == guruJumpFirst {
+			return c.jumpDefinition(eval.Cwd, eval.File, obj.ObjPos)
 		}
-		batch.SetWindowCursor(w, [2]int{line, col - 1})
-		if err := batch.Execute(); err != nil {
+		loclist, err = parseResult(mode, obj, eval.Cwd)
+		if err != nil {
 			return errors.WithStack(err)
 		}
-
-		return c.Nvim.Command(`lclose | normal! zz`)
+		if err := nvimutil.SetList(c.Nvim, w, listType, eval.Cwd, loclist); err != nil {
+			return errors.WithStack(err)
+		}
+		return nvimutil.OpenList(c.Nvim, w, listType, loclist, jump == guruKeepList)
+	}
+	if mode == "what" {
+		return c.guruWhat(query)
 	}
 
-	var scope string
-	switch c.ctx.Build.Tool {
-	case "go":
-		pkgID, err := pathutil.PackageID(filepath.Dir(eval.File))
+	if guruScopeRequired[mode] {
+		scope, err := c.guruScope(filepath.Dir(eval.File))
 		if err != nil {
-			return errors.WithStack(err)
+			return errors.Wrapf(err, "%s mode requires the pointer analysis scope", mode)
 		}
-		scope = pkgID
-	case "gb":
-		scope = pathutil.GbProjectName(c.ctx.Build.ProjectRoot)
+		query.Scope = scope
 	}
-	query.Scope = append(query.Scope, filepath.Join(scope, "..."))
 
 	var (
-		outputMu sync.Mutex
-		err      error
+		outputMu  sync.Mutex
+		outputErr error
+		describe  *serial.Describe
 	)
 	output := func(fset *token.FileSet, qr guru.QueryResult) {
-		var err error
 		outputMu.Lock()
 		defer outputMu.Unlock()
+		if outputErr != nil {
+			return
+		}
 
 		res := qr.Result(fset)
-		if loclist, err = parseResult(mode, res, eval.Cwd); err != nil {
-			err = errors.WithStack(err)
+		if mode == "describe" && config.GuruDescribeVerbose() {
+			if desc, ok := isVerboseDescribe(res); ok {
+				describe = desc
+				return
+			}
 		}
-	}
-	if err != nil {
-		return errors.WithStack(err)
+		list, err := parseResult(mode, res, eval.Cwd)
+		if err != nil {
+			outputErr = errors.WithStack(err)
+			return
+		}
+		if len(list) == 0 {
+			return
+		}
+
+		// referrers mode outputs the result per package. Stream it into the
+		// locationlist as it arrives instead of waiting for the whole query.
+		if mode == "referrers" {
+			if len(loclist) == 0 {
+				if err := nvimutil.SetList(c.Nvim, w, listType, eval.Cwd, list); err != nil {
+					outputErr = errors.WithStack(err)
+					return
+				}
+				// keep the cursor in the source window if it jumps to the first position after the query
+				if err := nvimutil.OpenList(c.Nvim, w, listType, list, jump != guruOpenList); err != nil {
+					outputErr = errors.WithStack(err)
+					return
+				}
+			} else if err := nvimutil.AppendList(c.Nvim, w, listType, eval.Cwd, list); err != nil {
+				outputErr = errors.WithStack(err)
+				return
+			}
+		}
+		loclist = append(loclist, list...)
 	}
 	query.Output = output
 
 	nvimutil.EchoProgress(c.Nvim, "Guru", fmt.Sprintf("analysing %s", mode))
-	if err := guru.Run(mode, &query); err != nil {
+	if err := guru.Run(mode, query); err != nil {
 		return errors.WithStack(err)
 	}
+	if outputErr != nil {
+		return outputErr
+	}
+	if describe != nil {
+		defer nvimutil.ClearMsg(c.Nvim)
+		return c.describeBuffer(eval.Cwd, describe)
+	}
 	if len(loclist) == 0 {
-		return errors.Errorf("%s not found", mode)
+		return nvimutil.Info(c.Nvim, "Guru", "%s not found", mode)
 	}
 
-	defer nvimutil.ClearMsg(c.Nvim)
-	if err := nvimutil.SetLoclist(c.Nvim, loclist); err != nil {
-		return errors.WithStack(err)
+	if mode == "referrers" {
+		defer nvimutil.EchoSuccess(c.Nvim, "Guru", fmt.Sprintf("%d references found", len(loclist)))
+	} else {
+		defer nvimutil.ClearMsg(c.Nvim)
+		if err := nvimutil.SetList(c.Nvim, w, listType, eval.Cwd, loclist); err != nil {
+			return errors.WithStack(err)
+		}
 	}
 
-	// jumpfirst or definition mode
-	if config.GuruJumpFirst {
-		batch.Command(`silent ll 1`)
+	if jump == guruJumpFirst {
+		batch.Command(nvimutil.JumpFirstCmd(listType))
 		batch.Command(`normal! zz`)
 		return batch.Execute()
 	}
 
-	var keepCursor bool
-	if int64(1) == config.GuruKeepCursor[mode] {
-		keepCursor = true
+	if mode == "referrers" {
+		// already opened while streaming
+		return nil
+	}
+	return nvimutil.OpenList(c.Nvim, w, listType, loclist, jump == guruKeepList)
+}
+
+// guruJump represents how Guru shows the result.
+type guruJump int
+
+const (
+	// guruOpenList opens the list and moves the cursor to it.
+	guruOpenList guruJump = iota
+	// guruKeepList opens the list and keeps the cursor in the source window.
+	guruKeepList
+	// guruJumpFirst jumps to the first position of the result.
+	guruJumpFirst
+)
+
+// guruJumpOf returns how Guru shows the result of mode.
+// g:go#guru#jump_first takes precedence over g:go#guru#keep_cursor, and the
+// keep_cursor takes precedence over the definition mode, which jumps to the
+// definition by default.
+func guruJumpOf(mode string, keepCursor, jumpFirst bool) guruJump {
+	switch {
+	case jumpFirst:
+		return guruJumpFirst
+	case keepCursor:
+		return guruKeepList
+	case mode == "definition":
+		return guruJumpFirst
+	}
+	return guruOpenList
+}
+
+// guruQuery returns the guru query at the cursor of eval. The modified buffer
+// is passed to guru as the overlay of the file.
+func (c *Command) guruQuery(eval *funcGuruEval) (*guru.Query, error) {
+	guruContext := &build.Default
+
+	// https://github.com/golang/tools/blob/master/cmd/guru/main.go
+	var fileHash string
+	if eval.Modified != 0 {
+		overlay := make(map[string][]byte)
+		buf, err := c.Nvim.BufferLines(nvim.Buffer(c.ctx.BufNr), 0, -1, true)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		overlay[eval.File] = bytes.Join(buf, []byte{'\n'})
+		guruContext = buildutil.OverlayContext(guruContext, overlay)
+		fileHash = fmt.Sprintf("%s:%x", eval.File, sha1.Sum(overlay[eval.File]))
+	}
+
+	query := &guru.Query{
+		Pos:        guruPos(eval.File, eval.Offset, eval.end),
+		Build:      guruContext,
+		Reflection: config.GuruReflection(),
+		FileHash:   fileHash,
+	}
+	if config.GuruCache() {
+		query.Cache = c.guruCache
+	}
+	return query, nil
+}
+
+// guruPos returns the guru query position of the file. It's the "file:#start,#end"
+// range form if end is greater than start, such as the freevars selection.
+func guruPos(file string, start, end int) string {
+	if end > start {
+		return fmt.Sprintf("%s:#%d,#%d", file, start, end)
+	}
+	return fmt.Sprintf("%s:#%d", file, start)
+}
+
+// guruWhat echoes the kind of the selected syntax node and the guru modes
+// which are applicable at the cursor. The what query doesn't need the scope.
+func (c *Command) guruWhat(query *guru.Query) error {
+	var what *serial.What
+	query.Output = func(fset *token.FileSet, qr guru.QueryResult) {
+		what, _ = qr.Result(fset).(*serial.What)
+	}
+	if err := guru.Run("what", query); err != nil {
+		return errors.WithStack(err)
+	}
+	if what == nil {
+		return nvimutil.Info(c.Nvim, "GoGuruWhat", "not found")
+	}
+
+	return nvimutil.Echomsg(c.Nvim, "GoGuruWhat:", whatSummary(what))
+}
+
+// whatKind returns the description of the innermost enclosing syntax node,
+// with the identified object name if any.
+func whatKind(what *serial.What) string {
+	kind := "unknown"
+	if len(what.Enclosing) > 0 {
+		kind = what.Enclosing[0].Description
+	}
+	if what.Object != "" {
+		kind += fmt.Sprintf(" %q", what.Object)
 	}
-	return nvimutil.OpenLoclist(c.Nvim, w, loclist, keepCursor)
+	return kind
+}
+
+// whatSummary returns the summary of the what query result.
+// like:
+//  identifier "Foo" [modes: definition, describe, referrers]
+func whatSummary(what *serial.What) string {
+	return fmt.Sprintf("%s [modes: %s]", whatKind(what), strings.Join(what.Modes, ", "))
+}
+
+// guruDefinitionCmd returns the command of open the definition file by mode.
+// Returns the empty string if the definition is in the same file, and just jump to it.
+func guruDefinitionCmd(mode string, sameFile bool, fname string) string {
+	if sameFile {
+		return ""
+	}
+
+	switch mode {
+	case "split", "vsplit":
+		return fmt.Sprintf("keepjumps %s %s", mode, fname)
+	case "tab":
+		return fmt.Sprintf("keepjumps tabedit %s", fname)
+	default: // "edit"
+		return fmt.Sprintf("keepjumps edit %s", fname)
+	}
+}
+
+// guruModes is the list of the guru query modes.
+var guruModes = []string{
+	"callees",
+	"callers",
+	"callstack",
+	"definition",
+	"describe",
+	"freevars",
+	"implements",
+	"peers",
+	"pointsto",
+	"referrers",
+	"what",
+	"whicherrs",
+}
+
+// cmdGuruComplete returns the guru modes which has the ArgLead prefix.
+func (c *Command) cmdGuruComplete(a *nvim.CommandCompletionArgs) ([]string, error) {
+	var modes []string
+	for _, mode := range guruModes {
+		if strings.HasPrefix(mode, a.ArgLead) {
+			modes = append(modes, mode)
+		}
+	}
+
+	return modes, nil
+}
+
+// guruScopeRequired represents whether the guru mode requires the pointer
+// analysis scope. The other modes only analyse the query package and its
+// dependencies, so setting the scope just makes them slow.
+var guruScopeRequired = map[string]bool{
+	"callees":    true,
+	"callers":    true,
+	"callstack":  true,
+	"definition": false,
+	"describe":   false,
+	"freevars":   false,
+	"implements": true, // optional, but searches the implementations over the scope
+	"peers":      true,
+	"pointsto":   true,
+	"referrers":  false,
+	"what":       false,
+	"whicherrs":  true,
+}
+
+// guruScope returns the pointer analysis scope of dir.
+//
+// The scope is the config.GuruScope() if set, otherwise estimated from the
+// module path of go.mod, the gb project name, the package ID or the path after
+// the src directory, in that order.
+func (c *Command) guruScope(dir string) ([]string, error) {
+	if len(config.GuruScope()) > 0 {
+		return config.GuruScope(), validateGuruScope(config.GuruScope(), dir)
+	}
+
+	var scope string
+	if modpath, err := pathutil.ModulePath(dir); err == nil {
+		scope = modpath
+	} else if c.ctx.Build.Tool == "gb" {
+		scope = pathutil.GbProjectName(c.ctx.Build.ProjectRoot)
+	} else if pkgID, err := pathutil.PackageID(dir); err == nil {
+		scope = pkgID
+	} else if importPath, err := pathutil.ImportPathFromDir(build.Default.GOPATH, dir); err == nil {
+		scope = importPath
+	} else {
+		return nil, errors.Errorf("couldn't estimate the guru scope of %s. Please set the g:go#guru#scope", dir)
+	}
+
+	pkgs := []string{path.Join(scope, "...")}
+	return pkgs, validateGuruScope(pkgs, dir)
+}
+
+// validateGuruScope checks whether the packages in scope actually exists.
+func validateGuruScope(scope []string, dir string) error {
+	for _, pkg := range scope {
+		// skip the excluded packages
+		if strings.HasPrefix(pkg, "-") {
+			continue
+		}
+		pkg = strings.TrimSuffix(pkg, "/...")
+		if strings.Contains(pkg, "...") {
+			continue
+		}
+		if _, err := build.Default.Import(pkg, dir, build.FindOnly); err != nil {
+			return errors.Errorf("guru scope package %q not found", pkg)
+		}
+	}
+	return nil
+}
+
+// InvalidateGuruCache discards the cached pointer analysis program of GoGuru.
+func (c *Command) InvalidateGuruCache() {
+	c.guruCache.Invalidate()
 }
 
 var errTypeAssertion = errors.New("type assertion error")
 
 func parseResult(mode string, res interface{}, cwd string) ([]*nvim.QuickfixError, error) {
-	if config.DebugEnable {
-		log.Printf("res:\n%+v\n", spew.Sdump(res))
+	if config.DebugEnable() {
+		nvimutil.NewLogger("guru").Debugf("res:\n%s", spew.Sdump(res))
 	}
 	var (
 		loclist []*nvim.QuickfixError
@@ -243,13 +524,41 @@ func parseResult(mode string, res interface{}, cwd string) ([]*nvim.QuickfixErro
 			})
 		}
 
+	case "definition":
+		value, ok := res.(*serial.Definition)
+		if !ok {
+			return loclist, errTypeAssertion
+		}
+		fname, line, col = nvimutil.SplitPos(value.ObjPos, cwd)
+		loclist = append(loclist, &nvim.QuickfixError{
+			FileName: fname,
+			LNum:     line,
+			Col:      col,
+			Text:     value.Desc,
+		})
+
 	case "describe":
-		value, ok := res.(serial.Describe)
+		value, ok := res.(*serial.Describe)
 		if !ok {
 			return loclist, errTypeAssertion
 		}
-		fname, line, col = nvimutil.SplitPos(value.Value.ObjPos, cwd)
-		text = value.Desc + " " + value.Value.Type
+		pos := value.Pos
+		text = value.Desc
+		switch {
+		case value.Value != nil:
+			if value.Value.ObjPos != "" {
+				pos = value.Value.ObjPos
+			}
+			text += " " + value.Value.Type
+		case value.Type != nil:
+			if value.Type.NamePos != "" {
+				pos = value.Type.NamePos
+			}
+			text += " " + value.Type.Type
+		case value.Package != nil:
+			text += " " + value.Package.Path
+		}
+		fname, line, col = nvimutil.SplitPos(pos, cwd)
 		loclist = append(loclist, &nvim.QuickfixError{
 			FileName: fname,
 			LNum:     line,
@@ -369,6 +678,8 @@ func parseResult(mode string, res interface{}, cwd string) ([]*nvim.QuickfixErro
 	// TODO(zchee): Support serial.ReferrersInitial type
 	case "referrers":
 		switch value := res.(type) {
+		case *serial.ReferrersInitial:
+			// nothing to do
 		case serial.ReferrersPackage:
 			for _, v := range value.Refs {
 				fname, line, col := nvimutil.SplitPos(v.Pos, cwd)
@@ -383,7 +694,20 @@ func parseResult(mode string, res interface{}, cwd string) ([]*nvim.QuickfixErro
 			return loclist, errTypeAssertion
 		}
 
-	// TODO(zchee): implements what mode
+	case "what":
+		value, ok := res.(*serial.What)
+		if !ok {
+			return loclist, errTypeAssertion
+		}
+		for _, v := range value.SameIDs {
+			fname, line, col := nvimutil.SplitPos(v, cwd)
+			loclist = append(loclist, &nvim.QuickfixError{
+				FileName: fname,
+				LNum:     line,
+				Col:      col,
+				Text:     whatKind(value),
+			})
+		}
 
 	case "whicherrs":
 		value, ok := res.(*serial.WhichErrs)
@@ -0,0 +1,169 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+// describeEntry represents an entry line of the GoGuru describe buffer.
+type describeEntry struct {
+	pos  string // "file:line:col" form
+	text string
+}
+
+// describeEntries returns the describe buffer entries of desc, such as the
+// method set of the type, or the members of the package.
+func describeEntries(desc *serial.Describe) []describeEntry {
+	entries := []describeEntry{{pos: desc.Pos, text: desc.Desc}}
+
+	switch {
+	case desc.Type != nil:
+		text := "type " + desc.Type.Type
+		if desc.Type.NameDef != "" {
+			text += " " + desc.Type.NameDef
+		}
+		entries = append(entries, describeEntry{pos: desc.Type.NamePos, text: text})
+		for _, m := range desc.Type.Methods {
+			entries = append(entries, describeEntry{pos: m.Pos, text: m.Name})
+		}
+
+	case desc.Value != nil:
+		text := "value " + desc.Value.Type
+		if desc.Value.Value != "" {
+			text += " = " + desc.Value.Value
+		}
+		entries = append(entries, describeEntry{pos: desc.Value.ObjPos, text: text})
+
+	case desc.Package != nil:
+		entries = append(entries, describeEntry{pos: desc.Pos, text: "package " + desc.Package.Path})
+		for _, m := range desc.Package.Members {
+			text := m.Kind + " " + m.Name
+			if m.Type != "" {
+				text += " " + m.Type
+			}
+			if m.Value != "" {
+				text += " = " + m.Value
+			}
+			entries = append(entries, describeEntry{pos: m.Pos, text: text})
+			for _, mm := range m.Methods {
+				entries = append(entries, describeEntry{pos: mm.Pos, text: "\t" + mm.Name})
+			}
+		}
+	}
+
+	return entries
+}
+
+// structFields returns the field entries of the struct type which is declared
+// at the namePos position. Returns nil if the type is not a struct.
+func structFields(namePos string) ([]describeEntry, error) {
+	m := defPosRe.FindStringSubmatch(namePos)
+	if m == nil {
+		return nil, errors.Errorf("invalid type position: %q", namePos)
+	}
+	fname := m[1]
+	line, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fname, nil, 0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var fields []describeEntry
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return fields == nil
+		}
+		if pos := fset.Position(spec.Name.Pos()); pos.Line != line || pos.Column != col {
+			return false
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+
+		for _, field := range st.Fields.List {
+			typ := types.ExprString(field.Type)
+			if len(field.Names) == 0 { // embedded field
+				fields = append(fields, describeEntry{pos: fset.Position(field.Type.Pos()).String(), text: "field " + typ})
+				continue
+			}
+			for _, name := range field.Names {
+				fields = append(fields, describeEntry{pos: fset.Position(name.Pos()).String(), text: fmt.Sprintf("field %s %s", name.Name, typ)})
+			}
+		}
+		return false
+	})
+
+	return fields, nil
+}
+
+// describeBuffer renders the verbose describe result into the scratch buffer.
+// Each line is "file:line:col: text" form, so the <CR> jumps to the position.
+func (c *Command) describeBuffer(cwd string, desc *serial.Describe) error {
+	entries := describeEntries(desc)
+	if desc.Type != nil && desc.Type.NamePos != "" {
+		fields, err := structFields(desc.Type.NamePos)
+		if err != nil {
+			return err
+		}
+		// insert the fields after the type entry
+		entries = append(entries[:2], append(fields, entries[2:]...)...)
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if e.pos == "" || e.pos == "-" {
+			fmt.Fprintf(&buf, "%s\n", e.text)
+			continue
+		}
+		fname, line, col := nvimutil.SplitPos(e.pos, cwd)
+		fmt.Fprintf(&buf, "%s:%d:%d: %s\n", fname, line, col, e.text)
+	}
+
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+		nvimutil.BufferMapping: {
+			"<CR>": "gF",
+		},
+	}
+	b := nvimutil.NewBuffer(c.Nvim)
+	b.Reuse = true
+	if _, err := b.Create("__GoGuruDescribe__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return b.SetBufferLines(0, -1, true, bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
+}
+
+// isVerboseDescribe reports whether the res is the describe result which has
+// the details to render into the describe buffer.
+func isVerboseDescribe(res interface{}) (*serial.Describe, bool) {
+	desc, ok := res.(*serial.Describe)
+	if !ok {
+		return nil, false
+	}
+	return desc, desc.Type != nil || desc.Package != nil
+}
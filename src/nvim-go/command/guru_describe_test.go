@@ -0,0 +1,79 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+func TestDescribeEntries(t *testing.T) {
+	desc := &serial.Describe{
+		Desc:   "identifier",
+		Pos:    "/go/src/foo.org/foo/foo.go:5:6",
+		Detail: "type",
+		Type: &serial.DescribeType{
+			Type:    "foo.org/foo.Foo",
+			NamePos: "/go/src/foo.org/foo/foo.go:5:6",
+			NameDef: "struct{Name string; Age int}",
+			Methods: []serial.DescribeMethod{
+				{Name: "method (Foo) String() string", Pos: "/go/src/foo.org/foo/foo.go:10:14"},
+				{Name: "method (*Foo) SetName(name string)", Pos: "/go/src/foo.org/foo/foo.go:14:15"},
+			},
+		},
+	}
+
+	want := []describeEntry{
+		{pos: "/go/src/foo.org/foo/foo.go:5:6", text: "identifier"},
+		{pos: "/go/src/foo.org/foo/foo.go:5:6", text: "type foo.org/foo.Foo struct{Name string; Age int}"},
+		{pos: "/go/src/foo.org/foo/foo.go:10:14", text: "method (Foo) String() string"},
+		{pos: "/go/src/foo.org/foo/foo.go:14:15", text: "method (*Foo) SetName(name string)"},
+	}
+	if got := describeEntries(desc); !reflect.DeepEqual(got, want) {
+		t.Errorf("describeEntries(%v) = %v, want %v", desc, got, want)
+	}
+}
+
+func TestStructFields(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "foo.go")
+	src := `package foo
+
+type Bar struct{}
+
+type Foo struct {
+	Bar
+	Name, Alias string
+	Age         int
+}
+`
+	if err := ioutil.WriteFile(fname, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []describeEntry{
+		{pos: fname + ":6:2", text: "field Bar"},
+		{pos: fname + ":7:2", text: "field Name string"},
+		{pos: fname + ":7:8", text: "field Alias string"},
+		{pos: fname + ":8:2", text: "field Age int"},
+	}
+	got, err := structFields(fname + ":5:6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("structFields(%v) = %v, want %v", fname+":5:6", got, want)
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"encoding/json"
+	"go/build"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nvim-go/internal/guru"
+
+	"github.com/neovim/go-client/nvim"
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+func TestGuruDefinitionCmd(t *testing.T) {
+	type args struct {
+		mode     string
+		sameFile bool
+		fname    string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "edit",
+			args: args{mode: "edit", fname: "foo/foo.go"},
+			want: "keepjumps edit foo/foo.go",
+		},
+		{
+			name: "split",
+			args: args{mode: "split", fname: "foo/foo.go"},
+			want: "keepjumps split foo/foo.go",
+		},
+		{
+			name: "vsplit",
+			args: args{mode: "vsplit", fname: "foo/foo.go"},
+			want: "keepjumps vsplit foo/foo.go",
+		},
+		{
+			name: "tab",
+			args: args{mode: "tab", fname: "foo/foo.go"},
+			want: "keepjumps tabedit foo/foo.go",
+		},
+		{
+			name: "empty mode (use edit)",
+			args: args{mode: "", fname: "foo/foo.go"},
+			want: "keepjumps edit foo/foo.go",
+		},
+		{
+			name: "same file",
+			args: args{mode: "vsplit", sameFile: true, fname: "foo/foo.go"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := guruDefinitionCmd(tt.args.mode, tt.args.sameFile, tt.args.fname); got != tt.want {
+				t.Errorf("%q. guruDefinitionCmd(%v, %v, %v) = %v, want %v", tt.name, tt.args.mode, tt.args.sameFile, tt.args.fname, got, tt.want)
+			}
+		})
+	}
+}
+
+const testWhatJSON = `{
+	"enclosing": [
+		{"desc": "identifier", "start": 180, "end": 183},
+		{"desc": "selector", "start": 176, "end": 183},
+		{"desc": "function declaration", "start": 120, "end": 240},
+		{"desc": "source file", "start": 0, "end": 260}
+	],
+	"modes": ["callers", "callstack", "definition", "describe", "freevars", "implements", "pointsto", "referrers", "whicherrs"],
+	"srcdir": "/go/src",
+	"importpath": "foo.org/foo",
+	"object": "Foo",
+	"sameids": ["/go/src/foo.org/foo/foo.go:5:6", "/go/src/foo.org/foo/foo.go:12:10"]
+}`
+
+func TestParseResult_What(t *testing.T) {
+	var what serial.What
+	if err := json.Unmarshal([]byte(testWhatJSON), &what); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*nvim.QuickfixError{
+		{FileName: "foo.go", LNum: 5, Col: 6, Text: `identifier "Foo"`},
+		{FileName: "foo.go", LNum: 12, Col: 10, Text: `identifier "Foo"`},
+	}
+	got, err := parseResult("what", &what, "/go/src/foo.org/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseResult(what) = %v, want %v", got, want)
+	}
+
+	wantSummary := `identifier "Foo" [modes: callers, callstack, definition, describe, freevars, implements, pointsto, referrers, whicherrs]`
+	if got := whatSummary(&what); got != wantSummary {
+		t.Errorf("whatSummary() = %v, want %v", got, wantSummary)
+	}
+}
+
+func TestGuruJumpOf(t *testing.T) {
+	tests := []struct {
+		mode       string
+		keepCursor bool
+		jumpFirst  bool
+		want       guruJump
+	}{
+		{mode: "referrers", want: guruOpenList},
+		{mode: "referrers", keepCursor: true, want: guruKeepList},
+		{mode: "referrers", jumpFirst: true, want: guruJumpFirst},
+		{mode: "referrers", keepCursor: true, jumpFirst: true, want: guruJumpFirst},
+		{mode: "definition", want: guruJumpFirst},
+		{mode: "definition", keepCursor: true, want: guruKeepList},
+		{mode: "definition", jumpFirst: true, want: guruJumpFirst},
+		{mode: "definition", keepCursor: true, jumpFirst: true, want: guruJumpFirst},
+	}
+	for _, tt := range tests {
+		if got := guruJumpOf(tt.mode, tt.keepCursor, tt.jumpFirst); got != tt.want {
+			t.Errorf("guruJumpOf(%q, keepCursor: %v, jumpFirst: %v) = %v, want %v", tt.mode, tt.keepCursor, tt.jumpFirst, got, tt.want)
+		}
+	}
+}
+
+func TestParseResult_Definition(t *testing.T) {
+	def := &serial.Definition{ObjPos: "/go/src/foo.org/foo/foo.go:5:6", Desc: "func foo.Foo()"}
+	want := []*nvim.QuickfixError{
+		{FileName: "foo.go", LNum: 5, Col: 6, Text: "func foo.Foo()"},
+	}
+	got, err := parseResult("definition", def, "/go/src/foo.org/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseResult(definition) = %v, want %v", got, want)
+	}
+}
+
+func TestGuruScopeRequired(t *testing.T) {
+	tests := []struct {
+		mode string
+		want bool
+	}{
+		{mode: "callees", want: true},
+		{mode: "callers", want: true},
+		{mode: "callstack", want: true},
+		{mode: "definition", want: false},
+		{mode: "describe", want: false},
+		{mode: "freevars", want: false},
+		{mode: "implements", want: true},
+		{mode: "peers", want: true},
+		{mode: "pointsto", want: true},
+		{mode: "referrers", want: false},
+		{mode: "what", want: false},
+		{mode: "whicherrs", want: true},
+		{mode: "unknown", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Parallel()
+			if got := guruScopeRequired[tt.mode]; got != tt.want {
+				t.Errorf("guruScopeRequired[%q] = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand_cmdGuruComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		argLead string
+		want    []string
+	}{
+		{
+			name:    "empty prefix",
+			argLead: "",
+			want:    guruModes,
+		},
+		{
+			name:    "callers prefix",
+			argLead: "call",
+			want:    []string{"callees", "callers", "callstack"},
+		},
+		{
+			name:    "no match",
+			argLead: "foo",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := new(Command)
+			a := &nvim.CommandCompletionArgs{ArgLead: tt.argLead}
+			got, err := c.cmdGuruComplete(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q. Command.cmdGuruComplete(%v) = %v, want %v", tt.name, tt.argLead, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuruFreevars_Selection(t *testing.T) {
+	src := `package foo
+
+type point struct{ x, y int }
+
+func f(p point, scale int) int {
+	offset := 1
+	sum := p.x * scale
+	sum += p.y + offset
+	return sum
+}
+`
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": src})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+
+	// select the two statements which refer the outer p, scale and offset
+	start := strings.Index(src, "sum := p.x")
+	end := strings.Index(src, "\n\treturn")
+
+	var list []*nvim.QuickfixError
+	query := guru.Query{
+		Pos:   guruPos(fname, start, end),
+		Build: &build.Default,
+		Output: func(fset *token.FileSet, qr guru.QueryResult) {
+			var err error
+			list, err = parseResult("freevars", qr.Result(fset), dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	if err := guru.Run("freevars", &query); err != nil {
+		t.Fatalf("guru.Run(freevars, %v) error = %v", query.Pos, err)
+	}
+
+	var got []string
+	for _, e := range list {
+		got = append(got, e.Text)
+	}
+	want := []string{"var int offset", "var int p.x", "var int p.y", "var int scale"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("freevars(%v) = %v, want %v", query.Pos, got, want)
+	}
+}
+
+func TestGuruPos(t *testing.T) {
+	tests := []struct {
+		start, end int
+		want       string
+	}{
+		{start: 10, want: "foo.go:#10"},
+		{start: 10, end: 20, want: "foo.go:#10,#20"},
+	}
+	for _, tt := range tests {
+		if got := guruPos("foo.go", tt.start, tt.end); got != tt.want {
+			t.Errorf("guruPos(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/ast"
+	"sync"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const pkgHighlightReferences = "GoHighlightReferences"
+
+// referencesNamespace is the namespace name of the GoHighlightReferences highlights.
+const referencesNamespace = "nvim-go-references"
+
+// errNoIdentifier is the error of the cursor is not on the identifier.
+var errNoIdentifier = errors.New("no identifier under the cursor")
+
+// CmdHighlightReferencesEval represents a GoHighlightReferences command Eval args.
+type CmdHighlightReferencesEval struct {
+	File   string `msgpack:",array"`
+	Offset int
+	Tick   int // b:changedtick
+}
+
+// referencesState represents the highlighted references.
+type referencesState struct {
+	mu     sync.Mutex
+	buffer nvim.Buffer // the highlighted buffer, or 0
+	// gen is incremented by each clear, so the highlight which started
+	// before the cursor moved is discarded.
+	gen int
+
+	// typed is the type-checked file of the last highlight, which is reused
+	// while the b:changedtick of the buffer is typedTick.
+	typed     *typedFile
+	typedName string
+	typedTick int
+}
+
+func (c *Command) cmdHighlightReferences(eval *CmdHighlightReferencesEval) {
+	go func() {
+		if err := c.HighlightReferences(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// HighlightReferences highlights the all occurrences of the identifier under
+// the cursor in the current file. The write occurrences are highlighted by
+// the GoReferenceWrite, and the others are the GoReferenceRead.
+func (c *Command) HighlightReferences(eval *CmdHighlightReferencesEval) error {
+	defer nvimutil.Profile(time.Now(), pkgHighlightReferences)
+
+	c.references.mu.Lock()
+	gen := c.references.gen
+	c.references.mu.Unlock()
+
+	b, f, err := c.referencesFile(eval.File, eval.Tick)
+	if err != nil {
+		return err
+	}
+	occurs, err := findOccurrences(f, eval.Offset)
+	if err != nil {
+		return err
+	}
+
+	var ns int
+	if err := c.Nvim.Call("nvim_create_namespace", &ns, referencesNamespace); err != nil {
+		return errors.WithStack(err)
+	}
+
+	c.references.mu.Lock()
+	defer c.references.mu.Unlock()
+	if c.references.gen != gen {
+		// the cursor moved while loading
+		return nil
+	}
+
+	batch := c.Nvim.NewBatch()
+	batch.Command("highlight default link GoReferenceRead Search")
+	batch.Command("highlight default link GoReferenceWrite IncSearch")
+	if c.references.buffer != 0 {
+		batch.Call("nvim_buf_clear_namespace", nil, c.references.buffer, ns, 0, -1)
+	}
+	for _, o := range occurs {
+		group := "GoReferenceRead"
+		if o.write {
+			group = "GoReferenceWrite"
+		}
+		batch.Call("nvim_buf_add_highlight", nil, b, ns, group, o.line-1, o.col-1, o.col-1+o.len)
+	}
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+	c.references.buffer = b
+
+	return nil
+}
+
+// referencesFile returns the current buffer and the type-checked file of it.
+// The cached file is used if the buffer is not changed since the last
+// highlight, so the CursorHold doesn't type-check the package each time.
+func (c *Command) referencesFile(file string, tick int) (nvim.Buffer, *typedFile, error) {
+	c.references.mu.Lock()
+	f := c.references.typed
+	cached := f != nil && c.references.typedName == file && c.references.typedTick == tick
+	c.references.mu.Unlock()
+	if cached {
+		return nvim.Buffer(c.ctx.BufNr), f, nil
+	}
+
+	b, _, src, err := c.bufferSource()
+	if err != nil {
+		return b, nil, err
+	}
+	f, err = loadTypedFile(file, src)
+	if err != nil {
+		return b, nil, err
+	}
+
+	c.references.mu.Lock()
+	c.references.typed = f
+	c.references.typedName = file
+	c.references.typedTick = tick
+	c.references.mu.Unlock()
+
+	return b, f, nil
+}
+
+// ClearReferences clears the highlights of HighlightReferences. It's called
+// on the cursor moved.
+func (c *Command) ClearReferences() error {
+	c.references.mu.Lock()
+	defer c.references.mu.Unlock()
+
+	c.references.gen++
+	if c.references.buffer == 0 {
+		return nil
+	}
+	b := c.references.buffer
+	c.references.buffer = 0
+
+	var ns int
+	if err := c.Nvim.Call("nvim_create_namespace", &ns, referencesNamespace); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(c.Nvim.Call("nvim_buf_clear_namespace", nil, b, ns, 0, -1))
+}
+
+// occurrence represents the occurrence of the identifier in the file.
+type occurrence struct {
+	line, col int // 1-based, col is the byte column
+	len       int
+	// write reports whether the occurrence is the declaration or the assignment.
+	write bool
+}
+
+// findOccurrences returns the occurrences of the object of the identifier at
+// the offset of f in the file, in order of the position.
+func findOccurrences(f *typedFile, offset int) ([]occurrence, error) {
+	node := f.enclosingNode(offset, func(n ast.Node) bool {
+		_, ok := n.(*ast.Ident)
+		return ok
+	})
+	if node == nil {
+		return nil, errNoIdentifier
+	}
+	obj := f.info.ObjectOf(node.(*ast.Ident))
+	if obj == nil {
+		return nil, errors.Errorf("couldn't resolve the %s", node.(*ast.Ident).Name)
+	}
+
+	writes := make(map[*ast.Ident]bool)
+	assigned := func(x ast.Expr) {
+		switch x := astutil.Unparen(x).(type) {
+		case *ast.Ident:
+			writes[x] = true
+		case *ast.SelectorExpr:
+			writes[x.Sel] = true
+		}
+	}
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, x := range n.Lhs {
+				assigned(x)
+			}
+		case *ast.IncDecStmt:
+			assigned(n.X)
+		case *ast.RangeStmt:
+			if n.Key != nil {
+				assigned(n.Key)
+			}
+			if n.Value != nil {
+				assigned(n.Value)
+			}
+		}
+		return true
+	})
+
+	var occurs []occurrence
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		def := f.info.Defs[ident] == obj
+		if !def && f.info.Uses[ident] != obj {
+			return true
+		}
+		pos := f.fset.Position(ident.Pos())
+		occurs = append(occurs, occurrence{
+			line:  pos.Line,
+			col:   pos.Column,
+			len:   len(ident.Name),
+			write: def || writes[ident],
+		})
+		return true
+	})
+
+	return occurs, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindOccurrences(t *testing.T) {
+	src := `package foo
+
+type T struct{ n int }
+
+func count(s []int) int {
+	n := 0
+	for _, v := range s {
+		n += v
+	}
+	t := T{}
+	t.n = n
+	(n)++
+	return t.n + len(s)
+}
+
+func other(n int) int { return n }
+`
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": src})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+
+	f, err := loadTypedFile(fname, []byte(src))
+	if err != nil {
+		t.Fatalf("loadTypedFile(%v) error = %v", fname, err)
+	}
+
+	tests := []struct {
+		name    string
+		at      string // the cursor is at the beginning of it
+		want    []occurrence
+		wantErr bool
+	}{
+		{
+			name: "local variable",
+			at:   "n := 0",
+			want: []occurrence{
+				{line: 6, col: 2, len: 1, write: true},
+				{line: 8, col: 3, len: 1, write: true},
+				{line: 11, col: 8, len: 1},
+				{line: 12, col: 3, len: 1, write: true},
+			},
+		},
+		{
+			name: "field",
+			at:   "n int }",
+			want: []occurrence{
+				{line: 3, col: 16, len: 1, write: true},
+				{line: 11, col: 4, len: 1, write: true},
+				{line: 13, col: 11, len: 1},
+			},
+		},
+		{
+			name: "parameter",
+			at:   "s []int",
+			want: []occurrence{
+				{line: 5, col: 12, len: 1, write: true},
+				{line: 7, col: 20, len: 1},
+				{line: 13, col: 19, len: 1},
+			},
+		},
+		{
+			name: "range value",
+			at:   "v :=",
+			want: []occurrence{
+				{line: 7, col: 9, len: 1, write: true},
+				{line: 8, col: 8, len: 1},
+			},
+		},
+		{
+			name: "type",
+			at:   "T{}",
+			want: []occurrence{
+				{line: 3, col: 6, len: 1, write: true},
+				{line: 10, col: 7, len: 1},
+			},
+		},
+		{
+			name:    "no identifier",
+			at:      "{ n int }",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		offset := strings.Index(src, tt.at)
+		got, err := findOccurrences(f, offset)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. findOccurrences(%v) error = %v, wantErr %v", tt.name, offset, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. findOccurrences(%v) = %+v, want %+v", tt.name, offset, got, tt.want)
+		}
+	}
+}
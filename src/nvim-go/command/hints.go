@@ -0,0 +1,177 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgHints = "GoHints"
+
+// hintsNamespace is the namespace name of the GoHints virtual text.
+const hintsNamespace = "nvim-go-hints"
+
+// CmdHintsEval represents a GoHints command Eval args. The Top and Bottom
+// are the visible line range of the current window.
+type CmdHintsEval struct {
+	File   string `msgpack:",array"`
+	Top    int
+	Bottom int
+}
+
+func (c *Command) cmdHints(eval *CmdHintsEval) {
+	go func() {
+		if err := c.Hints(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) cmdHintsClear() {
+	go func() {
+		if err := c.HintsClear(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) cmdHintsToggle(eval *CmdHintsEval) {
+	go func() {
+		if err := c.HintsToggle(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Hints shows the inferred types of the variables which declared by ":=" as
+// the end of line virtual text of the current buffer. Only the visible lines
+// of eval are annotated for the large files.
+func (c *Command) Hints(eval *CmdHintsEval) error {
+	defer nvimutil.Profile(time.Now(), pkgHints)
+
+	b, _, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	f, err := loadTypedFile(eval.File, src)
+	if err != nil {
+		return err
+	}
+
+	var ns int
+	if err := c.Nvim.Call("nvim_create_namespace", &ns, hintsNamespace); err != nil {
+		return errors.WithStack(err)
+	}
+
+	batch := c.Nvim.NewBatch()
+	batch.Command("highlight default link GoHint Comment")
+	batch.Call("nvim_buf_clear_namespace", nil, b, ns, 0, -1)
+	for _, h := range typeHints(f, eval.Top, eval.Bottom) {
+		batch.Call("nvim_buf_set_virtual_text", nil, b, ns, h.line-1, [][]string{{h.text, "GoHint"}}, map[string]interface{}{})
+	}
+	return errors.WithStack(batch.Execute())
+}
+
+// HintsClear clears the GoHints virtual text of the current buffer.
+func (c *Command) HintsClear() error {
+	var ns int
+	if err := c.Nvim.Call("nvim_create_namespace", &ns, hintsNamespace); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(c.Nvim.Call("nvim_buf_clear_namespace", nil, nvim.Buffer(c.ctx.BufNr), ns, 0, -1))
+}
+
+// HintsToggle toggles the config.HintsEnabled for the current session, and
+// shows or clears the hints of the current buffer.
+func (c *Command) HintsToggle(eval *CmdHintsEval) error {
+	if err := c.toggle(pkgHints, func(cfg *config.Config) *int64 { return &cfg.Hints.Enable }); err != nil {
+		return err
+	}
+	if config.HintsEnabled() {
+		return c.Hints(eval)
+	}
+	return c.HintsClear()
+}
+
+// typeHint represents the hint text of the line.
+type typeHint struct {
+	line int // 1-based
+	text string
+}
+
+// typeHints returns the hints of the variables which declared by ":=" between
+// the top and bottom lines of f, such as "x int, err error". The variables
+// of the same line are joined to the one hint.
+func typeHints(f *typedFile, top, bottom int) []typeHint {
+	qualifier := func(p *types.Package) string {
+		if p == f.pkg {
+			return ""
+		}
+		return p.Name()
+	}
+
+	var hints []typeHint
+	add := func(tokPos token.Pos, idents ...ast.Expr) {
+		var vars []string
+		for _, x := range idents {
+			ident, ok := x.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			// the redeclared variable and the blank identifier have no definition
+			obj := f.info.Defs[ident]
+			if obj == nil {
+				continue
+			}
+			vars = append(vars, ident.Name+" "+types.TypeString(obj.Type(), qualifier))
+		}
+		if len(vars) == 0 {
+			return
+		}
+
+		line := f.fset.Position(tokPos).Line
+		if line < top || line > bottom {
+			return
+		}
+		text := strings.Join(vars, ", ")
+		if n := len(hints); n > 0 && hints[n-1].line == line {
+			hints[n-1].text += ", " + text
+			return
+		}
+		hints = append(hints, typeHint{line: line, text: text})
+	}
+
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		if f.fset.Position(n.End()).Line < top || f.fset.Position(n.Pos()).Line > bottom {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				add(n.TokPos, n.Lhs...)
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				add(n.TokPos, n.Key, n.Value)
+			}
+		}
+		return true
+	})
+
+	return hints
+}
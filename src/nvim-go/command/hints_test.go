@@ -0,0 +1,80 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTypeHints(t *testing.T) {
+	// the file doesn't import bytes, the types are inferred from the other file
+	src := `package foo
+
+type T struct{}
+
+func run() error {
+	x := foo()
+	n, err := x.Write(nil)
+	n, err2 := 1, err
+	_ = n
+	for i, s := range []T{} {
+		_, _ = i, s
+	}
+	if v, ok := interface{}(x).(*T); ok {
+		_ = v
+	}
+	return err2
+}
+`
+	dir, cleanup := writePackage(t, map[string]string{
+		"foo.go": src,
+		"bar.go": "package foo\n\nimport \"bytes\"\n\nfunc foo() *bytes.Buffer { return nil }\n",
+	})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+
+	f, err := loadTypedFile(fname, []byte(src))
+	if err != nil {
+		t.Fatalf("loadTypedFile(%v) error = %v", fname, err)
+	}
+
+	tests := []struct {
+		name        string
+		top, bottom int
+		want        []typeHint
+	}{
+		{
+			name: "all lines",
+			top:  1, bottom: 18,
+			want: []typeHint{
+				{line: 6, text: "x *bytes.Buffer"},
+				{line: 7, text: "n int, err error"},
+				{line: 8, text: "err2 error"},
+				{line: 10, text: "i int, s T"},
+				{line: 13, text: "v *T, ok bool"},
+			},
+		},
+		{
+			name: "visible lines",
+			top:  7, bottom: 10,
+			want: []typeHint{
+				{line: 7, text: "n int, err error"},
+				{line: 8, text: "err2 error"},
+				{line: 10, text: "i int, s T"},
+			},
+		},
+		{
+			name: "no declarations",
+			top:  1, bottom: 5,
+		},
+	}
+	for _, tt := range tests {
+		if got := typeHints(f, tt.top, tt.bottom); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. typeHints(%d, %d) = %v, want %v", tt.name, tt.top, tt.bottom, got, tt.want)
+		}
+	}
+}
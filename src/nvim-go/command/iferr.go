@@ -27,7 +27,11 @@ import (
 )
 
 func (c *Command) cmdIferr(file string) {
-	go c.Iferr(file)
+	go func() {
+		if err := c.Iferr(file); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
 }
 
 // Iferr automatically insert 'if err' Go idiom by parse the current buffer's Go abstract syntax tree(AST).
@@ -37,9 +41,20 @@ func (c *Command) Iferr(file string) error {
 	b := nvim.Buffer(c.ctx.BufNr)
 	buflines, err := c.Nvim.BufferLines(b, 0, -1, true)
 	if err != nil {
-		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+		return errors.WithStack(err)
 	}
 
+	buf, err := iferrSource(file, nvimutil.ToByteSlice(buflines))
+	if err != nil {
+		return err
+	}
+
+	return errors.WithStack(c.Nvim.SetBufferLines(b, 0, -1, true, nvimutil.ToBufferLines(buf)))
+}
+
+// iferrSource inserts 'if err' Go idiom to the in source of file, and returns
+// the rewritten source.
+func iferrSource(file string, in []byte) ([]byte, error) {
 	conf := loader.Config{
 		ParserMode:  parser.ParseComments,
 		TypeChecker: types.Config{FakeImportC: true, DisableUnusedImportCheck: true},
@@ -48,22 +63,18 @@ func (c *Command) Iferr(file string) error {
 		AllowErrors: true,
 	}
 
-	var src bytes.Buffer
-	src.Write(nvimutil.ToByteSlice(buflines))
-
-	f, err := conf.ParseFile(file, src.Bytes())
+	f, err := conf.ParseFile(file, in)
 	if err != nil {
-		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+		return nil, errors.WithStack(err)
 	}
 
 	conf.CreateFromFiles(file, f)
 	prog, err := conf.Load()
 	if err != nil {
-		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+		return nil, errors.WithStack(err)
 	}
 
-	// Reuse src variable
-	src.Reset()
+	var src bytes.Buffer
 
 	for _, pkg := range prog.InitialPackages() {
 		for _, f := range pkg.Files {
@@ -73,8 +84,32 @@ func (c *Command) Iferr(file string) error {
 	}
 
 	// format.Node() will added pointless newline
-	buf := bytes.TrimSuffix(src.Bytes(), []byte{'\n'})
-	return c.Nvim.SetBufferLines(b, 0, -1, true, nvimutil.ToBufferLines(buf))
+	return bytes.TrimSuffix(src.Bytes(), []byte{'\n'}), nil
+}
+
+// IferrFmt inserts 'if err' Go idiom and formats the current buffer with the
+// single buffer update and write, instead of the Iferr buffer rewriting
+// followed by the Fmt formatting.
+// If the iferr fails, onIferrError is called with the error, and IferrFmt
+// formats the buffer without iferr only if onIferrError returns true.
+func (c *Command) IferrFmt(file string, onIferrError func(error) bool) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoIferrFmt")
+
+	b := nvim.Buffer(c.ctx.BufNr)
+	in, err := c.Nvim.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	src, err := iferrSource(file, nvimutil.ToByteSlice(in))
+	if err != nil {
+		if !onIferrError(err) {
+			return nil
+		}
+		src = nvimutil.ToByteSlice(in)
+	}
+
+	return c.format(b, in, src)
 }
 
 // The below code is copied from
@@ -0,0 +1,73 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nvim-go/config"
+	"nvim-go/ctx"
+	"nvim-go/nvimutil"
+)
+
+const iferrSrc = `package main
+
+import "os"
+
+func main() {
+	f, err := os.Open("foo")
+	_, _ = f, err
+}
+`
+
+func TestCommand_IferrFmt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-iferr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(file, []byte(iferrSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer config.Set(config.Update(func(cfg *config.Config) { cfg.Fmt.Mode = "fmt" }))
+
+	v := nvimutil.TestNvim(t, file)
+	b, err := v.CurrentBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCommand(v, ctx.NewContext())
+	c.ctx.BufNr = int(b)
+
+	// iferr -> fmt sequence with the single buffer update
+	if err := c.IferrFmt(file, func(err error) bool {
+		t.Fatalf("IferrFmt(%s) iferr error: %v", file, err)
+		return false
+	}); err != nil {
+		t.Fatalf("IferrFmt(%s) = %v, want nil", file, err)
+	}
+
+	lines, err := v.BufferLines(b, 0, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := nvimutil.ToByteSlice(lines)
+	if !bytes.Contains(got, []byte("if err != nil {")) {
+		t.Errorf("IferrFmt(%s) buffer = %s, want contains the if err idiom", file, got)
+	}
+	written, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSuffix(written, []byte{'\n'}), got) {
+		t.Errorf("IferrFmt(%s) written = %s, want %s", file, written, got)
+	}
+}
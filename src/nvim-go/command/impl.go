@@ -0,0 +1,226 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgImpl = "GoImpl"
+
+func (c *Command) cmdImpl(args []string, bang bool, file string) {
+	go func() {
+		if err := c.Impl(args, bang, file); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Impl generates the method stubs of the interface for the receiver, and
+// inserts them after the receiver type declaration. The args are the
+// receiver such as "f *File" and the interface such as "io.Reader".
+// The existing methods are kept, or overwritten if bang is true.
+func (c *Command) Impl(args []string, bang bool, file string) error {
+	defer nvimutil.Profile(time.Now(), pkgImpl)
+
+	if len(args) < 2 {
+		return errors.New("usage: GoImpl {receiver} {interface}")
+	}
+	recv := strings.Join(args[:len(args)-1], " ")
+	iface := args[len(args)-1]
+
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	var imports []string
+	if path, _ := splitIfaceName(iface); path != "" {
+		imports = append(imports, path)
+	}
+	f, err := loadTypedFile(file, src, imports...)
+	if err != nil {
+		return err
+	}
+
+	out, first, err := implStubs(f, recv, iface, bang)
+	if err != nil {
+		return err
+	}
+	if err := c.updateBuffer(b, in, out); err != nil {
+		return err
+	}
+
+	line, col := methodBodyPos(out, recvTypeName(recv), first)
+	if line == 0 {
+		return nil
+	}
+	return errors.WithStack(c.Nvim.SetWindowCursor(nvim.Window(c.ctx.WinID), [2]int{line, col - 1}))
+}
+
+// splitIfaceName splits the interface name to the import path and the type name.
+// like:
+//  splitIfaceName("io.Reader") == "io", "Reader"
+//  splitIfaceName("Reader") == "", "Reader"
+func splitIfaceName(iface string) (string, string) {
+	i := strings.LastIndex(iface, ".")
+	if i < 0 {
+		return "", iface
+	}
+	return iface[:i], iface[i+1:]
+}
+
+// recvTypeName returns the type name of the receiver such as "f *File".
+func recvTypeName(recv string) string {
+	fields := strings.Fields(recv)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fields[len(fields)-1], "*")
+}
+
+// lookupInterface looks up the iface interface type from the package scope of f,
+// or the imported package.
+func (f *typedFile) lookupInterface(iface string) (*types.Interface, error) {
+	path, name := splitIfaceName(iface)
+
+	scope := f.pkg.Scope()
+	if path != "" {
+		// the package name of the file imports, such as the renamed import or
+		// the package whose name differs from the import path
+		if pkgName, ok := f.info.Scopes[f.file].Lookup(path).(*types.PkgName); ok {
+			scope = pkgName.Imported().Scope()
+		} else {
+			info := f.prog.Package(path)
+			if info == nil {
+				return nil, errors.Errorf("couldn't load the %s package", path)
+			}
+			scope = info.Pkg.Scope()
+		}
+	}
+
+	obj, ok := scope.Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, errors.Errorf("couldn't find the %s type", iface)
+	}
+	it, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, errors.Errorf("%s is not an interface", iface)
+	}
+	return it, nil
+}
+
+// implStubs returns the f source which the method stubs of the iface
+// inserted, and the first generated method name.
+func implStubs(f *typedFile, recv, iface string, bang bool) ([]byte, string, error) {
+	it, err := f.lookupInterface(iface)
+	if err != nil {
+		return nil, "", err
+	}
+
+	typeName := recvTypeName(recv)
+	obj, ok := f.pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, "", errors.Errorf("couldn't find the receiver type %s", typeName)
+	}
+	mset := types.NewMethodSet(types.NewPointer(obj.Type()))
+
+	// insert after the receiver type declaration, or at the end of file
+	insert := len(f.src)
+	for _, decl := range f.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				if spec.(*ast.TypeSpec).Name.Name == typeName {
+					insert = f.offset(gen.End())
+				}
+			}
+		}
+	}
+
+	var (
+		edits []textEdit
+		stubs bytes.Buffer
+		first string
+	)
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		if sel := mset.Lookup(m.Pkg(), m.Name()); sel != nil {
+			if !bang {
+				continue
+			}
+			decl := f.funcDecl(sel.Obj())
+			if decl == nil {
+				continue // declared in the other file, or promoted
+			}
+			start := decl.Pos()
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+			edits = append(edits, textEdit{start: f.offset(start), end: f.offset(decl.End())})
+		}
+
+		if first == "" {
+			first = m.Name()
+		}
+		fmt.Fprintf(&stubs, "\n\nfunc (%s) %s", recv, m.Name())
+		types.WriteSignature(&stubs, m.Type().(*types.Signature), f.qualifier)
+		stubs.WriteString(" {\n\tpanic(\"not implemented\")\n}")
+	}
+	if first == "" {
+		return nil, "", errors.Errorf("%s already implements %s", typeName, iface)
+	}
+	edits = append(edits, textEdit{start: insert, end: insert, text: stubs.String()})
+
+	out, err := f.apply(edits)
+	return out, first, err
+}
+
+// funcDecl returns the function declaration of obj in f, or nil if obj is
+// not declared in f.
+func (f *typedFile) funcDecl(obj types.Object) *ast.FuncDecl {
+	for _, decl := range f.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Pos() == obj.Pos() {
+			return fn
+		}
+	}
+	return nil
+}
+
+// methodBodyPos returns the 1-based line and column of the first statement
+// in the method body of the typeName receiver, or zeros if not found.
+func methodBodyPos(src []byte, typeName, method string) (int, int) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return 0, 0
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != method || fn.Body == nil || len(fn.Body.List) == 0 {
+			continue
+		}
+		typ := fn.Recv.List[0].Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+		if ident, ok := typ.(*ast.Ident); ok && ident.Name == typeName {
+			pos := fset.Position(fn.Body.List[0].Pos())
+			return pos.Line, pos.Column
+		}
+	}
+	return 0, 0
+}
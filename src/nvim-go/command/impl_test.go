@@ -0,0 +1,148 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestImplStubs(t *testing.T) {
+	types := `package foo
+
+type Stringer interface {
+	String() string
+	Len(s ...string) (n int, err error)
+}
+`
+	tests := []struct {
+		name     string
+		src      string
+		recv     string
+		iface    string
+		bang     bool
+		want     string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:  "standard library",
+			src:   "package foo\n\ntype File struct{}\n\nfunc main() {}\n",
+			recv:  "f *File",
+			iface: "io.Reader",
+			want: `package foo
+
+type File struct{}
+
+func (f *File) Read(p []byte) (n int, err error) {
+	panic("not implemented")
+}
+
+func main() {}
+`,
+			wantName: "Read",
+		},
+		{
+			name:  "renamed import",
+			src:   "package foo\n\nimport rd \"io\"\n\ntype File struct{}\n\nvar _ rd.Writer\n",
+			recv:  "f *File",
+			iface: "rd.Reader",
+			want: `package foo
+
+import rd "io"
+
+type File struct{}
+
+func (f *File) Read(p []byte) (n int, err error) {
+	panic("not implemented")
+}
+
+var _ rd.Writer
+`,
+			wantName: "Read",
+		},
+		{
+			name:  "local interface and existing method",
+			src:   "package foo\n\nimport \"io\"\n\ntype T int\n\nfunc (T) String() string { return \"\" }\n\nvar _ io.Writer\n",
+			recv:  "t T",
+			iface: "Stringer",
+			want: `package foo
+
+import "io"
+
+type T int
+
+func (t T) Len(s ...string) (n int, err error) {
+	panic("not implemented")
+}
+
+func (T) String() string { return "" }
+
+var _ io.Writer
+`,
+			wantName: "Len",
+		},
+		{
+			name:  "overwrite existing method",
+			src:   "package foo\n\ntype T int\n\nfunc (T) String() string { return \"\" }\n",
+			recv:  "t T",
+			iface: "Stringer",
+			bang:  true,
+			want: `package foo
+
+type T int
+
+func (t T) Len(s ...string) (n int, err error) {
+	panic("not implemented")
+}
+
+func (t T) String() string {
+	panic("not implemented")
+}
+`,
+			wantName: "Len",
+		},
+		{
+			name:    "not an interface",
+			src:     "package foo\n\ntype T int\n",
+			recv:    "t T",
+			iface:   "T",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		dir, cleanup := writePackage(t, map[string]string{"types.go": types, "main.go": tt.src})
+
+		fname := filepath.Join(dir, "main.go")
+		var imports []string
+		if path, _ := splitIfaceName(tt.iface); path != "" {
+			imports = append(imports, path)
+		}
+		f, err := loadTypedFile(fname, []byte(tt.src), imports...)
+		if err != nil {
+			cleanup()
+			t.Fatalf("%q. loadTypedFile(%v) error = %v", tt.name, fname, err)
+		}
+		got, gotName, err := implStubs(f, tt.recv, tt.iface, tt.bang)
+		cleanup()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. implStubs(%v, %v) error = %v, wantErr %v", tt.name, tt.recv, tt.iface, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%q. implStubs(%v, %v) = %s, want %s", tt.name, tt.recv, tt.iface, got, tt.want)
+		}
+		if gotName != tt.wantName {
+			t.Errorf("%q. implStubs(%v, %v) name = %v, want %v", tt.name, tt.recv, tt.iface, gotName, tt.wantName)
+		}
+	}
+}
+
+func TestMethodBodyPos(t *testing.T) {
+	src := []byte("package foo\n\ntype T int\n\nfunc (t *T) Read() {\n\tpanic(\"not implemented\")\n}\n")
+	if line, col := methodBodyPos(src, "T", "Read"); line != 6 || col != 2 {
+		t.Errorf("methodBodyPos(%q) = %v, %v, want 6, 2", "Read", line, col)
+	}
+}
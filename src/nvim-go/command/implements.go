@@ -0,0 +1,148 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"go/build"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+const pkgImplements = "GoImplements"
+
+func (c *Command) cmdImplements(eval *funcGuruEval) {
+	go func() {
+		err := c.Implements(eval)
+		c.saveError("Implements", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Implements lists the interfaces which the type under the cursor implements,
+// and the types which implement it if it's an interface, grouped with the
+// headers. Jumps to the result if it's only one. The standard library
+// results are excluded unless config.ImplementsIncludeStdlib.
+func (c *Command) Implements(eval *funcGuruEval) error {
+	defer nvimutil.Profile(time.Now(), pkgImplements)
+	dir := filepath.Dir(eval.File)
+	c.ctx.SetContext(dir)
+
+	query, err := c.guruQuery(eval)
+	if err != nil {
+		return err
+	}
+	// the scope is optional, guru searches the reverse dependencies without it
+	if scope, err := c.guruScope(dir); err == nil {
+		query.Scope = scope
+	}
+
+	_, done := c.startOp(pkgImplements)
+	defer done()
+
+	var impl *serial.Implements
+	query.Output = func(fset *token.FileSet, qr guru.QueryResult) {
+		impl, _ = qr.Result(fset).(*serial.Implements)
+	}
+	nvimutil.EchoProgress(c.Nvim, pkgImplements, "analysing implements")
+	if err := guru.Run("implements", query); err != nil {
+		return errors.WithStack(err)
+	}
+	if impl == nil {
+		return nvimutil.Info(c.Nvim, pkgImplements, "not found")
+	}
+
+	list, n := implementsList(impl, eval.Cwd, config.ImplementsIncludeStdlib())
+	if n == 0 {
+		return nvimutil.Info(c.Nvim, pkgImplements, "no implements relation of %s", impl.T.Name)
+	}
+
+	w := nvim.Window(c.ctx.WinID)
+	listType := nvimutil.ListTypeOf("Implements")
+	if err := nvimutil.SetList(c.Nvim, w, listType, eval.Cwd, list); err != nil {
+		return errors.WithStack(err)
+	}
+	if n == 1 {
+		for i, e := range list {
+			if e.FileName != "" {
+				batch := c.Nvim.NewBatch()
+				batch.Command(nvimutil.JumpCmd(listType, i+1))
+				batch.Command("normal! zz")
+				return errors.WithStack(batch.Execute())
+			}
+		}
+	}
+	defer nvimutil.EchoSuccess(c.Nvim, pkgImplements, fmt.Sprintf("%d results found", n))
+	return nvimutil.OpenList(c.Nvim, w, listType, list, config.GuruKeepCursor()["implements"] == 1)
+}
+
+// implementsList returns the list of the implements result grouped with the
+// header entries, and the number of the results. The standard library
+// results are excluded unless stdlib is true.
+func implementsList(impl *serial.Implements, cwd string, stdlib bool) ([]*nvim.QuickfixError, int) {
+	groups := []struct {
+		header  string
+		types   []serial.ImplementsType
+		methods []serial.DescribeMethod
+		suffix  string
+	}{
+		{header: impl.T.Name + " implements:", types: impl.AssignableFrom, methods: impl.AssignableFromMethod},
+		{header: impl.T.Name + " implements:", types: impl.AssignableFromPtr, methods: impl.AssignableFromPtrMethod, suffix: " (pointer receiver)"},
+		{header: impl.T.Name + " is implemented by:", types: impl.AssignableTo, methods: impl.AssignableToMethod},
+	}
+
+	var (
+		list   []*nvim.QuickfixError
+		header string
+		n      int
+	)
+	for _, g := range groups {
+		for i, typ := range g.types {
+			name, pos := typ.Kind+" "+typ.Name, typ.Pos
+			if impl.Method != nil && i < len(g.methods) {
+				if g.methods[i].Name == "" {
+					continue // the type lacks the method
+				}
+				name, pos = g.methods[i].Name, g.methods[i].Pos
+			}
+			if !stdlib && isStdlibPos(pos) {
+				continue
+			}
+
+			if g.header != header {
+				header = g.header
+				list = append(list, &nvim.QuickfixError{Text: header})
+			}
+			e := &nvim.QuickfixError{Text: name + g.suffix}
+			if pos != "" && pos != "-" {
+				e.FileName, e.LNum, e.Col = nvimutil.SplitPos(pos, cwd)
+			}
+			list = append(list, e)
+			n++
+		}
+	}
+	return list, n
+}
+
+// isStdlibPos reports whether the pos is in the standard library. The
+// predeclared types such as error don't have the position.
+func isStdlibPos(pos string) bool {
+	if pos == "" || pos == "-" {
+		return true
+	}
+	return strings.HasPrefix(pos, filepath.Join(build.Default.GOROOT, "src")+string(filepath.Separator))
+}
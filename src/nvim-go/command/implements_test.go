@@ -0,0 +1,107 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/build"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nvim-go/internal/guru"
+
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+func TestImplementsList(t *testing.T) {
+	src := `package foo
+
+import "fmt"
+
+var _ fmt.Stringer = T{}
+
+type Namer interface {
+	Name() string
+}
+
+type T struct{}
+
+func (T) String() string { return "" }
+
+func (*T) Name() string { return "" }
+`
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": src})
+	defer cleanup()
+	fname := filepath.Join(dir, "foo.go")
+
+	var impl *serial.Implements
+	query := guru.Query{
+		Pos:   guruPos(fname, strings.Index(src, "T struct"), 0),
+		Build: &build.Default,
+		Output: func(fset *token.FileSet, qr guru.QueryResult) {
+			impl, _ = qr.Result(fset).(*serial.Implements)
+		},
+	}
+	if err := guru.Run("implements", &query); err != nil {
+		t.Fatalf("guru.Run(implements, %v) error = %v", query.Pos, err)
+	}
+	if impl == nil {
+		t.Fatalf("guru.Run(implements, %v) returns no result", query.Pos)
+	}
+
+	texts := func(stdlib bool) ([]string, int) {
+		list, n := implementsList(impl, dir, stdlib)
+		var got []string
+		for _, e := range list {
+			got = append(got, e.FileName+"|"+e.Text)
+		}
+		return got, n
+	}
+
+	pkg := impl.T.Name[:strings.LastIndex(impl.T.Name, ".")]
+	namer := []string{"|" + impl.T.Name + " implements:", "foo.go|interface " + pkg + ".Namer (pointer receiver)"}
+
+	got, n := texts(true)
+	// the fmt.Stringer is in the standard library, and the Namer is implemented by *T
+	if n < 2 || got[0] != namer[0] {
+		t.Fatalf("implementsList(stdlib) = %v, %d, want the results with the headers", got, n)
+	}
+	var stringer bool
+	for _, e := range got[1 : len(got)-1] {
+		if strings.HasSuffix(e, "|interface fmt.Stringer") && !strings.HasPrefix(e, "foo.go|") {
+			stringer = true
+		}
+	}
+	if !stringer {
+		t.Errorf("implementsList(stdlib) = %v, want the fmt.Stringer in GOROOT", got)
+	}
+	// the pointer receiver results follow in the same group
+	if got[len(got)-1] != namer[1] {
+		t.Errorf("implementsList(stdlib) = %v, want the %v at last", got, namer[1])
+	}
+
+	got, n = texts(false)
+	if n != 1 || !reflect.DeepEqual(got, namer) {
+		t.Errorf("implementsList(no stdlib) = %v, %d, want %v, 1", got, n, namer)
+	}
+}
+
+func TestIsStdlibPos(t *testing.T) {
+	tests := []struct {
+		pos  string
+		want bool
+	}{
+		{pos: filepath.Join(build.Default.GOROOT, "src", "fmt", "print.go") + ":63:6", want: true},
+		{pos: "-", want: true},
+		{pos: "/home/user/go/src/foo/foo.go:3:6", want: false},
+	}
+	for _, tt := range tests {
+		if got := isStdlibPos(tt.pos); got != tt.want {
+			t.Errorf("isStdlibPos(%v) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
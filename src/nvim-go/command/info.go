@@ -0,0 +1,160 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/build"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgInfo = "GoInfo"
+
+type cmdInfoEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+func (c *Command) cmdInfo(eval *cmdInfoEval) {
+	go func() {
+		if err := c.Info(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// infoTool represents an external tool which version is shown by GoInfo.
+type infoTool struct {
+	name string
+	args []string // prints the version, nil if the tool has no version flag
+}
+
+var infoTools = []infoTool{
+	{name: "dlv", args: []string{"version"}},
+	{name: "guru"},
+	{name: "gometalinter", args: []string{"--version"}},
+}
+
+// toolVersionTimeout is the timeout of each infoTools version command.
+const toolVersionTimeout = 3 * time.Second
+
+// Info renders the build tool, the environment, the package of the current
+// file and the external tool versions into the scratch buffer.
+func (c *Command) Info(eval *cmdInfoEval) error {
+	defer nvimutil.Profile(time.Now(), pkgInfo)
+
+	dir := eval.Cwd
+	if eval.File != "" {
+		dir = filepath.Dir(eval.File)
+	}
+	c.ctx.SetContext(dir)
+
+	ctx, done := c.startOp(pkgInfo)
+	defer done()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Build tool:   %s\n", c.ctx.Build.Tool)
+	fmt.Fprintf(&buf, "Project root: %s\n", c.ctx.Build.ProjectRoot)
+	fmt.Fprintf(&buf, "GOROOT:       %s\n", build.Default.GOROOT)
+	fmt.Fprintf(&buf, "GOPATH:       %s\n", build.Default.GOPATH)
+	fmt.Fprintf(&buf, "Project kind: %s\n", projectKind(dir, build.Default.GOPATH))
+	if root, err := pathutil.FindModuleRoot(dir); err == nil {
+		fmt.Fprintf(&buf, "Module root:  %s\n", root)
+	}
+	if id, err := pathutil.PackageID(dir); err == nil {
+		fmt.Fprintf(&buf, "Import path:  %s\n", id)
+	}
+
+	buf.WriteString("\nTools:\n")
+	for _, tool := range infoTools {
+		fmt.Fprintf(&buf, "  %-13s %s\n", tool.name+":", toolVersion(ctx, tool))
+	}
+
+	var (
+		bufs []nvim.Buffer
+		wins []nvim.Window
+		tabs []nvim.Tabpage
+	)
+	batch := c.Nvim.NewBatch()
+	batch.Buffers(&bufs)
+	batch.Windows(&wins)
+	batch.Tabpages(&tabs)
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+	buf.WriteString("\nNeovim:\n")
+	fmt.Fprintf(&buf, "  buffers:      %v\n", bufs)
+	fmt.Fprintf(&buf, "  windows:      %v\n", wins)
+	fmt.Fprintf(&buf, "  tabpages:     %v\n", tabs)
+
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	b := nvimutil.NewBuffer(c.Nvim)
+	b.Reuse = true
+	if _, err := b.Create("__GoInfo__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return b.SetBufferLines(0, -1, true, bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
+}
+
+// projectKind reports whether the dir is inside the module, gb project or
+// GOPATH trees.
+func projectKind(dir, gopath string) string {
+	dir = filepath.Clean(dir)
+
+	if root, err := pathutil.FindModuleRoot(dir); err == nil {
+		return fmt.Sprintf("module (%s)", root)
+	}
+	if root, ok := pathutil.IsGb(dir); ok {
+		return fmt.Sprintf("gb (%s)", root)
+	}
+	for _, p := range filepath.SplitList(gopath) {
+		src := filepath.Join(p, "src")
+		if rel, err := filepath.Rel(src, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return fmt.Sprintf("GOPATH (%s)", p)
+		}
+	}
+	return "outside of GOPATH"
+}
+
+// toolVersion returns the path and the first line of the version output of tool.
+func toolVersion(ctx context.Context, tool infoTool) string {
+	path, err := exec.LookPath(tool.name)
+	if err != nil {
+		return "not found on PATH"
+	}
+	if tool.args == nil {
+		return path
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, toolVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, tool.args...).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("%s (%v)", path, err)
+	}
+	if i := bytes.IndexByte(out, '\n'); i >= 0 {
+		out = out[:i]
+	}
+	return fmt.Sprintf("%s (%s)", path, bytes.TrimSpace(out))
+}
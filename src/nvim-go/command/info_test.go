@@ -0,0 +1,69 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectKind(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nvim-go-info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	for _, dir := range []string{
+		filepath.Join(tmp, "mod", "foo"),
+		filepath.Join(tmp, "gopath", "src", "foo"),
+		filepath.Join(tmp, "gb", "src", "foo"),
+		filepath.Join(tmp, "gb", "vendor"),
+		filepath.Join(tmp, "none", "foo"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, "mod", "go.mod"), []byte("module foo.org/mod\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gopath := filepath.Join(tmp, "gopath")
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{
+			name: "module",
+			dir:  filepath.Join(tmp, "mod", "foo"),
+			want: "module (" + filepath.Join(tmp, "mod") + ")",
+		},
+		{
+			name: "gb",
+			dir:  filepath.Join(tmp, "gb", "src", "foo"),
+			want: "gb (" + filepath.Join(tmp, "gb") + ")",
+		},
+		{
+			name: "GOPATH",
+			dir:  filepath.Join(tmp, "gopath", "src", "foo"),
+			want: "GOPATH (" + gopath + ")",
+		},
+		{
+			name: "outside",
+			dir:  filepath.Join(tmp, "none", "foo"),
+			want: "outside of GOPATH",
+		},
+	}
+	for _, tt := range tests {
+		if got := projectKind(tt.dir, gopath); got != tt.want {
+			t.Errorf("%q. projectKind(%v, %v) = %v, want %v", tt.name, tt.dir, gopath, got, tt.want)
+		}
+	}
+}
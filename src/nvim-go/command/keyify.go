@@ -0,0 +1,103 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/ast"
+	"go/types"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const pkgKeyify = "GoKeyify"
+
+type cmdKeyifyEval struct {
+	File   string `msgpack:",array"`
+	Offset int
+}
+
+func (c *Command) cmdKeyify(eval *cmdKeyifyEval) {
+	go func() {
+		if err := c.Keyify(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Keyify converts the positional struct literal under the cursor, and the
+// nested positional struct literals to the keyed form.
+func (c *Command) Keyify(eval *cmdKeyifyEval) error {
+	defer nvimutil.Profile(time.Now(), pkgKeyify)
+
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	f, err := loadTypedFile(eval.File, src)
+	if err != nil {
+		return err
+	}
+	out, err := keyify(f, eval.Offset)
+	if err != nil {
+		return err
+	}
+
+	return c.updateBuffer(b, in, out)
+}
+
+// keyify returns the f source which keyed the positional struct literals
+// in the composite literal at the offset.
+func keyify(f *typedFile, offset int) ([]byte, error) {
+	node := f.enclosingNode(offset, func(n ast.Node) bool {
+		_, ok := n.(*ast.CompositeLit)
+		return ok
+	})
+	if node == nil {
+		return nil, errors.New("no composite literal under the cursor")
+	}
+
+	var edits []textEdit
+	ast.Inspect(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || len(lit.Elts) == 0 {
+			return true
+		}
+		if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); keyed {
+			return true
+		}
+		st := f.literalStruct(lit)
+		if st == nil || st.NumFields() != len(lit.Elts) {
+			return true
+		}
+		for i, elt := range lit.Elts {
+			start := f.offset(elt.Pos())
+			edits = append(edits, textEdit{start: start, end: start, text: st.Field(i).Name() + ": "})
+		}
+		return true
+	})
+	if len(edits) == 0 {
+		return nil, errors.New("no positional struct literal under the cursor")
+	}
+
+	return f.apply(edits)
+}
+
+// literalStruct returns the struct type of lit, or nil if lit is not a struct
+// literal. The type of lit may be the elided pointer type such as the
+// elements of []*T{{...}}.
+func (f *typedFile) literalStruct(lit *ast.CompositeLit) *types.Struct {
+	typ := f.info.TypeOf(lit)
+	if typ == nil {
+		return nil
+	}
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	st, _ := typ.Underlying().(*types.Struct)
+	return st
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyify(t *testing.T) {
+	types := `package foo
+
+import "image"
+
+type Base struct {
+	ID int
+}
+
+type T struct {
+	Base
+	Name string
+	Pt   image.Point
+	Ptrs []*Base
+}
+`
+	tests := []struct {
+		name    string
+		src     string
+		cursor  string // the cursor is at the first occurrence
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "embedded, nested and imported types",
+			src:    "package foo\n\nimport \"image\"\n\nvar _ = T{Base{1}, \"foo\", image.Point{1, 2}, []*Base{{2}}}\n",
+			cursor: "T{",
+			want: `package foo
+
+import "image"
+
+var _ = T{Base: Base{ID: 1}, Name: "foo", Pt: image.Point{X: 1, Y: 2}, Ptrs: []*Base{{ID: 2}}}
+`,
+		},
+		{
+			name:   "inner literal",
+			src:    "package foo\n\nimport \"image\"\n\nvar _ = []image.Point{{1, 2}}\n",
+			cursor: "{1",
+			want: `package foo
+
+import "image"
+
+var _ = []image.Point{{X: 1, Y: 2}}
+`,
+		},
+		{
+			name:    "already keyed",
+			src:     "package foo\n\nvar _ = Base{ID: 1}\n",
+			cursor:  "Base{",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		dir, cleanup := writePackage(t, map[string]string{"types.go": types, "main.go": tt.src})
+
+		fname := filepath.Join(dir, "main.go")
+		f, err := loadTypedFile(fname, []byte(tt.src))
+		if err != nil {
+			cleanup()
+			t.Fatalf("%q. loadTypedFile(%v) error = %v", tt.name, fname, err)
+		}
+		offset := strings.Index(tt.src, tt.cursor) + 1
+		got, err := keyify(f, offset)
+		cleanup()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. keyify(%v) error = %v, wantErr %v", tt.name, offset, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%q. keyify(%v) = %s, want %s", tt.name, offset, got, tt.want)
+		}
+	}
+}
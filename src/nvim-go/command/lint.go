@@ -30,11 +30,12 @@ func (c *Command) cmdLint(v *nvim.Nvim, args []string, file string) {
 
 	go func() {
 		errlist, err := c.Lint(args, file)
+		c.saveError("Lint", err)
 		if err != nil {
 			nvimutil.ErrorWrap(c.Nvim, err)
 		}
 		c.ctx.Errlist["Lint"] = errlist
-		nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+		nvimutil.ErrorList(c.Nvim, "", c.ctx.Errlist, true)
 	}()
 }
 
@@ -49,6 +50,7 @@ const (
 // TODO(zchee): Support go packages.
 func (c *Command) Lint(args []string, file string) ([]*nvim.QuickfixError, error) {
 	defer nvimutil.Profile(time.Now(), "GoLint")
+	c.ctx.SetContext(filepath.Dir(file))
 
 	var (
 		errlist []*nvim.QuickfixError
@@ -56,7 +58,7 @@ func (c *Command) Lint(args []string, file string) ([]*nvim.QuickfixError, error
 	)
 	switch {
 	case len(args) == 0:
-		switch lintMode(config.GolintMode) {
+		switch lintMode(config.GolintMode()) {
 		case current:
 			errlist, err = c.lintDir(filepath.Dir(file))
 		case root:
@@ -103,15 +105,16 @@ func (c *Command) Lint(args []string, file string) ([]*nvim.QuickfixError, error
 	return errlist, errors.WithStack(err)
 }
 
-// TODO(zchee): Support list of go packages.
+// cmdLintComplete returns the files, or the project packages if no file
+// matches.
 func (c *Command) cmdLintComplete(a *nvim.CommandCompletionArgs, cwd string) (filelist []string, err error) {
+	argLead := a.ArgLead
 	files, err := nvimutil.CompleteFiles(c.Nvim, a, cwd)
 	if err != nil {
 		return nil, err
 	}
-	filelist = append(filelist, files...)
 
-	return filelist, nil
+	return c.appendPackages(files, argLead, cwd), nil
 }
 
 // ----------------------------------------------------------------------------
@@ -141,9 +144,9 @@ func (c *Command) lintFiles(filenames ...string) ([]*nvim.QuickfixError, error)
 
 	var errlist []*nvim.QuickfixError
 	for _, p := range ps {
-		if p.Confidence >= config.GolintMinConfidence {
+		if p.Confidence >= config.GolintMinConfidence() {
 			file := p.Position.Filename
-			if contain(file, config.GolintIgnore) {
+			if contain(file, config.GolintIgnore()) {
 				continue
 			}
 			frel, err := filepath.Rel(cwd, file)
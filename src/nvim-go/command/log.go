@@ -0,0 +1,25 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"nvim-go/nvimutil"
+)
+
+func (c *Command) cmdLogOpen() {
+	go func() {
+		if err := nvimutil.OpenLogBuffer(c.Nvim); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) cmdLogClear() {
+	go func() {
+		if err := nvimutil.ClearLog(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
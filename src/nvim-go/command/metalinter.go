@@ -13,6 +13,7 @@ import (
 
 	"nvim-go/config"
 	"nvim-go/internal/pathutil"
+	"nvim-go/internal/tools"
 	"nvim-go/nvimutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -35,6 +36,7 @@ type metalinterResult struct {
 // Metalinter lint the Go sources from current buffer's package use gometalinter tool.
 func (c *Command) Metalinter(cwd string) error {
 	defer nvimutil.Profile(time.Now(), "GoMetaLinter")
+	c.ctx.SetContext(cwd)
 
 	var loclist []*nvim.QuickfixError
 	w := nvim.Window(c.ctx.WinID)
@@ -46,18 +48,26 @@ func (c *Command) Metalinter(cwd string) error {
 	case "gb":
 		args = append(args, c.ctx.Build.ProjectRoot+"/...")
 	}
-	args = append(args, []string{"--json", "--disable-all", "--deadline", config.MetalinterDeadline}...)
+	args = append(args, []string{"--json", "--disable-all", "--deadline", config.MetalinterDeadline()}...)
 
-	for _, t := range config.MetalinterTools {
+	for _, t := range config.MetalinterTools() {
 		args = append(args, "--enable", t)
 	}
-	if len(config.MetalinterSkipDir) != 0 {
-		for _, dir := range config.MetalinterSkipDir {
+	if len(config.MetalinterSkipDir()) != 0 {
+		for _, dir := range config.MetalinterSkipDir() {
 			args = append(args, "--skip", dir)
 		}
 	}
 
-	cmd := exec.Command("gometalinter", args...)
+	ctx, done := c.startOp("GoMetaLinter")
+	defer done()
+
+	bin, err := tools.Require("Gometalinter", "gometalinter")
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Env = c.ctx.Build.Environ()
 	stdout, err := cmd.Output()
 	cmd.Run()
 
@@ -0,0 +1,298 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/pathutil"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const pkgAlternateMock = "GoAlternateMock"
+
+type cmdAlternateMockEval struct {
+	Cwd    string `msgpack:",array"`
+	File   string
+	Offset int
+}
+
+func (c *Command) cmdAlternateMock(args []string, eval *cmdAlternateMockEval) {
+	go func() {
+		if err := c.AlternateMock(args, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// AlternateMock jumps between the interface and the generated mock of it.
+// The interface is the args, or the type under the cursor. The mock is the
+// "Mock{interface}" type of mockgen or the "{interface}Mock" type of moq,
+// which is declared in the config.MockSuffix file of the same package. If the
+// mock doesn't exist, it offers to generate it by mockgen.
+func (c *Command) AlternateMock(args []string, eval *cmdAlternateMockEval) error {
+	defer nvimutil.Profile(time.Now(), pkgAlternateMock)
+	dir := filepath.Dir(eval.File)
+	c.ctx.SetContext(dir)
+
+	suffix := config.MockSuffix()
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		_, _, src, err := c.bufferSource()
+		if err != nil {
+			return err
+		}
+		name = typeNameAt(eval.File, src, eval.Offset)
+	}
+
+	if name == "" {
+		return errors.New("usage: GoAlternateMock [interface]")
+	}
+
+	w := nvim.Window(c.ctx.WinID)
+	if strings.HasSuffix(eval.File, suffix) {
+		pos, err := findInterface(pkg, mockedName(name), suffix)
+		if err != nil {
+			return err
+		}
+		return nvimutil.GotoPos(c.Nvim, w, pos, eval.Cwd)
+	}
+
+	pos, err := findInterface(pkg, name, suffix)
+	if err != nil {
+		return err
+	}
+	if mockPos, ok := findMock(pkg, name, suffix); ok {
+		return nvimutil.GotoPos(c.Nvim, w, mockPos, eval.Cwd)
+	}
+
+	mock := mockFileName(pos.Filename, suffix)
+	ask := fmt.Sprintf("GoAlternateMock: mock of %s not found. Generate %s with mockgen? (y, n): ", name, pathutil.Rel(eval.Cwd, mock))
+	var answer interface{}
+	if err := c.Nvim.Call("input", &answer, ask); err != nil {
+		return errors.WithStack(err)
+	}
+	if answer.(string) != "y" {
+		return nil
+	}
+
+	bin, err := tools.Require(pkgAlternateMock, "mockgen")
+	if err != nil {
+		return err
+	}
+	ctx, done := c.startOp(pkgAlternateMock)
+	defer done()
+	cmd := exec.CommandContext(ctx, bin, mockgenArgs(pkg, pos.Filename, mock)...)
+	cmd.Dir = dir
+	cmd.Env = c.ctx.Build.Environ()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Errorf("mockgen: %s", bytes.TrimSpace(out))
+	}
+
+	// reload the package files which includes the generated mock file
+	if pkg, err = build.ImportDir(dir, 0); err != nil {
+		return errors.WithStack(err)
+	}
+	mockPos, ok := findMock(pkg, name, suffix)
+	if !ok {
+		return errors.Errorf("mockgen didn't generate the mock of %s in %s", name, mock)
+	}
+	return nvimutil.GotoPos(c.Nvim, w, mockPos, eval.Cwd)
+}
+
+func (c *Command) cmdAlternateMockComplete(a *nvim.CommandCompletionArgs, dir string) ([]string, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil
+	}
+
+	var names []string
+	for _, name := range interfaceNames(pkg, config.MockSuffix()) {
+		if strings.HasPrefix(name, a.ArgLead) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// typeNameAt returns the type name of the declaration at the offset of src,
+// or the receiver type name of the method declaration. Returns the empty
+// string if the offset is not in them.
+func typeNameAt(filename string, src []byte, offset int) string {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, filename, src, 0)
+	if f == nil || offset < 0 || offset > fset.File(f.Pos()).Size() {
+		return ""
+	}
+	pos := fset.File(f.Pos()).Pos(offset)
+
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.TypeSpec:
+			return n.Name.Name
+		case *ast.FuncDecl:
+			if n.Recv == nil || len(n.Recv.List) == 0 {
+				continue
+			}
+			recv := n.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				return ident.Name
+			}
+		}
+	}
+	return ""
+}
+
+// mockedName returns the interface name of the mock type name. The name of
+// the mock is "Mock{interface}" or "Mock{interface}MockRecorder" of mockgen,
+// or "{interface}Mock" of moq.
+// like:
+//  mockedName("MockReader") == "Reader"
+//  mockedName("MockReaderMockRecorder") == "Reader"
+//  mockedName("ReaderMock") == "Reader"
+func mockedName(name string) string {
+	name = strings.TrimSuffix(name, "MockRecorder")
+	if strings.HasPrefix(name, "Mock") && len(name) > len("Mock") {
+		return strings.TrimPrefix(name, "Mock")
+	}
+	return strings.TrimSuffix(name, "Mock")
+}
+
+// mockFileName returns the mock filename of the src file.
+// like:
+//  mockFileName("foo/reader.go", "_mock.go") == "foo/reader_mock.go"
+func mockFileName(src, suffix string) string {
+	return strings.TrimSuffix(src, ".go") + suffix
+}
+
+// mockgenArgs returns the mockgen args which generates the mocks of the
+// interfaces in the src file of pkg to the mock file.
+func mockgenArgs(pkg *build.Package, src, mock string) []string {
+	args := []string{"-source=" + src, "-destination=" + mock, "-package=" + pkg.Name}
+	if pkg.ImportPath != "" && pkg.ImportPath != "." {
+		// refer the types of the same package without the package qualifier
+		args = append(args, "-self_package="+pkg.ImportPath)
+	}
+	return args
+}
+
+// parsePackageFiles parses the files of pkg, and calls fn with each of them.
+// The mock files are the config.MockSuffix files if mock is true, or the
+// other files.
+func parsePackageFiles(pkg *build.Package, suffix string, mock bool, fn func(fset *token.FileSet, f *ast.File) bool) {
+	var files []string
+	files = append(files, pkg.GoFiles...)
+	files = append(files, pkg.TestGoFiles...)
+	sort.Strings(files)
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, suffix) != mock {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, 0)
+		if err != nil {
+			continue
+		}
+		if !fn(fset, f) {
+			return
+		}
+	}
+}
+
+// typeSpecs calls fn with each top level type declaration of f.
+func typeSpecs(f *ast.File, fn func(spec *ast.TypeSpec) bool) bool {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if !fn(spec.(*ast.TypeSpec)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// findInterface returns the position of the name interface declaration in pkg.
+func findInterface(pkg *build.Package, name, suffix string) (token.Position, error) {
+	var pos token.Position
+	parsePackageFiles(pkg, suffix, false, func(fset *token.FileSet, f *ast.File) bool {
+		return typeSpecs(f, func(spec *ast.TypeSpec) bool {
+			if _, ok := spec.Type.(*ast.InterfaceType); ok && spec.Name.Name == name {
+				pos = fset.Position(spec.Name.Pos())
+				return false
+			}
+			return true
+		})
+	})
+	if !pos.IsValid() {
+		return pos, errors.Errorf("couldn't find the %s interface in the %s package", name, pkg.Name)
+	}
+	return pos, nil
+}
+
+// findMock returns the position of the mock type declaration of the name
+// interface in the mock files of pkg. The second return value reports
+// whether the mock is found.
+func findMock(pkg *build.Package, name, suffix string) (token.Position, bool) {
+	var pos token.Position
+	parsePackageFiles(pkg, suffix, true, func(fset *token.FileSet, f *ast.File) bool {
+		return typeSpecs(f, func(spec *ast.TypeSpec) bool {
+			if spec.Name.Name == "Mock"+name || spec.Name.Name == name+"Mock" {
+				pos = fset.Position(spec.Name.Pos())
+				return false
+			}
+			return true
+		})
+	})
+	return pos, pos.IsValid()
+}
+
+// interfaceNames returns the sorted interface names which declared in pkg,
+// except the mock files.
+func interfaceNames(pkg *build.Package, suffix string) []string {
+	var names []string
+	parsePackageFiles(pkg, suffix, false, func(fset *token.FileSet, f *ast.File) bool {
+		return typeSpecs(f, func(spec *ast.TypeSpec) bool {
+			if _, ok := spec.Type.(*ast.InterfaceType); ok {
+				names = append(names, spec.Name.Name)
+			}
+			return true
+		})
+	})
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,174 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAlternateMock(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"reader.go": `package foo
+
+type Reader interface {
+	Read() string
+}
+
+type Writer interface {
+	Write(s string)
+}
+
+type File struct{}
+
+func (f *File) Read() string { return "" }
+`,
+		"closer.go": `package foo
+
+type Closer interface {
+	Close() error
+}
+`,
+		"reader_mock.go": `package foo
+
+type MockReader struct{}
+
+type MockReaderMockRecorder struct{}
+
+func (m *MockReader) Read() string { return "" }
+`,
+		"closer_mock_test.go": `package foo
+
+type CloserMock struct{}
+`,
+	})
+	defer cleanup()
+
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := interfaceNames(pkg, "_mock.go"), []string{"Closer", "Reader", "Writer"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("interfaceNames(_mock.go) = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		suffix   string
+		wantIf   string
+		wantMock string // empty if the mock doesn't exist
+		wantFile string
+	}{
+		{name: "Reader", suffix: "_mock.go", wantIf: "reader.go:3:6", wantMock: "reader_mock.go:3:6"},
+		{name: "Writer", suffix: "_mock.go", wantIf: "reader.go:7:6", wantFile: "reader_mock.go"},
+		{name: "Closer", suffix: "_mock_test.go", wantIf: "closer.go:3:6", wantMock: "closer_mock_test.go:3:6"},
+		{name: "Reader", suffix: "_mock_test.go", wantIf: "reader.go:3:6", wantFile: "reader_mock_test.go"},
+	}
+	for _, tt := range tests {
+		pos, err := findInterface(pkg, tt.name, tt.suffix)
+		if err != nil {
+			t.Errorf("findInterface(%s, %s) error = %v", tt.name, tt.suffix, err)
+			continue
+		}
+		if got := relPos(dir, pos.String()); got != tt.wantIf {
+			t.Errorf("findInterface(%s, %s) = %v, want %v", tt.name, tt.suffix, got, tt.wantIf)
+		}
+
+		mockPos, ok := findMock(pkg, tt.name, tt.suffix)
+		if ok != (tt.wantMock != "") {
+			t.Errorf("findMock(%s, %s) found = %v, want %v", tt.name, tt.suffix, ok, tt.wantMock != "")
+			continue
+		}
+		if ok {
+			if got := relPos(dir, mockPos.String()); got != tt.wantMock {
+				t.Errorf("findMock(%s, %s) = %v, want %v", tt.name, tt.suffix, got, tt.wantMock)
+			}
+			continue
+		}
+		if got := mockFileName(pos.Filename, tt.suffix); got != filepath.Join(dir, tt.wantFile) {
+			t.Errorf("mockFileName(%s, %s) = %v, want %v", pos.Filename, tt.suffix, got, tt.wantFile)
+		}
+	}
+
+	if _, err := findInterface(pkg, "File", "_mock.go"); err == nil {
+		t.Error("findInterface(File) error = nil, want the not interface error")
+	}
+	// the mock type is not the interface
+	if _, err := findInterface(pkg, "MockReader", "_mock_test.go"); err == nil {
+		t.Error("findInterface(MockReader) error = nil, want the not found error")
+	}
+
+	wantArgs := []string{"-source=reader.go", "-destination=reader_mock.go", "-package=foo"}
+	if got := mockgenArgs(pkg, "reader.go", "reader_mock.go"); !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("mockgenArgs() = %v, want %v", got, wantArgs)
+	}
+	pkg.ImportPath = "foo.org/foo"
+	if got, want := mockgenArgs(pkg, "reader.go", "reader_mock.go"), append(wantArgs, "-self_package=foo.org/foo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("mockgenArgs() = %v, want %v", got, want)
+	}
+}
+
+func relPos(dir, pos string) string {
+	rel, err := filepath.Rel(dir, pos)
+	if err != nil {
+		return pos
+	}
+	return rel
+}
+
+func TestMockedName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "MockReader", want: "Reader"},
+		{name: "MockReaderMockRecorder", want: "Reader"},
+		{name: "ReaderMock", want: "Reader"},
+		{name: "Mock", want: ""},
+		{name: "Reader", want: "Reader"},
+	}
+	for _, tt := range tests {
+		if got := mockedName(tt.name); got != tt.want {
+			t.Errorf("mockedName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTypeNameAt(t *testing.T) {
+	src := []byte(`package foo
+
+type Reader interface {
+	Read() string
+}
+
+func (m *MockReader) Read() string { return "" }
+
+func Open() {}
+`)
+	tests := []struct {
+		at   string // the cursor is at the beginning of it
+		want string
+	}{
+		{at: "Reader interface", want: "Reader"},
+		{at: "Read() string\n", want: "Reader"},
+		{at: "MockReader)", want: "MockReader"},
+		{at: `return ""`, want: "MockReader"},
+		{at: "Open", want: ""},
+		{at: "package", want: ""},
+	}
+	for _, tt := range tests {
+		offset := bytes.Index(src, []byte(tt.at))
+		if got := typeNameAt("foo.go", src, offset); got != tt.want {
+			t.Errorf("typeNameAt(%q) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+	if got := typeNameAt("foo.go", src, len(src)+1); got != "" {
+		t.Errorf("typeNameAt(out of the source) = %q, want the empty string", got)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgMod = "GoMod"
+
+func (c *Command) cmdModTidy(args []string, dir string) {
+	go c.cmdMod("tidy", args, dir)
+}
+
+func (c *Command) cmdModDownload(args []string, dir string) {
+	go c.cmdMod("download", args, dir)
+}
+
+func (c *Command) cmdModVerify(args []string, dir string) {
+	go c.cmdMod("verify", args, dir)
+}
+
+func (c *Command) cmdMod(sub string, args []string, dir string) {
+	err := c.Mod(sub, args, dir)
+	c.saveError("Mod", err)
+	if err != nil {
+		nvimutil.ErrorWrap(c.Nvim, err)
+	}
+}
+
+// errNotModule is the error of the module commands outside of the module.
+var errNotModule = errors.New("not in a module, the go.mod file not found")
+
+// Mod runs the sub "go mod" subcommand such as "tidy" in the module root of
+// dir, and streams the output into the scratch buffer. The errors are set to
+// the quickfix list. It only warns if dir is not in a module.
+func (c *Command) Mod(sub string, args []string, dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgMod)
+	c.ctx.SetContext(dir)
+
+	ctx, done := c.startOp("GoMod" + strings.Title(sub))
+	defer done()
+
+	cmd, err := modCmd(ctx, sub, args, dir)
+	if err == errNotModule {
+		return nvimutil.EchohlAfter(c.Nvim, pkgMod, "WarningMsg", "%s", err)
+	}
+	if err != nil {
+		return err
+	}
+	cmd.Env = c.ctx.Build.Environ()
+
+	w, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	nvimutil.EchoProgress(c.Nvim, pkgMod, "go mod %s", sub)
+	output, runErr := c.runStream(cmd, "__GoMod__")
+	var errlist []*nvim.QuickfixError
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return runErr
+		}
+		errlist = parseModErrors(output, cmd.Dir)
+	}
+	if len(errlist) > 0 {
+		if err := nvimutil.SetList(c.Nvim, w, nvimutil.Quickfix, "", errlist); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := nvimutil.OpenList(c.Nvim, w, nvimutil.Quickfix, errlist, true); err != nil {
+		return errors.WithStack(err)
+	}
+	if runErr != nil {
+		return errors.Errorf("go mod %s failed", sub)
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, pkgMod, "go mod "+sub)
+}
+
+// modCmd returns the "go mod sub args..." command which runs in the module
+// root of dir. Returns errNotModule if dir is not in a module.
+func modCmd(ctx context.Context, sub string, args []string, dir string) (*exec.Cmd, error) {
+	root, err := pathutil.FindModuleRoot(dir)
+	if err != nil {
+		return nil, errNotModule
+	}
+
+	cmd := exec.CommandContext(ctx, "go", append([]string{"mod", sub}, args...)...)
+	cmd.Dir = root
+	return cmd, nil
+}
+
+// runStream runs cmd and streams the output into the name scratch buffer,
+// keeping the cursor in the current window. It returns the output and the
+// *exec.ExitError if cmd fails.
+func (c *Command) runStream(cmd *exec.Cmd, name string) ([]byte, error) {
+	buf, err := c.streamBuffer(name)
+	if err != nil {
+		return nil, err
+	}
+	return streamCmd(cmd, buf)
+}
+
+// streamBuffer creates or clears the name scratch buffer for the streamed
+// output, keeping the cursor in the current window.
+func (c *Command) streamBuffer(name string) (*nvimutil.Buffer, error) {
+	w, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create(name, "", "belowright new", option); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := c.Nvim.SetCurrentWindow(w); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf, nil
+}
+
+// streamCmd runs cmd and appends the command line and the output to buf. It
+// returns the output and the *exec.ExitError if cmd fails.
+func streamCmd(cmd *exec.Cmd, buf *nvimutil.Buffer) ([]byte, error) {
+	if _, err := buf.WriteString("$ " + filepath.Base(cmd.Args[0]) + " " + joinArgs(cmd.Args[1:]) + "\n"); err != nil {
+		return nil, err
+	}
+
+	// the same writer, so exec copies the stdout and stderr in one goroutine
+	var output bytes.Buffer
+	out := io.MultiWriter(buf, &output)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	runErr := cmd.Run()
+	if err := buf.Flush(); err != nil {
+		return nil, err
+	}
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); ok {
+			return output.Bytes(), runErr
+		}
+		return nil, errors.WithStack(runErr)
+	}
+	return output.Bytes(), nil
+}
+
+// joinArgs joins args with the space, and quotes the arg which contains the space.
+func joinArgs(args []string) string {
+	var buf bytes.Buffer
+	for i, arg := range args {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		if strings.ContainsAny(arg, " \t") {
+			arg = strconv.Quote(arg)
+		}
+		buf.WriteString(arg)
+	}
+	return buf.String()
+}
+
+// modErrRe matches the "go.mod:5:2: unknown directive: foo" form error.
+var modErrRe = regexp.MustCompile(`^(\S+\.(?:mod|sum)):(\d+)(?::(\d+))?:\s*(.*)`)
+
+// modProgressRe matches the progress message of the go mod commands.
+var modProgressRe = regexp.MustCompile(`^go: (?:downloading|finding|extracting|found) `)
+
+// parseModErrors parses the go mod output to the error list. The error which
+// has no position, such as the module download failure, is the go.mod file
+// error. The indented lines are the continuation of the previous error.
+func parseModErrors(output []byte, root string) []*nvim.QuickfixError {
+	var (
+		errlist []*nvim.QuickfixError
+		last    *nvim.QuickfixError
+	)
+	for _, line := range bytes.Split(output, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 || modProgressRe.Match(line) {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && last != nil {
+			last.Text += "\n" + string(bytes.TrimSpace(line))
+			continue
+		}
+
+		e := &nvim.QuickfixError{FileName: filepath.Join(root, "go.mod"), Text: string(line)}
+		if m := modErrRe.FindSubmatch(line); m != nil {
+			e.FileName = string(m[1])
+			if !filepath.IsAbs(e.FileName) {
+				e.FileName = filepath.Join(root, e.FileName)
+			}
+			e.LNum, _ = strconv.Atoi(string(m[2]))
+			e.Col, _ = strconv.Atoi(string(m[3]))
+			e.Text = string(m[4])
+		}
+		errlist = append(errlist, e)
+		last = e
+	}
+	return errlist
+}
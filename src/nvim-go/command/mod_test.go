@@ -0,0 +1,89 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestModCmd(t *testing.T) {
+	root, cleanup := writePackage(t, map[string]string{"go.mod": "module example.com/foo\n"})
+	defer cleanup()
+	sub := filepath.Join(root, "internal", "bar")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	outside, cleanup2 := writePackage(t, nil)
+	defer cleanup2()
+
+	tests := []struct {
+		name     string
+		sub      string
+		args     []string
+		dir      string
+		wantDir  string
+		wantArgs []string
+		wantErr  error
+	}{
+		{
+			name:     "tidy in the sub package",
+			sub:      "tidy",
+			dir:      sub,
+			wantDir:  root,
+			wantArgs: []string{"go", "mod", "tidy"},
+		},
+		{
+			name:     "download with args",
+			sub:      "download",
+			args:     []string{"-x", "golang.org/x/text"},
+			dir:      root,
+			wantDir:  root,
+			wantArgs: []string{"go", "mod", "download", "-x", "golang.org/x/text"},
+		},
+		{
+			name:    "not in a module",
+			sub:     "verify",
+			dir:     outside,
+			wantErr: errNotModule,
+		},
+	}
+	for _, tt := range tests {
+		cmd, err := modCmd(context.Background(), tt.sub, tt.args, tt.dir)
+		if err != tt.wantErr {
+			t.Errorf("%q. modCmd(%v, %v, %v) error = %v, wantErr %v", tt.name, tt.sub, tt.args, tt.dir, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if cmd.Dir != tt.wantDir {
+			t.Errorf("%q. modCmd(%v, %v, %v).Dir = %v, want %v", tt.name, tt.sub, tt.args, tt.dir, cmd.Dir, tt.wantDir)
+		}
+		if args := append([]string{"go"}, cmd.Args[1:]...); !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%q. modCmd(%v, %v, %v).Args = %v, want %v", tt.name, tt.sub, tt.args, tt.dir, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestParseModErrors(t *testing.T) {
+	output := `go: downloading example.com/bar v1.0.0
+go.mod:3:2: unknown directive: requir
+go: example.com/baz@v1.2.0: reading https://proxy.golang.org/example.com/baz/@v/v1.2.0.mod: 404 Not Found
+	server response: not found
+`
+	want := []*nvim.QuickfixError{
+		{FileName: "/foo/go.mod", LNum: 3, Col: 2, Text: "unknown directive: requir"},
+		{FileName: "/foo/go.mod", Text: "go: example.com/baz@v1.2.0: reading https://proxy.golang.org/example.com/baz/@v/v1.2.0.mod: 404 Not Found\nserver response: not found"},
+	}
+	if got := parseModErrors([]byte(output), "/foo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseModErrors() = %+v, want %+v", got, want)
+	}
+}
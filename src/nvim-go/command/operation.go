@@ -0,0 +1,148 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/nvimutil"
+)
+
+// operations represents a registry of the in-flight async operations.
+type operations struct {
+	mu     sync.Mutex
+	id     int
+	cancel map[int]context.CancelFunc
+	names  map[int]string
+
+	// frame is the current spinner frame, and spinning reports whether the
+	// spinner goroutine is running.
+	frame    int
+	spinning bool
+}
+
+// spinnerFrames is the frames of the progress spinner.
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerInterval is the interval of the spinner frame rotation.
+const spinnerInterval = 100 * time.Millisecond
+
+// startOp registers the new in-flight name operation and returns its context.
+// The returned function unregisters the operation and must be called when the
+// operation is done, including on error.
+// The progress spinner of GoStatus rotates while any operation is in-flight.
+func (c *Command) startOp(name string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.ops.mu.Lock()
+	if c.ops.cancel == nil {
+		c.ops.cancel = make(map[int]context.CancelFunc)
+		c.ops.names = make(map[int]string)
+	}
+	c.ops.id++
+	id := c.ops.id
+	c.ops.cancel[id] = cancel
+	c.ops.names[id] = name
+	if !c.ops.spinning && c.Nvim != nil {
+		c.ops.spinning = true
+		go c.spin()
+	}
+	c.ops.mu.Unlock()
+
+	return ctx, func() {
+		c.ops.mu.Lock()
+		delete(c.ops.cancel, id)
+		delete(c.ops.names, id)
+		c.ops.mu.Unlock()
+		cancel()
+	}
+}
+
+// spin rotates the spinner frame and redraws the statusline until all of the
+// operations are done. It runs on its own goroutine so that the redraw never
+// blocks the operations.
+func (c *Command) spin() {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.ops.mu.Lock()
+		running := len(c.ops.cancel) > 0
+		if running {
+			c.ops.frame++
+		} else {
+			c.ops.spinning = false
+		}
+		c.ops.mu.Unlock()
+
+		// the last redraw clears the status
+		c.Nvim.Command("redrawstatus!")
+		if !running {
+			return
+		}
+	}
+}
+
+// Status returns the current activity string which is the spinner frame and
+// the in-flight operation names in started order, such as "/ GoBuild, GoGuru
+// pointsto". It returns the empty string if no operation is in-flight.
+func (c *Command) Status() string {
+	c.ops.mu.Lock()
+	defer c.ops.mu.Unlock()
+
+	if len(c.ops.names) == 0 {
+		return ""
+	}
+	ids := make([]int, 0, len(c.ops.names))
+	for id := range c.ops.names {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if name := c.ops.names[id]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return spinnerFrames[c.ops.frame%len(spinnerFrames)] + " " + strings.Join(names, ", ")
+}
+
+func (c *Command) funcStatus() (string, error) {
+	return c.Status(), nil
+}
+
+// CancelAll cancels all of the in-flight operations, and returns the number
+// of canceled operations.
+func (c *Command) CancelAll() int {
+	c.ops.mu.Lock()
+	defer c.ops.mu.Unlock()
+
+	n := len(c.ops.cancel)
+	for id, cancel := range c.ops.cancel {
+		cancel()
+		delete(c.ops.cancel, id)
+		delete(c.ops.names, id)
+	}
+	return n
+}
+
+func (c *Command) cmdStop() {
+	go c.Stop()
+}
+
+// Stop cancels all of the in-flight operations, such as running external
+// processes.
+func (c *Command) Stop() error {
+	n := c.CancelAll()
+	return nvimutil.EchoSuccess(c.Nvim, "GoStop", fmt.Sprintf("canceled %d operations", n))
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import "testing"
+
+func TestCommand_CancelAll(t *testing.T) {
+	c := NewCommand(nil, nil)
+
+	ctx1, done1 := c.startOp("GoTest")
+	ctx2, _ := c.startOp("GoTest")
+	ctx3, done3 := c.startOp("GoTest")
+	done3()
+	if ctx3.Err() == nil {
+		t.Errorf("done operation context is not canceled")
+	}
+
+	if n := c.CancelAll(); n != 2 {
+		t.Errorf("CancelAll() = %v, want %v", n, 2)
+	}
+	for i, ctx := range []interface{ Err() error }{ctx1, ctx2} {
+		if ctx.Err() == nil {
+			t.Errorf("in-flight operation %d context is not canceled", i+1)
+		}
+	}
+
+	// calling the done function after CancelAll is no-op
+	done1()
+	if n := c.CancelAll(); n != 0 {
+		t.Errorf("CancelAll() = %v, want %v", n, 0)
+	}
+}
+
+func TestCommand_Status(t *testing.T) {
+	c := NewCommand(nil, nil)
+	if got := c.Status(); got != "" {
+		t.Errorf("Status() = %q, want empty", got)
+	}
+
+	_, doneBuild := c.startOp("GoBuild")
+	_, doneGuru := c.startOp("GoGuru pointsto")
+	_, doneBuild2 := c.startOp("GoBuild")
+	if got, want := c.Status(), "| GoBuild, GoGuru pointsto"; got != want {
+		t.Errorf("Status() = %q, want %q", got, want)
+	}
+
+	c.ops.frame++
+	doneBuild()
+	if got, want := c.Status(), "/ GoGuru pointsto, GoBuild"; got != want {
+		t.Errorf("Status() = %q, want %q", got, want)
+	}
+
+	doneGuru()
+	doneBuild2()
+	if got := c.Status(); got != "" {
+		t.Errorf("Status() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"sync"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgOutline = "GoOutline"
+
+// outlineBufferName is the buffer name of the GoOutline sidebar.
+const outlineBufferName = "__GoOutline__"
+
+// outlineSymbol represents a top-level declaration of the file.
+type outlineSymbol struct {
+	kind      string // "type", "func", "method", "var" or "const"
+	name      string
+	line, end int // the lines of the declaration range
+
+	// methods is the methods of the type symbol.
+	methods []outlineSymbol
+}
+
+// outlineState represents the displayed GoOutline sidebar.
+type outlineState struct {
+	mu   sync.Mutex
+	file string
+	win  nvim.Window
+
+	// rows is the symbols of each row in the sidebar.
+	rows []outlineSymbol
+	// row is the 0-based row which the sidebar cursor is last moved to by
+	// OutlineFollow, or -1 if it's not moved yet.
+	row int
+}
+
+func (c *Command) cmdOutline(file string) {
+	go func() {
+		if err := c.Outline(file); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Outline toggles the sidebar which lists the top-level declarations of the
+// file. The <CR> in the sidebar jumps to the declaration.
+func (c *Command) Outline(file string) error {
+	defer nvimutil.Profile(time.Now(), pkgOutline)
+
+	c.outline.mu.Lock()
+	defer c.outline.mu.Unlock()
+
+	if c.outline.win != 0 {
+		valid, err := c.Nvim.IsWindowValid(c.outline.win)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if valid {
+			var winnr int
+			if err := c.Nvim.Call("win_id2win", &winnr, c.outline.win); err != nil {
+				return errors.WithStack(err)
+			}
+			c.outline.win = 0
+			return errors.WithStack(c.Nvim.Command(fmt.Sprintf("%dclose", winnr)))
+		}
+	}
+
+	_, _, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	symbols, err := outlineSymbols(src)
+	if err != nil {
+		return err
+	}
+	text, rows := formatOutline(symbols)
+
+	srcWin, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+		nvimutil.BufferVar: {
+			"go_outline_win": int(srcWin),
+		},
+		nvimutil.WindowOption: {
+			"number":      false,
+			"winfixwidth": true,
+		},
+		// jump to the line number of the row in the source window
+		nvimutil.BufferMapping: {
+			"<CR>": `:<C-u>execute 'call win_gotoid(' . b:go_outline_win . ') <Bar> ' . matchstr(getline('.'), '\d\+')<CR>`,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	buf.Width = 40
+	if _, err := buf.Create(outlineBufferName, "", "vertical topleft new", option); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := buf.SetBufferLines(0, -1, true, text); err != nil {
+		return err
+	}
+
+	c.outline.file = file
+	c.outline.win = buf.Window
+	c.outline.rows = rows
+	c.outline.row = -1
+
+	// back to the source window
+	return errors.WithStack(c.Nvim.SetCurrentWindow(srcWin))
+}
+
+// OutlineFollow moves the cursor of the GoOutline sidebar to the declaration
+// which encloses the line of file. It's called on CursorMoved, so it does
+// nothing without the RPC unless the sidebar of file is open and the
+// enclosing declaration is changed.
+func (c *Command) OutlineFollow(file string, line int) error {
+	c.outline.mu.Lock()
+	defer c.outline.mu.Unlock()
+
+	if c.outline.win == 0 || c.outline.file != file {
+		return nil
+	}
+	row := outlineRow(c.outline.rows, line)
+	if row < 0 || row == c.outline.row {
+		return nil
+	}
+	valid, err := c.Nvim.IsWindowValid(c.outline.win)
+	if err != nil || !valid {
+		c.outline.win = 0
+		return errors.WithStack(err)
+	}
+	if err := c.Nvim.SetWindowCursor(c.outline.win, [2]int{row + 1, 0}); err != nil {
+		return errors.WithStack(err)
+	}
+	c.outline.row = row
+	return nil
+}
+
+// OutlineRefresh re-renders the GoOutline sidebar of file with the b buffer
+// contents, so the rows don't go stale after the edits. It's called on
+// TextChanged. The sidebar is kept as is while the buffer doesn't parse, such
+// as the incomplete code.
+func (c *Command) OutlineRefresh(file string, b nvim.Buffer) error {
+	c.outline.mu.Lock()
+	defer c.outline.mu.Unlock()
+
+	if c.outline.win == 0 || c.outline.file != file {
+		return nil
+	}
+	valid, err := c.Nvim.IsWindowValid(c.outline.win)
+	if err != nil || !valid {
+		c.outline.win = 0
+		return errors.WithStack(err)
+	}
+
+	lines, err := c.Nvim.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	symbols, err := outlineSymbols(append(nvimutil.ToByteSlice(lines), '\n'))
+	if err != nil {
+		return nil
+	}
+	text, rows := formatOutline(symbols)
+
+	ob, err := c.Nvim.WindowBuffer(c.outline.win)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := c.Nvim.SetBufferLines(ob, 0, -1, true, nvimutil.ToBufferLines(text)); err != nil {
+		return errors.WithStack(err)
+	}
+	c.outline.rows = rows
+	c.outline.row = -1
+
+	return nil
+}
+
+// outlineSymbols returns the top-level declarations of src in the declared
+// order. The methods are grouped under the receiver type. The receiver type
+// which is declared in the other file is placed at its first method.
+func outlineSymbols(src []byte) ([]outlineSymbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	newSymbol := func(kind, name string, n ast.Node) outlineSymbol {
+		return outlineSymbol{kind: kind, name: name, line: fset.Position(n.Pos()).Line, end: fset.Position(n.End()).Line}
+	}
+
+	var (
+		symbols []outlineSymbol
+		methods = make(map[string][]outlineSymbol) // the receiver type name to the methods
+		recvs   []string                           // the receiver type names in order
+	)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					symbols = append(symbols, newSymbol("type", spec.Name.Name, spec))
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						symbols = append(symbols, newSymbol(decl.Tok.String(), name.Name, spec))
+					}
+				}
+			}
+
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				symbols = append(symbols, newSymbol("func", decl.Name.Name, decl))
+				continue
+			}
+			recv := recvName(decl.Recv.List[0].Type)
+			if _, ok := methods[recv]; !ok {
+				recvs = append(recvs, recv)
+			}
+			methods[recv] = append(methods[recv], newSymbol("method", decl.Name.Name, decl))
+		}
+	}
+
+	for _, recv := range recvs {
+		found := false
+		for i := range symbols {
+			if symbols[i].kind == "type" && symbols[i].name == recv {
+				symbols[i].methods = methods[recv]
+				found = true
+				break
+			}
+		}
+		if !found {
+			first := methods[recv][0]
+			symbols = append(symbols, outlineSymbol{kind: "type", name: recv, line: first.line, end: first.line, methods: methods[recv]})
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool { return symbols[i].line < symbols[j].line })
+
+	return symbols, nil
+}
+
+// recvName returns the type name of the receiver type expression.
+func recvName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(t.X)
+	case *ast.ParenExpr:
+		return recvName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return types.ExprString(expr)
+}
+
+// formatOutline formats the symbols to the sidebar text, and returns the
+// symbols of each row. Each row is "line kind name" form, and the methods
+// are indented under the type.
+func formatOutline(symbols []outlineSymbol) ([]byte, []outlineSymbol) {
+	var (
+		buf  bytes.Buffer
+		rows []outlineSymbol
+	)
+	for _, sym := range symbols {
+		fmt.Fprintf(&buf, "%4d %s %s\n", sym.line, sym.kind, sym.name)
+		rows = append(rows, sym)
+		for _, m := range sym.methods {
+			fmt.Fprintf(&buf, "%4d   %s %s\n", m.line, m.kind, m.name)
+			rows = append(rows, m)
+		}
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), rows
+}
+
+// outlineRow returns the index of the innermost row which encloses the line,
+// or -1 if not found.
+func outlineRow(rows []outlineSymbol, line int) int {
+	row := -1
+	for i, sym := range rows {
+		if sym.line <= line && line <= sym.end {
+			row = i // the later row is the method of the type
+		}
+	}
+	return row
+}
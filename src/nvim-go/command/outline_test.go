@@ -0,0 +1,91 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutlineSymbols(t *testing.T) {
+	src := `package foo
+
+const (
+	A = 1
+	B = 2
+)
+
+var x, y int
+
+func (o *Other) Close() error { return nil }
+
+type T struct {
+	Name string
+}
+
+func New() *T {
+	return &T{}
+}
+
+func (t *T) String() string {
+	return t.Name
+}
+
+func (T) Len() int { return 0 }
+`
+	want := []outlineSymbol{
+		{kind: "const", name: "A", line: 4, end: 4},
+		{kind: "const", name: "B", line: 5, end: 5},
+		{kind: "var", name: "x", line: 8, end: 8},
+		{kind: "var", name: "y", line: 8, end: 8},
+		{kind: "type", name: "Other", line: 10, end: 10, methods: []outlineSymbol{
+			{kind: "method", name: "Close", line: 10, end: 10},
+		}},
+		{kind: "type", name: "T", line: 12, end: 14, methods: []outlineSymbol{
+			{kind: "method", name: "String", line: 20, end: 22},
+			{kind: "method", name: "Len", line: 24, end: 24},
+		}},
+		{kind: "func", name: "New", line: 16, end: 18},
+	}
+
+	got, err := outlineSymbols([]byte(src))
+	if err != nil {
+		t.Fatalf("outlineSymbols() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("outlineSymbols() = %+v, want %+v", got, want)
+	}
+
+	text, rows := formatOutline(got)
+	wantText := `   4 const A
+   5 const B
+   8 var x
+   8 var y
+  10 type Other
+  10   method Close
+  12 type T
+  20   method String
+  24   method Len
+  16 func New`
+	if string(text) != wantText {
+		t.Errorf("formatOutline() = %s, want %s", text, wantText)
+	}
+
+	tests := []struct {
+		line int
+		want int
+	}{
+		{line: 1, want: -1},
+		{line: 10, want: 5},
+		{line: 13, want: 6},
+		{line: 21, want: 7},
+		{line: 17, want: 9},
+	}
+	for _, tt := range tests {
+		if got := outlineRow(rows, tt.line); got != tt.want {
+			t.Errorf("outlineRow(%v) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
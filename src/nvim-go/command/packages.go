@@ -0,0 +1,427 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/ctx"
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgPackagesRefresh = "GoPackagesRefresh"
+
+// GoPackage represents a package of the project, which is the part of the
+// "go list -json" result.
+type GoPackage struct {
+	ImportPath   string
+	Name         string
+	Dir          string
+	Standard     bool
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// packagesState caches the packages of each project root, and the standard
+// library packages.
+type packagesState struct {
+	mu    sync.Mutex
+	roots map[string]*packageList
+	std   []*GoPackage
+}
+
+// packageList represents the cached packages of the project root.
+type packageList struct {
+	stamp dirStamp
+	pkgs  []*GoPackage // sorted by the ImportPath
+	deps  []*GoPackage // loaded on demand, sorted by the ImportPath
+}
+
+// reloadMode represents when loadPackages reloads the cached packages.
+type reloadMode int
+
+const (
+	// reloadNone uses the cached packages as is.
+	reloadNone reloadMode = iota
+	// reloadStale reloads the cached packages if the packages may be added
+	// or removed, which walks the directory tree.
+	reloadStale
+	// reloadForce always reloads the packages.
+	reloadForce
+)
+
+// dirStamp represents the state of the directory tree. The modification time
+// of the directory is changed by adding or removing its files, so the
+// different stamp means the packages may be changed.
+type dirStamp struct {
+	dirs   int
+	latest time.Time
+}
+
+// cmdPackagesComplete returns the import paths of the project packages which
+// has the ArgLead prefix.
+func (c *Command) cmdPackagesComplete(a *nvim.CommandCompletionArgs, cwd string) ([]string, error) {
+	c.ctx.SetContext(cwd)
+
+	paths, err := c.projectPackages(cwd)
+	if err != nil {
+		return nil, err
+	}
+	return filterPrefix(paths, a.ArgLead), nil
+}
+
+func (c *Command) cmdPackagesRefresh(dir string) {
+	go func() {
+		if err := c.PackagesRefresh(dir); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// PackagesRefresh rebuilds the package cache of the dir project.
+func (c *Command) PackagesRefresh(dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgPackagesRefresh)
+	c.ctx.SetContext(dir)
+
+	pkgs, err := c.loadPackages(c.ctx.Build, dir, reloadForce)
+	if err != nil {
+		return err
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgPackagesRefresh, fmt.Sprintf("%d packages", len(pkgs)))
+}
+
+// WarmPackages loads the package cache of the dir project in the background
+// if it's not cached yet, so the later lookups don't wait for the go list.
+// It's called on the Go buffer entered.
+func (c *Command) WarmPackages(dir string) {
+	c.ctx.SetContext(dir)
+	c.loadPackagesAsync(c.ctx.Build, dir, reloadNone)
+}
+
+// UpdatePackages reloads the package cache of the dir project in the
+// background if the packages are added or removed. It's called on the Go
+// file written.
+func (c *Command) UpdatePackages(dir string) {
+	c.ctx.SetContext(dir)
+	c.loadPackagesAsync(c.ctx.Build, dir, reloadStale)
+}
+
+// loadPackagesAsync runs loadPackages in the background, and logs the error.
+func (c *Command) loadPackagesAsync(b ctx.Build, dir string, mode reloadMode) {
+	go func() {
+		if _, err := c.loadPackages(b, dir, mode); err != nil {
+			nvimutil.NewLogger("packages").Printf("%v", err)
+		}
+	}()
+}
+
+// Packages returns the all packages in the project of dir, in order of the
+// import path. The cached packages are used as is, they are reloaded on the
+// file written or GoPackagesRefresh.
+func (c *Command) Packages(dir string) ([]*GoPackage, error) {
+	return c.loadPackages(c.ctx.Build, dir, reloadNone)
+}
+
+// LookupPackage returns the importPath package in the project of dir.
+func (c *Command) LookupPackage(dir, importPath string) (*GoPackage, error) {
+	pkgs, err := c.Packages(dir)
+	if err != nil {
+		return nil, err
+	}
+	i := sort.Search(len(pkgs), func(i int) bool { return pkgs[i].ImportPath >= importPath })
+	if i == len(pkgs) || pkgs[i].ImportPath != importPath {
+		return nil, errors.Errorf("package %s not found in the project", importPath)
+	}
+	return pkgs[i], nil
+}
+
+// appendPackages appends the project packages of cwd which has the argLead
+// prefix to the files if no file matches, so the files are preferred. The
+// flag argLead is not the package. The packages error is ignored because the
+// files are still the candidates.
+func (c *Command) appendPackages(files []string, argLead, cwd string) []string {
+	if len(files) > 0 || strings.HasPrefix(argLead, "-") {
+		return files
+	}
+	c.ctx.SetContext(cwd)
+
+	paths, err := c.projectPackages(cwd)
+	if err != nil {
+		nvimutil.NewLogger("packages").Printf("%v", err)
+		return files
+	}
+	return append(files, filterPrefix(paths, argLead)...)
+}
+
+// projectPackages returns the sorted import paths of the all packages in the
+// project of dir.
+func (c *Command) projectPackages(dir string) ([]string, error) {
+	if c.ctx.Build.Tool == "gb" {
+		return gbPackages(c.ctx.Build.ProjectRoot)
+	}
+	pkgs, err := c.Packages(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		paths[i] = pkg.ImportPath
+	}
+	return paths, nil
+}
+
+// gbPackages returns the sorted import paths of the packages in the src
+// directory of the gb project root, which the go list doesn't know.
+func gbPackages(root string) ([]string, error) {
+	src := filepath.Join(root, "src")
+	seen := make(map[string]bool)
+	var paths []string
+	err := filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if path != src && isIgnoredDir(fi.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.Dir(path)
+		if dir == src || filepath.Ext(path) != ".go" {
+			return nil
+		}
+		rel, err := filepath.Rel(src, dir)
+		if err != nil {
+			return err
+		}
+		if p := filepath.ToSlash(rel); !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// filterPrefix returns the elements of ss which has the prefix.
+func filterPrefix(ss []string, prefix string) []string {
+	var filtered []string
+	for _, s := range ss {
+		if strings.HasPrefix(s, prefix) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// packagesRoot returns the project root directory of dir, which is the module
+// root or the VCS root.
+func packagesRoot(b ctx.Build, dir string) string {
+	if b.ModuleRoot != "" {
+		return b.ModuleRoot
+	}
+	return pathutil.FindVCSRoot(dir)
+}
+
+// loadPackages returns the packages in the project of dir with the b build
+// context. The cached packages are reloaded according to mode, and the
+// reload clears the pathutil.PackageID cache.
+// The go list runs without the lock, so the slow list doesn't block the
+// other callers of the cached packages.
+// Returns nil if the build tool is not the go.
+func (c *Command) loadPackages(b ctx.Build, dir string, mode reloadMode) ([]*GoPackage, error) {
+	if b.Tool != "go" {
+		return nil, nil
+	}
+	root := packagesRoot(b, dir)
+
+	c.packages.mu.Lock()
+	l, ok := c.packages.roots[root]
+	c.packages.mu.Unlock()
+	if ok && mode == reloadNone {
+		return l.pkgs, nil
+	}
+
+	stamp, err := stampDir(root)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ok && mode == reloadStale && l.stamp == stamp {
+		return l.pkgs, nil
+	}
+
+	ctx, done := c.startOp(pkgPackagesRefresh)
+	defer done()
+
+	pkgs, err := listPackages(ctx, root, b.Env, "./...")
+	if err != nil {
+		return nil, err
+	}
+
+	c.packages.mu.Lock()
+	if c.packages.roots == nil {
+		c.packages.roots = make(map[string]*packageList)
+	}
+	c.packages.roots[root] = &packageList{stamp: stamp, pkgs: pkgs}
+	c.packages.mu.Unlock()
+	// the package IDs are stale as well as the packages
+	pathutil.ClearPackageIDCache()
+
+	return pkgs, nil
+}
+
+// StdPackages returns the standard library packages in order of the import
+// path. The packages are listed once, because it's not changed until the go
+// is updated.
+func (c *Command) StdPackages() ([]*GoPackage, error) {
+	c.packages.mu.Lock()
+	std := c.packages.std
+	c.packages.mu.Unlock()
+	if std != nil {
+		return std, nil
+	}
+
+	ctx, done := c.startOp(pkgPackagesRefresh)
+	defer done()
+
+	pkgs, err := listPackages(ctx, "", c.ctx.Build.Env, "std")
+	if err != nil {
+		return nil, err
+	}
+	c.packages.mu.Lock()
+	c.packages.std = pkgs
+	c.packages.mu.Unlock()
+	return pkgs, nil
+}
+
+// DepPackages returns the packages in the project of dir and the all
+// dependencies of them, in order of the import path. The dependencies are
+// cached with the project packages. Returns nil if the build tool is not the
+// go.
+func (c *Command) DepPackages(dir string) ([]*GoPackage, error) {
+	// updates the cached project packages first
+	if _, err := c.Packages(dir); err != nil {
+		return nil, err
+	}
+	b := c.ctx.Build
+	if b.Tool != "go" {
+		return nil, nil
+	}
+	root := packagesRoot(b, dir)
+
+	c.packages.mu.Lock()
+	l, ok := c.packages.roots[root]
+	var deps []*GoPackage
+	if ok {
+		deps = l.deps
+	}
+	c.packages.mu.Unlock()
+	if deps != nil {
+		return deps, nil
+	}
+
+	ctx, done := c.startOp(pkgPackagesRefresh)
+	defer done()
+
+	deps, err := listPackages(ctx, root, b.Env, "-deps", "./...")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		c.packages.mu.Lock()
+		l.deps = deps
+		c.packages.mu.Unlock()
+	}
+	return deps, nil
+}
+
+// listPackages runs the "go list -json args..." on root with the extra env,
+// and returns the packages in order of the import path.
+func listPackages(ctx context.Context, root string, env []string, args ...string) ([]*GoPackage, error) {
+	out, err := goList(ctx, root, env, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []*GoPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		pkg := new(GoPackage)
+		if err := dec.Decode(pkg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.WithStack(err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].ImportPath < pkgs[j].ImportPath })
+
+	return pkgs, nil
+}
+
+// goList runs the "go list -json args..." on dir with the extra env, and
+// returns the JSON stream of the packages.
+func goList(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"list", "-e", "-json"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("go list: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+// isIgnoredDir reports whether the go command ignores the packages of the
+// name directory, such as the vendor and the testdata.
+func isIgnoredDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// stampDir returns the stamp of the root directory tree. The directories
+// which the go command ignores, such as the vendor and the testdata, are
+// skipped.
+func stampDir(root string) (dirStamp, error) {
+	var stamp dirStamp
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if path != root && isIgnoredDir(fi.Name()) {
+			return filepath.SkipDir
+		}
+		stamp.dirs++
+		if fi.ModTime().After(stamp.latest) {
+			stamp.latest = fi.ModTime()
+		}
+		return nil
+	})
+	return stamp, err
+}
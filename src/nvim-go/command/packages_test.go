@@ -0,0 +1,157 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nvim-go/ctx"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestCommand_projectPackages(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"go.mod":      "module example.com/foo\n",
+		"foo.go":      "package foo\n",
+		"foo_test.go": "package foo_test\n",
+	})
+	defer cleanup()
+
+	writeFile := func(name, src string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("bar/bar.go", "package bar\n")
+	writeFile("bar/bar_test.go", "package bar\n")
+	writeFile("internal/baz/baz.go", "package baz\n")
+	writeFile("testdata/qux/qux.go", "package qux\n")
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	complete := func(lead string) []string {
+		got, err := c.cmdPackagesComplete(&nvim.CommandCompletionArgs{ArgLead: lead}, filepath.Join(dir, "bar"))
+		if err != nil {
+			t.Fatalf("cmdPackagesComplete(%q) error = %v", lead, err)
+		}
+		return got
+	}
+
+	want := []string{"example.com/foo", "example.com/foo/bar", "example.com/foo/internal/baz"}
+	if got := complete(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("cmdPackagesComplete(%q) = %v, want %v", "", got, want)
+	}
+	want = []string{"example.com/foo/internal/baz"}
+	if got := complete("example.com/foo/i"); !reflect.DeepEqual(got, want) {
+		t.Errorf("cmdPackagesComplete(%q) = %v, want %v", "example.com/foo/i", got, want)
+	}
+
+	// the completion uses the cache as is until the file written
+	writeFile("quux/quux.go", "package quux\n")
+	want = []string{"example.com/foo", "example.com/foo/bar", "example.com/foo/internal/baz"}
+	if got := complete(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("cmdPackagesComplete(%q) before reloaded = %v, want %v", "", got, want)
+	}
+
+	// the added package invalidates the cache on the file written
+	if _, err := c.loadPackages(c.ctx.Build, dir, reloadStale); err != nil {
+		t.Fatalf("loadPackages(%v) error = %v", dir, err)
+	}
+	want = []string{"example.com/foo", "example.com/foo/bar", "example.com/foo/internal/baz", "example.com/foo/quux"}
+	if got := complete(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("cmdPackagesComplete(%q) after added = %v, want %v", "", got, want)
+	}
+}
+
+func TestCommand_appendPackages(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"go.mod": "module example.com/foo\n",
+		"foo.go": "package foo\n",
+	})
+	defer cleanup()
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	tests := []struct {
+		name    string
+		files   []string
+		argLead string
+		want    []string
+	}{
+		{
+			name:  "files matched",
+			files: []string{"foo.go"},
+			want:  []string{"foo.go"},
+		},
+		{
+			name:    "no file matched",
+			argLead: "example.com/f",
+			want:    []string{"example.com/foo"},
+		},
+		{
+			name:    "flag",
+			argLead: "-",
+		},
+	}
+	for _, tt := range tests {
+		if got := c.appendPackages(tt.files, tt.argLead, dir); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. appendPackages(%v, %q) = %v, want %v", tt.name, tt.files, tt.argLead, got, tt.want)
+		}
+	}
+}
+
+func TestCommand_LookupPackage(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"go.mod":      "module example.com/foo\n",
+		"foo.go":      "package foo\n",
+		"foo_test.go": "package foo\n",
+		"x_test.go":   "package foo_test\n",
+	})
+	defer cleanup()
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	pkgs, err := c.Packages(dir)
+	if err != nil {
+		t.Fatalf("Packages(%v) error = %v", dir, err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("Packages(%v) = %v, want 1 package", dir, pkgs)
+	}
+	if _, ok := c.packages.roots[dir]; !ok {
+		t.Errorf("Packages(%v) didn't cache the packages", dir)
+	}
+
+	got, err := c.LookupPackage(dir, "example.com/foo")
+	if err != nil {
+		t.Fatalf("LookupPackage(%v) error = %v", "example.com/foo", err)
+	}
+	want := &GoPackage{
+		ImportPath:   "example.com/foo",
+		Name:         "foo",
+		Dir:          dir,
+		TestGoFiles:  []string{"foo_test.go"},
+		XTestGoFiles: []string{"x_test.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LookupPackage(%v) = %+v, want %+v", "example.com/foo", got, want)
+	}
+
+	if _, err := c.LookupPackage(dir, "example.com/bar"); err == nil {
+		t.Errorf("LookupPackage(%v) error = nil, want not found", "example.com/bar")
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgProfile = "GoProfile"
+
+// profileKinds is the GoProfile profile kinds in the completion order.
+var profileKinds = []string{"cpu", "mem", "block"}
+
+var pprofTerm *nvimutil.Terminal
+
+// profileState represents the profiles of GoProfile.
+type profileState struct {
+	mu sync.Mutex
+	// dirs is the package directory to the directory which stores the
+	// profiles and the test binary of the package.
+	dirs map[string]string
+	// web is the running pprof web UI.
+	web *exec.Cmd
+}
+
+func (c *Command) cmdProfile(args []string, bang bool, dir string) {
+	go func() {
+		err := c.Profile(args, bang, dir)
+		c.saveError("Profile", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Profile runs the all benchmarks of the dir package with the kind profile,
+// and opens the profile by go tool pprof. The profile is reused by the
+// repeated calls, bang re-runs the benchmarks.
+func (c *Command) Profile(args []string, bang bool, dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgProfile)
+	c.ctx.SetContext(dir)
+
+	if len(args) != 1 {
+		return errors.New("usage: GoProfile {cpu|mem|block}")
+	}
+	kind := args[0]
+
+	profDir, err := c.profiles.dir(dir)
+	if err != nil {
+		return err
+	}
+	prof := filepath.Join(profDir, kind+".prof")
+	bin := filepath.Join(profDir, filepath.Base(dir)+".test")
+	flags, err := profileFlags(kind, prof, bin)
+	if err != nil {
+		return err
+	}
+
+	if bang || !pathutil.IsExist(prof) || !pathutil.IsExist(bin) {
+		ctx, done := c.startOp(pkgProfile)
+		defer done()
+
+		nvimutil.EchoProgress(c.Nvim, pkgProfile, "go test -bench=. %s", strings.Join(flags, " "))
+		out, err := c.benchCmd(ctx, dir, ".", flags...).CombinedOutput()
+		if err != nil {
+			return errors.Errorf("go test -bench=.: %s", bytes.TrimSpace(out))
+		}
+		if len(parseBench(out)) == 0 {
+			// the profile of no benchmarks is empty, don't reuse it
+			os.Remove(prof)
+			return errors.Errorf("no benchmark in the %s package", filepath.Base(dir))
+		}
+	}
+
+	return c.pprof(dir, bin, prof)
+}
+
+// pprof opens the prof profile of the bin test binary by go tool pprof with
+// the config.BenchPprof mode.
+func (c *Command) pprof(dir, bin, prof string) error {
+	args := pprofArgs(config.BenchPprof(), bin, prof)
+	nvimutil.NewLogger("profile").Printf("go %s", strings.Join(args, " "))
+
+	if config.BenchPprof() == "web" {
+		c.profiles.mu.Lock()
+		defer c.profiles.mu.Unlock()
+
+		// the previous web UI serves the old profile
+		c.profiles.killWeb()
+
+		// run the pprof binary directly instead of via the go command, so
+		// that killing the cmd stops the web server. The go command doesn't
+		// forward the kill signal to the tool process.
+		tool, err := exec.Command("go", "tool", "-n", "pprof").Output()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		cmd := exec.Command(string(bytes.TrimSpace(tool)), args[2:]...)
+		cmd.Dir = dir
+		if err := cmd.Start(); err != nil {
+			return errors.WithStack(err)
+		}
+		go cmd.Wait()
+		c.profiles.web = cmd
+		return nvimutil.Echomsg(c.Nvim, pkgProfile+": opened the pprof web UI of "+prof)
+	}
+
+	cmd := append([]string{"go"}, args...)
+	if pprofTerm == nil {
+		pprofTerm = nvimutil.NewTerminal(c.Nvim, "__GoProfile__", cmd, config.TerminalMode())
+	}
+	pprofTerm.Dir = dir
+
+	return errors.WithStack(pprofTerm.Run(cmd))
+}
+
+// killWeb stops the running pprof web UI if any. s.mu must be held.
+func (s *profileState) killWeb() {
+	if s.web == nil {
+		return
+	}
+	s.web.Process.Kill()
+	s.web = nil
+}
+
+// cleanup stops the pprof web UI, and removes the profile directories.
+func (s *profileState) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.killWeb()
+	for pkgDir, dir := range s.dirs {
+		os.RemoveAll(dir)
+		delete(s.dirs, pkgDir)
+	}
+}
+
+// dir returns the directory which stores the profiles of the pkgDir package.
+// The directory is created at the first call, and reused after that.
+func (s *profileState) dir(pkgDir string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dir, ok := s.dirs[pkgDir]; ok && pathutil.IsExist(dir) {
+		return dir, nil
+	}
+	dir, err := ioutil.TempDir("", "nvim-go-profile")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if s.dirs == nil {
+		s.dirs = make(map[string]string)
+	}
+	s.dirs[pkgDir] = dir
+	return dir, nil
+}
+
+// profileFlags returns the go test flags which write the kind profile to
+// prof, and keep the test binary to bin for go tool pprof.
+func profileFlags(kind, prof, bin string) ([]string, error) {
+	switch kind {
+	case "cpu", "mem", "block":
+		return []string{"-" + kind + "profile=" + prof, "-o=" + bin}, nil
+	}
+	return nil, errors.Errorf("unknown profile %q, use cpu, mem or block", kind)
+}
+
+// pprofArgs returns the go tool pprof arguments which open the prof profile
+// of the bin. The "web" mode serves the web UI on the random port.
+func pprofArgs(mode, bin, prof string) []string {
+	args := []string{"tool", "pprof"}
+	if mode == "web" {
+		args = append(args, "-http=localhost:0")
+	}
+	return append(args, bin, prof)
+}
+
+func (c *Command) cmdProfileComplete(a *nvim.CommandCompletionArgs) ([]string, error) {
+	var kinds []string
+	for _, kind := range profileKinds {
+		if strings.HasPrefix(kind, a.ArgLead) {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds, nil
+}
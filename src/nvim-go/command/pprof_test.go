@@ -0,0 +1,112 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"nvim-go/ctx"
+	"nvim-go/internal/pathutil"
+)
+
+func TestCommand_profileCmd(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{"go.mod": "module foo\n", "foo.go": "package foo\n"})
+	defer cleanup()
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	tests := []struct {
+		kind     string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			kind:     "cpu",
+			wantArgs: []string{"go", "test", "-run=^$", "-bench=.", "-benchmem", "-cpuprofile=/tmp/p/cpu.prof", "-o=/tmp/p/foo.test", "."},
+		},
+		{
+			kind:     "mem",
+			wantArgs: []string{"go", "test", "-run=^$", "-bench=.", "-benchmem", "-memprofile=/tmp/p/mem.prof", "-o=/tmp/p/foo.test", "."},
+		},
+		{
+			kind:     "block",
+			wantArgs: []string{"go", "test", "-run=^$", "-bench=.", "-benchmem", "-blockprofile=/tmp/p/block.prof", "-o=/tmp/p/foo.test", "."},
+		},
+		{
+			kind:    "trace",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		flags, err := profileFlags(tt.kind, "/tmp/p/"+tt.kind+".prof", "/tmp/p/foo.test")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("profileFlags(%v) error = %v, wantErr %v", tt.kind, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		cmd := c.benchCmd(context.Background(), dir, ".", flags...)
+		if !reflect.DeepEqual(cmd.Args, tt.wantArgs) {
+			t.Errorf("benchCmd(%v) = %v, want %v", tt.kind, cmd.Args, tt.wantArgs)
+		}
+		if cmd.Dir != dir {
+			t.Errorf("benchCmd(%v).Dir = %v, want %v", tt.kind, cmd.Dir, dir)
+		}
+	}
+}
+
+func TestPprofArgs(t *testing.T) {
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{mode: "terminal", want: []string{"tool", "pprof", "foo.test", "cpu.prof"}},
+		{mode: "web", want: []string{"tool", "pprof", "-http=localhost:0", "foo.test", "cpu.prof"}},
+	}
+	for _, tt := range tests {
+		if got := pprofArgs(tt.mode, "foo.test", "cpu.prof"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pprofArgs(%v) = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestProfileState_dir(t *testing.T) {
+	var s profileState
+	first, err := s.dir("/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(first)
+
+	second, err := s.dir("/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Errorf("dir(%q) = %v, want the reused %v", "/foo", second, first)
+	}
+}
+
+func TestProfileState_cleanup(t *testing.T) {
+	var s profileState
+	dir, err := s.dir("/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s.cleanup()
+	if pathutil.IsExist(dir) {
+		t.Errorf("cleanup() left the profile directory %v", dir)
+	}
+	if len(s.dirs) != 0 {
+		t.Errorf("cleanup() dirs = %v, want empty", s.dirs)
+	}
+}
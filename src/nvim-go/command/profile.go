@@ -0,0 +1,74 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+func (c *Command) cmdProfileReport(bang bool) {
+	go func() {
+		if err := c.ProfileReport(bang); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// ProfileReport shows the min, median and max durations of each command
+// which measured by nvimutil.Profile in the scratch buffer. The recorded
+// durations are cleared if bang is true.
+func (c *Command) ProfileReport(bang bool) error {
+	if bang {
+		nvimutil.ResetProfileStats()
+		return nvimutil.Echomsg(c.Nvim, "GoProfileReport: cleared the recorded durations")
+	}
+
+	stats := nvimutil.ProfileStats()
+	if len(stats) == 0 {
+		return nvimutil.Echomsg(c.Nvim, "GoProfileReport: no recorded durations")
+	}
+
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create("__GoProfileReport__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+	return buf.SetBufferLines(0, -1, true, bytes.TrimSuffix(formatProfileStats(stats), []byte{'\n'}))
+}
+
+// formatProfileStats formats the stats to the aligned table.
+func formatProfileStats(stats []nvimutil.ProfileStat) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "command\tcount\tmin\tmedian\tmax")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", s.Name, s.Count, roundDuration(s.Min), roundDuration(s.Median), roundDuration(s.Max))
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// roundDuration rounds d to the readable precision.
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
@@ -0,0 +1,169 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+const pkgReferrers = "GoReferrers"
+
+// referrersMapping is the buffer local mappings which cycle the references.
+var referrersMapping = map[string]string{
+	"]r": ":<C-u>call GoReferrersJump(1)<CR>",
+	"[r": ":<C-u>call GoReferrersJump(0)<CR>",
+}
+
+func (c *Command) cmdReferrers(eval *funcGuruEval) {
+	go func() {
+		err := c.Referrers(eval)
+		c.saveError("Referrers", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Referrers lists the references of the identifier under the cursor to the
+// error list, and maps the ]r and [r to cycle through them. Only the
+// references in the current package are listed if config.ReferrersSameOnly.
+func (c *Command) Referrers(eval *funcGuruEval) error {
+	defer nvimutil.Profile(time.Now(), pkgReferrers)
+	c.ctx.SetContext(filepath.Dir(eval.File))
+
+	query, err := c.guruQuery(eval)
+	if err != nil {
+		return err
+	}
+
+	_, done := c.startOp(pkgReferrers)
+	defer done()
+
+	var dir string
+	if config.ReferrersSameOnly() {
+		dir = filepath.Dir(eval.File)
+	}
+	var (
+		outputMu  sync.Mutex
+		outputErr error
+		list      []*nvim.QuickfixError
+	)
+	query.Output = func(fset *token.FileSet, qr guru.QueryResult) {
+		outputMu.Lock()
+		defer outputMu.Unlock()
+
+		refs, err := referrersList(qr.Result(fset), eval.Cwd, dir)
+		if err != nil {
+			outputErr = errors.WithStack(err)
+			return
+		}
+		list = append(list, refs...)
+	}
+
+	nvimutil.EchoProgress(c.Nvim, pkgReferrers, "analysing referrers")
+	if err := guru.Run("referrers", query); err != nil {
+		return errors.WithStack(err)
+	}
+	if outputErr != nil {
+		return outputErr
+	}
+	if len(list) == 0 {
+		return nvimutil.Info(c.Nvim, pkgReferrers, "not found")
+	}
+	// the packages are output in any order
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].FileName != list[j].FileName {
+			return list[i].FileName < list[j].FileName
+		}
+		if list[i].LNum != list[j].LNum {
+			return list[i].LNum < list[j].LNum
+		}
+		return list[i].Col < list[j].Col
+	})
+
+	w := nvim.Window(c.ctx.WinID)
+	listType := nvimutil.ListTypeOf("Referrers")
+	if err := nvimutil.SetList(c.Nvim, w, listType, eval.Cwd, list); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := c.mapReferrers(); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("%d references found in %d files", len(list), referrersFiles(list))
+	if dir != "" {
+		msg += " of the current package"
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgReferrers, msg)
+}
+
+func (c *Command) funcReferrersJump(forward int) {
+	go func() {
+		if err := c.ReferrersJump(forward); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// ReferrersJump jumps to the next reference of the GoReferrers list, or the
+// previous reference if forward is 0, with the centered view.
+func (c *Command) ReferrersJump(forward int) error {
+	batch := c.Nvim.NewBatch()
+	batch.Command(nvimutil.CycleCmd(nvimutil.ListTypeOf("Referrers"), forward != 0))
+	batch.Command("normal! zz")
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+	// the jumped buffer also cycles the references
+	return c.mapReferrers()
+}
+
+// mapReferrers maps the referrersMapping to the current buffer.
+func (c *Command) mapReferrers() error {
+	batch := c.Nvim.NewBatch()
+	for lhs, rhs := range referrersMapping {
+		batch.Command(fmt.Sprintf("silent %s <buffer><silent>%s %s", nvimutil.NoremapNormal, lhs, rhs))
+	}
+	return errors.WithStack(batch.Execute())
+}
+
+// referrersList parses the referrers query result to the list. The
+// references out of the dir package are excluded if dir is not empty.
+func referrersList(res interface{}, cwd, dir string) ([]*nvim.QuickfixError, error) {
+	if pkg, ok := res.(serial.ReferrersPackage); ok && dir != "" {
+		var refs []serial.Ref
+		for _, ref := range pkg.Refs {
+			if fname, _, _ := nvimutil.SplitPos(ref.Pos, dir); filepath.Dir(pathutil.Abs(dir, fname)) == dir {
+				refs = append(refs, ref)
+			}
+		}
+		pkg.Refs = refs
+		res = pkg
+	}
+	return parseResult("referrers", res, cwd)
+}
+
+// referrersFiles returns the number of the files in list.
+func referrersFiles(list []*nvim.QuickfixError) int {
+	files := make(map[string]bool)
+	for _, e := range list {
+		files[e.FileName] = true
+	}
+	return len(files)
+}
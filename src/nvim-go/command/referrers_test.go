@@ -0,0 +1,81 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+func TestReferrersList(t *testing.T) {
+	pkg := serial.ReferrersPackage{
+		Package: "foo/bar",
+		Refs: []serial.Ref{
+			{Pos: "/src/foo/bar/bar.go:10:2", Text: "\tbar.Baz()"},
+			{Pos: "/src/foo/bar/sub/sub.go:4:9", Text: "\treturn bar.Baz"},
+			{Pos: "/src/foo/qux/qux.go:20:5", Text: "\tx := bar.Baz"},
+		},
+	}
+	tests := []struct {
+		name string
+		res  interface{}
+		dir  string
+		want []*nvim.QuickfixError
+	}{
+		{
+			name: "initial",
+			res:  &serial.ReferrersInitial{ObjPos: "/src/foo/bar/bar.go:3:6", Desc: "func Baz"},
+			want: nil,
+		},
+		{
+			name: "all packages",
+			res:  pkg,
+			want: []*nvim.QuickfixError{
+				{FileName: "bar.go", LNum: 10, Col: 2, Text: "\tbar.Baz()"},
+				{FileName: "sub/sub.go", LNum: 4, Col: 9, Text: "\treturn bar.Baz"},
+				{FileName: "/src/foo/qux/qux.go", LNum: 20, Col: 5, Text: "\tx := bar.Baz"},
+			},
+		},
+		{
+			name: "same package only",
+			res:  pkg,
+			dir:  "/src/foo/bar",
+			want: []*nvim.QuickfixError{
+				{FileName: "bar.go", LNum: 10, Col: 2, Text: "\tbar.Baz()"},
+			},
+		},
+		{
+			name: "other package only",
+			res:  pkg,
+			dir:  "/src/foo/baz",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := referrersList(tt.res, "/src/foo/bar", tt.dir)
+			if err != nil {
+				t.Fatalf("referrersList(%v) error = %v", tt.dir, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("referrersList(%v) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferrersFiles(t *testing.T) {
+	list := []*nvim.QuickfixError{
+		{FileName: "bar.go", LNum: 10},
+		{FileName: "bar.go", LNum: 20},
+		{FileName: "sub/sub.go", LNum: 4},
+	}
+	if got := referrersFiles(list); got != 2 {
+		t.Errorf("referrersFiles() = %v, want 2", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"go/build"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
 	"nvim-go/config"
@@ -36,7 +37,7 @@ func (c *Command) cmdRename(args []string, bang bool, eval *cmdRenameEval) {
 			nvimutil.ErrorWrap(c.Nvim, e)
 		case []*nvim.QuickfixError:
 			c.ctx.Errlist["Rename"] = e
-			nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+			nvimutil.ErrorList(c.Nvim, eval.Cwd, c.ctx.Errlist, true)
 		}
 	}()
 }
@@ -44,6 +45,7 @@ func (c *Command) cmdRename(args []string, bang bool, eval *cmdRenameEval) {
 // Rename rename the current cursor word use golang.org/x/tools/refactor/rename.
 func (c *Command) Rename(args []string, bang bool, eval *cmdRenameEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoRename")
+	c.ctx.SetContext(filepath.Dir(eval.File))
 
 	b := nvim.Buffer(c.ctx.BufNr)
 	w := nvim.Window(c.ctx.WinID)
@@ -60,7 +62,7 @@ func (c *Command) Rename(args []string, bang bool, eval *cmdRenameEval) interfac
 	} else {
 		askMessage := fmt.Sprintf("%s: Rename '%s' to: ", pkgRename, eval.RenameFrom)
 		var toResult interface{}
-		if config.RenamePrefill {
+		if config.RenamePrefill() {
 			err := c.Nvim.Call("input", &toResult, askMessage, eval.RenameFrom)
 			if err != nil {
 				return errors.New("GoRename: Keyboard interrupt")
@@ -83,8 +85,17 @@ func (c *Command) Rename(args []string, bang bool, eval *cmdRenameEval) interfac
 		rename.Force = true
 	}
 
+	_, done := c.startOp(pkgRename)
+	defer done()
+
+	stamps, err := nvimutil.StampFiles(c.Nvim)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	// TODO(zchee): More elegant way
 	// save original stdout and stderr
+	nvimutil.StdioMu.Lock()
 	saveStdout, saveStderr := os.Stdout, os.Stderr
 	read, write, _ := os.Pipe()
 	// migrate stderr and stdout
@@ -93,6 +104,7 @@ func (c *Command) Rename(args []string, bang bool, eval *cmdRenameEval) interfac
 	defer func() {
 		os.Stderr = saveStdout
 		os.Stderr = saveStderr
+		nvimutil.StdioMu.Unlock()
 	}()
 
 	// TODO(zchee): reached race limit, dying when race build
@@ -114,7 +126,7 @@ func (c *Command) Rename(args []string, bang bool, eval *cmdRenameEval) interfac
 	out, _ := ioutil.ReadAll(read)
 	defer nvimutil.EchoSuccess(c.Nvim, pkgRename, fmt.Sprintf("%s", out))
 
-	// TODO(zchee): 'edit' command is ugly.
-	// Should create tempfile and use SetBufferLines.
-	return c.Nvim.Command("silent edit")
+	// rename may rewrite the other files of the package and its importers
+	files := stamps.ChangedFiles()
+	return nvimutil.ReloadChangedBuffers(c.Nvim, files)
 }
@@ -53,7 +53,7 @@ func (c *Command) Run(args []string, file string) error {
 	}
 
 	if runTerm == nil {
-		runTerm = nvimutil.NewTerminal(c.Nvim, "__GO_RUN__", cmd, config.TerminalMode)
+		runTerm = nvimutil.NewTerminal(c.Nvim, "__GO_RUN__", cmd, config.TerminalMode())
 	}
 	runTerm.Dir = pathutil.FindVCSRoot(filepath.Dir(file))
 
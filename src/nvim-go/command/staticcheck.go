@@ -0,0 +1,184 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgStaticcheck = "GoStaticcheck"
+
+// staticcheckSignGroup is the sign group of the GoStaticcheck signs.
+const staticcheckSignGroup = "nvim-go-staticcheck"
+
+// staticcheckSigns is the sign name and highlight group of each quickfix
+// type.
+var staticcheckSigns = map[string]struct{ name, text, hl string }{
+	"E": {name: "go_staticcheck_error", text: "E>", hl: "GoStaticcheckError"},
+	"W": {name: "go_staticcheck_warning", text: "W>", hl: "GoStaticcheckWarning"},
+}
+
+// CmdStaticcheckEval represents a GoStaticcheck command Eval args.
+type CmdStaticcheckEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+// staticcheckDiagnostic represents a diagnostic of the "staticcheck -f json"
+// output.
+type staticcheckDiagnostic struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Location struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	} `json:"location"`
+}
+
+func (c *Command) cmdStaticcheck(eval *CmdStaticcheckEval) {
+	go func() {
+		delete(c.ctx.Errlist, "Staticcheck")
+
+		switch e := c.Staticcheck(eval).(type) {
+		case error:
+			c.saveError("Staticcheck", e)
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.saveError("Staticcheck", nil)
+			c.ctx.Errlist["Staticcheck"] = e
+			nvimutil.ErrorList(c.Nvim, eval.Cwd, c.ctx.Errlist, true)
+		case nil:
+			c.saveError("Staticcheck", nil)
+			nvimutil.ErrorList(c.Nvim, eval.Cwd, c.ctx.Errlist, true)
+		}
+	}()
+}
+
+// Staticcheck runs the staticcheck over the current package, and lists the
+// diagnostics which severity is config.StaticcheckSeverity or higher. The
+// checks are limited by config.StaticcheckChecks. The signs are placed to the
+// current file if config.StaticcheckSigns is enabled.
+func (c *Command) Staticcheck(eval *CmdStaticcheckEval) interface{} {
+	defer nvimutil.Profile(time.Now(), pkgStaticcheck)
+	dir := filepath.Dir(eval.File)
+	c.ctx.SetContext(dir)
+
+	bin, err := tools.Require(pkgStaticcheck, "staticcheck")
+	if err != nil {
+		return err
+	}
+	ctx, done := c.startOp(pkgStaticcheck)
+	defer done()
+
+	args := []string{"-f", "json"}
+	if checks := config.StaticcheckChecks(); len(checks) > 0 {
+		args = append(args, "-checks", strings.Join(checks, ","))
+	}
+	args = append(args, ".")
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
+	cmd.Env = c.ctx.Build.Environ()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// staticcheck exits with 1 if found the problems
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		return errors.Errorf("staticcheck: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	errlist, err := parseStaticcheck(&stdout, dir, config.StaticcheckSeverity())
+	if err != nil {
+		return err
+	}
+	if config.StaticcheckSigns() {
+		if err := c.placeStaticcheckSigns(eval.File, errlist); err != nil {
+			return err
+		}
+	}
+	if len(errlist) == 0 {
+		return nil
+	}
+	return errlist
+}
+
+// placeStaticcheckSigns places the signs of the errlist diagnostics of file.
+// The previous signs of file are removed.
+func (c *Command) placeStaticcheckSigns(file string, errlist []*nvim.QuickfixError) error {
+	for _, s := range staticcheckSigns {
+		if _, err := nvimutil.NewSign(c.Nvim, s.name, s.text, s.hl, ""); err != nil {
+			return err
+		}
+	}
+
+	batch := c.Nvim.NewBatch()
+	batch.Call("sign_unplace", nil, staticcheckSignGroup, map[string]interface{}{"buffer": file})
+	for _, e := range errlist {
+		if e.FileName == file {
+			batch.Call("sign_place", nil, 0, staticcheckSignGroup, staticcheckSigns[e.Type].name, file, map[string]interface{}{"lnum": e.LNum})
+		}
+	}
+	return errors.WithStack(batch.Execute())
+}
+
+// parseStaticcheck parses the "staticcheck -f json" output r to the list. The
+// relative file names are based on dir. The diagnostics which severity is
+// lower than minSeverity, and the ignored ones, are omitted.
+func parseStaticcheck(r io.Reader, dir, minSeverity string) ([]*nvim.QuickfixError, error) {
+	var errlist []*nvim.QuickfixError
+
+	dec := json.NewDecoder(r)
+	for {
+		var d staticcheckDiagnostic
+		if err := dec.Decode(&d); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.WithStack(err)
+		}
+
+		var typ string
+		switch d.Severity {
+		case "error":
+			typ = "E"
+		case "warning":
+			if minSeverity == "error" {
+				continue
+			}
+			typ = "W"
+		default: // "ignored"
+			continue
+		}
+
+		fname := d.Location.File
+		if !filepath.IsAbs(fname) {
+			fname = filepath.Join(dir, fname)
+		}
+		errlist = append(errlist, &nvim.QuickfixError{
+			FileName: fname,
+			LNum:     d.Location.Line,
+			Col:      d.Location.Column,
+			Text:     fmt.Sprintf("%s: %s", d.Code, d.Message),
+			Type:     typ,
+		})
+	}
+	return errlist, nil
+}
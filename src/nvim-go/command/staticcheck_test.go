@@ -0,0 +1,53 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestParseStaticcheck(t *testing.T) {
+	out := `{"code":"SA4006","severity":"error","location":{"file":"/go/src/foo/foo.go","line":5,"column":2},"end":{"file":"/go/src/foo/foo.go","line":5,"column":5},"message":"this value of err is never used"}
+{"code":"ST1005","severity":"warning","location":{"file":"bar.go","line":12,"column":9},"end":{"file":"bar.go","line":12,"column":30},"message":"error strings should not be capitalized"}
+{"code":"SA1019","severity":"ignored","location":{"file":"/go/src/foo/foo.go","line":8,"column":3},"end":{"file":"/go/src/foo/foo.go","line":8,"column":10},"message":"os.SEEK_SET is deprecated"}
+`
+
+	tests := []struct {
+		name        string
+		minSeverity string
+		want        []*nvim.QuickfixError
+	}{
+		{
+			name:        "warning",
+			minSeverity: "warning",
+			want: []*nvim.QuickfixError{
+				{FileName: "/go/src/foo/foo.go", LNum: 5, Col: 2, Text: "SA4006: this value of err is never used", Type: "E"},
+				{FileName: filepath.FromSlash("/go/src/foo/bar.go"), LNum: 12, Col: 9, Text: "ST1005: error strings should not be capitalized", Type: "W"},
+			},
+		},
+		{
+			name:        "error",
+			minSeverity: "error",
+			want: []*nvim.QuickfixError{
+				{FileName: "/go/src/foo/foo.go", LNum: 5, Col: 2, Text: "SA4006: this value of err is never used", Type: "E"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseStaticcheck(strings.NewReader(out), filepath.FromSlash("/go/src/foo"), tt.minSeverity)
+		if err != nil {
+			t.Errorf("%q. parseStaticcheck() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. parseStaticcheck() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
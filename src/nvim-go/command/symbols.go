@@ -0,0 +1,264 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"nvim-go/config"
+	"nvim-go/internal/pathutil"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgSymbols = "GoSymbols"
+
+type cmdSymbolsEval struct {
+	Cwd string `msgpack:",array"`
+	Dir string
+}
+
+func (c *Command) cmdSymbols(args []string, bang bool, eval *cmdSymbolsEval) {
+	go func() {
+		if err := c.Symbols(args, bang, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Symbols searches the top-level declarations which fuzzy match the args
+// query in the config.SymbolsScope packages, and sets the matches to the
+// quickfix list. The unexported declarations are also searched if bang is
+// true.
+func (c *Command) Symbols(args []string, bang bool, eval *cmdSymbolsEval) error {
+	defer nvimutil.Profile(time.Now(), pkgSymbols)
+	c.ctx.SetContext(eval.Dir)
+
+	dirs := []string{eval.Dir}
+	if config.SymbolsScope() == "module" {
+		var err error
+		dirs, err = c.listPackageDirs(symbolsRoot(c.ctx.ModuleRoot, eval.Dir))
+		if err != nil {
+			return err
+		}
+	}
+
+	syms, err := c.symbols.find(dirs, strings.Join(args, " "), bang)
+	if err != nil {
+		return err
+	}
+	if len(syms) == 0 {
+		return nvimutil.EchoSuccess(c.Nvim, pkgSymbols, "no symbols found")
+	}
+
+	list := make([]*nvim.QuickfixError, len(syms))
+	for i, sym := range syms {
+		list[i] = &nvim.QuickfixError{
+			FileName: sym.file,
+			LNum:     sym.line,
+			Col:      1,
+			Text:     sym.kind + " " + sym.name,
+		}
+	}
+	w, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := nvimutil.SetList(c.Nvim, w, nvimutil.Quickfix, eval.Cwd, list); err != nil {
+		return errors.WithStack(err)
+	}
+	return nvimutil.OpenList(c.Nvim, w, nvimutil.Quickfix, list, true)
+}
+
+// InvalidateSymbols discards the cached symbols of the file for GoSymbols.
+func (c *Command) InvalidateSymbols(file string) {
+	c.symbols.invalidate(file)
+}
+
+// symbolsRoot returns the root directory of the module scope, which is the
+// module root, or the VCS root of dir in the case of GOPATH.
+func symbolsRoot(moduleRoot, dir string) string {
+	if moduleRoot != "" {
+		return moduleRoot
+	}
+	if root := pathutil.FindVCSRoot(dir); root != "" {
+		return root
+	}
+	return dir
+}
+
+// listPackageDirs returns the directories of the all packages under the root.
+func (c *Command) listPackageDirs(root string) ([]string, error) {
+	cmd := exec.Command("go", "list", "-e", "-f", "{{.Dir}}", "./...")
+	cmd.Dir = root
+	cmd.Env = c.ctx.Build.Environ()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("go list: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// workspaceSymbol represents a top-level declaration of the package.
+type workspaceSymbol struct {
+	kind string
+	name string // "T.Method" form if the method
+	file string
+	line int
+
+	score int // the fuzzy match score of the query
+}
+
+// symbolCache caches the symbols of each file. The entry is invalidated by
+// the saving of the file, or the modification time change.
+type symbolCache struct {
+	mu    sync.Mutex
+	files map[string]*symbolFile
+}
+
+// symbolFile represents the cached symbols of the file.
+type symbolFile struct {
+	modTime time.Time
+	symbols []workspaceSymbol
+}
+
+func (sc *symbolCache) invalidate(file string) {
+	sc.mu.Lock()
+	delete(sc.files, file)
+	sc.mu.Unlock()
+}
+
+// fileSymbols returns the symbols of the file from the cache, or parses it.
+func (sc *symbolCache) fileSymbols(file string, modTime time.Time) ([]workspaceSymbol, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if f, ok := sc.files[file]; ok && f.modTime.Equal(modTime) {
+		return f.symbols, nil
+	}
+
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	outline, err := outlineSymbols(src)
+	if err != nil {
+		return nil, err
+	}
+	var syms []workspaceSymbol
+	for _, o := range outline {
+		syms = append(syms, workspaceSymbol{kind: o.kind, name: o.name, file: file, line: o.line})
+		for _, m := range o.methods {
+			syms = append(syms, workspaceSymbol{kind: m.kind, name: o.name + "." + m.name, file: file, line: m.line})
+		}
+	}
+
+	if sc.files == nil {
+		sc.files = make(map[string]*symbolFile)
+	}
+	sc.files[file] = &symbolFile{modTime: modTime, symbols: syms}
+	return syms, nil
+}
+
+// find returns the symbols of the Go files in dirs which fuzzy match the
+// query, in the order of the match score. The unexported symbols are also
+// returned if unexported is true. The files which can't be parsed are skipped.
+func (sc *symbolCache) find(dirs []string, query string, unexported bool) ([]workspaceSymbol, error) {
+	var matches []workspaceSymbol
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+		for _, fi := range files {
+			if fi.IsDir() || filepath.Ext(fi.Name()) != ".go" {
+				continue
+			}
+			syms, err := sc.fileSymbols(filepath.Join(dir, fi.Name()), fi.ModTime())
+			if err != nil {
+				continue
+			}
+			for _, sym := range syms {
+				if !unexported && !isExportedSymbol(sym.name) {
+					continue
+				}
+				score, ok := fuzzyMatch(query, sym.name)
+				if !ok {
+					continue
+				}
+				sym.score = score
+				matches = append(matches, sym)
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].name < matches[j].name
+	})
+	return matches, nil
+}
+
+// isExportedSymbol reports whether the name symbol is exported. The method
+// symbol is exported if both the type and the method are exported.
+func isExportedSymbol(name string) bool {
+	for _, s := range strings.Split(name, ".") {
+		if s == "" || !unicode.IsUpper([]rune(s)[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyMatch reports whether the all characters of query appear in name in
+// order, ignoring case. The score is higher for the consecutive characters
+// and the match at the start of name or words.
+func fuzzyMatch(query, name string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	n := []rune(name)
+
+	score, qi := 0, 0
+	prev := -2
+	for i := 0; i < len(n) && qi < len(q); i++ {
+		if unicode.ToLower(n[i]) != q[qi] {
+			continue
+		}
+		switch {
+		case i == 0:
+			score += 3
+		case prev == i-1:
+			score += 2
+		case unicode.IsUpper(n[i]) || n[i-1] == '.' || n[i-1] == '_':
+			score++
+		}
+		prev = i
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	if len(q) == len(n) {
+		score++ // the exact match
+	}
+	return score, true
+}
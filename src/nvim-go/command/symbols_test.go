@@ -0,0 +1,123 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSymbolCache_Find(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"server.go": `package foo
+
+type Server struct{}
+
+func NewServer() *Server { return &Server{} }
+
+func (s *Server) Serve() error { return nil }
+
+func (s *Server) close() {}
+`,
+		"client.go": `package foo
+
+const DefaultPort = 80
+
+type client struct{}
+
+func (c *client) Serve() {}
+`,
+		"broken.go": "package foo\n\nfunc",
+	})
+	defer cleanup()
+
+	type result struct {
+		name, file string
+		line       int
+	}
+	tests := []struct {
+		name       string
+		query      string
+		unexported bool
+		want       []result
+	}{
+		{
+			name:  "exported",
+			query: "serve",
+			want: []result{
+				{name: "Server", file: "server.go", line: 3},
+				{name: "Server.Serve", file: "server.go", line: 7},
+				{name: "NewServer", file: "server.go", line: 5},
+			},
+		},
+		{
+			name:       "unexported",
+			query:      "clos",
+			unexported: true,
+			want: []result{
+				{name: "Server.close", file: "server.go", line: 9},
+			},
+		},
+		{
+			name:  "no match",
+			query: "xyz",
+		},
+	}
+	var sc symbolCache
+	for _, tt := range tests {
+		syms, err := sc.find([]string{dir}, tt.query, tt.unexported)
+		if err != nil {
+			t.Errorf("%q. find(%v) error = %v", tt.name, tt.query, err)
+			continue
+		}
+		var got []result
+		for _, sym := range syms {
+			got = append(got, result{name: sym.name, file: filepath.Base(sym.file), line: sym.line})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. find(%v) = %v, want %v", tt.name, tt.query, got, tt.want)
+		}
+	}
+
+	// the invalidated file is parsed again
+	fname := filepath.Join(dir, "client.go")
+	if err := ioutil.WriteFile(fname, []byte("package foo\n\nconst DefaultHost = \"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sc.invalidate(fname)
+	syms, err := sc.find([]string{dir}, "DefaultH", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(syms) != 1 || syms[0].name != "DefaultHost" {
+		t.Errorf("find(%v) after invalidate = %v, want DefaultHost", "DefaultH", syms)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query, name string
+		wantOK      bool
+	}{
+		{"", "Server", true},
+		{"srv", "Server", true},
+		{"SERVE", "Server.Serve", true},
+		{"vs", "Server", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyMatch(tt.query, tt.name); ok != tt.wantOK {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.query, tt.name, ok, tt.wantOK)
+		}
+	}
+
+	// the prefix and consecutive match is higher than the scattered match
+	prefix, _ := fuzzyMatch("ser", "Server")
+	scattered, _ := fuzzyMatch("ser", "NewSpeaker")
+	if prefix <= scattered {
+		t.Errorf("fuzzyMatch(%q) score = %v, want greater than %v", "ser", prefix, scattered)
+	}
+}
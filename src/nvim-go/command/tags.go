@@ -0,0 +1,328 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const pkgTags = "GoTags"
+
+type cmdTagsEval struct {
+	File   string `msgpack:",array"`
+	Offset int
+}
+
+func (c *Command) cmdAddTags(args []string, ranges [2]int, eval *cmdTagsEval) {
+	go func() {
+		if err := c.AddTags(args, ranges, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) cmdRemoveTags(args []string, ranges [2]int, eval *cmdTagsEval) {
+	go func() {
+		if err := c.RemoveTags(args, ranges, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// AddTags adds the args[0] key tags to the fields of the struct under the
+// cursor, or the fields in the ranges lines. The rest of args are the tag
+// options such as "omitempty". The tag value is the field name which
+// transformed by config.StructTagCase. Updates the value if the key exists.
+func (c *Command) AddTags(args []string, ranges [2]int, eval *cmdTagsEval) error {
+	defer nvimutil.Profile(time.Now(), pkgTags)
+
+	key := args[0]
+	options := args[1:]
+	tagCase := config.StructTagCase()
+	return c.editTags(ranges, eval, func(name string, tag structTag) structTag {
+		value := strings.Join(append([]string{transformCase(name, tagCase)}, options...), ",")
+		return tag.set(key, value)
+	})
+}
+
+// RemoveTags removes the args keys tags from the fields of the struct under
+// the cursor, or the fields in the ranges lines.
+func (c *Command) RemoveTags(args []string, ranges [2]int, eval *cmdTagsEval) error {
+	defer nvimutil.Profile(time.Now(), pkgTags)
+
+	return c.editTags(ranges, eval, func(name string, tag structTag) structTag {
+		for _, key := range args {
+			tag = tag.delete(key)
+		}
+		return tag
+	})
+}
+
+func (c *Command) editTags(ranges [2]int, eval *cmdTagsEval, edit func(name string, tag structTag) structTag) error {
+	b, in, src, err := c.bufferSource()
+	if err != nil {
+		return err
+	}
+	lines := ranges
+	if lines[0] == lines[1] {
+		lines = [2]int{} // the struct under the cursor
+	}
+	out, skipped, err := editTags(src, eval.Offset, lines, edit)
+	if err != nil {
+		return err
+	}
+	if err := c.updateBuffer(b, in, out); err != nil {
+		return err
+	}
+
+	if len(skipped) > 0 {
+		return nvimutil.EchohlAfter(c.Nvim, pkgTags, "WarningMsg", "skipped the malformed tags of %s", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// editTags returns the src which the tags of the target fields edited by
+// edit. The target fields are the fields in the lines range if lines is not
+// zero, otherwise the fields of the innermost struct which encloses offset.
+// The fields which have the malformed tag are left as is not to lose the
+// tag, and their names are returned as skipped.
+func editTags(src []byte, offset int, lines [2]int, edit func(name string, tag structTag) structTag) (out []byte, skipped []string, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	var fields []*ast.Field
+	if lines != [2]int{} {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				for _, field := range st.Fields.List {
+					if line := fset.Position(field.Pos()).Line; lines[0] <= line && line <= lines[1] {
+						fields = append(fields, field)
+					}
+				}
+			}
+			return true
+		})
+	} else {
+		pos := fset.File(file.Pos()).Pos(offset)
+		path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+		for _, n := range path {
+			if st, ok := n.(*ast.StructType); ok {
+				fields = st.Fields.List
+				break
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil, errors.New("no struct fields found")
+	}
+
+	var edits []textEdit
+	for _, field := range fields {
+		name := fieldName(field)
+		if name == "" {
+			continue
+		}
+
+		var tag structTag
+		if field.Tag != nil {
+			s, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+			if tag, err = parseStructTag(s); err != nil {
+				skipped = append(skipped, name)
+				continue
+			}
+		}
+		tag = edit(name, tag)
+
+		var e textEdit
+		switch {
+		case field.Tag != nil && len(tag) == 0:
+			e = textEdit{start: fset.Position(field.Type.End()).Offset, end: fset.Position(field.Tag.End()).Offset}
+		case field.Tag != nil:
+			e = textEdit{start: fset.Position(field.Tag.Pos()).Offset, end: fset.Position(field.Tag.End()).Offset, text: "`" + tag.String() + "`"}
+		case len(tag) > 0:
+			end := fset.Position(field.Type.End()).Offset
+			e = textEdit{start: end, end: end, text: " `" + tag.String() + "`"}
+		default:
+			continue
+		}
+		edits = append(edits, e)
+	}
+
+	out, err = format.Source(applyEdits(src, edits))
+	return out, skipped, errors.WithStack(err)
+}
+
+// fieldName returns the first name of field, or the type name if field is
+// the embedded field.
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	typ := field.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// tagPair represents a key and value pair of the struct tag.
+type tagPair struct {
+	key, value string
+}
+
+// structTag represents the ordered key and value pairs of the struct tag.
+type structTag []tagPair
+
+// parseStructTag parses the conventional `key:"value" key:"value"` format
+// struct tag. Returns the error if s has the malformed part, because the
+// String of the tag would drop it.
+func parseStructTag(s string) (structTag, error) {
+	var tag structTag
+	malformed := errors.Errorf("malformed struct tag %q", s)
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return tag, nil
+		}
+		i := strings.Index(s, `:"`)
+		if i <= 0 || strings.ContainsAny(s[:i], " \"`") {
+			return tag, malformed
+		}
+		key := s[:i]
+		rest := s[i+1:]
+
+		// find the closing quote of the value
+		j := 1
+		for j < len(rest) && rest[j] != '"' {
+			if rest[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(rest) {
+			return tag, malformed
+		}
+		value, err := strconv.Unquote(rest[:j+1])
+		if err != nil {
+			return tag, malformed
+		}
+		tag = append(tag, tagPair{key: key, value: value})
+		s = rest[j+1:]
+	}
+}
+
+func (t structTag) String() string {
+	pairs := make([]string, len(t))
+	for i, p := range t {
+		pairs[i] = p.key + ":" + strconv.Quote(p.value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// set returns the tag which the key value set. The existing key keeps its order.
+func (t structTag) set(key, value string) structTag {
+	tag := append(structTag{}, t...)
+	for i, p := range tag {
+		if p.key == key {
+			tag[i].value = value
+			return tag
+		}
+	}
+	return append(tag, tagPair{key: key, value: value})
+}
+
+// delete returns the tag which the key removed.
+func (t structTag) delete(key string) structTag {
+	var tag structTag
+	for _, p := range t {
+		if p.key != key {
+			tag = append(tag, p)
+		}
+	}
+	return tag
+}
+
+// transformCase transforms the Go identifier name to tagCase, which is
+// "snake", "camel" or "kebab".
+// like:
+//  transformCase("UserID", "snake") == "user_id"
+func transformCase(name, tagCase string) string {
+	words := splitWords(name)
+	switch tagCase {
+	case "camel":
+		for i, w := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(w)
+				continue
+			}
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+		return strings.Join(words, "")
+	case "kebab":
+		return strings.ToLower(strings.Join(words, "-"))
+	default: // "snake"
+		return strings.ToLower(strings.Join(words, "_"))
+	}
+}
+
+// splitWords splits the mixed caps name to words. The consecutive upper case
+// letters are the one word, such as the initialism.
+// like:
+//  splitWords("HTTPServerID") == []string{"HTTP", "Server", "ID"}
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var (
+		words []string
+		start int
+	)
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case cur == '_':
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		case unicode.IsLower(prev) && unicode.IsUpper(cur),
+			unicode.IsDigit(prev) && unicode.IsUpper(cur):
+			words = append(words, string(runes[start:i]))
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,199 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tagsSrc = `package foo
+
+type User struct {
+	ID       int
+	UserName string ` + "`yaml:\"user\" json:\"name\"`" + `
+	*Base
+}
+
+type Other struct {
+	HTTPServer string
+}
+` + brokenTagsSrc
+
+// brokenTagsSrc is the struct of tagsSrc which has the malformed tag.
+const brokenTagsSrc = `
+type Broken struct {
+	Name string ` + "`json:\"name\" db`" + `
+	ID   int
+}
+`
+
+func TestEditTags(t *testing.T) {
+	add := func(key string, options ...string) func(string, structTag) structTag {
+		return func(name string, tag structTag) structTag {
+			return tag.set(key, strings.Join(append([]string{transformCase(name, "snake")}, options...), ","))
+		}
+	}
+	remove := func(key string) func(string, structTag) structTag {
+		return func(name string, tag structTag) structTag { return tag.delete(key) }
+	}
+
+	tests := []struct {
+		name        string
+		cursor      string
+		lines       [2]int
+		edit        func(string, structTag) structTag
+		want        string
+		wantSkipped []string
+	}{
+		{
+			name:   "add",
+			cursor: "ID ",
+			edit:   add("xml"),
+			want: `type User struct {
+	ID       int    ` + "`xml:\"id\"`" + `
+	UserName string ` + "`yaml:\"user\" json:\"name\" xml:\"user_name\"`" + `
+	*Base    ` + "`xml:\"base\"`" + `
+}
+
+type Other struct {
+	HTTPServer string
+}
+` + brokenTagsSrc,
+		},
+		{
+			name:   "update with options",
+			cursor: "ID ",
+			edit:   add("json", "omitempty"),
+			want: `type User struct {
+	ID       int    ` + "`json:\"id,omitempty\"`" + `
+	UserName string ` + "`yaml:\"user\" json:\"user_name,omitempty\"`" + `
+	*Base    ` + "`json:\"base,omitempty\"`" + `
+}
+
+type Other struct {
+	HTTPServer string
+}
+` + brokenTagsSrc,
+		},
+		{
+			name:   "remove",
+			cursor: "ID ",
+			edit:   remove("yaml"),
+			want: `type User struct {
+	ID       int
+	UserName string ` + "`json:\"name\"`" + `
+	*Base
+}
+
+type Other struct {
+	HTTPServer string
+}
+` + brokenTagsSrc,
+		},
+		{
+			name:  "lines range",
+			lines: [2]int{5, 10},
+			edit:  remove("json"),
+			want: `type User struct {
+	ID       int
+	UserName string ` + "`yaml:\"user\"`" + `
+	*Base
+}
+
+type Other struct {
+	HTTPServer string
+}
+` + brokenTagsSrc,
+		},
+		{
+			name:   "malformed tag",
+			cursor: "\tName ",
+			edit:   add("xml"),
+			want: `type User struct {
+	ID       int
+	UserName string ` + "`yaml:\"user\" json:\"name\"`" + `
+	*Base
+}
+
+type Other struct {
+	HTTPServer string
+}
+
+type Broken struct {
+	Name string ` + "`json:\"name\" db`" + `
+	ID   int    ` + "`xml:\"id\"`" + `
+}
+`,
+			wantSkipped: []string{"Name"},
+		},
+	}
+	for _, tt := range tests {
+		offset := strings.Index(tagsSrc, tt.cursor)
+		got, skipped, err := editTags([]byte(tagsSrc), offset, tt.lines, tt.edit)
+		if err != nil {
+			t.Errorf("%q. editTags(%v, %v) error = %v", tt.name, offset, tt.lines, err)
+			continue
+		}
+		want := "package foo\n\n" + tt.want
+		if string(got) != want {
+			t.Errorf("%q. editTags(%v, %v) = %s, want %s", tt.name, offset, tt.lines, got, want)
+		}
+		if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+			t.Errorf("%q. editTags(%v, %v) skipped = %v, want %v", tt.name, offset, tt.lines, skipped, tt.wantSkipped)
+		}
+	}
+}
+
+func TestParseStructTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want structTag
+	}{
+		{tag: "", want: nil},
+		{tag: `json:"id,omitempty"`, want: structTag{{key: "json", value: "id,omitempty"}}},
+		{tag: `yaml:"a" json:"b\"c"`, want: structTag{{key: "yaml", value: "a"}, {key: "json", value: `b"c`}}},
+	}
+	for _, tt := range tests {
+		got, err := parseStructTag(tt.tag)
+		if err != nil {
+			t.Errorf("parseStructTag(%q) error = %v", tt.tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseStructTag(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+		if got.String() != tt.tag {
+			t.Errorf("parseStructTag(%q).String() = %q, want %q", tt.tag, got.String(), tt.tag)
+		}
+	}
+
+	for _, tag := range []string{`json:"id" broken`, `broken`, `json:"id`, `json: "id"`, `a b:"c"`} {
+		if _, err := parseStructTag(tag); err == nil {
+			t.Errorf("parseStructTag(%q) error = nil, want the malformed error", tag)
+		}
+	}
+}
+
+func TestTransformCase(t *testing.T) {
+	tests := []struct {
+		name    string
+		tagCase string
+		want    string
+	}{
+		{name: "UserID", tagCase: "snake", want: "user_id"},
+		{name: "HTTPServerID", tagCase: "snake", want: "http_server_id"},
+		{name: "HTTPServerID", tagCase: "camel", want: "httpServerID"},
+		{name: "UserName", tagCase: "kebab", want: "user-name"},
+		{name: "name", tagCase: "camel", want: "name"},
+		{name: "Base64Data", tagCase: "snake", want: "base64_data"},
+	}
+	for _, tt := range tests {
+		if got := transformCase(tt.name, tt.tagCase); got != tt.want {
+			t.Errorf("transformCase(%q, %q) = %q, want %q", tt.name, tt.tagCase, got, tt.want)
+		}
+	}
+}
@@ -5,14 +5,20 @@
 package command
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"go/ast"
 	"go/build"
 	"go/parser"
 	"go/token"
-	"log"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,7 +35,13 @@ import (
 // GoTest
 
 func (c *Command) cmdTest(args []string, dir string) {
-	go c.Test(args, dir)
+	go func() {
+		err := c.Test(args, dir)
+		c.saveError("Test", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
 }
 
 // testTerm cache nvimutil.Terminal use global variable.
@@ -39,19 +51,59 @@ var testTerm *nvimutil.Terminal
 // the directory structure.
 func (c *Command) Test(args []string, dir string) error {
 	defer nvimutil.Profile(time.Now(), "GoTest")
+	c.ctx.SetContext(dir)
+
+	cmd, env, err := c.testCmd(args, dir, config.TestRace())
+	if err != nil {
+		return err
+	}
+	// the terminal command runs on the Neovim environment
+	if env = append(append([]string{}, c.ctx.Build.Env...), env...); len(env) > 0 {
+		cmd = append(append([]string{"env"}, env...), cmd...)
+	}
+	nvimutil.NewLogger("test").Printf("%s", strings.Join(cmd, " "))
+
+	if testTerm == nil {
+		testTerm = nvimutil.NewTerminal(c.Nvim, "__GO_TEST__", cmd, config.TerminalMode())
+	}
+	testTerm.Dir = c.testDir(dir)
+
+	if err := testTerm.Run(cmd); err != nil {
+		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	}
+
+	return nil
+}
+
+// testCmd returns the test command of the dir package, and the extra
+// environment variables of the command. The race enables the race detector,
+// which requires cgo.
+// The project packages of args, such as the GoPackagesCompletion result, are
+// tested instead of the dir package.
+func (c *Command) testCmd(args []string, dir string, race bool) ([]string, []string, error) {
+	args, testPkgs, err := c.splitPackageArgs(args, dir)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	cmd := []string{c.ctx.Build.Tool, "test", strings.Join(config.TestFlags, " ")}
-	if len(args) > 0 {
-		cmd = append(cmd, args...)
+	var env []string
+	if race {
+		args = append([]string{"-race"}, args...)
+		env = append(env, "CGO_ENABLED=1")
 	}
 
-	var testPkgs []string
-	if config.TestAll {
+	cmd := []string{c.ctx.Build.Tool, "test"}
+	cmd = append(cmd, c.mergeGoFlags(dir, args, config.TestFlags())...)
+
+	switch {
+	case len(testPkgs) > 0:
+		// nothing to do
+	case config.TestAll():
 		switch c.ctx.Build.Tool {
 		case "go":
 			pkgs, err := pathutil.FindAllPackage(dir, build.Default, nil, pathutil.ModeExcludeVendor)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, nil, errors.WithStack(err)
 			}
 			for _, p := range pkgs {
 				testPkgs = append(testPkgs, pathutil.TrimGoPath(p.Dir))
@@ -59,27 +111,259 @@ func (c *Command) Test(args []string, dir string) error {
 		case "gb":
 			// nothing to do
 		}
-	} else {
+	default:
 		pkgs, err := pathutil.PackageID(dir)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
 		testPkgs = append(testPkgs, pkgs)
 	}
 
-	cmd = append(cmd, testPkgs...)
-	log.Println(cmd)
+	return append(cmd, testPkgs...), env, nil
+}
 
-	if testTerm == nil {
-		testTerm = nvimutil.NewTerminal(c.Nvim, "__GO_TEST__", cmd, config.TerminalMode)
-		testTerm.Dir = pathutil.FindVCSRoot(dir)
+// splitPackageArgs splits args into the flags and the import paths of the
+// project packages of dir.
+func (c *Command) splitPackageArgs(args []string, dir string) (flags, pkgs []string, err error) {
+	maybePkg := false
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			maybePkg = true
+			break
+		}
+	}
+	if !maybePkg {
+		return args, nil, nil
 	}
 
-	if err := testTerm.Run(cmd); err != nil {
-		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
+	paths, err := c.projectPackages(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, arg := range args {
+		if i := sort.SearchStrings(paths, arg); i < len(paths) && paths[i] == arg {
+			pkgs = append(pkgs, arg)
+			continue
+		}
+		flags = append(flags, arg)
 	}
+	return flags, pkgs, nil
+}
 
-	return nil
+// testDir returns the working directory of the test command for dir, which
+// is the module root, the gb project root or the VCS root.
+func (c *Command) testDir(dir string) string {
+	switch {
+	case c.ctx.Build.ModuleRoot != "":
+		return c.ctx.Build.ModuleRoot
+	case c.ctx.Build.Tool == "gb":
+		return c.ctx.Build.ProjectRoot
+	}
+	return pathutil.FindVCSRoot(dir)
+}
+
+// ----------------------------------------------------------------------------
+// GoTestCompile
+
+const pkgTestCompile = "GoTestCompile"
+
+func (c *Command) cmdTestCompile(dir string) {
+	go func() {
+		c.errs.Delete("TestCompile")
+
+		err := c.TestCompile(dir)
+		switch e := err.(type) {
+		case error:
+			c.saveError("TestCompile", e)
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case nil:
+			c.saveError("TestCompile", nil)
+		case []*nvim.QuickfixError:
+			c.saveError("TestCompile", nil)
+			c.errs.Store("TestCompile", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, "", errlist, true)
+		}
+	}()
+}
+
+// TestCompile compiles the test binary of the dir package without running
+// any test, and returns the compile errors as the quickfix list. It's much
+// faster than the GoTest for checking the _test.go files compile.
+func (c *Command) TestCompile(dir string) interface{} {
+	defer nvimutil.Profile(time.Now(), pkgTestCompile)
+	c.ctx.SetContext(dir)
+
+	ctx, done := c.startOp(pkgTestCompile)
+	defer done()
+
+	errlist, err := c.testCompile(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if len(errlist) > 0 {
+		return errlist
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgTestCompile, "")
+}
+
+// testCompile runs "go test -c" for the dir package, which outputs the test
+// binary to os.DevNull instead of running it, and returns the compile errors.
+// The gb has no equivalent of the "go test -c", so it's refused.
+func (c *Command) testCompile(ctx context.Context, dir string) ([]*nvim.QuickfixError, error) {
+	if tool := c.ctx.Build.Tool; tool != "go" {
+		return nil, errors.Errorf("%s doesn't support the %s build tool", pkgTestCompile, tool)
+	}
+
+	args := append([]string{"test", "-c", "-o", os.DevNull}, c.mergeGoFlags(dir, nil, nil)...)
+	cmd := exec.CommandContext(ctx, "go", append(args, ".")...)
+	cmd.Dir = dir
+	cmd.Env = c.ctx.Build.Environ()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, errors.WithStack(err)
+		}
+		errlist, err := nvimutil.ParseError(stderr.Bytes(), dir, &c.ctx.Build, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(errlist) == 0 {
+			return nil, errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return errlist, nil
+	}
+	return nil, nil
+}
+
+// ----------------------------------------------------------------------------
+// GoTestRace
+
+const pkgTestRace = "GoTestRace"
+
+func (c *Command) cmdTestRace(args []string, dir string) {
+	go func() {
+		err := c.TestRace(args, dir)
+		c.saveError("TestRace", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// TestRace runs the package test with the race detector, and streams the
+// output into the scratch buffer. The data race reports and the test failures
+// are set to the quickfix list.
+func (c *Command) TestRace(args []string, dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgTestRace)
+	c.ctx.SetContext(dir)
+
+	ctx, done := c.startOp(pkgTestRace)
+	defer done()
+
+	w, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	args, env, err := c.testCmd(args, dir, true)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = c.testDir(dir)
+	cmd.Env = append(c.ctx.Build.Environ(), env...)
+
+	nvimutil.EchoProgress(c.Nvim, pkgTestRace, "%s test -race", c.ctx.Build.Tool)
+	output, runErr := c.runStream(cmd, "__GoTestRace__")
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return runErr
+		}
+	}
+
+	races, rest := parseRaceReport(output)
+	errlist, err := nvimutil.ParseError(rest, dir, &c.ctx.Build, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, e := range errlist {
+		if !strings.Contains(e.Text, "race detected during execution of test") {
+			races = append(races, e)
+		}
+	}
+	if len(races) > 0 {
+		if err := nvimutil.SetList(c.Nvim, w, nvimutil.Quickfix, "", races); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := nvimutil.OpenList(c.Nvim, w, nvimutil.Quickfix, races, true); err != nil {
+		return errors.WithStack(err)
+	}
+	if runErr != nil {
+		return errors.New("go test -race failed")
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, pkgTestRace, "no data race detected")
+}
+
+const (
+	raceDelim   = "=================="
+	raceWarning = "WARNING: DATA RACE"
+)
+
+// raceFrameRe matches the file line of the stack frame in the race report.
+// like "      /path/to/foo_test.go:12 +0x44"
+var raceFrameRe = regexp.MustCompile(`^\s+(\S+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+
+// parseRaceReport parses the data race reports in out, and returns the
+// quickfix entries of the first user frame of each section, such as
+// "Write at 0x00c000 by goroutine 7", and the rest of out.
+func parseRaceReport(out []byte) ([]*nvim.QuickfixError, []byte) {
+	var (
+		races   []*nvim.QuickfixError
+		rest    bytes.Buffer
+		inRace  bool
+		section string
+	)
+	goroot := filepath.Clean(runtime.GOROOT()) + string(filepath.Separator)
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case line == raceDelim:
+			inRace = !inRace
+			section = ""
+			continue
+		case !inRace:
+			rest.WriteString(line + "\n")
+			continue
+		case line == raceWarning || line == "":
+			continue
+		case !strings.HasPrefix(line, " ") && strings.HasSuffix(line, ":"):
+			section = strings.TrimSuffix(line, ":")
+			continue
+		}
+
+		m := raceFrameRe.FindStringSubmatch(line)
+		if m == nil || section == "" || !filepath.IsAbs(m[1]) || strings.HasPrefix(m[1], goroot) {
+			continue
+		}
+		lnum, _ := strconv.Atoi(m[2])
+		races = append(races, &nvim.QuickfixError{
+			FileName: m[1],
+			LNum:     lnum,
+			Text:     "DATA RACE: " + section,
+			Type:     "E",
+		})
+		section = "" // only the first user frame of the section
+	}
+	return races, bytes.TrimSuffix(rest.Bytes(), []byte{'\n'})
 }
 
 // ----------------------------------------------------------------------------
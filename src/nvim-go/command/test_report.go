@@ -0,0 +1,359 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+const pkgTestReport = "GoTestReport"
+
+// testReportBufferName is the buffer name of the GoTestReport summary.
+const testReportBufferName = "__GoTestReport__"
+
+// testEvent represents an event of the "go test -json" output.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64 // seconds
+	Output  string
+}
+
+// testFailure represents a failed test of the report.
+type testFailure struct {
+	pkg string
+	// name is the test name, empty if the package failed without the failed
+	// tests, such as the build failure, os.Exit in TestMain or timeout.
+	name string
+	// file is the file name of the first failure location, which is relative
+	// to the package directory until it's resolved.
+	file    string
+	line    int
+	message string
+}
+
+// testReport represents the summary of a test run.
+type testReport struct {
+	passed    int
+	failed    int
+	skipped   int
+	failedPkg int     // the packages failed without the failed tests
+	elapsed   float64 // seconds, the sum of the packages
+	failures  []*testFailure
+	// stderr is the standard error of the failed run, such as the build
+	// errors which are not in the -json output.
+	stderr string
+}
+
+// testReportState represents the displayed GoTestReport summary.
+type testReportState struct {
+	mu sync.Mutex
+	// rows is the failure of each row in the summary, nil for the header rows.
+	rows []*testFailure
+}
+
+// testLocationRe matches the failure location of the test output, such as:
+//  "    foo_test.go:12: got 1, want 2"
+var testLocationRe = regexp.MustCompile(`^\s+([^\s:]+\.go):(\d+): ?(.*)`)
+
+func (c *Command) cmdTestReport(args []string, dir string) {
+	go func() {
+		err := c.TestReport(args, dir)
+		c.saveError("TestReport", err)
+		if err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) funcTestReportJump(row int) {
+	go func() {
+		if err := c.TestReportJump(row); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// TestReport runs the package tests with the -json flag, and shows the
+// summary of the failed tests in the scratch buffer, apart from the raw
+// output of the GoTest. The <CR> in the summary jumps to the failure.
+func (c *Command) TestReport(args []string, dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgTestReport)
+	c.ctx.SetContext(dir)
+
+	if c.ctx.Build.Tool != "go" {
+		return errors.Errorf("%s test doesn't support the -json flag", c.ctx.Build.Tool)
+	}
+
+	ctx, done := c.startOp(pkgTestReport)
+	defer done()
+
+	args, env, err := c.testCmd(args, dir, false)
+	if err != nil {
+		return err
+	}
+	args = append([]string{args[0], args[1], "-json"}, args[2:]...)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = c.testDir(dir)
+	cmd.Env = append(c.ctx.Build.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	nvimutil.EchoProgress(c.Nvim, pkgTestReport, "go test -json")
+	// go test exits with 1 if any test failed
+	runErr := cmd.Run()
+	if runErr != nil && stdout.Len() == 0 {
+		return errors.Errorf("go test: %v: %s", runErr, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	report, err := parseTestReport(&stdout)
+	if err != nil {
+		return err
+	}
+	if runErr != nil {
+		report.stderr = string(bytes.TrimSpace(stderr.Bytes()))
+	}
+	for _, f := range report.failures {
+		if f.file == "" || filepath.IsAbs(f.file) {
+			continue
+		}
+		if pkg, err := c.LookupPackage(dir, f.pkg); err == nil {
+			f.file = filepath.Join(pkg.Dir, f.file)
+		}
+	}
+
+	return c.showTestReport(report)
+}
+
+// showTestReport writes the report to the GoTestReport summary buffer.
+func (c *Command) showTestReport(report *testReport) error {
+	c.testReport.mu.Lock()
+	defer c.testReport.mu.Unlock()
+
+	srcWin, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenHide,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+		nvimutil.BufferVar: {
+			"go_test_report_win": int(srcWin),
+		},
+		nvimutil.BufferMapping: {
+			"<CR>": ":<C-u>call GoTestReportJump(line('.'))<CR>",
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create(testReportBufferName, "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines, rows := report.render()
+	c.testReport.rows = rows
+	if err := buf.SetBufferLines(0, -1, true, bytes.Join(lines, []byte{'\n'})); err != nil {
+		return err
+	}
+
+	// back to the source window
+	return errors.WithStack(c.Nvim.SetCurrentWindow(srcWin))
+}
+
+// TestReportJump opens the failure location at the 1-based row of the
+// GoTestReport summary in the source window.
+func (c *Command) TestReportJump(row int) error {
+	c.testReport.mu.Lock()
+	defer c.testReport.mu.Unlock()
+
+	if row < 1 || row > len(c.testReport.rows) {
+		return nil
+	}
+	f := c.testReport.rows[row-1]
+	if f == nil {
+		return nil
+	}
+	if f.file == "" {
+		return errors.Errorf("%s: no failure location", f.title())
+	}
+
+	var srcWin int
+	if err := c.Nvim.Eval("b:go_test_report_win", &srcWin); err != nil {
+		return errors.WithStack(err)
+	}
+	// the file may contain the special characters of Ex commands, such as space
+	var file string
+	if err := c.Nvim.Call("fnameescape", &file, f.file); err != nil {
+		return errors.WithStack(err)
+	}
+	batch := c.Nvim.NewBatch()
+	batch.Call("win_gotoid", nil, srcWin)
+	batch.Command(fmt.Sprintf("edit +%d %s", f.line, file))
+	return errors.WithStack(batch.Execute())
+}
+
+// parseTestReport parses the "go test -json" output r to the report. The
+// output of each test is grouped, and the first failure location of the
+// failed test is its location.
+// The parent test whose subtests failed is collapsed into the subtests unless
+// it has its own failure location. The failed package without the failed
+// tests is the failure of the package output.
+func parseTestReport(r io.Reader) (*testReport, error) {
+	report := new(testReport)
+	outputs := make(map[[2]string][]string)
+	failedPkgs := make(map[string]bool) // the packages which have the failed tests
+
+	dec := json.NewDecoder(r)
+	for {
+		var e testEvent
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.WithStack(err)
+		}
+
+		key := [2]string{e.Package, e.Test}
+		if e.Action == "output" {
+			outputs[key] = append(outputs[key], e.Output)
+			continue
+		}
+		if e.Test == "" {
+			// the package result
+			if e.Action == "pass" || e.Action == "fail" {
+				report.elapsed += e.Elapsed
+			}
+			if e.Action == "fail" && !failedPkgs[e.Package] {
+				report.failedPkg++
+				report.failures = append(report.failures, newTestFailure(e.Package, "", outputs[key]))
+			}
+			continue
+		}
+
+		switch e.Action {
+		case "pass":
+			report.passed++
+		case "skip":
+			report.skipped++
+		case "fail":
+			failedPkgs[e.Package] = true
+			f := newTestFailure(e.Package, e.Test, outputs[key])
+			if f.file == "" && report.hasFailedSubtest(e.Package, e.Test) {
+				continue
+			}
+			report.failed++
+			report.failures = append(report.failures, f)
+		}
+	}
+
+	return report, nil
+}
+
+// hasFailedSubtest reports whether r has the failed subtest of the name test
+// of pkg.
+func (r *testReport) hasFailedSubtest(pkg, name string) bool {
+	for _, f := range r.failures {
+		if f.pkg == pkg && strings.HasPrefix(f.name, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestFailure returns the failure of the name test of pkg from its output.
+func newTestFailure(pkg, name string, output []string) *testFailure {
+	f := &testFailure{pkg: pkg, name: name}
+	for _, out := range output {
+		trimmed := strings.TrimSpace(out)
+		if trimmed == "" || strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") {
+			continue
+		}
+		if m := testLocationRe.FindStringSubmatch(out); m != nil {
+			f.file = m[1]
+			f.line, _ = strconv.Atoi(m[2])
+			f.message = strings.TrimSpace(m[3])
+			break
+		}
+		if f.message == "" {
+			f.message = trimmed
+		}
+	}
+	return f
+}
+
+// title returns the test name of f, or the package path if the package failed.
+func (f *testFailure) title() string {
+	if f.name == "" {
+		return f.pkg
+	}
+	return f.name
+}
+
+// render returns the lines of the summary, and the failure of each line.
+// The first line is the counts and the elapsed time, and each failure is the
+// "TestFoo  foo_test.go:12: message" form, or the "foo: message" form of the
+// failed package. The stderr of the failed run follows the failures.
+func (r *testReport) render() ([][]byte, []*testFailure) {
+	status := "PASS"
+	if r.failed > 0 || r.failedPkg > 0 || r.stderr != "" {
+		status = "FAIL"
+	}
+	header := fmt.Sprintf("%s: %d passed, %d failed, %d skipped", status, r.passed, r.failed, r.skipped)
+	if r.failedPkg > 0 {
+		header += fmt.Sprintf(", %d failed packages", r.failedPkg)
+	}
+	lines := [][]byte{
+		[]byte(fmt.Sprintf("%s (%.2fs)", header, r.elapsed)),
+	}
+	rows := []*testFailure{nil}
+
+	if len(r.failures) > 0 {
+		lines = append(lines, []byte{})
+		rows = append(rows, nil)
+	}
+	for _, f := range r.failures {
+		if f.name == "" {
+			lines = append(lines, []byte(fmt.Sprintf("%s: %s", f.pkg, f.message)))
+			rows = append(rows, f)
+			continue
+		}
+		loc := f.pkg
+		if f.file != "" {
+			loc = fmt.Sprintf("%s:%d", filepath.Base(f.file), f.line)
+		}
+		lines = append(lines, []byte(fmt.Sprintf("%s  %s: %s", f.name, loc, f.message)))
+		rows = append(rows, f)
+	}
+
+	if r.stderr != "" {
+		lines = append(lines, []byte{})
+		rows = append(rows, nil)
+		for _, l := range strings.Split(r.stderr, "\n") {
+			lines = append(lines, []byte(l))
+			rows = append(rows, nil)
+		}
+	}
+	return lines, rows
+}
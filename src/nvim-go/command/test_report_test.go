@@ -0,0 +1,110 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTestReport(t *testing.T) {
+	events := `{"Action":"run","Package":"foo","Test":"TestAdd"}
+{"Action":"output","Package":"foo","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"output","Package":"foo","Test":"TestAdd","Output":"--- PASS: TestAdd (0.00s)\n"}
+{"Action":"pass","Package":"foo","Test":"TestAdd","Elapsed":0}
+{"Action":"run","Package":"foo","Test":"TestSub"}
+{"Action":"output","Package":"foo","Test":"TestSub","Output":"=== RUN   TestSub\n"}
+{"Action":"output","Package":"foo","Test":"TestSub","Output":"--- FAIL: TestSub (0.00s)\n"}
+{"Action":"output","Package":"foo","Test":"TestSub","Output":"    foo_test.go:12: Sub(2, 1) = 3, want 1\n"}
+{"Action":"fail","Package":"foo","Test":"TestSub","Elapsed":0}
+{"Action":"run","Package":"foo","Test":"TestSkip"}
+{"Action":"output","Package":"foo","Test":"TestSkip","Output":"    foo_test.go:20: not yet\n"}
+{"Action":"skip","Package":"foo","Test":"TestSkip","Elapsed":0}
+{"Action":"output","Package":"foo","Output":"FAIL\n"}
+{"Action":"fail","Package":"foo","Elapsed":0.25}
+{"Action":"run","Package":"foo/bar","Test":"TestPanic"}
+{"Action":"output","Package":"foo/bar","Test":"TestPanic","Output":"=== RUN   TestPanic\n"}
+{"Action":"output","Package":"foo/bar","Test":"TestPanic","Output":"panic: boom\n"}
+{"Action":"fail","Package":"foo/bar","Test":"TestPanic","Elapsed":0}
+{"Action":"fail","Package":"foo/bar","Elapsed":0.5}
+{"Action":"run","Package":"foo/baz","Test":"TestTable"}
+{"Action":"run","Package":"foo/baz","Test":"TestTable/one"}
+{"Action":"output","Package":"foo/baz","Test":"TestTable/one","Output":"    baz_test.go:8: one failed\n"}
+{"Action":"fail","Package":"foo/baz","Test":"TestTable/one","Elapsed":0}
+{"Action":"output","Package":"foo/baz","Test":"TestTable","Output":"--- FAIL: TestTable (0.00s)\n"}
+{"Action":"fail","Package":"foo/baz","Test":"TestTable","Elapsed":0}
+{"Action":"fail","Package":"foo/baz","Elapsed":0}
+{"Action":"output","Package":"foo/broken","Output":"FAIL\tfoo/broken [build failed]\n"}
+{"Action":"fail","Package":"foo/broken","Elapsed":0}
+{"Action":"output","Package":"foo/exit","Output":"exit status 1\n"}
+{"Action":"output","Package":"foo/exit","Output":"FAIL\tfoo/exit\t0.01s\n"}
+{"Action":"fail","Package":"foo/exit","Elapsed":0.01}
+`
+
+	got, err := parseTestReport(strings.NewReader(events))
+	if err != nil {
+		t.Fatalf("parseTestReport() error = %v", err)
+	}
+	want := &testReport{
+		passed:    1,
+		failed:    3,
+		skipped:   1,
+		failedPkg: 2,
+		elapsed:   0.76,
+		failures: []*testFailure{
+			{pkg: "foo", name: "TestSub", file: "foo_test.go", line: 12, message: "Sub(2, 1) = 3, want 1"},
+			{pkg: "foo/bar", name: "TestPanic", message: "panic: boom"},
+			{pkg: "foo/baz", name: "TestTable/one", file: "baz_test.go", line: 8, message: "one failed"},
+			{pkg: "foo/broken", message: "FAIL\tfoo/broken [build failed]"},
+			{pkg: "foo/exit", message: "exit status 1"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTestReport() = %+v, want %+v", got, want)
+	}
+
+	lines, rows := got.render()
+	wantLines := []string{
+		"FAIL: 1 passed, 3 failed, 1 skipped, 2 failed packages (0.76s)",
+		"",
+		"TestSub  foo_test.go:12: Sub(2, 1) = 3, want 1",
+		"TestPanic  foo/bar: panic: boom",
+		"TestTable/one  baz_test.go:8: one failed",
+		"foo/broken: FAIL\tfoo/broken [build failed]",
+		"foo/exit: exit status 1",
+	}
+	var gotLines []string
+	for _, l := range lines {
+		gotLines = append(gotLines, string(l))
+	}
+	if !reflect.DeepEqual(gotLines, wantLines) {
+		t.Errorf("render() lines = %q, want %q", gotLines, wantLines)
+	}
+	if len(rows) != len(lines) || rows[0] != nil || rows[2] != got.failures[0] || rows[6] != got.failures[4] {
+		t.Errorf("render() rows = %v, want the failure of each line", rows)
+	}
+}
+
+func TestTestReport_renderStderr(t *testing.T) {
+	r := &testReport{elapsed: 0.1, stderr: "# foo\n./foo.go:3:2: undefined: x"}
+	lines, rows := r.render()
+	want := []string{
+		"FAIL: 0 passed, 0 failed, 0 skipped (0.10s)",
+		"",
+		"# foo",
+		"./foo.go:3:2: undefined: x",
+	}
+	var got []string
+	for _, l := range lines {
+		got = append(got, string(l))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("render() lines = %q, want %q", got, want)
+	}
+	if len(rows) != len(lines) {
+		t.Errorf("render() rows = %v, want %d rows", rows, len(lines))
+	}
+}
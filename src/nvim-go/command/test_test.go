@@ -0,0 +1,232 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"nvim-go/ctx"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestCommand_testCmd(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"go.mod":      "module foo\n",
+		"foo_test.go": "package foo\n",
+	})
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		race    bool
+		wantCmd []string
+		wantEnv []string
+	}{
+		{
+			name:    "race",
+			race:    true,
+			wantCmd: []string{"go", "test", "-race", "-run", "TestFoo", "foo"},
+			wantEnv: []string{"CGO_ENABLED=1"},
+		},
+		{
+			name:    "no race",
+			wantCmd: []string{"go", "test", "-run", "TestFoo", "foo"},
+		},
+	}
+	for _, tt := range tests {
+		c := NewCommand(nil, ctx.NewContext())
+		c.ctx.SetContext(dir)
+		gotCmd, gotEnv, err := c.testCmd([]string{"-run", "TestFoo"}, dir, tt.race)
+		if err != nil {
+			t.Errorf("%q. testCmd(%v) error = %v", tt.name, tt.race, err)
+			continue
+		}
+		if !reflect.DeepEqual(gotCmd, tt.wantCmd) {
+			t.Errorf("%q. testCmd(%v) cmd = %v, want %v", tt.name, tt.race, gotCmd, tt.wantCmd)
+		}
+		if !reflect.DeepEqual(gotEnv, tt.wantEnv) {
+			t.Errorf("%q. testCmd(%v) env = %v, want %v", tt.name, tt.race, gotEnv, tt.wantEnv)
+		}
+	}
+}
+
+func TestCommand_testCmdPackages(t *testing.T) {
+	dir, cleanup := writePackage(t, map[string]string{
+		"go.mod":      "module foo\n",
+		"foo_test.go": "package foo\n",
+	})
+	defer cleanup()
+	if err := os.Mkdir(filepath.Join(dir, "bar"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "bar", "bar_test.go"), []byte("package bar\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	got, _, err := c.testCmd([]string{"-run", "TestFoo", "foo/bar"}, dir, false)
+	if err != nil {
+		t.Fatalf("testCmd() error = %v", err)
+	}
+	want := []string{"go", "test", "-run", "TestFoo", "foo/bar"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("testCmd() cmd = %v, want %v", got, want)
+	}
+}
+
+func TestCommand_testCmdGb(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nvim-go-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	dir := filepath.Join(tmp, "src", "foo")
+	for _, d := range []string{dir, filepath.Join(tmp, "vendor")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo_test.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	got, _, err := c.testCmd([]string{"-run", "TestFoo"}, dir, false)
+	if err != nil {
+		t.Fatalf("testCmd() error = %v", err)
+	}
+	want := []string{"gb", "test", "-run", "TestFoo", "foo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("testCmd() cmd = %v, want %v", got, want)
+	}
+	if got := c.testDir(dir); got != tmp {
+		t.Errorf("testDir(%v) = %v, want %v", dir, got, tmp)
+	}
+}
+
+func TestParseRaceReport(t *testing.T) {
+	goroot := filepath.Clean(runtime.GOROOT())
+	out := `==================
+WARNING: DATA RACE
+Write at 0x00c0000182c8 by goroutine 8:
+  racepkg.TestRace.func1()
+      /tmp/racepkg/race_test.go:9 +0x33
+
+Previous write at 0x00c0000182c8 by goroutine 7:
+  racepkg.TestRace()
+      /tmp/racepkg/race_test.go:12 +0x104
+  testing.tRunner()
+      GOROOT/src/testing/testing.go:2193 +0x21c
+
+Goroutine 8 (running) created at:
+  racepkg.TestRace()
+      /tmp/racepkg/race_test.go:8 +0xf9
+
+Goroutine 7 (running) created at:
+  testing.(*T).Run()
+      GOROOT/src/testing/testing.go:2258 +0xb12
+  main.main()
+      _testmain.go:46 +0x164
+==================
+--- FAIL: TestRace (0.00s)
+    testing.go:1865: race detected during execution of test
+FAIL`
+	out = strings.Replace(out, "GOROOT", goroot, -1)
+
+	gotRaces, gotRest := parseRaceReport([]byte(out))
+	wantRaces := []*nvim.QuickfixError{
+		{FileName: "/tmp/racepkg/race_test.go", LNum: 9, Text: "DATA RACE: Write at 0x00c0000182c8 by goroutine 8", Type: "E"},
+		{FileName: "/tmp/racepkg/race_test.go", LNum: 12, Text: "DATA RACE: Previous write at 0x00c0000182c8 by goroutine 7", Type: "E"},
+		{FileName: "/tmp/racepkg/race_test.go", LNum: 8, Text: "DATA RACE: Goroutine 8 (running) created at", Type: "E"},
+	}
+	if !reflect.DeepEqual(gotRaces, wantRaces) {
+		for _, e := range gotRaces {
+			t.Logf("%+v", e)
+		}
+		t.Errorf("parseRaceReport() races = %v, want %v", gotRaces, wantRaces)
+	}
+	wantRest := "--- FAIL: TestRace (0.00s)\n    testing.go:1865: race detected during execution of test\nFAIL"
+	if string(gotRest) != wantRest {
+		t.Errorf("parseRaceReport() rest = %q, want %q", gotRest, wantRest)
+	}
+}
+
+func TestCommand_testCompile(t *testing.T) {
+	// the sentinel test and TestMain fail if the tests are run
+	sentinel := `package foo
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) { os.Exit(1) }
+
+func TestSentinel(t *testing.T) { t.Fatal("the test is run") }
+`
+	tests := []struct {
+		name     string
+		src      string
+		wantErrs int
+	}{
+		{name: "sentinel", src: sentinel, wantErrs: 0},
+		{name: "compile error", src: "package foo\n\nimport \"testing\"\n\nfunc TestFoo(t *testing.T) {\n\tt.Fatal(undefined)\n}\n", wantErrs: 1},
+	}
+	for _, tt := range tests {
+		dir, cleanup := writePackage(t, map[string]string{"go.mod": "module foo\n", "foo_test.go": tt.src})
+
+		c := NewCommand(nil, ctx.NewContext())
+		c.ctx.SetContext(dir)
+		got, err := c.testCompile(context.Background(), dir)
+		cleanup()
+		if err != nil {
+			t.Errorf("%q. testCompile(%v) error = %v", tt.name, dir, err)
+			continue
+		}
+		if len(got) != tt.wantErrs {
+			t.Errorf("%q. testCompile(%v) = %d errors, want %d", tt.name, dir, len(got), tt.wantErrs)
+		}
+	}
+}
+
+func TestCommand_testCompileGb(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nvim-go-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	dir := filepath.Join(tmp, "src", "foo")
+	for _, d := range []string{dir, filepath.Join(tmp, "vendor")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo_test.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	if _, err := c.testCompile(context.Background(), dir); err == nil {
+		t.Errorf("testCompile(%v) error = nil, want the gb is not supported", dir)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"strings"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+)
+
+func (c *Command) cmdAutocmdToggle() {
+	go c.toggle("GoAutocmd", func(cfg *config.Config) *int64 { return &cfg.Autocmd.Enable })
+}
+
+func (c *Command) cmdFmtAutosaveToggle() {
+	go c.toggle("GoFmtAutosave", func(cfg *config.Config) *int64 { return &cfg.Fmt.Autosave })
+}
+
+func (c *Command) cmdVetAutosaveToggle() {
+	go c.toggle("GoVetAutosave", func(cfg *config.Config) *int64 { return &cfg.Lint.GoVetAutosave })
+}
+
+// toggle flips the config value which returned by field for the current
+// session, and echoes the new state.
+func (c *Command) toggle(prefix string, field func(cfg *config.Config) *int64) error {
+	var enabled bool
+	config.Update(func(cfg *config.Config) {
+		v := field(cfg)
+		enabled = *v == 0
+		if enabled {
+			*v = 1
+		} else {
+			*v = 0
+		}
+	})
+
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	return nvimutil.EchoSuccess(c.Nvim, prefix, state)
+}
+
+func (c *Command) cmdBuildTagsToggle(args []string, dir string) {
+	go c.BuildTagsToggle(args[0], dir)
+}
+
+// BuildTagsToggle adds the tag to config.BuildTags for the current session,
+// or removes it if already added. It sets the build context of dir again and
+// discards the guru cache, so the analysis sees the files of the new tags.
+func (c *Command) BuildTagsToggle(tag, dir string) error {
+	tags := toggleBuildTag(tag)
+	c.InvalidateGuruCache()
+	c.ctx.SetContext(dir)
+
+	msg := "no tags"
+	if len(tags) > 0 {
+		msg = "tags: " + strings.Join(tags, " ")
+	}
+	return nvimutil.EchoSuccess(c.Nvim, "GoBuildTagsToggle", msg)
+}
+
+// toggleBuildTag adds the tag to config.BuildTags, or removes it if already
+// added, and returns the new tags.
+func toggleBuildTag(tag string) []string {
+	var tags []string
+	config.Update(func(cfg *config.Config) {
+		// the Config copy shares the slice, so don't modify it in place
+		var removed bool
+		for _, t := range cfg.Build.Tags {
+			if t == tag {
+				removed = true
+				continue
+			}
+			tags = append(tags, t)
+		}
+		if !removed {
+			tags = append(tags, tag)
+		}
+		cfg.Build.Tags = tags
+	})
+	return tags
+}
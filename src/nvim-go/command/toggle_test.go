@@ -0,0 +1,51 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/build"
+	"reflect"
+	"testing"
+
+	"nvim-go/config"
+	"nvim-go/ctx"
+)
+
+func TestToggleBuildTag(t *testing.T) {
+	prev := config.Update(func(cfg *config.Config) { cfg.Build.Tags = []string{"linux"} })
+	defer config.Set(prev)
+	defaultContext := build.Default
+	defer func() { build.Default = defaultContext }()
+
+	dir, cleanup := writePackage(t, map[string]string{"foo.go": "package foo\n"})
+	defer cleanup()
+	c := ctx.NewContext()
+	c.SetContext(dir)
+
+	static := config.BuildTags()
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{tag: "integration", want: []string{"linux", "integration"}},
+		{tag: "integration", want: []string{"linux"}},
+	}
+	for _, tt := range tests {
+		if got := toggleBuildTag(tt.tag); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("toggleBuildTag(%v) = %v, want %v", tt.tag, got, tt.want)
+		}
+		if got := config.BuildTags(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("config.BuildTags() after toggle %v = %v, want %v", tt.tag, got, tt.want)
+		}
+		// the same directory, but the build context is set again with the new tags
+		c.SetContext(dir)
+		if got := build.Default.BuildTags; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("build.Default.BuildTags after toggle %v = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(static, []string{"linux"}) {
+		t.Errorf("the previous config.BuildTags = %v, want unchanged [linux]", static)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/build"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+func (c *Command) cmdTools() {
+	go func() {
+		if err := c.Tools(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Tools shows the installed and missing external tools, and the install
+// commands of the missing tools in the scratch buffer.
+func (c *Command) Tools() error {
+	option := map[nvimutil.NvimOption]map[string]interface{}{
+		nvimutil.BufferOption: {
+			nvimutil.BufOptionBufhidden: nvimutil.BufhiddenWipe,
+			nvimutil.BufOptionBuflisted: false,
+			nvimutil.BufOptionBuftype:   nvimutil.BuftypeNofile,
+			nvimutil.BufOptionSwapfile:  false,
+		},
+	}
+	buf := nvimutil.NewBuffer(c.Nvim)
+	buf.Reuse = true
+	if _, err := buf.Create("__GoTools__", "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+	return buf.SetBufferLines(0, -1, true, bytes.TrimSuffix(formatTools(tools.Tools), []byte{'\n'}))
+}
+
+// formatTools formats the status of ts to the aligned table, followed by the
+// install commands of the missing tools.
+func formatTools(ts []tools.Tool) []byte {
+	var (
+		buf     bytes.Buffer
+		missing []tools.Tool
+	)
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "tool\tpath\tcommands")
+	for _, t := range ts {
+		path, err := tools.Look(t.Name)
+		if err != nil {
+			path = "missing"
+			missing = append(missing, t)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, path, strings.Join(t.Cmds, ", "))
+	}
+	w.Flush()
+
+	if len(missing) > 0 {
+		buf.WriteString("\nInstall the missing tools:\n")
+		for _, t := range missing {
+			fmt.Fprintf(&buf, "  %s: %s\n", t.Name, t.Install)
+		}
+	}
+	return buf.Bytes()
+}
+
+// checkTools logs the missing external tools. It also caches the
+// availability of each tool.
+func checkTools() {
+	logger := nvimutil.NewLogger("tools")
+	for _, t := range tools.Missing() {
+		logger.Printf("%s is not installed, %s requires it", t.Name, strings.Join(t.Cmds, ", "))
+	}
+}
+
+// ----------------------------------------------------------------------------
+// GoToolsInstall, GoToolsUpdate
+
+const pkgToolsInstall = "GoToolsInstall"
+
+func (c *Command) cmdToolsInstall(args []string) {
+	go c.cmdInstallTools(args, false)
+}
+
+func (c *Command) cmdToolsUpdate(args []string) {
+	go c.cmdInstallTools(args, true)
+}
+
+func (c *Command) cmdInstallTools(args []string, update bool) {
+	err := c.ToolsInstall(args, update)
+	c.saveError("ToolsInstall", err)
+	if err != nil {
+		nvimutil.ErrorWrap(c.Nvim, err)
+	}
+}
+
+func (c *Command) cmdToolsComplete(a *nvim.CommandCompletionArgs) ([]string, error) {
+	var names []string
+	for _, pkg := range config.Tools() {
+		if name := path.Base(pkg); strings.HasPrefix(name, a.ArgLead) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// ToolsInstall installs the config.Tools packages, or only the args tools of
+// them, by the go command, and streams the progress into the scratch buffer.
+// The tools are installed to GOBIN if set. If update is true, it reinstalls
+// the tools with the latest version.
+func (c *Command) ToolsInstall(args []string, update bool) error {
+	defer nvimutil.Profile(time.Now(), pkgToolsInstall)
+
+	pkgs, err := selectTools(config.Tools(), args)
+	if err != nil {
+		return err
+	}
+
+	ctx, done := c.startOp(pkgToolsInstall)
+	defer done()
+
+	buf, err := c.streamBuffer("__GoToolsInstall__")
+	if err != nil {
+		return err
+	}
+	// the installed tools are looked up again
+	defer tools.Reset()
+
+	var failed []string
+	for i, pkg := range pkgs {
+		name := path.Base(pkg)
+		nvimutil.EchoProgress(c.Nvim, pkgToolsInstall, "installing %s (%d/%d)", name, i+1, len(pkgs))
+
+		result := "ok"
+		if _, err := streamCmd(installCmd(ctx, pkg, update), buf); err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				return err
+			}
+			if ctx.Err() != nil {
+				return errors.WithStack(ctx.Err())
+			}
+			result = "FAIL"
+			failed = append(failed, name)
+		}
+		if _, err := fmt.Fprintf(buf, "%s\t%s\n\n", result, name); err != nil {
+			return err
+		}
+	}
+
+	dir := installDir(os.Getenv("GOBIN"), build.Default.GOPATH)
+	if !inPath(dir, os.Getenv("PATH")) {
+		fmt.Fprintf(buf, "%s is not in $PATH, nvim-go can't find the installed tools\n", dir)
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("failed to install %s", strings.Join(failed, ", "))
+	}
+	return nvimutil.EchoSuccess(c.Nvim, pkgToolsInstall, fmt.Sprintf("%d tools installed to %s", len(pkgs), dir))
+}
+
+// selectTools returns the pkgs whose the base name is in names. Returns all
+// pkgs if names is empty.
+func selectTools(pkgs, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return pkgs, nil
+	}
+
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		var found bool
+		for _, pkg := range pkgs {
+			if path.Base(pkg) == name {
+				selected = append(selected, pkg)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("%s is not in g:go#tools#packages", name)
+		}
+	}
+	return selected, nil
+}
+
+// installCmd returns the command which installs the pkg tool. If update is
+// true, the command also updates the pkg and its dependencies.
+// The command inherits the environment, so it honors GOBIN.
+func installCmd(ctx context.Context, pkg string, update bool) *exec.Cmd {
+	args := []string{"get"}
+	if update {
+		args = append(args, "-u")
+	}
+	return exec.CommandContext(ctx, "go", append(args, pkg)...)
+}
+
+// installDir returns the directory where the go command installs the tools,
+// which is gobin if set, otherwise the bin directory of the first gopath.
+func installDir(gobin, gopath string) string {
+	if gobin != "" {
+		return gobin
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "bin")
+}
+
+// inPath reports whether the dir is in the list of the PATH environment variable.
+func inPath(dir, list string) bool {
+	for _, p := range filepath.SplitList(list) {
+		if filepath.Clean(p) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
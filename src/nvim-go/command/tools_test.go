@@ -0,0 +1,79 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInstallCmd(t *testing.T) {
+	pkgs := []string{
+		"github.com/derekparker/delve/cmd/dlv",
+		"github.com/cweill/gotests/gotests",
+		"golang.org/x/tools/cmd/gopls",
+	}
+
+	tests := []struct {
+		names  []string
+		update bool
+		want   [][]string
+	}{
+		{
+			want: [][]string{
+				{"go", "get", "github.com/derekparker/delve/cmd/dlv"},
+				{"go", "get", "github.com/cweill/gotests/gotests"},
+				{"go", "get", "golang.org/x/tools/cmd/gopls"},
+			},
+		},
+		{
+			names:  []string{"gopls", "gotests"},
+			update: true,
+			want: [][]string{
+				{"go", "get", "-u", "golang.org/x/tools/cmd/gopls"},
+				{"go", "get", "-u", "github.com/cweill/gotests/gotests"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		selected, err := selectTools(pkgs, tt.names)
+		if err != nil {
+			t.Fatalf("selectTools(%v) error = %v", tt.names, err)
+		}
+		var got [][]string
+		for _, pkg := range selected {
+			cmd := installCmd(context.Background(), pkg, tt.update)
+			if cmd.Env != nil {
+				t.Errorf("installCmd(%v).Env = %v, want the inherited environment", pkg, cmd.Env)
+			}
+			got = append(got, cmd.Args)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("install commands of %v, update %v = %v, want %v", tt.names, tt.update, got, tt.want)
+		}
+	}
+
+	if _, err := selectTools(pkgs, []string{"godef"}); err == nil {
+		t.Errorf("selectTools(godef) error = nil, want the unknown tool error")
+	}
+}
+
+func TestInstallDir(t *testing.T) {
+	gopath := filepath.Join("home", "go") + string(filepath.ListSeparator) + filepath.Join("src", "go")
+	tests := []struct {
+		gobin string
+		want  string
+	}{
+		{gobin: filepath.Join("opt", "bin"), want: filepath.Join("opt", "bin")},
+		{want: filepath.Join("home", "go", "bin")},
+	}
+	for _, tt := range tests {
+		if got := installDir(tt.gobin, gopath); got != tt.want {
+			t.Errorf("installDir(%q, %q) = %v, want %v", tt.gobin, gopath, got, tt.want)
+		}
+	}
+}
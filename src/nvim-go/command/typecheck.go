@@ -0,0 +1,225 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+// typedFile represents the type-checked file of the current buffer.
+type typedFile struct {
+	fset *token.FileSet
+	file *ast.File
+	src  []byte
+	pkg  *types.Package
+	info *types.Info
+	prog *loader.Program
+
+	// imports is the import paths which are referred by qualifier but not
+	// imported by file yet.
+	imports map[string]bool
+}
+
+// loadTypedFile type-checks the package of fname with the overlay of the src
+// buffer contents. The imports packages are also loaded, such as the
+// interface package of GoImpl.
+// The type errors are ignored so that the incomplete code can be loaded.
+func loadTypedFile(fname string, src []byte, imports ...string) (*typedFile, error) {
+	ctxt := build.Default // copy
+	overlay := buildutil.OverlayContext(&ctxt, map[string][]byte{fname: src})
+
+	dir := filepath.Dir(fname)
+	bp, err := overlay.ImportDir(dir, build.ImportComment)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); !ok {
+			return nil, errors.WithStack(err)
+		}
+	}
+	files := append([]string{}, bp.GoFiles...)
+	if strings.HasSuffix(fname, "_test.go") {
+		files = append(files, bp.TestGoFiles...)
+	}
+	found := false
+	for i, f := range files {
+		files[i] = filepath.Join(dir, f)
+		found = found || files[i] == fname
+	}
+	if !found {
+		files = append(files, fname)
+	}
+
+	conf := loader.Config{
+		Fset:        token.NewFileSet(),
+		Build:       overlay,
+		Cwd:         dir,
+		ParserMode:  parser.ParseComments,
+		AllowErrors: true,
+		TypeChecker: types.Config{Error: func(error) {}},
+	}
+	// skip the function bodies of the dependencies for speed
+	pkgPath := bp.ImportPath
+	if pkgPath == "" || pkgPath == "." {
+		pkgPath = bp.Name
+	}
+	conf.TypeCheckFuncBodies = func(path string) bool { return path == pkgPath }
+	conf.CreateFromFilenames(pkgPath, files...)
+	for _, path := range imports {
+		conf.Import(path)
+	}
+
+	prog, err := conf.Load()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	info := prog.Created[0]
+	for _, f := range info.Files {
+		if conf.Fset.File(f.Pos()).Name() == fname {
+			return &typedFile{
+				fset:    conf.Fset,
+				file:    f,
+				src:     src,
+				pkg:     info.Pkg,
+				info:    &info.Info,
+				prog:    prog,
+				imports: make(map[string]bool),
+			}, nil
+		}
+	}
+
+	return nil, errors.Errorf("couldn't load %s", fname)
+}
+
+// offset returns the byte offset of pos in src.
+func (f *typedFile) offset(pos token.Pos) int {
+	return f.fset.Position(pos).Offset
+}
+
+// pos returns the token.Pos of the byte offset in src.
+func (f *typedFile) pos(offset int) token.Pos {
+	return f.fset.File(f.file.Pos()).Pos(offset)
+}
+
+// text returns the src text of node.
+func (f *typedFile) text(node ast.Node) string {
+	return string(f.src[f.offset(node.Pos()):f.offset(node.End())])
+}
+
+// qualifier returns the package name of p which is used in file. The package
+// which is not imported yet is added to imports.
+func (f *typedFile) qualifier(p *types.Package) string {
+	if p == f.pkg {
+		return ""
+	}
+	for _, spec := range f.file.Imports {
+		path := strings.Trim(spec.Path.Value, "`\"")
+		if path != p.Path() {
+			continue
+		}
+		if spec.Name != nil && spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name
+		}
+		return p.Name()
+	}
+	f.imports[p.Path()] = true
+	return p.Name()
+}
+
+// typeString returns the string of typ which is qualified by the package
+// names of the file.
+func (f *typedFile) typeString(typ types.Type) string {
+	return types.TypeString(typ, f.qualifier)
+}
+
+// enclosingNode returns the innermost node which encloses the offset and
+// satisfies match, or nil if not found.
+func (f *typedFile) enclosingNode(offset int, match func(ast.Node) bool) ast.Node {
+	pos := f.pos(offset)
+	path, _ := astutil.PathEnclosingInterval(f.file, pos, pos)
+	for _, n := range path {
+		if match(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+// textEdit represents the replacement of the [start, end) bytes range of src.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits returns the copy of src which the non-overlapping edits applied.
+func applyEdits(src []byte, edits []textEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte{}, src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out
+}
+
+// apply applies edits to src, adds the imports, and formats it.
+func (f *typedFile) apply(edits []textEdit) ([]byte, error) {
+	src := applyEdits(f.src, edits)
+	if len(f.imports) == 0 {
+		out, err := format.Source(src)
+		return out, errors.WithStack(err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	paths := make([]string, 0, len(f.imports))
+	for path := range f.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		astutil.AddImport(fset, file, path)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// bufferSource returns the current buffer lines and the source contents.
+func (c *Command) bufferSource() (nvim.Buffer, [][]byte, []byte, error) {
+	b := nvim.Buffer(c.ctx.BufNr)
+	in, err := c.Nvim.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return b, nil, nil, errors.WithStack(err)
+	}
+	return b, in, append(nvimutil.ToByteSlice(in), '\n'), nil
+}
+
+// updateBuffer updates the b buffer which has the in lines to the out source
+// with minimum changes.
+func (c *Command) updateBuffer(b nvim.Buffer, in [][]byte, out []byte) error {
+	return minUpdate(c.Nvim, b, in, nvimutil.ToBufferLines(bytes.TrimSuffix(out, []byte{'\n'})))
+}
@@ -38,11 +38,26 @@ func (c *Command) cmdTabpagas() error {
 	return nvimutil.Echomsg(c.Nvim, "Tabpages:", t)
 }
 
-func (c *Command) cmdByteOffset() error {
+type cmdByteOffsetEval struct {
+	File  string `msgpack:",array"`
+	Start []int  // getpos("'<")
+	End   []int  // getpos("'>")
+}
+
+func (c *Command) cmdByteOffset(ranges [2]int, eval *cmdByteOffsetEval) error {
 	b, err := c.Nvim.CurrentBuffer()
 	if err != nil {
 		return err
 	}
+
+	if start, end, ok := selectionPos(ranges, eval.Start, eval.End); ok {
+		so, eo, err := nvimutil.ByteOffsetRange(c.Nvim, b, start, end)
+		if err != nil {
+			return err
+		}
+		return nvimutil.Echomsg(c.Nvim, fmt.Sprintf("start: %d, end: %d, length: %d", so, eo, eo-so))
+	}
+
 	w, err := c.Nvim.CurrentWindow()
 	if err != nil {
 		return err
@@ -51,3 +66,18 @@ func (c *Command) cmdByteOffset() error {
 	offset, _ := nvimutil.ByteOffset(c.Nvim, b, w)
 	return nvimutil.Echomsg(c.Nvim, offset)
 }
+
+// selectionPos returns the start and end position of the visual selection if
+// the command invoked with the visual selection range. The startPos and endPos
+// are the getpos("'<") and getpos("'>") results.
+// The returned positions are the 1-based line and 0-based byte column pair.
+func selectionPos(ranges [2]int, startPos, endPos []int) (start, end [2]int, ok bool) {
+	if len(startPos) < 3 || len(endPos) < 3 {
+		return start, end, false
+	}
+	if startPos[1] == 0 || startPos[1] != ranges[0] || endPos[1] != ranges[1] {
+		return start, end, false
+	}
+
+	return [2]int{startPos[1], startPos[2] - 1}, [2]int{endPos[1], endPos[2] - 1}, true
+}
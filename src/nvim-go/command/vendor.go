@@ -0,0 +1,211 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"nvim-go/ctx"
+	"nvim-go/internal/pathutil"
+	"nvim-go/internal/tools"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+const pkgVendor = "GoVendor"
+
+// vendorOp represents the operation of the GoVendor commands.
+type vendorOp string
+
+const (
+	// vendorAdd adds the dependencies to the vendor directory.
+	vendorAdd vendorOp = "add"
+	// vendorUpdate updates the vendored dependencies.
+	vendorUpdate vendorOp = "update"
+	// vendorList lists the vendored dependencies.
+	vendorList vendorOp = "list"
+)
+
+// vendorTool represents the vendoring tool of the project.
+type vendorTool struct {
+	// name is the tool name of the internal/tools.
+	name string
+	// bin is the command name which runs the tool.
+	bin string
+	// root is the project root directory which the command runs on.
+	root string
+	// manifest is the file which records the vendored dependencies.
+	manifest string
+	// subs is the subcommand args of each operation.
+	subs map[vendorOp][]string
+}
+
+// errNoVendorTool is the error of the GoVendor commands out of the gb or dep
+// project.
+var errNoVendorTool = errors.New("no vendoring tool found, the gb project or the Gopkg.toml file is required")
+
+// detectVendorTool detects the vendoring tool from the project layout of dir,
+// which is the gb-vendor in the gb project, or the dep if the Gopkg.toml file
+// found walking up from dir. Returns errNoVendorTool if not detected.
+func detectVendorTool(b ctx.Build, dir string) (*vendorTool, error) {
+	if b.Tool == "gb" {
+		return &vendorTool{
+			name:     "gb-vendor",
+			bin:      "gb",
+			root:     b.ProjectRoot,
+			manifest: filepath.Join(b.ProjectRoot, "vendor", "manifest"),
+			subs: map[vendorOp][]string{
+				vendorAdd:    {"vendor", "fetch"},
+				vendorUpdate: {"vendor", "update"},
+				vendorList:   {"vendor", "list"},
+			},
+		}, nil
+	}
+
+	if root, err := pathutil.FindDepRoot(dir); err == nil {
+		return &vendorTool{
+			name:     "dep",
+			bin:      "dep",
+			root:     root,
+			manifest: filepath.Join(root, "Gopkg.toml"),
+			subs: map[vendorOp][]string{
+				vendorAdd:    {"ensure", "-add"},
+				vendorUpdate: {"ensure", "-update"},
+				vendorList:   {"status"},
+			},
+		}, nil
+	}
+
+	return nil, errNoVendorTool
+}
+
+// command returns the op command of t with args, which runs on the project
+// root. The gb-vendor updates the all dependencies if args is empty.
+func (t *vendorTool) command(ctx context.Context, op vendorOp, args []string) *exec.Cmd {
+	sub := append([]string{}, t.subs[op]...)
+	if op == vendorUpdate && t.name == "gb-vendor" && len(args) == 0 {
+		sub = append(sub, "-all")
+	}
+
+	cmd := exec.CommandContext(ctx, t.bin, append(sub, args...)...)
+	cmd.Dir = t.root
+	return cmd
+}
+
+func (c *Command) cmdVendorAdd(args []string, dir string) {
+	go c.cmdVendor(vendorAdd, args, dir)
+}
+
+func (c *Command) cmdVendorUpdate(args []string, dir string) {
+	go c.cmdVendor(vendorUpdate, args, dir)
+}
+
+func (c *Command) cmdVendorList(dir string) {
+	go c.cmdVendor(vendorList, nil, dir)
+}
+
+func (c *Command) cmdVendor(op vendorOp, args []string, dir string) {
+	err := c.Vendor(op, args, dir)
+	c.saveError("Vendor", err)
+	if err != nil {
+		nvimutil.ErrorWrap(c.Nvim, err)
+	}
+}
+
+// Vendor runs the op operation of the vendoring tool which is detected from
+// the project layout of dir, and streams the output into the scratch buffer.
+// The errors are set to the quickfix list. It only warns if dir is not in the
+// gb or dep project.
+func (c *Command) Vendor(op vendorOp, args []string, dir string) error {
+	defer nvimutil.Profile(time.Now(), pkgVendor)
+	c.ctx.SetContext(dir)
+
+	t, err := detectVendorTool(c.ctx.Build, dir)
+	if err == errNoVendorTool {
+		return nvimutil.EchohlAfter(c.Nvim, pkgVendor, "WarningMsg", "%s", err)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tools.Require(pkgVendor, t.name); err != nil {
+		return err
+	}
+
+	ctx, done := c.startOp(pkgVendor + strings.Title(string(op)))
+	defer done()
+
+	w, err := c.Nvim.CurrentWindow()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cmd := t.command(ctx, op, args)
+	cmd.Env = c.ctx.Build.Environ()
+	name := joinArgs(cmd.Args)
+	nvimutil.EchoProgress(c.Nvim, pkgVendor, "%s", name)
+	output, runErr := c.runStream(cmd, "__GoVendor__")
+	var errlist []*nvim.QuickfixError
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return runErr
+		}
+		errlist = parseVendorErrors(output, t.manifest)
+	}
+	if len(errlist) > 0 {
+		if err := nvimutil.SetList(c.Nvim, w, nvimutil.Quickfix, "", errlist); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := nvimutil.OpenList(c.Nvim, w, nvimutil.Quickfix, errlist, true); err != nil {
+		return errors.WithStack(err)
+	}
+	if runErr != nil {
+		return errors.Errorf("%s failed", name)
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, pkgVendor, name)
+}
+
+// vendorErrorRe matches the error line of the vendoring tools, such as the
+// "FATAL: command "fetch" failed" of the gb and the "Solving failure" of the dep.
+var vendorErrorRe = regexp.MustCompile(`(?i)\b(error|fatal|fail(ed|ure)?|cannot|could not|unable)\b`)
+
+// parseVendorErrors parses the output of the failed vendoring tool to the
+// error list of the manifest file. Only the error lines are listed, the other
+// progress lines are left in the output buffer. The indented lines are the
+// continuation of the previous line.
+func parseVendorErrors(output []byte, manifest string) []*nvim.QuickfixError {
+	var (
+		errlist []*nvim.QuickfixError
+		last    *nvim.QuickfixError
+	)
+	for _, line := range bytes.Split(output, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if last != nil {
+				last.Text += "\n" + string(bytes.TrimSpace(line))
+			}
+			continue
+		}
+
+		last = nil
+		if !vendorErrorRe.Match(line) {
+			continue
+		}
+		last = &nvim.QuickfixError{FileName: manifest, Text: string(line)}
+		errlist = append(errlist, last)
+	}
+	return errlist
+}
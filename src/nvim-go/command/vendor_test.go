@@ -0,0 +1,170 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nvim-go/ctx"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestDetectVendorTool(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nvim-go-vendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	for _, dir := range []string{
+		filepath.Join(tmp, "gb", "src", "foo"),
+		filepath.Join(tmp, "gb", "vendor"),
+		filepath.Join(tmp, "dep", "foo"),
+		filepath.Join(tmp, "none", "foo"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, "dep", "Gopkg.toml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	gbRoot, depRoot := filepath.Join(tmp, "gb"), filepath.Join(tmp, "dep")
+
+	tests := []struct {
+		name     string
+		build    ctx.Build
+		dir      string
+		wantName string
+		wantRoot string
+		wantErr  error
+	}{
+		{
+			name:     "gb",
+			build:    ctx.Build{Tool: "gb", ProjectRoot: gbRoot},
+			dir:      filepath.Join(gbRoot, "src", "foo"),
+			wantName: "gb-vendor",
+			wantRoot: gbRoot,
+		},
+		{
+			name:     "dep",
+			build:    ctx.Build{Tool: "go"},
+			dir:      filepath.Join(depRoot, "foo"),
+			wantName: "dep",
+			wantRoot: depRoot,
+		},
+		{
+			name:    "none",
+			build:   ctx.Build{Tool: "go"},
+			dir:     filepath.Join(tmp, "none", "foo"),
+			wantErr: errNoVendorTool,
+		},
+	}
+	for _, tt := range tests {
+		got, err := detectVendorTool(tt.build, tt.dir)
+		if err != tt.wantErr {
+			t.Errorf("%q. detectVendorTool(%v) error = %v, want %v", tt.name, tt.dir, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.name != tt.wantName || got.root != tt.wantRoot {
+			t.Errorf("%q. detectVendorTool(%v) = %s on %s, want %s on %s", tt.name, tt.dir, got.name, got.root, tt.wantName, tt.wantRoot)
+		}
+	}
+}
+
+func TestVendorTool_command(t *testing.T) {
+	root := filepath.FromSlash("/go/gb")
+	vt, err := detectVendorTool(ctx.Build{Tool: "gb", ProjectRoot: root}, filepath.Join(root, "src", "foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		op   vendorOp
+		args []string
+		want []string
+	}{
+		{
+			name: "add",
+			op:   vendorAdd,
+			args: []string{"github.com/pkg/errors"},
+			want: []string{"gb", "vendor", "fetch", "github.com/pkg/errors"},
+		},
+		{
+			name: "update all",
+			op:   vendorUpdate,
+			want: []string{"gb", "vendor", "update", "-all"},
+		},
+		{
+			name: "update",
+			op:   vendorUpdate,
+			args: []string{"github.com/pkg/errors"},
+			want: []string{"gb", "vendor", "update", "github.com/pkg/errors"},
+		},
+		{
+			name: "list",
+			op:   vendorList,
+			want: []string{"gb", "vendor", "list"},
+		},
+	}
+	for _, tt := range tests {
+		cmd := vt.command(context.Background(), tt.op, tt.args)
+		if !reflect.DeepEqual(cmd.Args, tt.want) {
+			t.Errorf("%q. command(%v, %v) args = %v, want %v", tt.name, tt.op, tt.args, cmd.Args, tt.want)
+		}
+		if cmd.Dir != root {
+			t.Errorf("%q. command(%v, %v) dir = %v, want %v", tt.name, tt.op, tt.args, cmd.Dir, root)
+		}
+	}
+}
+
+func TestParseVendorErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []*nvim.QuickfixError
+	}{
+		{
+			name: "gb",
+			output: `fetching github.com/foo/bar
+FATAL: command "fetch" failed: lstat /tmp/vendor: no such file or directory
+`,
+			want: []*nvim.QuickfixError{
+				{FileName: "manifest", Text: `FATAL: command "fetch" failed: lstat /tmp/vendor: no such file or directory`},
+			},
+		},
+		{
+			name: "dep",
+			output: `Fetching sources...
+  (1/2) github.com/foo/bar@v1.0.0
+Solving failure: No versions of github.com/foo/baz met constraints:
+	v1.0.0: Could not introduce github.com/foo/baz@v1.0.0
+`,
+			want: []*nvim.QuickfixError{
+				{FileName: "manifest", Text: "Solving failure: No versions of github.com/foo/baz met constraints:\nv1.0.0: Could not introduce github.com/foo/baz@v1.0.0"},
+			},
+		},
+		{
+			name:   "no error",
+			output: "Fetching sources...\n  (1/2) github.com/foo/bar@v1.0.0\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := parseVendorErrors([]byte(tt.output), "manifest"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. parseVendorErrors() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ package command
 
 import (
 	"bytes"
+	"context"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -26,6 +27,7 @@ type CmdVetEval struct {
 }
 
 func (c *Command) cmdVet(args []string, eval *CmdVetEval) {
+	cwd := eval.Cwd // the Vet changes eval.Cwd to the package directory
 	errch := make(chan interface{}, 1)
 	go func() {
 		delete(c.ctx.Errlist, "Vet") // cleanup
@@ -34,63 +36,130 @@ func (c *Command) cmdVet(args []string, eval *CmdVetEval) {
 
 	switch err := <-errch; e := err.(type) {
 	case error:
+		c.saveError("Vet", e)
 		nvimutil.ErrorWrap(c.Nvim, e)
+	case nil:
+		c.saveError("Vet", nil)
 	case []*nvim.QuickfixError:
+		c.saveError("Vet", nil)
 		c.ctx.Errlist["Vet"] = e
-		nvimutil.ErrorList(c.Nvim, c.ctx.Errlist, true)
+		nvimutil.ErrorList(c.Nvim, cwd, c.ctx.Errlist, true)
 	}
 }
 
-// Vet is a simple checker for static errors in Go source code use go tool vet command.
+// Vet is a simple checker for static errors in Go source code use go tool vet
+// command, or the gb vet in the gb project.
 func (c *Command) Vet(args []string, eval *CmdVetEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoVet")
+	c.ctx.SetContext(filepath.Dir(eval.File))
 
-	vetCmd := exec.Command("go", "tool", "vet")
+	ctx, done := c.startOp("GoVet")
+	defer done()
+
+	var (
+		vetCmd *exec.Cmd
+		err    error
+	)
+	switch c.ctx.Build.Tool {
+	case "gb":
+		vetCmd, err = c.gbVetCmd(ctx, args, filepath.Dir(eval.File))
+	default:
+		vetCmd, err = c.goVetCmd(ctx, args, eval)
+	}
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	vetCmd.Stderr = &stderr
+
+	vetErr := vetCmd.Run()
+	if vetErr != nil {
+		errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, &c.ctx.Build, config.GoVetIgnore())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return errlist
+	}
+
+	return nil
+}
+
+// goVetCmd returns the "go tool vet" command. The eval.Cwd is changed to the
+// directory of args if any.
+func (c *Command) goVetCmd(ctx context.Context, args []string, eval *CmdVetEval) (*exec.Cmd, error) {
+	vetCmd := exec.CommandContext(ctx, "go", "tool", "vet")
 	vetCmd.Dir = eval.Cwd
+	vetCmd.Env = c.ctx.Build.Environ()
 
+	var vetArgs, vetFlags []string
 	switch {
 	case len(args) > 0:
 		lastArg := args[len(args)-1]
 		if !strings.HasPrefix(lastArg, "-") {
 			switch path := filepath.Join(eval.Cwd, lastArg); {
 			case args[0] == ".":
-				vetCmd.Args = append(vetCmd.Args, ".")
+				vetArgs = append(vetArgs, ".")
 			case pathutil.IsDir(path):
 				eval.Cwd = path
-				vetCmd.Args = append(vetCmd.Args, args[:len(args)-1]...)
+				vetArgs = append(vetArgs, args[:len(args)-1]...)
 			case pathutil.IsExist(path) && pathutil.IsGoFile(path):
-				vetCmd.Args = append(vetCmd.Args, path)
+				vetArgs = append(vetArgs, path)
 			case filepath.Base(path) == "%":
 				path = eval.File
-				vetCmd.Args = append(vetCmd.Args, path)
+				vetArgs = append(vetArgs, path)
 			default:
-				err := errors.New("Invalid directory path")
-				return errors.WithStack(err)
+				// the project package, such as the GoVetCompletion result
+				pkg, err := c.LookupPackage(eval.Cwd, lastArg)
+				if err != nil {
+					return nil, errors.New("Invalid directory path")
+				}
+				eval.Cwd = pkg.Dir
+				vetCmd.Dir = pkg.Dir
+				vetArgs = append(vetArgs, args[:len(args)-1]...)
+				vetArgs = append(vetArgs, ".")
 			}
 		} else {
-			vetCmd.Args = append(vetCmd.Args, args...)
-			vetCmd.Args = append(vetCmd.Args, ".")
+			vetArgs = append(vetArgs, args...)
+			vetArgs = append(vetArgs, ".")
 		}
-	case len(config.GoVetFlags) > 0:
-		vetCmd.Args = append(vetCmd.Args, config.GoVetFlags...)
-		vetCmd.Args = append(vetCmd.Args, ".")
+	case len(config.GoVetFlags()) > 0:
+		vetFlags = config.GoVetFlags()
+		vetArgs = append(vetArgs, ".")
 	default:
-		vetCmd.Args = append(vetCmd.Args, ".")
+		vetArgs = append(vetArgs, ".")
 	}
+	// the "go tool vet" rejects the build flags, so the go flags are not
+	// merged, and the $GOFLAGS is left in the environment
+	vetCmd.Args = append(append(vetCmd.Args, vetFlags...), vetArgs...)
 
-	var stderr bytes.Buffer
-	vetCmd.Stderr = &stderr
+	return vetCmd, nil
+}
 
-	vetErr := vetCmd.Run()
-	if vetErr != nil {
-		errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, &c.ctx.Build, config.GoVetIgnore)
+// gbVetCmd returns the "gb vet" command which runs on the gb project root.
+// The args which are the project packages are vetted, and the dir package is
+// vetted if no package is given. The other args are passed as the flags.
+func (c *Command) gbVetCmd(ctx context.Context, args []string, dir string) (*exec.Cmd, error) {
+	flags, pkgs, err := c.splitPackageArgs(args, dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		flags = config.GoVetFlags()
+	}
+	if len(pkgs) == 0 {
+		pkg, err := pathutil.PackageID(dir)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, errors.WithStack(err)
 		}
-		return errlist
+		pkgs = append(pkgs, pkg)
 	}
 
-	return nil
+	vetCmd := exec.CommandContext(ctx, "gb", append(append([]string{"vet"}, flags...), pkgs...)...)
+	vetCmd.Dir = c.ctx.Build.ProjectRoot
+	vetCmd.Env = c.ctx.Build.Environ()
+
+	return vetCmd, nil
 }
 
 func (c *Command) cmdVetComplete(v *nvim.Nvim, a *nvim.CommandCompletionArgs, dir string) ([]string, error) {
@@ -151,10 +220,12 @@ func (c *Command) cmdVetComplete(v *nvim.Nvim, a *nvim.CommandCompletionArgs, di
 	//        comma-separated list of names of methods of type func() string whose results must be used (default "Error,String")
 	//  -v
 	//        verbose
+	argLead := a.ArgLead
 	complete, err := nvimutil.CompleteFiles(v, a, dir)
 	if err != nil {
 		return nil, err
 	}
+	complete = c.appendPackages(complete, argLead, dir)
 
 	complete = append(complete, []string{
 		"-all",
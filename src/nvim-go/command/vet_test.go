@@ -5,6 +5,9 @@
 package command
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -140,3 +143,57 @@ func TestCommand_Vet(t *testing.T) {
 		})
 	}
 }
+
+func TestCommand_gbVetCmd(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nvim-go-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	dir := filepath.Join(tmp, "src", "foo")
+	for _, d := range []string{filepath.Join(dir, "bar"), filepath.Join(tmp, "vendor")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{filepath.Join(dir, "foo.go"), filepath.Join(dir, "bar", "bar.go")} {
+		if err := ioutil.WriteFile(f, []byte("package foo\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewCommand(nil, ctx.NewContext())
+	c.ctx.SetContext(dir)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "package",
+			args: []string{"-printfuncs", "Logf", "foo/bar"},
+			want: []string{"gb", "vet", "-printfuncs", "Logf", "foo/bar"},
+		},
+		{
+			name: "no package",
+			args: []string{"-printfuncs", "Logf"},
+			want: []string{"gb", "vet", "-printfuncs", "Logf", "foo"},
+		},
+	}
+	for _, tt := range tests {
+		cmd, err := c.gbVetCmd(context.Background(), tt.args, dir)
+		if err != nil {
+			t.Errorf("%q. gbVetCmd(%v) error = %v", tt.name, tt.args, err)
+			continue
+		}
+		if got := append([]string{filepath.Base(cmd.Args[0])}, cmd.Args[1:]...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. gbVetCmd(%v) = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+		if cmd.Dir != tmp {
+			t.Errorf("%q. gbVetCmd(%v).Dir = %v, want %v", tt.name, tt.args, cmd.Dir, tmp)
+		}
+	}
+}
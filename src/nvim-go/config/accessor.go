@@ -0,0 +1,284 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import "time"
+
+// ChannelID remote plugins channel id.
+func ChannelID() int { return Current().Global.ChannelID }
+
+// ServerName Neovim socket listen location.
+func ServerName() string { return Current().Global.ServerName }
+
+// ErrorListType type of error list window.
+func ErrorListType() string { return Current().Global.ErrorListType }
+
+// ListType type of error list window per command, such as {"Build": "quickfix"}. Overrides ErrorListType.
+func ListType() map[string]string { return Current().Global.ListType }
+
+// QuickfixAutoClose closes the error list window when the result is empty.
+func QuickfixAutoClose() bool { return itob(Current().Global.AutoClose) }
+
+// QuickfixRelativePaths shows the error list file names as the relative path from the current working directory.
+func QuickfixRelativePaths() bool { return itob(Current().Global.RelativePaths) }
+
+// QuickfixMaxHeight maximum height of the error list window, which is fitted to the number of entries.
+func QuickfixMaxHeight() int { return int(Current().Global.MaxHeight) }
+
+// QuickfixDedup removes the duplicate entries of the error list which have the same file, position and text.
+func QuickfixDedup() bool { return itob(Current().Global.Dedup) }
+
+// GoFlags common flags of the go build, test and vet commands, such as "-mod=vendor". Takes precedence over the .go-flags file and $GOFLAGS.
+func GoFlags() []string { return Current().Global.GoFlags }
+
+// LogLevel verbosity level of the echoed messages, "error", "warn", "info" or "debug". The lower level messages are only logged to the GoLog buffer.
+func LogLevel() string { return Current().Global.LogLevel }
+
+// AutocmdEnable enable the autosave commands on autocmd. Toggled by GoAutocmdToggle command.
+func AutocmdEnable() bool { return itob(Current().Autocmd.Enable) }
+
+// BenchMem reports the memory allocation statistics on GoBench with -benchmem flag.
+func BenchMem() bool { return itob(Current().Bench.Mem) }
+
+// BenchTime run enough iterations of each benchmark to take the time on GoBench, such as "3s" or "100x". Empty uses the go test default.
+func BenchTime() string { return Current().Bench.Time }
+
+// BenchBaseline saved benchmark results file which GoBench compares with by benchstat.
+func BenchBaseline() string { return Current().Bench.Baseline }
+
+// BenchPprof open the GoProfile result mode, "terminal" runs go tool pprof in the terminal, "web" opens the pprof web UI.
+func BenchPprof() string { return Current().Bench.Pprof }
+
+// BuildAutosave call the GoBuild command automatically at during the BufWritePost.
+func BuildAutosave() bool { return itob(Current().Build.Autosave) }
+
+// BuildForce builds the binary instead of fake(use ioutil.TempFiile) build.
+func BuildForce() bool { return itob(Current().Build.Force) }
+
+// BuildFlags flag of compile tools build command.
+func BuildFlags() []string { return Current().Build.Flags }
+
+// BuildRunVet runs go vet for the package after the successful GoBuild, and merges the diagnostics into the same list.
+func BuildRunVet() bool { return itob(Current().Build.RunVet) }
+
+// BuildGOOS the GOOS of GoBuild for the cross compilation check. If empty, the host's GOOS.
+func BuildGOOS() string { return Current().Build.GOOS }
+
+// BuildGOARCH the GOARCH of GoBuild for the cross compilation check. If empty, the host's GOARCH.
+func BuildGOARCH() string { return Current().Build.GOARCH }
+
+// BuildTags build tags of the go/build context, which the analysis such as GoGuru sees.
+func BuildTags() []string { return Current().Build.Tags }
+
+// CallgraphDepth maximum depth of the calls from the root function on GoCallgraph. Zero is unlimited.
+func CallgraphDepth() int64 { return Current().Callgraph.Depth }
+
+// CallgraphFormat rendering format of the GoCallgraph DOT file by dot, such as "svg" or "png". Empty disables the rendering.
+func CallgraphFormat() string { return Current().Callgraph.Format }
+
+// CoverFlags flags for cover command.
+func CoverFlags() []string { return Current().Cover.Flags }
+
+// CoverMode mode of cover command.
+func CoverMode() string { return Current().Cover.Mode }
+
+// DefTool resolver tools of GoDef command. Tries each tool in order until one returns a position.
+func DefTool() []string { return Current().Def.Tool }
+
+// DefDebug echo the tool name which resolved the definition.
+func DefDebug() bool { return itob(Current().Def.Debug) }
+
+// DepsHideStdlib hides the standard library packages of the GoDeps tree.
+func DepsHideStdlib() bool { return itob(Current().Deps.HideStdlib) }
+
+// DelveBreakpointSymbol sign text of the breakpoint. It must be at most two display cells.
+func DelveBreakpointSymbol() string { return Current().Delve.BreakpointSymbol }
+
+// DelvePCSymbol sign text of the program counter. It must be at most two display cells.
+func DelvePCSymbol() string { return Current().Delve.PCSymbol }
+
+// DelveAsmFlavor assembly flavor of the DlvDisassemble. Either "gnu" or "intel".
+func DelveAsmFlavor() string { return Current().Delve.AsmFlavor }
+
+// DelveConnectTimeout timeout of waiting for the dlv headless server listening.
+func DelveConnectTimeout() time.Duration {
+	return time.Duration(Current().Delve.ConnectTimeout) * time.Second
+}
+
+// DelveRPCTimeout timeout of the client calls to the dlv server, except the continue which waits for the breakpoint.
+func DelveRPCTimeout() time.Duration {
+	return time.Duration(Current().Delve.RPCTimeout) * time.Second
+}
+
+// DelveBackend backend of the DlvDebug server. available value are "default", "native", "lldb" and "rr".
+func DelveBackend() string { return Current().Delve.Backend }
+
+// DelveLayout layout of the debug buffers. Each spec is the "name:split:size" form, split from the previous buffer in order.
+func DelveLayout() []string { return Current().Delve.Layout }
+
+// FmtAutosave call the GoFmt command automatically at during the BufWritePre.
+func FmtAutosave() bool { return itob(Current().Fmt.Autosave) }
+
+// FmtAutosaveContinueOnError continue the FmtAutosave formatting even if the IferrAutosave failed.
+func FmtAutosaveContinueOnError() bool { return itob(Current().Fmt.AutosaveContinueOnError) }
+
+// FmtMode formatting mode of Fmt command.
+func FmtMode() string { return Current().Fmt.Mode }
+
+// ImportPreferences preferred import paths of the GoFixImports, which win over the other packages of the same name.
+func ImportPreferences() []string { return Current().Fmt.ImportPreferences }
+
+// GenerateEnv extra environment variables of the GoGenerate generators, such as "FOO=bar".
+func GenerateEnv() []string { return Current().Generate.Env }
+
+// GenerateTestAllFuncs accept all functions to the GenerateTest.
+func GenerateTestAllFuncs() bool { return itob(Current().Generate.TestAllFuncs) }
+
+// GenerateTestExclFuncs exclude function of GenerateTest.
+func GenerateTestExclFuncs() string { return Current().Generate.TestExclFuncs }
+
+// GenerateTestExportedFuncs accept exported functions to the GenerateTest.
+func GenerateTestExportedFuncs() bool { return itob(Current().Generate.TestExportedFuncs) }
+
+// GenerateTestSubTest whether the use Go subtest idiom or not.
+func GenerateTestSubTest() bool { return itob(Current().Generate.TestSubTest) }
+
+// GuruReflection use the type reflection on GoGuru commmands.
+func GuruReflection() bool { return itob(Current().Guru.Reflection) }
+
+// GuruKeepCursor keep the cursor focus to source buffer instead of quickfix or locationlist.
+func GuruKeepCursor() map[string]int64 { return Current().Guru.KeepCursor }
+
+// GuruJumpFirst jump the first error position on GoGuru commands. It takes precedence over GuruKeepCursor.
+func GuruJumpFirst() bool { return itob(Current().Guru.JumpFirst) }
+
+// GuruDescribeVerbose renders the methods and fields of the GoGuru describe result into the buffer.
+func GuruDescribeVerbose() bool { return itob(Current().Guru.DescribeVerbose) }
+
+// GuruCache reuse the loaded pointer analysis program on GoGuru callers, callstack and pointsto commands.
+func GuruCache() bool { return itob(Current().Guru.Cache) }
+
+// GuruScope pointer analysis scope of GoGuru commands. If empty, estimated from the go.mod or current package.
+func GuruScope() []string { return Current().Guru.Scope }
+
+// GuruDefinitionMode open the definition file mode. available value are "edit", "split", "vsplit" and "tab".
+func GuruDefinitionMode() string { return Current().Guru.DefMode }
+
+// AutoHighlightReferences highlights the references of the identifier under the cursor on CursorHold.
+func AutoHighlightReferences() bool { return itob(Current().Highlight.AutoReferences) }
+
+// HintsEnabled shows the inferred types of the ":=" declarations as the virtual text on BufWritePost.
+func HintsEnabled() bool { return itob(Current().Hints.Enable) }
+
+// IferrAutosave call the GoIferr command automatically at during the BufWritePre.
+func IferrAutosave() bool { return itob(Current().Iferr.Autosave) }
+
+// ImplementsIncludeStdlib includes the standard library types and interfaces in the GoImplements results.
+func ImplementsIncludeStdlib() bool { return itob(Current().Implements.IncludeStdlib) }
+
+// ErrcheckIgnore regexp patterns of the unchecked calls which ignored by GoErrcheck, such as "fmt.Print".
+func ErrcheckIgnore() []string { return Current().Lint.ErrcheckIgnore }
+
+// GolintAutosave call the GoLint command automatically at during the BufWritePost.
+func GolintAutosave() bool { return Current().Lint.GolintAutosave }
+
+// GolintIgnore ignore file for lint command.
+func GolintIgnore() []string { return Current().Lint.GolintIgnore }
+
+// GolintMinConfidence minimum confidence of a problem to print it
+func GolintMinConfidence() float64 { return Current().Lint.GolintMinConfidence }
+
+// GolintMode mode of golint. available value are "root", "current" and "recursive".
+func GolintMode() string { return Current().Lint.GolintMode }
+
+// GoVetAutosave call the GoVet command automatically at during the BufWritePost.
+func GoVetAutosave() bool { return itob(Current().Lint.GoVetAutosave) }
+
+// GoVetFlags default flags for GoVet commands
+func GoVetFlags() []string { return Current().Lint.GoVetFlags }
+
+// GoVetIgnore ignore directories for go vet command.
+func GoVetIgnore() []string { return Current().Lint.GoVetIgnore }
+
+// MetalinterAutosave call the GoMetaLinter command automatically at during the BufWritePre.
+func MetalinterAutosave() bool { return itob(Current().Lint.MetalinterAutosave) }
+
+// MetalinterAutosaveTools lint tool list for MetalinterAutosave.
+func MetalinterAutosaveTools() []string { return Current().Lint.MetalinterAutosaveTools }
+
+// MetalinterTools lint tool list for GoMetaLinter command.
+func MetalinterTools() []string { return Current().Lint.MetalinterTools }
+
+// MetalinterDeadline deadline of GoMetaLinter command timeout.
+func MetalinterDeadline() string { return Current().Lint.MetalinterDeadline }
+
+// MetalinterSkipDir skips of lint of the directory.
+func MetalinterSkipDir() []string { return Current().Lint.MetalinterSkipDir }
+
+// MockSuffix the filename suffix of the generated mock file for GoAlternateMock.
+func MockSuffix() string { return Current().Mock.Suffix }
+
+// ReferrersSameOnly lists only the references in the current package on GoReferrers.
+func ReferrersSameOnly() bool { return itob(Current().Referrers.SameOnly) }
+
+// RenamePrefill Enable naming prefill.
+func RenamePrefill() bool { return itob(Current().Rename.Prefill) }
+
+// SignHighlight overrides the link destination of the sign highlight groups. map[group]destination.
+func SignHighlight() map[string]string { return Current().Sign.Highlight }
+
+// StaticcheckChecks checks of the staticcheck -checks flag, such as ["inherit", "-SA1019"]. If empty, the staticcheck config.
+func StaticcheckChecks() []string { return Current().Staticcheck.Checks }
+
+// StaticcheckSeverity minimum severity of the GoStaticcheck diagnostics. available value are "warning" and "error".
+func StaticcheckSeverity() string { return Current().Staticcheck.Severity }
+
+// StaticcheckSigns places the signs of the GoStaticcheck diagnostics.
+func StaticcheckSigns() bool { return itob(Current().Staticcheck.Signs) }
+
+// SymbolsScope search scope of the GoSymbols command. available value are "package" and "module".
+func SymbolsScope() string { return Current().Symbols.Scope }
+
+// StructTagCase name transform of the GoAddTags tag value. available value are "snake", "camel" and "kebab".
+func StructTagCase() string { return Current().Tags.Case }
+
+// TerminalMode open the terminal window mode.
+func TerminalMode() string { return Current().Terminal.Mode }
+
+// TerminalPosition open the terminal window position.
+func TerminalPosition() string { return Current().Terminal.Position }
+
+// TerminalHeight open the terminal window height.
+func TerminalHeight() int64 { return Current().Terminal.Height }
+
+// TerminalWidth open the terminal window width.
+func TerminalWidth() int64 { return Current().Terminal.Width }
+
+// TerminalStopInsert workaround if users set "autocmd BufEnter term://* startinsert".
+func TerminalStopInsert() bool { return itob(Current().Terminal.StopInsert) }
+
+// TestAutosave call the GoBuild command automatically at during the BufWritePost.
+func TestAutosave() bool { return itob(Current().Test.Autosave) }
+
+// TestAll enable all package test on GoTest. similar "go test ./...", but ignored vendor and testdata.
+func TestAll() bool { return itob(Current().Test.AllPackage) }
+
+// TestFlags test command default flags.
+func TestFlags() []string { return Current().Test.Flags }
+
+// TestRace enables the race detector on GoTest, which sets CGO_ENABLED=1 for the test command.
+func TestRace() bool { return itob(Current().Test.Race) }
+
+// TestCompileAutosave call the GoTestCompile command automatically at during the BufWritePost.
+func TestCompileAutosave() bool { return itob(Current().Test.CompileAutosave) }
+
+// Tools the import paths of the tools which GoToolsInstall installs.
+func Tools() []string { return Current().Tools.Packages }
+
+// DebugEnable Enable debugging.
+func DebugEnable() bool { return itob(Current().Debug.Enable) }
+
+// DebugPprof Enable net/http/pprof debugging.
+func DebugPprof() bool { return itob(Current().Debug.Pprof) }
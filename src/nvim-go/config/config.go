@@ -4,24 +4,49 @@
 
 package config
 
-import "github.com/neovim/go-client/nvim"
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
 
-// Config represents a config variable for nvim-go.
-// Each type must be exported for plugin.HandleAutocmd Eval option.
-// Also it does not support embeded type.
+// Config represents the all config variables of nvim-go.
+// Each field is read from the Neovim global variable of the eval tag by Load,
+// and the package level accessors such as BuildForce read the loaded Config.
+// It does not support embeded type.
 type Config struct {
 	Global Global
 
-	Build    build
-	Cover    cover
-	Fmt      fmt
-	Generate generate
-	Guru     guru
-	Iferr    iferr
-	Lint     lint
-	Rename   rename
-	Terminal terminal
-	Test     test
+	Autocmd     autocmd
+	Bench       bench
+	Build       build
+	Callgraph   callgraph
+	Cover       cover
+	Def         def
+	Delve       delve
+	Deps        deps
+	Fmt         fmt
+	Generate    generate
+	Guru        guru
+	Highlight   highlight
+	Hints       hints
+	Iferr       iferr
+	Implements  implements
+	Lint        lint
+	Mock        mock
+	Referrers   referrers
+	Rename      rename
+	Sign        sign
+	Staticcheck staticcheck
+	Symbols     symbols
+	Tags        tags
+	Terminal    terminal
+	Test        test
+	Tools       tools
 
 	Debug debug
 }
@@ -29,8 +54,28 @@ type Config struct {
 // Global represents a global config variable.
 type Global struct {
 	ChannelID     int
-	ServerName    string `eval:"v:servername"`
-	ErrorListType string `eval:"g:go#global#errorlisttype"`
+	ServerName    string            `eval:"v:servername"`
+	ErrorListType string            `eval:"g:go#global#errorlisttype"`
+	ListType      map[string]string `eval:"g:go#global#listtype"`
+	AutoClose     int64             `eval:"g:go#global#autoclose"`
+	RelativePaths int64             `eval:"g:go#global#relative_paths"`
+	MaxHeight     int64             `eval:"g:go#global#max_height"`
+	Dedup         int64             `eval:"g:go#global#dedup"`
+	GoFlags       []string          `eval:"g:go#global#goflags"`
+	LogLevel      string            `eval:"g:go#global#loglevel"`
+}
+
+// autocmd represents a autocmd config variable.
+type autocmd struct {
+	Enable int64 `eval:"g:go#autocmd#enable"`
+}
+
+// bench represents a GoBench command config variables.
+type bench struct {
+	Mem      int64  `eval:"g:go#bench#mem"`
+	Time     string `eval:"g:go#bench#time"`
+	Baseline string `eval:"g:go#bench#baseline"`
+	Pprof    string `eval:"g:go#bench#pprof"`
 }
 
 // build GoBuild command config variable.
@@ -38,6 +83,16 @@ type build struct {
 	Autosave int64    `eval:"g:go#build#autosave"`
 	Force    int64    `eval:"g:go#build#force"`
 	Flags    []string `eval:"g:go#build#flags"`
+	RunVet   int64    `eval:"g:go#build#run_vet"`
+	Tags     []string `eval:"g:go#build#tags"`
+	GOOS     string   `eval:"g:go#build#goos"`
+	GOARCH   string   `eval:"g:go#build#goarch"`
+}
+
+// callgraph represents a GoCallgraph command config variable.
+type callgraph struct {
+	Depth  int64  `eval:"g:go#callgraph#depth"`
+	Format string `eval:"g:go#callgraph#format"`
 }
 
 type cover struct {
@@ -45,25 +100,64 @@ type cover struct {
 	Mode  string   `eval:"g:go#cover#mode"`
 }
 
+// def represents a GoDef command config variable.
+type def struct {
+	Tool  []string `eval:"g:go#def#tool"`
+	Debug int64    `eval:"g:go#def#debug"`
+}
+
+// deps represents a GoDeps command config variable.
+type deps struct {
+	HideStdlib int64 `eval:"g:go#deps#hide_stdlib"`
+}
+
+// delve represents a Delve commands config variable.
+type delve struct {
+	BreakpointSymbol string   `eval:"g:go#delve#breakpoint_symbol"`
+	PCSymbol         string   `eval:"g:go#delve#pc_symbol"`
+	AsmFlavor        string   `eval:"g:go#delve#asm_flavor"`
+	ConnectTimeout   int64    `eval:"g:go#delve#connect_timeout"`
+	RPCTimeout       int64    `eval:"g:go#delve#rpc_timeout"`
+	Backend          string   `eval:"g:go#delve#backend"`
+	Layout           []string `eval:"g:go#delve#layout"`
+}
+
 // fmt represents a GoFmt command config variable.
 type fmt struct {
-	Autosave int64  `eval:"g:go#fmt#autosave"`
-	Mode     string `eval:"g:go#fmt#mode"`
+	Autosave                int64    `eval:"g:go#fmt#autosave"`
+	AutosaveContinueOnError int64    `eval:"g:go#fmt#autosave_continue_on_error"`
+	Mode                    string   `eval:"g:go#fmt#mode"`
+	ImportPreferences       []string `eval:"g:go#fmt#import_preferences"`
 }
 
 // generate represents a GoGenerate command config variables.
 type generate struct {
-	TestAllFuncs      int64  `eval:"g:go#generate#test#allfuncs"`
-	TestExclFuncs     string `eval:"g:go#generate#test#exclude"`
-	TestExportedFuncs int64  `eval:"g:go#generate#test#exportedfuncs"`
-	TestSubTest       int64  `eval:"g:go#generate#test#subtest"`
+	TestAllFuncs      int64    `eval:"g:go#generate#test#allfuncs"`
+	TestExclFuncs     string   `eval:"g:go#generate#test#exclude"`
+	TestExportedFuncs int64    `eval:"g:go#generate#test#exportedfuncs"`
+	TestSubTest       int64    `eval:"g:go#generate#test#subtest"`
+	Env               []string `eval:"g:go#generate#env"`
 }
 
 // guru represents a GoGuru command config variable.
 type guru struct {
-	Reflection int64            `eval:"g:go#guru#reflection"`
-	KeepCursor map[string]int64 `eval:"g:go#guru#keep_cursor"`
-	JumpFirst  int64            `eval:"g:go#guru#jump_first"`
+	Reflection      int64            `eval:"g:go#guru#reflection"`
+	KeepCursor      map[string]int64 `eval:"g:go#guru#keep_cursor"`
+	JumpFirst       int64            `eval:"g:go#guru#jump_first"`
+	Cache           int64            `eval:"g:go#guru#cache"`
+	Scope           []string         `eval:"g:go#guru#scope"`
+	DefMode         string           `eval:"g:go#guru#definition_mode"`
+	DescribeVerbose int64            `eval:"g:go#guru#describe_verbose"`
+}
+
+// highlight represents a GoHighlightReferences command config variable.
+type highlight struct {
+	AutoReferences int64 `eval:"g:go#highlight#auto_references"`
+}
+
+// hints represents a GoHints command config variable.
+type hints struct {
+	Enable int64 `eval:"g:go#hints#enable"`
 }
 
 // iferr represents a GoIferr command config variable.
@@ -71,8 +165,14 @@ type iferr struct {
 	Autosave int64 `eval:"g:go#iferr#autosave"`
 }
 
+// implements represents a GoImplements command config variable.
+type implements struct {
+	IncludeStdlib int64 `eval:"g:go#implements#include_stdlib"`
+}
+
 // lint represents a code lint commands config variable.
 type lint struct {
+	ErrcheckIgnore          []string `eval:"g:go#lint#errcheck#ignore"`
 	GolintAutosave          bool     `eval:"g:go#lint#golint#autosave"`
 	GolintIgnore            []string `eval:"g:go#lint#golint#ignore"`
 	GolintMinConfidence     float64  `eval:"g:go#lint#golint#min_confidence"`
@@ -87,11 +187,43 @@ type lint struct {
 	MetalinterSkipDir       []string `eval:"g:go#lint#metalinter#skip_dir"`
 }
 
+// mock represents a GoAlternateMock command config variable.
+type mock struct {
+	Suffix string `eval:"g:go#mock#suffix"`
+}
+
+// referrers represents a GoReferrers command config variable.
+type referrers struct {
+	SameOnly int64 `eval:"g:go#referrers#same_only"`
+}
+
 // rename represents a GoRename command config variable.
 type rename struct {
 	Prefill int64 `eval:"g:go#rename#prefill"`
 }
 
+// sign represents a sign config variable.
+type sign struct {
+	Highlight map[string]string `eval:"g:go#sign#highlight"`
+}
+
+// staticcheck represents a GoStaticcheck command config variable.
+type staticcheck struct {
+	Checks   []string `eval:"g:go#staticcheck#checks"`
+	Severity string   `eval:"g:go#staticcheck#severity"`
+	Signs    int64    `eval:"g:go#staticcheck#signs"`
+}
+
+// symbols represents a GoSymbols command config variable.
+type symbols struct {
+	Scope string `eval:"g:go#symbols#scope"`
+}
+
+// tags represents a GoAddTags command config variable.
+type tags struct {
+	Case string `eval:"g:go#tags#case"`
+}
+
 // terminal represents a configure of Neovim terminal buffer.
 type terminal struct {
 	Mode       string `eval:"g:go#terminal#mode"`
@@ -106,6 +238,14 @@ type test struct {
 	AllPackage int64    `eval:"g:go#test#all_package"`
 	Autosave   int64    `eval:"g:go#test#autosave"`
 	Flags      []string `eval:"g:go#test#flags"`
+	Race       int64    `eval:"g:go#test#race"`
+
+	CompileAutosave int64 `eval:"g:go#test#compile_autosave"`
+}
+
+// tools represents a GoToolsInstall command config variable.
+type tools struct {
+	Packages []string `eval:"g:go#tools#packages"`
 }
 
 // Debug represents a debug of nvim-go config variable.
@@ -114,168 +254,167 @@ type debug struct {
 	Pprof  int64 `eval:"g:go#debug#pprof"`
 }
 
+// Default returns the Config which has the default values of each variables.
+// The defaults are the same as plugin/nvim-go.vim.
+func Default() *Config {
+	return &Config{
+		Global: Global{
+			ErrorListType: "locationlist",
+			ListType:      map[string]string{},
+			AutoClose:     1,
+			RelativePaths: 1,
+			MaxHeight:     10,
+			Dedup:         1,
+			LogLevel:      "info",
+		},
+		Autocmd: autocmd{Enable: 1},
+		Bench:   bench{Mem: 1, Pprof: "terminal"},
+		Callgraph: callgraph{
+			Depth:  3,
+			Format: "svg",
+		},
+		Cover: cover{Mode: "atomic"},
+		Def:   def{Tool: []string{"gopls", "guru", "godef"}},
+		Delve: delve{
+			AsmFlavor:      "gnu",
+			ConnectTimeout: 10,
+			RPCTimeout:     30,
+			Backend:        "default",
+			Layout:         []string{"terminal:vsplit:2/5", "context:split:2/3", "thread:split:1/5", "breakpoint:split:1/5"},
+		},
+		Fmt: fmt{Mode: "goimports"},
+		Generate: generate{
+			TestAllFuncs:  1,
+			TestExclFuncs: "init$",
+			TestSubTest:   1,
+		},
+		Guru: guru{
+			KeepCursor: map[string]int64{},
+			Cache:      1,
+			DefMode:    "edit",
+		},
+		Implements: implements{IncludeStdlib: 1},
+		Lint: lint{
+			GolintMinConfidence:     0.8,
+			GolintMode:              "current",
+			MetalinterAutosaveTools: []string{"vet", "golint"},
+			MetalinterTools:         []string{"vet", "golint", "errcheck"},
+			MetalinterDeadline:      "5s",
+		},
+		Mock: mock{Suffix: "_mock.go"},
+		Sign: sign{Highlight: map[string]string{}},
+		Staticcheck: staticcheck{
+			Severity: "warning",
+			Signs:    1,
+		},
+		Symbols: symbols{Scope: "package"},
+		Tags:    tags{Case: "snake"},
+		Terminal: terminal{
+			Mode:       "vsplit",
+			Position:   "belowright",
+			StopInsert: 1,
+		},
+		Tools: tools{
+			Packages: []string{
+				"github.com/alecthomas/gometalinter",
+				"github.com/cweill/gotests/gotests",
+				"github.com/derekparker/delve/cmd/dlv",
+				"github.com/golang/mock/mockgen",
+				"github.com/kisielk/errcheck",
+				"github.com/koron/iferr",
+				"github.com/rogpeppe/godef",
+				"golang.org/x/perf/cmd/benchstat",
+				"golang.org/x/tools/cmd/goimports",
+				"golang.org/x/tools/cmd/gopls",
+				"golang.org/x/tools/cmd/guru",
+				"honnef.co/go/tools/cmd/staticcheck",
+			},
+		},
+	}
+}
+
 var (
-	// ChannelID remote plugins channel id.
-	ChannelID int
-	// ServerName Neovim socket listen location.
-	ServerName string
-	// ErrorListType type of error list window.
-	ErrorListType string
-
-	// BuildAutosave call the GoBuild command automatically at during the BufWritePost.
-	BuildAutosave bool
-	// BuildForce builds the binary instead of fake(use ioutil.TempFiile) build.
-	BuildForce bool
-	// BuildFlags flag of compile tools build command.
-	BuildFlags []string
-
-	// CoverFlags flags for cover command.
-	CoverFlags []string
-	// CoverMode mode of cover command.
-	CoverMode string
-
-	// FmtAutosave call the GoFmt command automatically at during the BufWritePre.
-	FmtAutosave bool
-	// FmtMode formatting mode of Fmt command.
-	FmtMode string
-
-	// GenerateTestAllFuncs accept all functions to the GenerateTest.
-	GenerateTestAllFuncs bool
-	// GenerateTestExclFuncs exclude function of GenerateTest.
-	GenerateTestExclFuncs string
-	// GenerateTestExportedFuncs accept exported functions to the GenerateTest.
-	GenerateTestExportedFuncs bool
-	// GenerateTestSubTest whether the use Go subtest idiom or not.
-	GenerateTestSubTest bool
-
-	// GuruReflection use the type reflection on GoGuru commmands.
-	GuruReflection bool
-	// GuruKeepCursor keep the cursor focus to source buffer instead of quickfix or locationlist.
-	GuruKeepCursor map[string]int64
-	// GuruJumpFirst jump the first error position on GoGuru commands.
-	GuruJumpFirst bool
-
-	// IferrAutosave call the GoIferr command automatically at during the BufWritePre.
-	IferrAutosave bool
-
-	// GolintAutosave call the GoLint command automatically at during the BufWritePost.
-	GolintAutosave bool
-	// GolintIgnore ignore file for lint command.
-	GolintIgnore []string
-	// GolintMinConfidence minimum confidence of a problem to print it
-	GolintMinConfidence float64
-	// GolintMode mode of golint. available value are "root", "current" and "recursive".
-	GolintMode string
-	// GoVetAutosave call the GoVet command automatically at during the BufWritePost.
-	GoVetAutosave bool
-	// GoVetFlags default flags for GoVet commands
-	GoVetFlags []string
-	// GoVetIgnore ignore directories for go vet command.
-	GoVetIgnore []string
-	// MetalinterAutosave call the GoMetaLinter command automatically at during the BufWritePre.
-	MetalinterAutosave bool
-	// MetalinterAutosaveTools lint tool list for MetalinterAutosave.
-	MetalinterAutosaveTools []string
-	// MetalinterTools lint tool list for GoMetaLinter command.
-	MetalinterTools []string
-	// MetalinterDeadline deadline of GoMetaLinter command timeout.
-	MetalinterDeadline string
-	// MetalinterSkipDir skips of lint of the directory.
-	MetalinterSkipDir []string
-
-	// RenamePrefill Enable naming prefill.
-	RenamePrefill bool
-
-	// TerminalMode open the terminal window mode.
-	TerminalMode string
-	// TerminalPosition open the terminal window position.
-	TerminalPosition string
-	// TerminalHeight open the terminal window height.
-	TerminalHeight int64
-	// TerminalWidth open the terminal window width.
-	TerminalWidth int64
-	// TerminalStopInsert workaround if users set "autocmd BufEnter term://* startinsert".
-	TerminalStopInsert bool
-
-	// TestAutosave call the GoBuild command automatically at during the BufWritePost.
-	TestAutosave bool
-	// TestAll enable all package test on GoTest. similar "go test ./...", but ignored vendor and testdata.
-	TestAll bool
-	// TestFlags test command default flags.
-	TestFlags []string
-
-	// DebugEnable Enable debugging.
-	DebugEnable bool
-	// DebugPprof Enable net/http/pprof debugging.
-	DebugPprof bool
+	current atomic.Value // *Config
+	// updateMu serializes the read-modify-write of Update.
+	updateMu sync.Mutex
 )
 
-// Get gets the user config variables and convert to global varialble.
-func Get(v *nvim.Nvim, cfg *Config) {
-	// Client
-	ChannelID = cfg.Global.ChannelID
-	ServerName = cfg.Global.ServerName
-	ErrorListType = cfg.Global.ErrorListType
-
-	// Build
-	BuildAutosave = itob(cfg.Build.Autosave)
-	BuildForce = itob(cfg.Build.Force)
-	BuildFlags = cfg.Build.Flags
-
-	// Cover
-	CoverFlags = cfg.Cover.Flags
-	CoverMode = cfg.Cover.Mode
-
-	// Fmt
-	FmtAutosave = itob(cfg.Fmt.Autosave)
-	FmtMode = cfg.Fmt.Mode
-
-	// Generate
-	GenerateTestAllFuncs = itob(cfg.Generate.TestAllFuncs)
-	GenerateTestExclFuncs = cfg.Generate.TestExclFuncs
-	GenerateTestExportedFuncs = itob(cfg.Generate.TestExportedFuncs)
-	GenerateTestSubTest = itob(cfg.Generate.TestSubTest)
-
-	// Guru
-	GuruReflection = itob(cfg.Guru.Reflection)
-	GuruKeepCursor = cfg.Guru.KeepCursor
-	GuruJumpFirst = itob(cfg.Guru.JumpFirst)
-
-	// Iferr
-	IferrAutosave = itob(cfg.Iferr.Autosave)
-
-	// Lint
-	GolintAutosave = cfg.Lint.GolintAutosave
-	GolintIgnore = cfg.Lint.GolintIgnore
-	GolintMinConfidence = cfg.Lint.GolintMinConfidence
-	GolintMode = cfg.Lint.GolintMode
-	GoVetAutosave = itob(cfg.Lint.GoVetAutosave)
-	GoVetFlags = cfg.Lint.GoVetFlags
-	GoVetIgnore = cfg.Lint.GoVetIgnore
-	MetalinterAutosave = itob(cfg.Lint.MetalinterAutosave)
-	MetalinterAutosaveTools = cfg.Lint.MetalinterAutosaveTools
-	MetalinterTools = cfg.Lint.MetalinterTools
-	MetalinterDeadline = cfg.Lint.MetalinterDeadline
-	MetalinterSkipDir = cfg.Lint.MetalinterSkipDir
-
-	// Rename
-	RenamePrefill = itob(cfg.Rename.Prefill)
-
-	// Terminal
-	TerminalMode = cfg.Terminal.Mode
-	TerminalPosition = cfg.Terminal.Position
-	TerminalHeight = cfg.Terminal.Height
-	TerminalWidth = cfg.Terminal.Width
-	TerminalStopInsert = itob(cfg.Terminal.StopInsert)
-
-	// Test
-	TestAutosave = itob(cfg.Test.Autosave)
-	TestAll = itob(cfg.Test.AllPackage)
-	TestFlags = cfg.Test.Flags
-
-	// Debug
-	DebugEnable = itob(cfg.Debug.Enable)
-	DebugPprof = itob(cfg.Debug.Pprof)
+// Current returns the current loaded Config. Returns the Default if not loaded yet.
+// The returned Config must not be modified, use Update instead.
+func Current() *Config {
+	if cfg, ok := current.Load().(*Config); ok {
+		return cfg
+	}
+	return Default()
+}
+
+// Set replaces the current Config to cfg, and returns the previous Config.
+func Set(cfg *Config) *Config {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	prev := Current()
+	current.Store(cfg)
+	return prev
+}
+
+// Update applies f to the copy of the current Config and replaces the current
+// Config to it, and returns the previous Config.
+// The copy is shallow, f must not modify the maps and slices in place.
+func Update(f func(cfg *Config)) *Config {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	prev := Current()
+	cfg := *prev
+	f(&cfg)
+	current.Store(&cfg)
+	return prev
+}
+
+// Load reads the all config variables from the Neovim global variables, and
+// stores it as the current Config. The unset variable is the Default value.
+//
+// The invalid variables fallback to the Default value, and Load returns the
+// loaded Config with the ValidationError which has the all problems.
+func Load(v *nvim.Nvim) (*Config, error) {
+	cfg := Default()
+	if err := v.Eval(evalExpr(reflect.TypeOf(cfg).Elem()), cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to load the config variables")
+	}
+	cfg.Global.ChannelID = v.ChannelID()
+	verr := validate(cfg, Default())
+
+	Set(cfg)
+	return cfg, verr
+}
+
+// evalExpr returns the dictionary expression which evaluates the eval tags of
+// t fields. The unset global variables are omitted from the dictionary so as
+// not to override the default values.
+// like:
+//
+//	{'Build': filter({'Force': get(g:, 'go#build#force', v:null)}, 'v:val isnot v:null')}
+func evalExpr(t reflect.Type) string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		eval := sf.Tag.Get("eval")
+		switch {
+		case eval == "" && sf.Type.Kind() == reflect.Struct:
+			eval = evalExpr(sf.Type)
+		case strings.HasPrefix(eval, "g:"):
+			eval = "get(g:, '" + strings.TrimPrefix(eval, "g:") + "', v:null)"
+		}
+		if eval == "" {
+			continue
+		}
+
+		fields = append(fields, "'"+sf.Name+"': "+eval)
+	}
+
+	return "filter({" + strings.Join(fields, ", ") + "}, 'v:val isnot v:null')"
 }
 
 func itob(i int64) bool { return i != int64(0) }
@@ -0,0 +1,82 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvalExpr(t *testing.T) {
+	type sub struct {
+		Flags []string `eval:"g:go#test#flags"`
+		Skip  int
+	}
+	type cfg struct {
+		Server string `eval:"v:servername"`
+		Sub    sub
+	}
+
+	got := evalExpr(reflect.TypeOf(cfg{}))
+	want := "filter({'Server': v:servername, 'Sub': filter({'Flags': get(g:, 'go#test#flags', v:null)}, 'v:val isnot v:null')}, 'v:val isnot v:null')"
+	if got != want {
+		t.Errorf("evalExpr(%T) = %q, want %q", cfg{}, got, want)
+	}
+}
+
+func TestDump(t *testing.T) {
+	cfg := Default()
+	cfg.Build.Flags = []string{"-tags", "it's"}
+	cfg.Guru.KeepCursor = map[string]int64{"referrers": 1, "callers": 0}
+
+	got := string(Dump(cfg))
+	for _, want := range []string{
+		"g:go#global#errorlisttype = 'locationlist'\n",
+		"g:go#build#flags = ['-tags', 'it''s']\n",
+		"g:go#guru#keep_cursor = {'callers': 0, 'referrers': 1}\n",
+		"g:go#lint#golint#min_confidence = 0.8\n",
+		"g:go#lint#golint#autosave = v:false\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Dump(%v) = %q, want contains %q", cfg, got, want)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	defer Set(Current())
+
+	prev := Default()
+	Set(prev)
+	if got := Update(func(cfg *Config) { cfg.Fmt.Mode = "gofmt" }); got != prev {
+		t.Errorf("Update() = %p, want the previous Config %p", got, prev)
+	}
+	if got, want := prev.Fmt.Mode, "goimports"; got != want {
+		t.Errorf("previous Config Fmt.Mode = %q, want %q", got, want)
+	}
+	if got, want := FmtMode(), "gofmt"; got != want {
+		t.Errorf("FmtMode() = %q, want %q", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := Default()
+	new := Default()
+	new.Fmt.Mode = "gofmt"
+	new.Guru.Scope = []string{"nvim-go/..."}
+
+	got := Diff(old, new)
+	want := []string{
+		"g:go#fmt#mode = 'gofmt' (was 'goimports')",
+		"g:go#guru#scope = ['nvim-go/...'] (was [])",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff(%v, %v) = %q, want %q", old, new, got, want)
+	}
+	if got := Diff(old, Default()); got != nil {
+		t.Errorf("Diff(%v, Default()) = %q, want nil", old, got)
+	}
+}
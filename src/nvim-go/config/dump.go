@@ -0,0 +1,99 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// variable represents a config variable name and the Vim script literal value.
+type variable struct {
+	name  string
+	value string
+}
+
+// variables returns the cfg variables in the Config field order.
+func variables(cfg *Config) []variable {
+	return appendVariables(nil, reflect.ValueOf(cfg).Elem())
+}
+
+func appendVariables(vars []variable, v reflect.Value) []variable {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		eval := sf.Tag.Get("eval")
+		if eval == "" {
+			if sf.Type.Kind() == reflect.Struct {
+				vars = appendVariables(vars, v.Field(i))
+			}
+			continue
+		}
+		vars = append(vars, variable{name: eval, value: vimLiteral(v.Field(i))})
+	}
+	return vars
+}
+
+// Dump formats the cfg values as the "g:go#... = value" lines in the Config
+// field order. Each value is the Vim script literal.
+func Dump(cfg *Config) []byte {
+	var buf bytes.Buffer
+	for _, v := range variables(cfg) {
+		buf.WriteString(v.name + " = " + v.value + "\n")
+	}
+	return buf.Bytes()
+}
+
+// Diff returns the changed variables from old to new as the
+// "g:go#... = new (was old)" form.
+func Diff(old, new *Config) []string {
+	oldVars, newVars := variables(old), variables(new)
+
+	var diff []string
+	for i, v := range newVars {
+		if v.value != oldVars[i].value {
+			diff = append(diff, v.name+" = "+v.value+" (was "+oldVars[i].value+")")
+		}
+	}
+	return diff
+}
+
+// vimLiteral returns the Vim script literal of v.
+func vimLiteral(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return "'" + strings.Replace(v.String(), "'", "''", -1) + "'"
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		if v.Bool() {
+			return "v:true"
+		}
+		return "v:false"
+	case reflect.Slice:
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = vimLiteral(v.Index(i))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = vimLiteral(reflect.ValueOf(k)) + ": " + vimLiteral(v.MapIndex(reflect.ValueOf(k)))
+		}
+		return "{" + strings.Join(elems, ", ") + "}"
+	}
+	return "''"
+}
@@ -0,0 +1,134 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError represents the problems of the config variables.
+// Each problem is the "g:go#...: reason" form.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid config variables, fallback to the default value:\n\t" + strings.Join(e, "\n\t")
+}
+
+// validator collects the problems of the config variables.
+type validator struct {
+	problems ValidationError
+}
+
+func (v *validator) errorf(name, reason string, def string) {
+	v.problems = append(v.problems, name+": "+reason+", use "+def)
+}
+
+// oneOf resets s to def if s is not any of values.
+func (v *validator) oneOf(name string, s *string, def string, values ...string) {
+	for _, value := range values {
+		if *s == value {
+			return
+		}
+	}
+	v.errorf(name, "unknown value '"+*s+"'", "'"+def+"'")
+	*s = def
+}
+
+// eachOf removes the elements of ss which are not any of values. Resets ss to
+// def if all elements are removed.
+func (v *validator) eachOf(name string, ss *[]string, def []string, values ...string) {
+	var valid []string
+	for _, s := range *ss {
+		ok := false
+		for _, value := range values {
+			if s == value {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			v.problems = append(v.problems, name+": unknown value '"+s+"', ignored")
+			continue
+		}
+		valid = append(valid, s)
+	}
+	if len(valid) == 0 && len(*ss) > 0 {
+		v.errorf(name, "no valid values", vimLiteral(reflect.ValueOf(def)))
+		valid = def
+	}
+	*ss = valid
+}
+
+// atLeast resets i to def if i is less than min.
+func (v *validator) atLeast(name string, i *int64, def, min int64) {
+	if *i < min {
+		v.errorf(name, "must be at least "+strconv.FormatInt(min, 10)+", got "+strconv.FormatInt(*i, 10), strconv.FormatInt(def, 10))
+		*i = def
+	}
+}
+
+// validate checks the enumerated values and the numeric ranges of cfg, and
+// resets the invalid values to the def values. Returns nil if cfg is valid.
+func validate(cfg, def *Config) error {
+	v := new(validator)
+
+	v.oneOf("g:go#global#errorlisttype", &cfg.Global.ErrorListType, def.Global.ErrorListType, "locationlist", "quickfix")
+	v.oneOf("g:go#global#loglevel", &cfg.Global.LogLevel, def.Global.LogLevel, "error", "warn", "info", "debug")
+	v.atLeast("g:go#global#max_height", &cfg.Global.MaxHeight, def.Global.MaxHeight, 1)
+	for name, typ := range cfg.Global.ListType {
+		switch typ {
+		case "locationlist", "location", "quickfix":
+		default:
+			v.problems = append(v.problems, "g:go#global#listtype: unknown value '"+typ+"' of '"+name+"', use the g:go#global#errorlisttype")
+		}
+	}
+
+	v.oneOf("g:go#bench#pprof", &cfg.Bench.Pprof, def.Bench.Pprof, "terminal", "web")
+	v.oneOf("g:go#callgraph#format", &cfg.Callgraph.Format, def.Callgraph.Format, "", "svg", "png", "pdf")
+	v.oneOf("g:go#cover#mode", &cfg.Cover.Mode, def.Cover.Mode, "set", "count", "atomic")
+	v.eachOf("g:go#def#tool", &cfg.Def.Tool, def.Def.Tool, "gopls", "guru", "godef")
+
+	v.oneOf("g:go#delve#asm_flavor", &cfg.Delve.AsmFlavor, def.Delve.AsmFlavor, "gnu", "intel")
+	v.atLeast("g:go#delve#connect_timeout", &cfg.Delve.ConnectTimeout, def.Delve.ConnectTimeout, 1)
+	v.oneOf("g:go#delve#backend", &cfg.Delve.Backend, def.Delve.Backend, "default", "native", "lldb", "rr")
+	v.atLeast("g:go#delve#rpc_timeout", &cfg.Delve.RPCTimeout, def.Delve.RPCTimeout, 1)
+
+	v.oneOf("g:go#fmt#mode", &cfg.Fmt.Mode, def.Fmt.Mode, "fmt", "goimports")
+
+	v.oneOf("g:go#guru#definition_mode", &cfg.Guru.DefMode, def.Guru.DefMode, "edit", "split", "vsplit", "tab")
+
+	v.oneOf("g:go#lint#golint#mode", &cfg.Lint.GolintMode, def.Lint.GolintMode, "root", "current", "recursive")
+	if c := cfg.Lint.GolintMinConfidence; c < 0 || c > 1 {
+		v.errorf("g:go#lint#golint#min_confidence", "must be between 0 and 1, got "+strconv.FormatFloat(c, 'f', -1, 64), strconv.FormatFloat(def.Lint.GolintMinConfidence, 'f', -1, 64))
+		cfg.Lint.GolintMinConfidence = def.Lint.GolintMinConfidence
+	}
+	if _, err := time.ParseDuration(cfg.Lint.MetalinterDeadline); err != nil {
+		v.errorf("g:go#lint#metalinter#deadline", "invalid duration '"+cfg.Lint.MetalinterDeadline+"'", "'"+def.Lint.MetalinterDeadline+"'")
+		cfg.Lint.MetalinterDeadline = def.Lint.MetalinterDeadline
+	}
+
+	if !strings.HasSuffix(cfg.Mock.Suffix, ".go") || cfg.Mock.Suffix == ".go" {
+		v.errorf("g:go#mock#suffix", "must be the filename suffix which ends with '.go', got '"+cfg.Mock.Suffix+"'", "'"+def.Mock.Suffix+"'")
+		cfg.Mock.Suffix = def.Mock.Suffix
+	}
+
+	v.oneOf("g:go#staticcheck#severity", &cfg.Staticcheck.Severity, def.Staticcheck.Severity, "warning", "error")
+	v.oneOf("g:go#symbols#scope", &cfg.Symbols.Scope, def.Symbols.Scope, "package", "module")
+
+	v.oneOf("g:go#tags#case", &cfg.Tags.Case, def.Tags.Case, "snake", "camel", "kebab")
+
+	v.oneOf("g:go#terminal#mode", &cfg.Terminal.Mode, def.Terminal.Mode, "split", "vsplit")
+	v.oneOf("g:go#terminal#position", &cfg.Terminal.Position, def.Terminal.Position, "aboveleft", "belowright", "leftabove", "rightbelow", "topleft", "botright")
+	v.atLeast("g:go#terminal#height", &cfg.Terminal.Height, def.Terminal.Height, 0)
+	v.atLeast("g:go#terminal#width", &cfg.Terminal.Width, def.Terminal.Width, 0)
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return v.problems
+}
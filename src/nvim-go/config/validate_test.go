@@ -0,0 +1,88 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		want    func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) { cfg.Fmt.Mode = "fmt" },
+			want:   func(cfg *Config) { cfg.Fmt.Mode = "fmt" },
+		},
+		{
+			name:    "unknown fmt mode",
+			modify:  func(cfg *Config) { cfg.Fmt.Mode = "gofumpt" },
+			want:    func(cfg *Config) {},
+			wantErr: "invalid config variables, fallback to the default value:\n\tg:go#fmt#mode: unknown value 'gofumpt', use 'goimports'",
+		},
+		{
+			name:    "unknown def tool is ignored",
+			modify:  func(cfg *Config) { cfg.Def.Tool = []string{"godef", "gotags"} },
+			want:    func(cfg *Config) { cfg.Def.Tool = []string{"godef"} },
+			wantErr: "invalid config variables, fallback to the default value:\n\tg:go#def#tool: unknown value 'gotags', ignored",
+		},
+		{
+			name:   "all def tools are unknown",
+			modify: func(cfg *Config) { cfg.Def.Tool = []string{"gotags"} },
+			want:   func(cfg *Config) {},
+			wantErr: "invalid config variables, fallback to the default value:\n" +
+				"\tg:go#def#tool: unknown value 'gotags', ignored\n" +
+				"\tg:go#def#tool: no valid values, use ['gopls', 'guru', 'godef']",
+		},
+		{
+			name: "aggregated",
+			modify: func(cfg *Config) {
+				cfg.Global.ErrorListType = "loclist"
+				cfg.Delve.ConnectTimeout = 0
+				cfg.Lint.GolintMinConfidence = 1.5
+				cfg.Lint.MetalinterDeadline = "5"
+				cfg.Terminal.Height = -1
+			},
+			want: func(cfg *Config) {},
+			wantErr: "invalid config variables, fallback to the default value:\n" +
+				"\tg:go#global#errorlisttype: unknown value 'loclist', use 'locationlist'\n" +
+				"\tg:go#delve#connect_timeout: must be at least 1, got 0, use 10\n" +
+				"\tg:go#lint#golint#min_confidence: must be between 0 and 1, got 1.5, use 0.8\n" +
+				"\tg:go#lint#metalinter#deadline: invalid duration '5', use '5s'\n" +
+				"\tg:go#terminal#height: must be at least 0, got -1, use 0",
+		},
+		{
+			name:    "unknown list type is reported",
+			modify:  func(cfg *Config) { cfg.Global.ListType = map[string]string{"Build": "qf"} },
+			want:    func(cfg *Config) { cfg.Global.ListType = map[string]string{"Build": "qf"} },
+			wantErr: "invalid config variables, fallback to the default value:\n\tg:go#global#listtype: unknown value 'qf' of 'Build', use the g:go#global#errorlisttype",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, want := Default(), Default()
+			tt.modify(cfg)
+			tt.want(want)
+
+			err := validate(cfg, Default())
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("%q. validate() error = %v, wantErr %q", tt.name, err, tt.wantErr)
+			}
+			if err != nil && err.Error() != tt.wantErr {
+				t.Errorf("%q. validate() error = %q, want %q", tt.name, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("%q. validate() = %+v, want %+v", tt.name, cfg, want)
+			}
+		})
+	}
+}
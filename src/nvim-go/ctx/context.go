@@ -8,8 +8,10 @@ import (
 	"go/build"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
+	"nvim-go/config"
 	"nvim-go/internal/pathutil"
 
 	"github.com/neovim/go-client/nvim"
@@ -20,8 +22,9 @@ type Context struct {
 	// Errlist map the nvim quickfix errors.
 	Errlist map[string][]*nvim.QuickfixError
 
-	prevDir string // for cache
-	m       sync.Mutex
+	prevDir  string // for cache
+	prevTags string
+	m        sync.Mutex
 
 	Buffer
 	Build
@@ -42,6 +45,19 @@ type Build struct {
 	// ProjectRoot package directory full path in the case of go project,
 	// GB_PROJECT_DIR in the case of gb project.
 	ProjectRoot string
+	// ModuleRoot directory full path which contains the go.mod file in the
+	// case of module mode go project, otherwise empty.
+	ModuleRoot string
+	// Env environment variables for the build tool, such as "GO111MODULE=on".
+	Env []string
+}
+
+// Environ returns the environment variables of the build tool command, which
+// is the process environment overridden by Env. Set it to exec.Cmd.Env
+// instead of changing the process environment, so that the commands of the
+// different build contexts can run concurrently.
+func (b *Build) Environ() []string {
+	return append(os.Environ(), b.Env...)
 }
 
 // NewContext return the Context type with initialize Context.Errlist.
@@ -52,39 +68,75 @@ func NewContext() *Context {
 }
 
 // buildContext return the new build context estimated from the path p directory structure.
-func buildContext(dir string, defaultContext build.Context) (string, string, build.Context) {
+// The build tags are config.BuildTags.
+func buildContext(dir string, defaultContext build.Context) (Build, build.Context) {
 	// copy context
 	buildContext := defaultContext
+	buildContext.BuildTags = config.BuildTags()
+
+	// Prefer the module mode if the go.mod file found walking up from dir.
+	if root, err := pathutil.FindModuleRoot(dir); err == nil {
+		return Build{
+			Tool:        "go",
+			ProjectRoot: root,
+			ModuleRoot:  root,
+			Env:         []string{"GO111MODULE=on"},
+		}, buildContext
+	}
 
 	// Default is go context
-	tool := "go"
+	b := Build{
+		Tool: "go",
+		Env:  []string{"GO111MODULE=off"},
+	}
 	// Assign package directory full path from dir
-	projectRoot, _ := pathutil.PackagePath(dir)
+	b.ProjectRoot, _ = pathutil.PackagePath(dir)
 
 	// Check whether the dir is Gb directory structure.
 	// If ok, append gb root and vendor path to the goPath lists.
 	if gbpath, ok := pathutil.IsGb(filepath.Clean(dir)); ok {
-		tool = "gb"
-		projectRoot = gbpath
+		b.Tool = "gb"
+		b.ProjectRoot = gbpath
 		buildContext.GOPATH = gbpath + string(filepath.ListSeparator) + filepath.Join(gbpath, "vendor")
+		b.Env = append(b.Env, "GOPATH="+buildContext.GOPATH)
 	}
 
-	return tool, projectRoot, buildContext
+	return b, buildContext
 }
 
-// SetContext sets the Tool, ProjectRoot, go/build.Default and $GOPATH to buildContext.
-// This function initializes for functions that use go/build.Default.
+// WorkDir returns the working directory of the build tool command for dir.
+// It is the module root in the case of module mode, the gb project root in
+// the case of gb project, otherwise dir.
+func (b *Build) WorkDir(dir string) string {
+	switch {
+	case b.ModuleRoot != "":
+		return b.ModuleRoot
+	case b.Tool == "gb":
+		return b.ProjectRoot
+	default:
+		return dir
+	}
+}
+
+// defaultContext is the go/build.Default at startup. Each build context is
+// derived from it, so the previous gb GOPATH is not inherited.
+var defaultContext = build.Default
+
+// SetContext sets the Tool, ProjectRoot, ModuleRoot, Env and go/build.Default
+// to the build context of dir. It does not change the environment variables,
+// the build tool commands use Build.Environ.
+// The build context is set again if config.BuildTags is changed.
 func (ctx *Context) SetContext(dir string) {
-	if dir != "" && ctx.prevDir != dir {
-		ctx.m.Lock()
-		defer ctx.m.Unlock()
+	ctx.m.Lock()
+	defer ctx.m.Unlock()
 
-		ctx.Build.Tool, ctx.Build.ProjectRoot, build.Default = buildContext(dir, build.Default)
+	tags := strings.Join(config.BuildTags(), ",")
+	if dir != "" && (ctx.prevDir != dir || ctx.prevTags != tags) {
+		ctx.Build, build.Default = buildContext(dir, defaultContext)
 		if ctx.Build.Tool == "gb" {
 			build.Default.JoinPath = ctx.Build.GbJoinPath
 		}
 		ctx.prevDir = dir
-
-		os.Setenv("GOPATH", build.Default.GOPATH)
+		ctx.prevTags = tags
 	}
 }
@@ -0,0 +1,123 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ctx
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeProjectTree creates the temporary module, GOPATH and gb projects.
+// It returns the temporary directory path and the cleanup function.
+func makeProjectTree(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "nvim-go-ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	dirs := []string{
+		filepath.Join(tmp, "mod", "foo"),
+		filepath.Join(tmp, "gopath", "src", "foo"),
+		filepath.Join(tmp, "gb", "src", "foo"),
+		filepath.Join(tmp, "gb", "vendor"),
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(tmp, "mod", "go.mod"):                  "module foo.org/mod\n",
+		filepath.Join(tmp, "mod", "foo", "foo.go"):           "package foo\n",
+		filepath.Join(tmp, "gopath", "src", "foo", "foo.go"): "package foo\n",
+		filepath.Join(tmp, "gb", "src", "foo", "foo.go"):     "package foo\n",
+	}
+	for fname, data := range files {
+		if err := ioutil.WriteFile(fname, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tmp, func() { os.RemoveAll(tmp) }
+}
+
+func TestBuildContext(t *testing.T) {
+	tmp, cleanup := makeProjectTree(t)
+	defer cleanup()
+
+	defaultContext := build.Default
+	defaultContext.GOPATH = filepath.Join(tmp, "gopath")
+
+	tests := []struct {
+		name       string
+		dir        string
+		want       Build
+		wantGopath string
+	}{
+		{
+			name: "module",
+			dir:  filepath.Join(tmp, "mod", "foo"),
+			want: Build{
+				Tool:        "go",
+				ProjectRoot: filepath.Join(tmp, "mod"),
+				ModuleRoot:  filepath.Join(tmp, "mod"),
+				Env:         []string{"GO111MODULE=on"},
+			},
+			wantGopath: filepath.Join(tmp, "gopath"),
+		},
+		{
+			name: "GOPATH",
+			dir:  filepath.Join(tmp, "gopath", "src", "foo"),
+			want: Build{
+				Tool:        "go",
+				ProjectRoot: filepath.Join(tmp, "gopath", "src", "foo"),
+				Env:         []string{"GO111MODULE=off"},
+			},
+			wantGopath: filepath.Join(tmp, "gopath"),
+		},
+		{
+			name: "gb",
+			dir:  filepath.Join(tmp, "gb", "src", "foo"),
+			want: Build{
+				Tool:        "gb",
+				ProjectRoot: filepath.Join(tmp, "gb"),
+				Env:         []string{"GO111MODULE=off", "GOPATH=" + filepath.Join(tmp, "gb") + string(filepath.ListSeparator) + filepath.Join(tmp, "gb", "vendor")},
+			},
+			wantGopath: filepath.Join(tmp, "gb") + string(filepath.ListSeparator) + filepath.Join(tmp, "gb", "vendor"),
+		},
+	}
+	for _, tt := range tests {
+		got, gotContext := buildContext(tt.dir, defaultContext)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. buildContext(%v) = %+v, want %+v", tt.name, tt.dir, got, tt.want)
+		}
+		if gotContext.GOPATH != tt.wantGopath {
+			t.Errorf("%q. buildContext(%v) GOPATH = %v, want %v", tt.name, tt.dir, gotContext.GOPATH, tt.wantGopath)
+		}
+	}
+}
+
+func TestBuild_WorkDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		build Build
+		dir   string
+		want  string
+	}{
+		{name: "module", build: Build{Tool: "go", ModuleRoot: "/mod"}, dir: "/mod/foo", want: "/mod"},
+		{name: "GOPATH", build: Build{Tool: "go", ProjectRoot: "/go/src/foo"}, dir: "/go/src/foo/bar", want: "/go/src/foo/bar"},
+		{name: "gb", build: Build{Tool: "gb", ProjectRoot: "/gb"}, dir: "/gb/src/foo", want: "/gb"},
+	}
+	for _, tt := range tests {
+		if got := tt.build.WorkDir(tt.dir); got != tt.want {
+			t.Errorf("%q. WorkDir(%v) = %v, want %v", tt.name, tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guru
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Cache caches the loaded program of the pointer analysis scope, and reuses it
+// across the callers, callstack and pointsto queries.
+//
+// The cache is keyed by the scope, build context such as the GOPATH, GOOS,
+// GOARCH and build tags, and hash of the unsaved file set.
+type Cache struct {
+	mu    sync.Mutex
+	key   string
+	lprog *loader.Program
+}
+
+// Invalidate discards the cached program.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.key = ""
+	c.lprog = nil
+	c.mu.Unlock()
+}
+
+// cacheKey returns the cache key of q.
+func cacheKey(q *Query) string {
+	b := q.Build
+	return strings.Join([]string{
+		strings.Join(q.Scope, ","),
+		b.GOROOT,
+		b.GOPATH,
+		b.GOOS,
+		b.GOARCH,
+		strconv.FormatBool(b.CgoEnabled),
+		b.InstallSuffix,
+		strings.Join(b.BuildTags, ","),
+		strings.Join(b.ReleaseTags, ","),
+		q.FileHash,
+	}, "|")
+}
+
+// loadPTAProgram loads the program of the pointer analysis scope with
+// loadWithSoftErrors, or returns the cached program if q.Cache has the same key.
+func loadPTAProgram(q *Query, lconf *loader.Config) (*loader.Program, error) {
+	if q.Cache == nil {
+		return loadWithSoftErrors(lconf)
+	}
+
+	// Hold the lock while loading so that concurrent queries wait for the
+	// result instead of loading the same program twice.
+	q.Cache.mu.Lock()
+	defer q.Cache.mu.Unlock()
+
+	key := cacheKey(q)
+	if q.Cache.lprog != nil && q.Cache.key == key {
+		return q.Cache.lprog, nil
+	}
+
+	lprog, err := loadWithSoftErrors(lconf)
+	if err != nil {
+		return nil, err
+	}
+	q.Cache.key = key
+	q.Cache.lprog = lprog
+
+	return lprog, nil
+}
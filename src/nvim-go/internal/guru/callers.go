@@ -27,7 +27,7 @@ func callers(q *Query) error {
 	}
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadPTAProgram(q, &lconf)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,138 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guru
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/static"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Callgraph is the result of the callgraph query.
+type Callgraph struct {
+	Root  string          `json:"root"`
+	Edges []CallgraphEdge `json:"edges,omitempty"`
+}
+
+// CallgraphEdge is a call from the Caller function to the Callee function.
+type CallgraphEdge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+	Pos    string `json:"pos"` // the first call site
+}
+
+// callgraphQuery reports the static calls reachable from the function
+// immediately enclosing the specified source location, up to q.Depth
+// calls deep. The query package is loaded with the scope packages if any.
+func callgraphQuery(q *Query) error {
+	lconf := loader.Config{Build: q.Build}
+
+	if len(q.Scope) > 0 {
+		if err := setPTAScope(&lconf, q.Scope); err != nil {
+			return err
+		}
+	}
+	if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
+		return err
+	}
+
+	// Load/parse/type-check the program.
+	lprog, err := loadWithSoftErrors(&lconf)
+	if err != nil {
+		return err
+	}
+
+	qpos, err := parseQueryPos(lprog, q.Pos, false)
+	if err != nil {
+		return err
+	}
+
+	prog := ssautil.CreateProgram(lprog, 0)
+	pkg := prog.Package(qpos.info.Pkg)
+	if pkg == nil {
+		return fmt.Errorf("no SSA package")
+	}
+	if !ssa.HasEnclosingFunction(pkg, qpos.path) {
+		return fmt.Errorf("this position is not inside a function")
+	}
+	prog.Build()
+
+	root := ssa.EnclosingFunction(pkg, qpos.path)
+	if root == nil {
+		return fmt.Errorf("no SSA function built for this location (dead code?)")
+	}
+
+	cg := static.CallGraph(prog)
+	cg.DeleteSyntheticNodes()
+
+	q.Output(lprog.Fset, &callgraphResult{
+		root:  root,
+		edges: callgraphEdges(cg.CreateNode(root), q.Depth),
+	})
+	return nil
+}
+
+// callgraphEdges returns the unique caller and callee edges reachable from
+// the root node in breadth-first order, up to depth calls deep. Zero depth
+// is unlimited.
+func callgraphEdges(root *callgraph.Node, depth int) []*callgraph.Edge {
+	var edges []*callgraph.Edge
+	seen := map[*callgraph.Node]bool{root: true}
+	queue := []*callgraph.Node{root}
+	for level := 0; len(queue) > 0 && (depth <= 0 || level < depth); level++ {
+		var next []*callgraph.Node
+		for _, n := range queue {
+			out := append([]*callgraph.Edge(nil), n.Out...)
+			sort.SliceStable(out, func(i, j int) bool { return out[i].Pos() < out[j].Pos() })
+
+			called := make(map[*callgraph.Node]bool)
+			for _, e := range out {
+				if called[e.Callee] {
+					continue
+				}
+				called[e.Callee] = true
+				edges = append(edges, e)
+				if !seen[e.Callee] {
+					seen[e.Callee] = true
+					next = append(next, e.Callee)
+				}
+			}
+		}
+		queue = next
+	}
+	return edges
+}
+
+type callgraphResult struct {
+	root  *ssa.Function
+	edges []*callgraph.Edge
+}
+
+// funcName returns the name of fn relative to the root package.
+func (r *callgraphResult) funcName(fn *ssa.Function) string {
+	var from *types.Package
+	if r.root.Pkg != nil {
+		from = r.root.Pkg.Pkg
+	}
+	return fn.RelString(from)
+}
+
+func (r *callgraphResult) PrintPlain(printf printfFunc) {
+	printf(r.root, "%s calls these %d functions:", r.funcName(r.root), len(r.edges))
+	for _, e := range r.edges {
+		printf(e, "\t%s -> %s", r.funcName(e.Caller.Func), r.funcName(e.Callee.Func))
+	}
+}
+
+func (r *callgraphResult) JSON(fset *token.FileSet) []byte {
+	return toJSON(r.Result(fset))
+}
@@ -77,6 +77,13 @@ type Query struct {
 	PTALog     io.Writer // (optional) pointer-analysis log file
 	Reflection bool      // model reflection soundly (currently slow).
 
+	// callgraph options
+	Depth int // (optional) maximum depth of the calls, or zero for unlimited
+
+	// program cache options
+	Cache    *Cache // (optional) cache of the loaded pointer analysis program
+	FileHash string // hash of the unsaved file set, part of the Cache key
+
 	// result-printing function
 	Output func(*token.FileSet, QueryResult)
 }
@@ -88,6 +95,8 @@ func Run(mode string, q *Query) error {
 		return callees(q)
 	case "callers":
 		return callers(q)
+	case "callgraph":
+		return callgraphQuery(q)
 	case "callstack":
 		return callstack(q)
 	case "peers":
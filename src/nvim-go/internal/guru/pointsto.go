@@ -34,7 +34,7 @@ func pointsto(q *Query) error {
 	}
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadPTAProgram(q, &lconf)
 	if err != nil {
 		return err
 	}
@@ -55,6 +55,19 @@ func (r *callersResult) Result(fset *token.FileSet) interface{} {
 	return callers
 }
 
+// callgraph
+func (r *callgraphResult) Result(fset *token.FileSet) interface{} {
+	j := &Callgraph{Root: r.funcName(r.root)}
+	for _, e := range r.edges {
+		j.Edges = append(j.Edges, CallgraphEdge{
+			Caller: r.funcName(e.Caller.Func),
+			Callee: r.funcName(e.Callee.Func),
+			Pos:    fset.Position(e.Pos()).String(),
+		})
+	}
+	return j
+}
+
 // callstack
 func (r *callstackResult) Result(fset *token.FileSet) interface{} {
 	var callers []serial.Caller
@@ -0,0 +1,22 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FindDepRoot finds the dep project root path which is the nearest ancestor
+// of dir containing the Gopkg.toml file.
+func FindDepRoot(dir string) (string, error) {
+	root, ok := findRoot(filepath.Clean(dir), "Gopkg.toml")
+	if !ok {
+		return "", errors.New("couldn't find the Gopkg.toml file")
+	}
+
+	return root, nil
+}
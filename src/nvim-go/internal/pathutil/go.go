@@ -6,9 +6,12 @@ package pathutil
 
 import (
 	"go/build"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sync/syncmap"
 )
 
 // parsePackage search the parent directory of dir with the recursive loop.
@@ -71,15 +74,75 @@ func PackagePath(dir string) (string, error) {
 	return pkg.Dir, nil
 }
 
+// pkgIDCache caches the PackageID result per GOPATH and directory.
+var pkgIDCache syncmap.Map
+
+// ClearPackageIDCache clears the cached PackageID results, so the next
+// PackageID follows the added or moved packages and the go.mod changes.
+func ClearPackageIDCache() {
+	pkgIDCache.Range(func(key, _ interface{}) bool {
+		pkgIDCache.Delete(key)
+		return true
+	})
+}
+
 // PackageID returns the package ID(ImportPath) estimated from the dir
 // directory structure.
+// If the package is in the module, returns the import path based on the
+// module path of go.mod. The result is cached per directory until
+// ClearPackageIDCache.
 // like:
 //  return "github.com/pkg/errors", nil
 func PackageID(dir string) (string, error) {
+	key := build.Default.GOPATH + string(filepath.ListSeparator) + filepath.Clean(dir)
+	if id, ok := pkgIDCache.Load(key); ok {
+		return id.(string), nil
+	}
+
 	pkg, err := parsePackage(dir)
 	if err != nil {
 		return "", err
 	}
 
-	return pkg.ImportPath, nil
+	id := pkg.ImportPath
+	if root, err := FindModuleRoot(pkg.Dir); err == nil {
+		modpath, err := ModulePath(root)
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(root, pkg.Dir)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		id = path.Join(modpath, filepath.ToSlash(rel))
+	}
+
+	pkgIDCache.Store(key, id)
+	return id, nil
+}
+
+// ImportPathFromDir returns the import path of dir estimated from the src
+// directory of gopath, which is a list of GOPATH trees like build.Default.GOPATH.
+// If dir is not in any gopath trees, falls back to the path after the first "src"
+// directory element of dir.
+// like:
+//  return "github.com/foo/src/bar", nil
+func ImportPathFromDir(gopath, dir string) (string, error) {
+	dir = filepath.Clean(dir)
+
+	for _, p := range filepath.SplitList(gopath) {
+		src := filepath.Join(p, "src")
+		if rel, err := filepath.Rel(src, dir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel), nil
+		}
+	}
+
+	elems := strings.Split(filepath.ToSlash(dir), "/")
+	for i, elem := range elems {
+		if elem == "src" && i+1 < len(elems) {
+			return strings.Join(elems[i+1:], "/"), nil
+		}
+	}
+
+	return "", errors.Errorf("couldn't estimate the import path of %s", dir)
 }
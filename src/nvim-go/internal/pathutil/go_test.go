@@ -6,7 +6,9 @@ package pathutil_test
 
 import (
 	"go/build"
+	"io/ioutil"
 	"nvim-go/internal/pathutil"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -132,6 +134,24 @@ func TestPackageID(t *testing.T) {
 			want:    filepath.Join("foo.org", "foo", "bar", "baz", "qux"),
 			wantErr: false,
 		},
+		{
+			name:    "vendored package",
+			args:    args{dir: filepath.Join(gopath, "src", "foo.org", "vendored", "vendor", "bar.org", "bar")},
+			want:    "foo.org/vendored/vendor/bar.org/bar",
+			wantErr: false,
+		},
+		{
+			name:    "module root package",
+			args:    args{dir: filepath.Join("testdata", "mod")},
+			want:    "example.com/mod",
+			wantErr: false,
+		},
+		{
+			name:    "module sub package",
+			args:    args{dir: filepath.Join("testdata", "mod", "sub")},
+			want:    "example.com/mod/sub",
+			wantErr: false,
+		},
 		{
 			name:    "no such file or directory",
 			args:    args{dir: filepath.Join("nosuch", "src", "foo.org", "notexists")},
@@ -168,3 +188,104 @@ func TestPackageID(t *testing.T) {
 		})
 	}
 }
+
+func TestClearPackageIDCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-pathutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	writeFile := func(name, src string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("go.mod", "module example.com/foo\n")
+	writeFile("foo.go", "package foo\n")
+
+	if got, err := pathutil.PackageID(dir); err != nil || got != "example.com/foo" {
+		t.Fatalf("PackageID(%v) = %v, %v, want %v", dir, got, err, "example.com/foo")
+	}
+
+	// the cached ID is used until the cache cleared
+	writeFile("go.mod", "module example.com/bar\n")
+	if got, _ := pathutil.PackageID(dir); got != "example.com/foo" {
+		t.Errorf("PackageID(%v) before cleared = %v, want %v", dir, got, "example.com/foo")
+	}
+	pathutil.ClearPackageIDCache()
+	if got, _ := pathutil.PackageID(dir); got != "example.com/bar" {
+		t.Errorf("PackageID(%v) after cleared = %v, want %v", dir, got, "example.com/bar")
+	}
+}
+
+func TestImportPathFromDir(t *testing.T) {
+	type args struct {
+		gopath string
+		dir    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "typical package",
+			args:    args{gopath: "/go", dir: "/go/src/github.com/foo/bar"},
+			want:    "github.com/foo/bar",
+			wantErr: false,
+		},
+		{
+			name:    "vendored package",
+			args:    args{gopath: "/go", dir: "/go/src/github.com/foo/bar/vendor/github.com/baz/qux"},
+			want:    "github.com/foo/bar/vendor/github.com/baz/qux",
+			wantErr: false,
+		},
+		{
+			name:    "nested src directory",
+			args:    args{gopath: "/go", dir: "/go/src/github.com/foo/src/bar"},
+			want:    "github.com/foo/src/bar",
+			wantErr: false,
+		},
+		{
+			name:    "src directory in GOPATH",
+			args:    args{gopath: "/home/src/go", dir: "/home/src/go/src/github.com/foo/bar"},
+			want:    "github.com/foo/bar",
+			wantErr: false,
+		},
+		{
+			name:    "multiple GOPATH",
+			args:    args{gopath: "/go" + string(filepath.ListSeparator) + "/work", dir: "/work/src/foo.org/bar"},
+			want:    "foo.org/bar",
+			wantErr: false,
+		},
+		{
+			name:    "not in GOPATH (use src heuristic)",
+			args:    args{gopath: "/go", dir: "/work/src/foo.org/src/bar"},
+			want:    "foo.org/src/bar",
+			wantErr: false,
+		},
+		{
+			name:    "no src directory",
+			args:    args{gopath: "/go", dir: "/work/foo.org/bar"},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := pathutil.ImportPathFromDir(tt.args.gopath, tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ImportPathFromDir(%v, %v) error = %v, wantErr %v", tt.args.gopath, tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ImportPathFromDir(%v, %v) = %v, want %v", tt.args.gopath, tt.args.dir, got, tt.want)
+			}
+		})
+	}
+}
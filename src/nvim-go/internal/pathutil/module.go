@@ -0,0 +1,49 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+var moduleRe = regexp.MustCompile(`(?m)^\s*module\s+"?([^"\s]+)"?`)
+
+// FindModuleRoot finds the module root path which is the nearest ancestor of
+// dir containing the go.mod file.
+func FindModuleRoot(dir string) (string, error) {
+	root, ok := findRoot(filepath.Clean(dir), "go.mod")
+	if !ok {
+		return "", errors.New("couldn't find the go.mod file")
+	}
+
+	return root, nil
+}
+
+// ModulePath returns the module path declared in the go.mod file which found
+// by searching upwards from dir.
+// like:
+//  return "github.com/pkg/errors", nil
+func ModulePath(dir string) (string, error) {
+	root, err := FindModuleRoot(dir)
+	if err != nil {
+		return "", err
+	}
+
+	gomod := filepath.Join(root, "go.mod")
+	data, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	m := moduleRe.FindSubmatch(data)
+	if m == nil {
+		return "", errors.Errorf("couldn't find the module directive in %s", gomod)
+	}
+
+	return string(m[1]), nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nvim-go/internal/pathutil"
+)
+
+func TestModulePath(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nvim-go-module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	mod := filepath.Join(tmp, "mod")
+	nested := filepath.Join(mod, "foo", "bar")
+	quoted := filepath.Join(tmp, "quoted")
+	nomodule := filepath.Join(tmp, "nomodule")
+	for _, dir := range []string{nested, quoted, nomodule} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(mod, "go.mod"):      "module github.com/foo/mod\n\nrequire github.com/pkg/errors v0.8.0\n",
+		filepath.Join(quoted, "go.mod"):   "// comment\nmodule \"foo.org/quoted\" // comment\n",
+		filepath.Join(nomodule, "go.mod"): "require github.com/pkg/errors v0.8.0\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "module root",
+			args:    args{dir: mod},
+			want:    "github.com/foo/mod",
+			wantErr: false,
+		},
+		{
+			name:    "nested directory of module",
+			args:    args{dir: nested},
+			want:    "github.com/foo/mod",
+			wantErr: false,
+		},
+		{
+			name:    "quoted module path",
+			args:    args{dir: quoted},
+			want:    "foo.org/quoted",
+			wantErr: false,
+		},
+		{
+			name:    "no module directive",
+			args:    args{dir: nomodule},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathutil.ModulePath(tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ModulePath(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ModulePath(%v) = %v, want %v", tt.args.dir, got, tt.want)
+			}
+		})
+	}
+}
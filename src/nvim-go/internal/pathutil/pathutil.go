@@ -13,25 +13,45 @@ import (
 	"sync"
 
 	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
 )
 
 var pkgPathutil = "pathutil"
 
+// chdirMu serializes the vim current working directory changes across the commands.
+var chdirMu sync.Mutex
+
+// dirChanger is the subset of *nvim.Nvim methods used by chdir.
+type dirChanger interface {
+	Eval(expr string, result interface{}) error
+	SetCurrentDirectory(dir string) error
+}
+
 // Chdir changes the vim current working directory.
+// It holds the package level lock until the returned function is called.
 // The returned function restores working directory to `getcwd()` result path
 // and unlocks the mutex.
-func Chdir(v *nvim.Nvim, dir string) func() {
-	var (
-		m   sync.Mutex
-		cwd interface{}
-	)
-	m.Lock()
-	v.Eval("getcwd()", &cwd)
-	v.SetCurrentDirectory(dir)
-	return func() {
-		v.SetCurrentDirectory(cwd.(string))
-		m.Unlock()
+func Chdir(v *nvim.Nvim, dir string) (func(), error) {
+	return chdir(v, dir)
+}
+
+func chdir(v dirChanger, dir string) (func(), error) {
+	chdirMu.Lock()
+
+	var cwd string
+	if err := v.Eval("getcwd()", &cwd); err != nil {
+		chdirMu.Unlock()
+		return nil, errors.WithStack(err)
 	}
+	if err := v.SetCurrentDirectory(dir); err != nil {
+		chdirMu.Unlock()
+		return nil, errors.WithStack(err)
+	}
+
+	return func() {
+		v.SetCurrentDirectory(cwd)
+		chdirMu.Unlock()
+	}, nil
 }
 
 // TrimGoPath trims the GOPATH and {bin,pkg,src}, basically for the converts
@@ -61,8 +81,28 @@ func ShortFilePath(p, cwd string) string {
 	return strings.Replace(p, cwd, ".", 1)
 }
 
+// caseInsensitive whether the filesystem of the platform is case-insensitive by default.
+var caseInsensitive = runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+
 // Rel wrapper of filepath.Rel function that return only one variable.
+// Returns f as is if f can't be made relative to cwd, such as on different volumes.
 func Rel(cwd, f string) string {
+	return rel(cwd, f, caseInsensitive)
+}
+
+// rel computes the relative path of f from cwd. If fold is true, the cwd
+// prefix of f is compared case-insensitively.
+func rel(cwd, f string, fold bool) string {
+	cwd, f = filepath.Clean(cwd), filepath.Clean(f)
+
+	if fold && len(f) >= len(cwd) && strings.EqualFold(f[:len(cwd)], cwd) {
+		// normalize the case of f prefix to cwd
+		f = cwd + f[len(cwd):]
+	}
+	if !strings.EqualFold(filepath.VolumeName(cwd), filepath.VolumeName(f)) {
+		return f
+	}
+
 	rel, err := filepath.Rel(cwd, f)
 	if err != nil {
 		return f
@@ -70,13 +110,28 @@ func Rel(cwd, f string) string {
 	return rel
 }
 
-// ExpandGoRoot expands the "$GOROOT" include from p.
+// Abs returns the absolute path of f which is relative to cwd. Returns f as is
+// if f is already absolute.
+func Abs(cwd, f string) string {
+	if filepath.IsAbs(f) {
+		return f
+	}
+	return filepath.Join(cwd, f)
+}
+
+// ExpandGoRoot expands the "$GOROOT" and "$GOPATH" include from p.
+// The "$GOPATH" is expanded to the first GOPATH entry.
 func ExpandGoRoot(p string) string {
-	if strings.Index(p, "$GOROOT") != -1 {
-		return strings.Replace(p, "$GOROOT", runtime.GOROOT(), 1)
+	if strings.Contains(p, "$GOROOT") {
+		p = strings.Replace(p, "$GOROOT", runtime.GOROOT(), 1)
+	}
+	if strings.Contains(p, "$GOPATH") {
+		if gopath := filepath.SplitList(build.Default.GOPATH); len(gopath) > 0 {
+			p = strings.Replace(p, "$GOPATH", gopath[0], 1)
+		}
 	}
 
-	return p // Not hit
+	return p
 }
 
 // IsDir returns whether the filename is directory.
@@ -0,0 +1,146 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDirChanger is a fake of the vim current working directory.
+type fakeDirChanger struct {
+	mu  sync.Mutex
+	cwd string
+}
+
+func (f *fakeDirChanger) Eval(expr string, result interface{}) error {
+	f.mu.Lock()
+	*(result.(*string)) = f.cwd
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeDirChanger) SetCurrentDirectory(dir string) error {
+	f.mu.Lock()
+	f.cwd = dir
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeDirChanger) getcwd() string {
+	var cwd string
+	f.Eval("getcwd()", &cwd)
+	return cwd
+}
+
+func TestChdirConcurrent(t *testing.T) {
+	v := &fakeDirChanger{cwd: "/root"}
+	dirs := []string{"/foo", "/bar"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, dir := range dirs {
+			wg.Add(1)
+			go func(dir string) {
+				defer wg.Done()
+
+				restore, err := chdir(v, dir)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer restore()
+
+				// the other goroutine must not change the directory while holding the lock
+				time.Sleep(time.Microsecond)
+				if got := v.getcwd(); got != dir {
+					t.Errorf("chdir(%v): getcwd() = %v, want %v", dir, got, dir)
+				}
+			}(dir)
+		}
+	}
+	wg.Wait()
+
+	if got := v.getcwd(); got != "/root" {
+		t.Errorf("getcwd() after restore = %v, want %v", got, "/root")
+	}
+}
+
+func TestRelFold(t *testing.T) {
+	type args struct {
+		cwd  string
+		f    string
+		fold bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		windows bool // only run on windows
+	}{
+		{
+			name: "mixed-case prefix (case-insensitive)",
+			args: args{
+				cwd:  filepath.FromSlash("/Users/Foo/go/src/foo.org/foo"),
+				f:    filepath.FromSlash("/users/foo/go/src/foo.org/foo/bar/bar.go"),
+				fold: true,
+			},
+			want: filepath.FromSlash("bar/bar.go"),
+		},
+		{
+			name: "mixed-case prefix (case-sensitive)",
+			args: args{
+				cwd:  filepath.FromSlash("/Users/Foo/go"),
+				f:    filepath.FromSlash("/users/foo/go/foo.go"),
+				fold: false,
+			},
+			want: filepath.FromSlash("../../../users/foo/go/foo.go"),
+		},
+		{
+			name: "unclean paths",
+			args: args{
+				cwd:  filepath.FromSlash("/Users/Foo/go/src/"),
+				f:    filepath.FromSlash("/users/foo/go/src/./foo.org/foo.go"),
+				fold: true,
+			},
+			want: filepath.FromSlash("foo.org/foo.go"),
+		},
+		{
+			name: "drive letter case",
+			args: args{
+				cwd:  `C:\Users\foo\go`,
+				f:    `c:\users\foo\go\src\foo.go`,
+				fold: true,
+			},
+			want:    `src\foo.go`,
+			windows: true,
+		},
+		{
+			name: "different volumes (use absolute path)",
+			args: args{
+				cwd:  `C:\Users\foo\go`,
+				f:    `D:\go\src\foo.go`,
+				fold: true,
+			},
+			want:    `D:\go\src\foo.go`,
+			windows: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.windows && runtime.GOOS != "windows" {
+				t.Skip("windows only")
+			}
+			t.Parallel()
+			if got := rel(tt.args.cwd, tt.args.f, tt.args.fold); got != tt.want {
+				t.Errorf("%q. rel(%v, %v, %v) = %v, want %v", tt.name, tt.args.cwd, tt.args.f, tt.args.fold, got, tt.want)
+			}
+		})
+	}
+}
@@ -56,7 +56,11 @@ func TestChdir(t *testing.T) {
 					t.Errorf("%q. Chdir(%v, %v) = %v, want %v", tt.name, tt.args.v, tt.args.dir, testCwd, tt.wantCwd)
 				}
 			}()
-			defer pathutil.Chdir(tt.args.v, tt.args.dir)()
+			restore, err := pathutil.Chdir(tt.args.v, tt.args.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer restore()
 			var ccwd interface{}
 			tt.args.v.Eval("getcwd()", &ccwd)
 			if ccwd.(string) != tt.wantCwd {
@@ -183,6 +187,33 @@ func TestShortFilePath(t *testing.T) {
 	}
 }
 
+func TestAbs(t *testing.T) {
+	tests := []struct {
+		name string
+		cwd  string
+		f    string
+		want string
+	}{
+		{
+			name: "relative",
+			cwd:  testCwd,
+			f:    "pathutil_test.go",
+			want: filepath.Join(testCwd, "pathutil_test.go"),
+		},
+		{
+			name: "absolute",
+			cwd:  filepath.Join(testCwd, "testdata"),
+			f:    filepath.Join(testCwd, "pathutil_test.go"),
+			want: filepath.Join(testCwd, "pathutil_test.go"),
+		},
+	}
+	for _, tt := range tests {
+		if got := pathutil.Abs(tt.cwd, tt.f); got != tt.want {
+			t.Errorf("%q. Abs(%v, %v) = %v, want %v", tt.name, tt.cwd, tt.f, got, tt.want)
+		}
+	}
+}
+
 func TestRel(t *testing.T) {
 	type args struct {
 		f   string
@@ -239,6 +270,7 @@ func TestRel(t *testing.T) {
 
 func TestExpandGoRoot(t *testing.T) {
 	goroot := runtime.GOROOT()
+	gopath := filepath.SplitList(build.Default.GOPATH)[0]
 
 	type args struct {
 		p string
@@ -253,11 +285,26 @@ func TestExpandGoRoot(t *testing.T) {
 			args: args{p: "$GOROOT/src/go/ast/ast.go"},
 			want: filepath.Join(goroot, "src/go/ast/ast.go"),
 		},
+		{
+			name: "$GOROOT in the middle",
+			args: args{p: "-I $GOROOT/src/go/ast"},
+			want: "-I " + filepath.Join(goroot, "src/go/ast"),
+		},
 		{
 			name: "not exist $GOROOT",
 			args: args{p: "src/go/ast/ast.go"},
 			want: "src/go/ast/ast.go",
 		},
+		{
+			name: "exist $GOPATH",
+			args: args{p: "$GOPATH/src/foo.org/foo/foo.go"},
+			want: filepath.Join(gopath, "src/foo.org/foo/foo.go"),
+		},
+		{
+			name: "$GOPATH in the middle",
+			args: args{p: "-I $GOPATH/src/foo.org/foo"},
+			want: "-I " + filepath.Join(gopath, "src/foo.org/foo"),
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
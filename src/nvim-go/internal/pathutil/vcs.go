@@ -4,41 +4,43 @@
 
 package pathutil
 
-import (
-	"os"
-	"path/filepath"
-)
-
-var vcsDirs = []string{".git", ".svn", ".hg"}
+import "path/filepath"
+
+// rootMarkers list of the file or directory name which identifies the project root.
+var rootMarkers = []string{
+	".git",
+	".svn",
+	".hg",
+	".bzr",
+	".fossil", ".fslckout", "_FOSSIL_",
+	"go.mod",
+}
 
-// FindVCSRoot find package root path from arg path
+// FindVCSRoot finds the project root path which is the nearest ancestor of
+// basedir containing any of the VCS directory or go.mod file.
+// Returns the cleaned basedir if not found.
 func FindVCSRoot(basedir string) string {
-	var foundVCSDir bool
+	basedir = filepath.Clean(basedir)
+	if root, ok := findRoot(basedir, rootMarkers...); ok {
+		return root
+	}
 
-	findvcsDirWalkFunc := func(path string, info os.FileInfo, err error) error {
-		if err != nil || info == nil || info.IsDir() == false {
-			return nil
-		}
+	return basedir
+}
 
-		for _, d := range vcsDirs {
-			_, err := os.Stat(filepath.Join(path, d))
-			if err == nil {
-				foundVCSDir = true
-				break
+// findRoot works upwards from dir searching for the directory which contains any of markers.
+func findRoot(dir string, markers ...string) (string, bool) {
+	for {
+		for _, m := range markers {
+			if IsExist(filepath.Join(dir, m)) {
+				return dir, true
 			}
 		}
 
-		return nil
-	}
-
-	for {
-		filepath.Walk(basedir, findvcsDirWalkFunc)
-		if !foundVCSDir {
-			basedir = filepath.Dir(basedir)
-			continue
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
 		}
-		break
+		dir = parent
 	}
-
-	return filepath.Clean(basedir)
 }
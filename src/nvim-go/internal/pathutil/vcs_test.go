@@ -5,6 +5,9 @@
 package pathutil_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"nvim-go/internal/pathutil"
@@ -31,3 +34,148 @@ func TestFindVCSRoot(t *testing.T) {
 		}
 	}
 }
+
+// makeRootTree creates the temporary directory tree for root marker tests.
+// It returns the temporary directory path and the cleanup function.
+func makeRootTree(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "nvim-go-vcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp, _ = filepath.EvalSymlinks(tmp)
+
+	dirs := []string{
+		filepath.Join(tmp, "git", ".git"),
+		filepath.Join(tmp, "git", "foo", "bar"),
+		filepath.Join(tmp, "hg", ".hg"),
+		filepath.Join(tmp, "hg", "foo"),
+		filepath.Join(tmp, "bzr", ".bzr"),
+		filepath.Join(tmp, "bzr", "foo"),
+		filepath.Join(tmp, "fossil", "foo"),
+		filepath.Join(tmp, "mod", "foo", "bar"),
+		filepath.Join(tmp, "git", "mod", "foo"),
+		filepath.Join(tmp, "none", "foo"),
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []string{
+		filepath.Join(tmp, "fossil", ".fossil"),
+		filepath.Join(tmp, "mod", "go.mod"),
+		filepath.Join(tmp, "git", "mod", "go.mod"),
+	}
+	for _, file := range files {
+		if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tmp, func() { os.RemoveAll(tmp) }
+}
+
+func TestFindVCSRoot_Markers(t *testing.T) {
+	tmp, cleanup := makeRootTree(t)
+	defer cleanup()
+
+	type args struct {
+		basedir string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "git",
+			args: args{basedir: filepath.Join(tmp, "git", "foo", "bar")},
+			want: filepath.Join(tmp, "git"),
+		},
+		{
+			name: "mercurial",
+			args: args{basedir: filepath.Join(tmp, "hg", "foo")},
+			want: filepath.Join(tmp, "hg"),
+		},
+		{
+			name: "bazaar",
+			args: args{basedir: filepath.Join(tmp, "bzr", "foo")},
+			want: filepath.Join(tmp, "bzr"),
+		},
+		{
+			name: "fossil",
+			args: args{basedir: filepath.Join(tmp, "fossil", "foo")},
+			want: filepath.Join(tmp, "fossil"),
+		},
+		{
+			name: "go.mod",
+			args: args{basedir: filepath.Join(tmp, "mod", "foo", "bar")},
+			want: filepath.Join(tmp, "mod"),
+		},
+		{
+			name: "nearest ancestor (go.mod in git repository)",
+			args: args{basedir: filepath.Join(tmp, "git", "mod", "foo")},
+			want: filepath.Join(tmp, "git", "mod"),
+		},
+		{
+			name: "not found (use basedir)",
+			args: args{basedir: filepath.Join(tmp, "none", "foo")},
+			want: filepath.Join(tmp, "none", "foo"),
+		},
+	}
+	for _, tt := range tests {
+		if got := pathutil.FindVCSRoot(tt.args.basedir); got != tt.want {
+			t.Errorf("%q. FindVCSRoot(%v) = %v, want %v", tt.name, tt.args.basedir, got, tt.want)
+		}
+	}
+}
+
+func TestFindModuleRoot(t *testing.T) {
+	tmp, cleanup := makeRootTree(t)
+	defer cleanup()
+
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "module root",
+			args:    args{dir: filepath.Join(tmp, "mod")},
+			want:    filepath.Join(tmp, "mod"),
+			wantErr: false,
+		},
+		{
+			name:    "nested directory of module",
+			args:    args{dir: filepath.Join(tmp, "mod", "foo", "bar")},
+			want:    filepath.Join(tmp, "mod"),
+			wantErr: false,
+		},
+		{
+			name:    "module in git repository",
+			args:    args{dir: filepath.Join(tmp, "git", "mod", "foo")},
+			want:    filepath.Join(tmp, "git", "mod"),
+			wantErr: false,
+		},
+		{
+			name:    "git repository without go.mod",
+			args:    args{dir: filepath.Join(tmp, "git", "foo")},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := pathutil.FindModuleRoot(tt.args.dir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. FindModuleRoot(%v) error = %v, wantErr %v", tt.name, tt.args.dir, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. FindModuleRoot(%v) = %v, want %v", tt.name, tt.args.dir, got, tt.want)
+		}
+	}
+}
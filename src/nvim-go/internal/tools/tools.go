@@ -0,0 +1,133 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tools checks the availability of the external tools which the
+// nvim-go commands shell out to. The guru is built in nvim-go, so it's not
+// the external tool.
+package tools
+
+import (
+	"os/exec"
+	"sync"
+)
+
+// Tool represents an external tool.
+type Tool struct {
+	Name    string
+	Install string   // the command to install the tool
+	Cmds    []string // the nvim-go commands which require the tool
+}
+
+// Tools is the external tools which the nvim-go commands require.
+var Tools = []Tool{
+	{Name: "benchstat", Install: "go get golang.org/x/perf/cmd/benchstat", Cmds: []string{"GoBench"}},
+	{Name: "dep", Install: "go get github.com/golang/dep/cmd/dep", Cmds: []string{"GoVendorAdd", "GoVendorList", "GoVendorUpdate"}},
+	{Name: "dlv", Install: "go get github.com/derekparker/delve/cmd/dlv", Cmds: []string{"DlvConnect", "DlvDebug"}},
+	{Name: "dot", Install: "install the graphviz package of your system", Cmds: []string{"GoCallgraph"}},
+	{Name: "errcheck", Install: "go get github.com/kisielk/errcheck", Cmds: []string{"GoErrcheck"}},
+	{Name: "gb-vendor", Install: "go get github.com/constabulary/gb/...", Cmds: []string{"GoVendorAdd", "GoVendorList", "GoVendorUpdate"}},
+	{Name: "godef", Install: "go get github.com/rogpeppe/godef", Cmds: []string{"GoDef"}},
+	{Name: "gometalinter", Install: "go get github.com/alecthomas/gometalinter && gometalinter --install", Cmds: []string{"Gometalinter"}},
+	{Name: "gopls", Install: "go get golang.org/x/tools/cmd/gopls", Cmds: []string{"GoDef"}},
+	{Name: "mockgen", Install: "go get github.com/golang/mock/mockgen", Cmds: []string{"GoAlternateMock"}},
+	{Name: "staticcheck", Install: "go get honnef.co/go/tools/cmd/staticcheck", Cmds: []string{"GoStaticcheck"}},
+}
+
+// lookPath is exec.LookPath, replaced by the tests.
+var lookPath = exec.LookPath
+
+var (
+	mu sync.Mutex
+	// paths is the cached path of each tool, or the empty string if missing.
+	paths = make(map[string]string)
+	// notified is the reported "command tool" pairs of Require.
+	notified = make(map[string]bool)
+)
+
+// MissingError represents the error of the tool is not installed.
+type MissingError struct {
+	Tool Tool
+	// Hint reports whether the error message has the install command.
+	Hint bool
+}
+
+func (e *MissingError) Error() string {
+	msg := e.Tool.Name + " is not installed"
+	if e.Hint && e.Tool.Install != "" {
+		msg += ", install with: " + e.Tool.Install
+	}
+	return msg
+}
+
+// Look returns the path of the name tool. The result is cached per tool,
+// Reset discards it.
+func Look(name string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return look(name)
+}
+
+func look(name string) (string, error) {
+	path, ok := paths[name]
+	if !ok {
+		path, _ = lookPath(name)
+		paths[name] = path
+	}
+	if path == "" {
+		return "", &MissingError{Tool: lookup(name)}
+	}
+	return path, nil
+}
+
+// Require returns the path of the name tool which is required by the cmd
+// command. The error message of the missing tool has the install command
+// only at the first time of each command.
+func Require(cmd, name string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := look(name)
+	if err, ok := err.(*MissingError); ok {
+		key := cmd + " " + name
+		err.Hint = !notified[key]
+		notified[key] = true
+		return "", err
+	}
+	return path, err
+}
+
+// Missing returns the missing Tools. It also fills the cache of Look.
+func Missing() []Tool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var missing []Tool
+	for _, t := range Tools {
+		if _, err := look(t.Name); err != nil {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// Reset discards the cached paths and the notified commands, such as after
+// the tools are installed.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	paths = make(map[string]string)
+	notified = make(map[string]bool)
+}
+
+// lookup returns the Tool of name. The unknown tool has no install command.
+func lookup(name string) Tool {
+	for _, t := range Tools {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Tool{Name: name}
+}
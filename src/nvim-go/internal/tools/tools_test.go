@@ -0,0 +1,80 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tools
+
+import (
+	"errors"
+	"testing"
+)
+
+func stubLookPath(installed map[string]string) (calls map[string]int, restore func()) {
+	calls = make(map[string]int)
+	saved := lookPath
+	lookPath = func(name string) (string, error) {
+		calls[name]++
+		if path, ok := installed[name]; ok {
+			return path, nil
+		}
+		return "", errors.New("executable file not found in $PATH")
+	}
+	Reset()
+	return calls, func() {
+		lookPath = saved
+		Reset()
+	}
+}
+
+func TestRequire(t *testing.T) {
+	calls, restore := stubLookPath(map[string]string{"godef": "/go/bin/godef"})
+	defer restore()
+
+	tests := []struct {
+		cmd, name string
+		want      string
+		wantErr   string
+	}{
+		{cmd: "GoDef", name: "godef", want: "/go/bin/godef"},
+		{cmd: "DlvDebug", name: "dlv", wantErr: "dlv is not installed, install with: go get github.com/derekparker/delve/cmd/dlv"},
+		// the install command is only at the first time of each command
+		{cmd: "DlvDebug", name: "dlv", wantErr: "dlv is not installed"},
+		{cmd: "DlvConnect", name: "dlv", wantErr: "dlv is not installed, install with: go get github.com/derekparker/delve/cmd/dlv"},
+		{cmd: "GoFoo", name: "foo", wantErr: "foo is not installed"},
+	}
+	for _, tt := range tests {
+		got, err := Require(tt.cmd, tt.name)
+		if tt.wantErr != "" {
+			if _, ok := err.(*MissingError); !ok || err.Error() != tt.wantErr {
+				t.Errorf("Require(%v, %v) error = %v, want %q", tt.cmd, tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Require(%v, %v) = %v, %v, want %v", tt.cmd, tt.name, got, err, tt.want)
+		}
+	}
+
+	// the availability is cached per tool
+	if calls["dlv"] != 1 || calls["godef"] != 1 {
+		t.Errorf("lookPath calls = %v, want once per tool", calls)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	installed := make(map[string]string)
+	for _, tool := range Tools {
+		installed[tool.Name] = "/go/bin/" + tool.Name
+	}
+	delete(installed, "gometalinter")
+	_, restore := stubLookPath(installed)
+	defer restore()
+
+	missing := Missing()
+	if len(missing) != 1 || missing[0].Name != "gometalinter" {
+		t.Errorf("Missing() = %v, want [gometalinter]", missing)
+	}
+	if path, err := Look("dlv"); err != nil || path != "/go/bin/dlv" {
+		t.Errorf("Look(dlv) = %v, %v, want /go/bin/dlv", path, err)
+	}
+}
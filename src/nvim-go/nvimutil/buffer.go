@@ -6,9 +6,13 @@ package nvimutil
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
@@ -31,6 +35,14 @@ type Buffer struct {
 	Width    int
 	Data     []byte
 
+	// Reuse reuses the existing buffer which has the same Name and clears it
+	// instead of creating the new buffer.
+	Reuse bool
+
+	// partial holds the incomplete trailing line of Write until the next
+	// Write or Flush.
+	partial []byte
+
 	WindowContext
 	TabpageContext
 }
@@ -49,18 +61,47 @@ func (b *Buffer) Buffer() nvim.Buffer {
 }
 
 // Create creates the new buffer and return the Buffer structure type.
-func (b *Buffer) Create(name, filetype, mode string, option map[NvimOption]map[string]interface{}) error {
+// If b.Reuse is true and the buffer which has the same name already exists,
+// Create reuses and clears that buffer. The created reports whether the buffer
+// was newly created.
+func (b *Buffer) Create(name, filetype, mode string, option map[NvimOption]map[string]interface{}) (created bool, err error) {
 	b.Name = name
 	b.Filetype = filetype
 	b.Mode = mode
 
-	err := b.n.Command(fmt.Sprintf("silent %s %s", b.Mode, b.Name))
+	var win nvim.Window = -1
+	if b.Reuse {
+		win, err = b.findExisting(name)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+	}
+	created = win == -1
+
+	switch {
+	case win > 0:
+		// the buffer is already displayed, so jump to its window.
+		err = b.n.SetCurrentWindow(win)
+	default:
+		// ":new {name}" also reuses the existing but hidden buffer.
+		err = b.n.Command(fmt.Sprintf("silent %s %s", b.Mode, b.Name))
+	}
 	if err != nil {
-		return errors.WithStack(err)
+		return false, errors.WithStack(err)
 	}
 
 	if err := b.GetBufferContext(); err != nil {
-		return errors.WithStack(err)
+		return false, errors.WithStack(err)
+	}
+	if !created {
+		var modifiable bool
+		if err := b.n.BufferOption(b.buffer, BufOptionModifiable, &modifiable); err != nil {
+			return false, errors.WithStack(err)
+		}
+		if !modifiable {
+			defer Modifiable(b.n, b.buffer)()
+		}
+		b.Reset()
 	}
 
 	if b.Height != 0 {
@@ -72,39 +113,72 @@ func (b *Buffer) Create(name, filetype, mode string, option map[NvimOption]map[s
 
 	b.b.BufferNumber(b.buffer, &b.Bufnr)
 
-	if option != nil {
-		if option[BufferOption] != nil {
-			for k, op := range option[BufferOption] {
-				b.b.SetBufferOption(b.buffer, k, op)
-			}
-		}
-		if option[BufferVar] != nil {
-			for k, op := range option[BufferVar] {
-				b.b.SetBufferVar(b.buffer, k, op)
-			}
-		}
-		if option[WindowOption] != nil {
-			for k, op := range option[WindowOption] {
-				b.b.SetWindowOption(b.Window, k, op)
-			}
-		}
-		if option[WindowVar] != nil {
-			for k, op := range option[WindowVar] {
-				b.b.SetWindowVar(b.Window, k, op)
-			}
-		}
-		if option[TabpageVar] != nil {
-			for k, op := range option[TabpageVar] {
-				b.b.SetTabpageVar(b.Tabpage, k, op)
-			}
-		}
-	}
+	b.SetOptions(b.b, option)
 
 	if !strings.Contains(b.Name, ".") {
 		b.b.Command(fmt.Sprintf("runtime! syntax/%s.vim", filetype))
 	}
 
-	return b.b.Execute()
+	return created, b.b.Execute()
+}
+
+// bufferAugroup is the augroup name of the BufferAutocmd option.
+const bufferAugroup = "nvim-go-buffer"
+
+// SetOptions queues the buffer, window and tabpage options, and the buffer
+// local mappings and autocmds of b to batch.
+// It allows the caller to apply the options of several buffers at once.
+func (b *Buffer) SetOptions(batch *nvim.Batch, option map[NvimOption]map[string]interface{}) {
+	for k, op := range option[BufferOption] {
+		batch.SetBufferOption(b.buffer, k, op)
+	}
+	for k, op := range option[BufferVar] {
+		batch.SetBufferVar(b.buffer, k, op)
+	}
+	for k, op := range option[WindowOption] {
+		batch.SetWindowOption(b.Window, k, op)
+	}
+	for k, op := range option[WindowVar] {
+		batch.SetWindowVar(b.Window, k, op)
+	}
+	for k, op := range option[TabpageVar] {
+		batch.SetTabpageVar(b.Tabpage, k, op)
+	}
+	for lhs, rhs := range option[BufferMapping] {
+		batch.Call("nvim_buf_set_keymap", nil, b.buffer, "n", lhs, rhs, map[string]bool{"noremap": true, "silent": true})
+	}
+	if autocmds := option[BufferAutocmd]; len(autocmds) > 0 {
+		// clear the autocmds of the reused buffer
+		batch.Command(fmt.Sprintf("augroup %s", bufferAugroup))
+		batch.Command("augroup END")
+		batch.Command(fmt.Sprintf("autocmd! %s * <buffer=%d>", bufferAugroup, b.buffer))
+		for event, cmd := range autocmds {
+			batch.Command(fmt.Sprintf("autocmd %s %s <buffer=%d> %v", bufferAugroup, event, b.buffer, cmd))
+		}
+	}
+}
+
+// findExisting finds the existing buffer which has the name.
+// It returns the window ID which displays the buffer, 0 if the buffer is
+// hidden, or -1 if the buffer does not exist.
+func (b *Buffer) findExisting(name string) (nvim.Window, error) {
+	var bufnr int
+	if err := b.n.Call("bufnr", &bufnr, fmt.Sprintf("^%s$", name)); err != nil {
+		return -1, err
+	}
+	if bufnr == -1 {
+		return -1, nil
+	}
+
+	var winid int
+	if err := b.n.Call("bufwinid", &winid, bufnr); err != nil {
+		return -1, err
+	}
+	if winid == -1 {
+		return 0, nil
+	}
+
+	return nvim.Window(winid), nil
 }
 
 // GetBufferContext gets the current buffers context.
@@ -147,9 +221,11 @@ func (b *Buffer) SetBufferLinesAll(replacement []byte) error {
 	}
 
 	b.Data = replacement
-	b.Write(b.Data)
+	if _, err := b.Write(b.Data); err != nil {
+		return err
+	}
 
-	return nil
+	return b.Flush()
 }
 
 // UpdateSyntax updates the syntax highlight of the buffer.
@@ -187,51 +263,128 @@ func (b *Buffer) SetLocalMapping(mode string, mapping map[string]string) error {
 	return b.b.Execute()
 }
 
-// lineCount counts the Neovim buffer line count and check whether 1 count,
-// Because new(empty) buffer and one line buffer are both 1 count.
-func (b *Buffer) lineCount() (int, error) {
-	lineCount, err := b.n.BufferLineCount(b.buffer)
+// WriteDiff replaces the buffer lines with lines, but only sets the changed
+// lines range to keep the cursor position and reduce the redraw.
+func (b *Buffer) WriteDiff(lines [][]byte) error {
+	if b.buffer == 0 {
+		return errors.New("Does not exist of target buffer")
+	}
+
+	old, err := b.n.BufferLines(b.buffer, 0, -1, true)
 	if err != nil {
-		return 0, errors.WithStack(err)
+		return errors.WithStack(err)
+	}
+	start, end, repl, ok := diffLines(old, lines)
+	if !ok {
+		return nil
 	}
 
-	if lineCount == 1 {
-		line, err := b.n.CurrentLine()
-		if err != nil {
-			return 0, errors.WithStack(err)
-		}
-		// Set 0 to lineCount if buffer is empty
-		if len(line) == 0 {
-			lineCount = 0
-		}
+	return errors.WithStack(b.n.SetBufferLines(b.buffer, start, end, true, repl))
+}
+
+// diffLines returns the changed lines range [start, end) of old, and its
+// replacement lines of new. The ok reports whether old and new differ.
+func diffLines(old, new [][]byte) (start, end int, repl [][]byte, ok bool) {
+	for start < len(old) && start < len(new) && bytes.Equal(old[start], new[start]) {
+		start++
+	}
+	if start == len(old) && start == len(new) {
+		return 0, 0, nil, false
+	}
+
+	oend, nend := len(old), len(new)
+	for oend > start && nend > start && bytes.Equal(old[oend-1], new[nend-1]) {
+		oend--
+		nend--
 	}
 
-	return lineCount, nil
+	return start, oend, new[start:nend], true
 }
 
-// Write appends the contents of p to the Neovim buffer.
-func (b *Buffer) Write(p []byte) (int, error) {
-	lineCount, err := b.lineCount()
-	if err != nil {
+// LineCount returns the line count of the b buffer, which is the line to
+// append the lines. It's 0 for the empty buffer, so the appended lines start
+// at the first line without the leading blank line.
+func LineCount(n *nvim.Nvim, b nvim.Buffer) (int, error) {
+	var (
+		count int
+		first [][]byte
+	)
+	batch := n.NewBatch()
+	batch.BufferLineCount(b, &count)
+	batch.BufferLines(b, 0, 1, false, &first)
+	if err := batch.Execute(); err != nil {
 		return 0, errors.WithStack(err)
 	}
 
-	buf := bytes.NewBuffer(p)
-	b.n.SetBufferLines(b.buffer, lineCount, -1, true, ToBufferLines(buf.Bytes()))
+	return contentLineCount(count, first), nil
+}
+
+// contentLineCount returns count, or 0 if the buffer which first lines are
+// first is empty. The new(empty) buffer and the one line buffer are both 1
+// count, the empty buffer has only one empty line.
+func contentLineCount(count int, first [][]byte) int {
+	if count <= 1 && (len(first) == 0 || len(first[0]) == 0) {
+		return 0
+	}
+	return count
+}
+
+// Write appends the complete lines of p to the Neovim buffer, and implements
+// the io.Writer interface.
+// The incomplete trailing line of p is buffered until the next Write or Flush.
+func (b *Buffer) Write(p []byte) (int, error) {
+	lines := b.splitLines(p)
+	if len(lines) == 0 {
+		return len(p), nil
+	}
+
+	if err := b.appendLines(lines); err != nil {
+		return 0, err
+	}
 
 	return len(p), nil
 }
 
-// WriteString appends the contents of s to the Neovim buffer.
-func (b *Buffer) WriteString(s string) error {
-	lineCount, err := b.lineCount()
-	if err != nil {
-		return errors.WithStack(err)
+// WriteString is like Write, but writes the contents of string s.
+func (b *Buffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
+
+// Flush appends the buffered incomplete line to the Neovim buffer.
+func (b *Buffer) Flush() error {
+	if len(b.partial) == 0 {
+		return nil
 	}
 
-	buf := bytes.NewBufferString(s)
+	line := b.partial
+	b.partial = nil
 
-	return b.n.SetBufferLines(b.buffer, lineCount, -1, true, ToBufferLines(buf.Bytes()))
+	return b.appendLines([][]byte{line})
+}
+
+// splitLines splits the p, which follows the buffered incomplete line, into
+// the complete lines, and buffers the remaining incomplete line.
+func (b *Buffer) splitLines(p []byte) [][]byte {
+	data := append(b.partial, p...)
+
+	i := bytes.LastIndexByte(data, '\n')
+	if i < 0 {
+		b.partial = data
+		return nil
+	}
+	b.partial = append([]byte(nil), data[i+1:]...)
+
+	return bytes.Split(data[:i], []byte{'\n'})
+}
+
+// appendLines appends the lines to the end of the Neovim buffer.
+func (b *Buffer) appendLines(lines [][]byte) error {
+	lineCount, err := LineCount(b.n, b.buffer)
+	if err != nil {
+		return err
+	}
+
+	return errors.WithStack(b.n.SetBufferLines(b.buffer, lineCount, -1, true, lines))
 }
 
 // Truncate discards all but the first n unread bytes from the
@@ -305,6 +458,115 @@ func Modifiable(n *nvim.Nvim, b nvim.Buffer) func() {
 	}
 }
 
+// FileStamps represents the snapshot of the files of the listed buffers.
+type FileStamps map[string]fileStamp
+
+// fileStamp represents the modification time and the size of the file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// StampFiles takes the snapshot of the files of the listed buffers before
+// running the external tools which may modify them.
+func StampFiles(n *nvim.Nvim) (FileStamps, error) {
+	buffers, err := n.Buffers()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	stamps := make(FileStamps)
+	for _, b := range buffers {
+		var listed bool
+		if err := n.BufferOption(b, "buflisted", &listed); err != nil || !listed {
+			continue
+		}
+		name, err := n.BufferName(b)
+		if err != nil || name == "" {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		stamps[name] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+	}
+	return stamps, nil
+}
+
+// ChangedFiles returns the file names whose modification time or size differ
+// from the snapshot. Comparing with the snapshot, not with the start time,
+// detects the writes within the modification time granularity of the file
+// system.
+func (s FileStamps) ChangedFiles() []string {
+	var files []string
+	for name, stamp := range s {
+		fi, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		if !fi.ModTime().Equal(stamp.modTime) || fi.Size() != stamp.size {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ReloadChangedBuffers reloads the buffers of files which were changed on
+// disk by the external tools, such as Gorename. The buffers which have the
+// unsaved changes are not reloaded, and warned instead.
+func ReloadChangedBuffers(n *nvim.Nvim, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	targets := make(map[string]bool)
+	for _, f := range files {
+		if abs, err := filepath.Abs(f); err == nil {
+			targets[filepath.Clean(abs)] = true
+		}
+	}
+
+	buffers, err := n.Buffers()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var autoread bool
+	if err := n.Option("autoread", &autoread); err != nil {
+		return errors.WithStack(err)
+	}
+	if !autoread {
+		// checktime reloads the unmodified buffers silently with autoread
+		n.SetOption("autoread", true)
+		defer n.SetOption("autoread", false)
+	}
+
+	var skipped []string
+	for _, b := range buffers {
+		name, err := n.BufferName(b)
+		if err != nil || !targets[name] {
+			continue
+		}
+		var modified bool
+		if err := n.BufferOption(b, "modified", &modified); err != nil {
+			return errors.WithStack(err)
+		}
+		if modified {
+			skipped = append(skipped, filepath.Base(name))
+			continue
+		}
+		if err := n.Command(fmt.Sprintf("silent! checktime %d", b)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if len(skipped) > 0 {
+		return EchohlAfter(n, "nvim-go", "WarningMsg", "%s changed on disk but has unsaved changes, not reloaded", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
 // ToByteSlice converts the 2D buffer byte data to sigle byte slice.
 func ToByteSlice(byt [][]byte) []byte { return bytes.Join(byt, []byte{'\n'}) }
 
@@ -312,30 +574,75 @@ func ToByteSlice(byt [][]byte) []byte { return bytes.Join(byt, []byte{'\n'}) }
 func ToBufferLines(byt []byte) [][]byte { return bytes.Split(byt, []byte{'\n'}) }
 
 // ByteOffset calculates the byte-offset of current cursor position.
+// The returned offset is 0-based byte offset, same as the guru's "#offset" form.
 func ByteOffset(n *nvim.Nvim, b nvim.Buffer, w nvim.Window) (int, error) {
 	cursor, err := n.WindowCursor(w)
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
-	byteBuf, err := n.BufferLines(b, 0, -1, true)
+	lines, err := n.BufferLines(b, 0, -1, true)
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
 
-	if cursor[0] == 1 {
-		return (1 + (cursor[1] - 1)), nil
+	return byteOffset(lines, cursor), nil
+}
+
+// byteOffset calculates the byte-offset of the cursor position in lines.
+// The cursor column is a 0-based byte index, not a rune index, even if the
+// cursor line contains multibyte characters.
+func byteOffset(lines [][]byte, cursor [2]int) int {
+	return lineOffset(lines, cursor[0]) + clampCol(lines, cursor[0], cursor[1])
+}
+
+// ByteOffsetRange calculates the byte-offsets of the start and end position.
+// The start and end are the 1-based line and 0-based byte column pair, same as
+// the cursor position. The returned end offset is exclusive, it includes the
+// whole multibyte character at the end position.
+func ByteOffsetRange(n *nvim.Nvim, b nvim.Buffer, start, end [2]int) (int, int, error) {
+	lines, err := n.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
 	}
 
-	var offset int
-	line := 1
-	for _, buf := range byteBuf {
-		if line == cursor[0] {
-			offset++
-			break
+	so, eo := offsetRange(lines, start, end)
+	return so, eo, nil
+}
+
+// offsetRange calculates the byte-offsets of the start and end position in lines.
+func offsetRange(lines [][]byte, start, end [2]int) (int, int) {
+	so := byteOffset(lines, start)
+
+	eo := lineOffset(lines, end[0])
+	col := clampCol(lines, end[0], end[1])
+	if end[0]-1 < len(lines) {
+		if line := lines[end[0]-1]; col < len(line) {
+			_, size := utf8.DecodeRune(line[col:])
+			col += size
 		}
-		offset += (binary.Size(buf) + 1)
-		line++
 	}
+	eo += col
 
-	return (offset + (cursor[1] - 1)), nil
+	return so, eo
+}
+
+// lineOffset returns the byte-offset of the beginning of 1-based line in lines.
+func lineOffset(lines [][]byte, line int) int {
+	var offset int
+	for i := 0; i < line-1 && i < len(lines); i++ {
+		offset += len(lines[i]) + 1 // include newline
+	}
+	return offset
+}
+
+// clampCol clamps the 0-based byte column col to the length of 1-based line,
+// such as the linewise visual selection end column.
+func clampCol(lines [][]byte, line, col int) int {
+	if line < 1 || line > len(lines) || col < 0 {
+		return 0
+	}
+	if l := len(lines[line-1]); col > l {
+		return l
+	}
+	return col
 }
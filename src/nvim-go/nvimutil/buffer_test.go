@@ -0,0 +1,443 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestBuffer_CreateReuse(t *testing.T) {
+	n := TestNvim(t)
+
+	option := map[NvimOption]map[string]interface{}{
+		BufferOption: {
+			BufOptionBuftype: BuftypeNofile,
+		},
+	}
+
+	first := NewBuffer(n)
+	first.Reuse = true
+	created, err := first.Create("__reuse__", "", "belowright new", option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Errorf("first Create() created = %v, want true", created)
+	}
+	if err := first.SetBufferLines(0, -1, true, []byte("foo\nbar")); err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewBuffer(n)
+	second.Reuse = true
+	created, err = second.Create("__reuse__", "", "belowright new", option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Errorf("second Create() created = %v, want false", created)
+	}
+	if second.Buffer() != first.Buffer() {
+		t.Errorf("second Create() buffer = %v, want %v", second.Buffer(), first.Buffer())
+	}
+
+	lines, err := n.BufferLines(second.Buffer(), 0, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || len(lines[0]) != 0 {
+		t.Errorf("reused buffer lines = %q, want empty", lines)
+	}
+}
+
+func TestBuffer_CreateWindowOption(t *testing.T) {
+	n := TestNvim(t)
+	if err := n.Command("setlocal number"); err != nil {
+		t.Fatal(err)
+	}
+
+	option := map[NvimOption]map[string]interface{}{
+		WindowOption: {
+			WinOptionNumber: false,
+		},
+	}
+	b := NewBuffer(n)
+	if _, err := b.Create("__winoption__", "", "belowright new", option); err != nil {
+		t.Fatal(err)
+	}
+
+	var number bool
+	if err := n.WindowOption(b.Window, WinOptionNumber, &number); err != nil {
+		t.Fatal(err)
+	}
+	if number {
+		t.Errorf("Create() window option %q = %v, want false", WinOptionNumber, number)
+	}
+}
+
+func TestBuffer_CreateMapping(t *testing.T) {
+	n := TestNvim(t)
+
+	option := map[NvimOption]map[string]interface{}{
+		BufferMapping: {
+			"q": ":<C-u>close<CR>",
+		},
+		BufferAutocmd: {
+			"BufLeave": "let b:left = 1",
+		},
+	}
+	b := NewBuffer(n)
+	if _, err := b.Create("__mapping__", "", "belowright new", option); err != nil {
+		t.Fatal(err)
+	}
+
+	var mapping struct {
+		RHS     string `msgpack:"rhs"`
+		Buffer  int    `msgpack:"buffer"`
+		Noremap int    `msgpack:"noremap"`
+	}
+	if err := n.Call("maparg", &mapping, "q", "n", false, true); err != nil {
+		t.Fatal(err)
+	}
+	if mapping.RHS != ":<C-u>close<CR>" || mapping.Buffer != 1 || mapping.Noremap != 1 {
+		t.Errorf("Create() mapping of %q = %+v, want buffer local noremap %q", "q", mapping, ":<C-u>close<CR>")
+	}
+
+	var exists int
+	if err := n.Call("exists", &exists, "#nvim-go-buffer#BufLeave#<buffer>"); err != nil {
+		t.Fatal(err)
+	}
+	if exists != 1 {
+		t.Errorf("Create() buffer local BufLeave autocmd exists = %v, want 1", exists)
+	}
+}
+
+func TestContentLineCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		first [][]byte
+		want  int
+	}{
+		{name: "empty", count: 0, first: nil, want: 0},
+		{name: "single blank line", count: 1, first: [][]byte{{}}, want: 0},
+		{name: "single line", count: 1, first: [][]byte{[]byte("foo")}, want: 1},
+		{name: "multi line", count: 3, first: [][]byte{[]byte("foo")}, want: 3},
+		{name: "multi line with blank first line", count: 2, first: [][]byte{{}}, want: 2},
+	}
+	for _, tt := range tests {
+		if got := contentLineCount(tt.count, tt.first); got != tt.want {
+			t.Errorf("%q. contentLineCount(%v, %q) = %v, want %v", tt.name, tt.count, tt.first, got, tt.want)
+		}
+	}
+}
+
+func TestBuffer_splitLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunks      []string
+		wantLines   []string
+		wantPartial string
+	}{
+		{
+			name:        "complete lines",
+			chunks:      []string{"foo\nbar\n"},
+			wantLines:   []string{"foo", "bar"},
+			wantPartial: "",
+		},
+		{
+			name:        "split mid-line",
+			chunks:      []string{"fo", "o\nba", "r\n"},
+			wantLines:   []string{"foo", "bar"},
+			wantPartial: "",
+		},
+		{
+			name:        "trailing incomplete line",
+			chunks:      []string{"foo\nb", "ar"},
+			wantLines:   []string{"foo"},
+			wantPartial: "bar",
+		},
+		{
+			name:        "empty line",
+			chunks:      []string{"foo\n", "\n", "bar\n"},
+			wantLines:   []string{"foo", "", "bar"},
+			wantPartial: "",
+		},
+		{
+			name:        "no newline",
+			chunks:      []string{"foo", "bar"},
+			wantLines:   nil,
+			wantPartial: "foobar",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b := new(Buffer)
+			var got []string
+			for _, chunk := range tt.chunks {
+				for _, line := range b.splitLines([]byte(chunk)) {
+					got = append(got, string(line))
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("%q. splitLines(%q) = %q, want %q", tt.name, tt.chunks, got, tt.wantLines)
+			}
+			if string(b.partial) != tt.wantPartial {
+				t.Errorf("%q. splitLines(%q) partial = %q, want %q", tt.name, tt.chunks, b.partial, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestOffsetRange(t *testing.T) {
+	lines := [][]byte{
+		[]byte("package main"),
+		[]byte(""),
+		[]byte(`var s = "日本語"`),
+		[]byte(`var e = "🍣 and 🍺"`),
+	}
+
+	tests := []struct {
+		name      string
+		start     [2]int
+		end       [2]int
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			name:      "single line ascii",
+			start:     [2]int{1, 0},
+			end:       [2]int{1, 6},
+			wantStart: 0,
+			wantEnd:   7, // "package"
+		},
+		{
+			name:      "single line multibyte",
+			start:     [2]int{3, 9},
+			end:       [2]int{3, 15},
+			wantStart: 23,
+			wantEnd:   32, // "日本語"
+		},
+		{
+			name:      "multi line multibyte",
+			start:     [2]int{3, 12},
+			end:       [2]int{4, 9},
+			wantStart: 26,
+			wantEnd:   47, // `本語"` ... `"🍣`
+		},
+		{
+			name:      "linewise selection",
+			start:     [2]int{1, 0},
+			end:       [2]int{4, 2147483646},
+			wantStart: 0,
+			wantEnd:   57,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gotStart, gotEnd := offsetRange(lines, tt.start, tt.end)
+			if gotStart != tt.wantStart || gotEnd != tt.wantEnd {
+				t.Errorf("%q. offsetRange(%v, %v) = %v, %v, want %v, %v", tt.name, tt.start, tt.end, gotStart, gotEnd, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	lines := [][]byte{
+		[]byte("package main"),
+		[]byte(""),
+		[]byte(`func main() { s := "日本語"; println(s) }`),
+		[]byte(`func emoji() { e := "🍣🍺"; println(e) }`),
+	}
+	src := bytes.Join(lines, []byte{'\n'})
+
+	tests := []struct {
+		name   string
+		cursor [2]int
+		target string // the text at the cursor position
+	}{
+		{
+			name:   "first line",
+			cursor: [2]int{1, 8},
+			target: "main",
+		},
+		{
+			name:   "cjk rune",
+			cursor: [2]int{3, 23},
+			target: "本語",
+		},
+		{
+			name:   "after cjk",
+			cursor: [2]int{3, 32},
+			target: "println(s)",
+		},
+		{
+			name:   "emoji rune",
+			cursor: [2]int{4, 25},
+			target: "🍺",
+		},
+		{
+			name:   "after emoji",
+			cursor: [2]int{4, 32},
+			target: "println(e)",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			want := bytes.Index(src, []byte(tt.target))
+			if got := byteOffset(lines, tt.cursor); got != want {
+				t.Errorf("%q. byteOffset(%v) = %v, want %v", tt.name, tt.cursor, got, want)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	lines := func(s ...string) [][]byte {
+		b := make([][]byte, len(s))
+		for i := range s {
+			b[i] = []byte(s[i])
+		}
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		old       [][]byte
+		new       [][]byte
+		wantStart int
+		wantEnd   int
+		wantRepl  [][]byte
+		wantOk    bool
+	}{
+		{
+			name:   "same",
+			old:    lines("a", "b", "c"),
+			new:    lines("a", "b", "c"),
+			wantOk: false,
+		},
+		{
+			name:      "change middle line",
+			old:       lines("a", "hits: 1", "c"),
+			new:       lines("a", "hits: 2", "c"),
+			wantStart: 1,
+			wantEnd:   2,
+			wantRepl:  lines("hits: 2"),
+			wantOk:    true,
+		},
+		{
+			name:      "append lines",
+			old:       lines("a"),
+			new:       lines("a", "b", "c"),
+			wantStart: 1,
+			wantEnd:   1,
+			wantRepl:  lines("b", "c"),
+			wantOk:    true,
+		},
+		{
+			name:      "delete lines",
+			old:       lines("a", "b", "c"),
+			new:       lines("a", "c"),
+			wantStart: 1,
+			wantEnd:   2,
+			wantRepl:  lines(),
+			wantOk:    true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			start, end, repl, ok := diffLines(tt.old, tt.new)
+			if ok != tt.wantOk {
+				t.Fatalf("%q. diffLines() ok = %v, want %v", tt.name, ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if start != tt.wantStart || end != tt.wantEnd || !reflect.DeepEqual(repl, tt.wantRepl) {
+				t.Errorf("%q. diffLines() = %v, %v, %q, want %v, %v, %q", tt.name, start, end, repl, tt.wantStart, tt.wantEnd, tt.wantRepl)
+			}
+		})
+	}
+}
+
+func TestReloadChangedBuffers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	clean := filepath.Join(dir, "clean.go")
+	dirty := filepath.Join(dir, "dirty.go")
+	for _, f := range []string{clean, dirty} {
+		if err := ioutil.WriteFile(f, []byte("package foo\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := TestNvim(t, clean, dirty)
+
+	bufnr := func(file string) nvim.Buffer {
+		var b int
+		if err := n.Call("bufnr", &b, file); err != nil {
+			t.Fatal(err)
+		}
+		return nvim.Buffer(b)
+	}
+	// the unsaved change of the dirty buffer
+	if err := n.SetBufferLines(bufnr(dirty), 0, -1, true, [][]byte{[]byte("package bar")}); err != nil {
+		t.Fatal(err)
+	}
+
+	stamps, err := StampFiles(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// simulate the external edit such as Gorename, within the modification
+	// time granularity of the file system
+	for _, f := range []string{clean, dirty} {
+		if err := ioutil.WriteFile(f, []byte("package quux\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := stamps.ChangedFiles()
+	if err := ReloadChangedBuffers(n, files); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: clean, want: "package quux"}, // reloaded
+		{file: dirty, want: "package bar"},  // kept the unsaved change
+	}
+	for _, tt := range tests {
+		lines, err := n.BufferLines(bufnr(tt.file), 0, -1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(bytes.Join(lines, []byte{'\n'})); got != tt.want {
+			t.Errorf("ReloadChangedBuffers(%v) %s lines = %q, want %q", files, filepath.Base(tt.file), got, tt.want)
+		}
+	}
+}
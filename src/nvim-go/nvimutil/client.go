@@ -22,7 +22,7 @@ func NewSocketClient() *nvim.Nvim {
 		err error
 	)
 
-	addr := config.ServerName
+	addr := config.ServerName()
 	if addr == "" {
 		return nil
 	}
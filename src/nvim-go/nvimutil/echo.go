@@ -6,9 +6,7 @@ package nvimutil
 
 import (
 	"fmt"
-	"log"
-
-	"nvim-go/config"
+	"strings"
 
 	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
@@ -35,14 +33,19 @@ func EchoRaw(v *nvim.Nvim, a string) error {
 	return v.Command("echo \"" + a + "\"")
 }
 
-// Echomsg provide the vim 'echomsg' command.
+// Echomsg provide the vim 'echomsg' command. It's the result which the user
+// asked for, so it's always echoed regardless of config.LogLevel, and
+// recorded as the info level message. Use Info for the informational message.
 func Echomsg(v *nvim.Nvim, a ...interface{}) error {
-	return v.WriteOut(fmt.Sprintln(a...))
+	msg := fmt.Sprintln(a...)
+	record(LevelInfo, "", strings.TrimSuffix(msg, "\n"))
+	return v.WriteOut(msg)
 }
 
-// Echoerr provide the vim 'echoerr' command.
+// Echoerr provide the vim 'echoerr' command. It's the error level message,
+// which is always echoed.
 func Echoerr(v *nvim.Nvim, format string, a ...interface{}) error {
-	return v.WritelnErr(fmt.Sprintf(format, a...))
+	return Log(v, LevelError, "", format, a...)
 }
 
 type stackTracer interface {
@@ -61,16 +64,14 @@ func ErrorWrap(v *nvim.Nvim, err error) error {
 		st := err.StackTrace()
 		// "%n" verb is function name
 		funcName = fmt.Sprintf("%n", st[0])
-		if config.DebugEnable {
-			log.Printf("Error stack%+v", st[:])
-		}
+		record(LevelDebug, funcName, fmt.Sprintf("error stack%+v", st[:]))
 	}
 	// fallback use plugin name
 	if funcName == "" {
 		funcName = "nvim-go"
 	}
 
-	return v.WritelnErr(fmt.Sprintf("%s: %s", funcName, err))
+	return Log(v, LevelError, funcName, "%s", err)
 }
 
 // EchohlBefore provide the vim 'echo' command with the 'echohl' highlighting prefix text.
@@ -27,7 +27,7 @@ func SetLoclist(v *nvim.Nvim, loclist []*nvim.QuickfixError) error {
 	// setloclist({nr}, {list} [, {action}])
 	// v.Call(fname string, result interface{}, args ...interface{})
 	if len(loclist) > 0 {
-		v.Call("setloclist", nil, 0, loclist)
+		v.Call("setloclist", nil, 0, dedupList(loclist))
 	} else {
 		v.Command("lexpr ''")
 	}
@@ -35,14 +35,6 @@ func SetLoclist(v *nvim.Nvim, loclist []*nvim.QuickfixError) error {
 	return nil
 }
 
-var (
-	listtype     ErrorListType
-	openlistCmd  func() error
-	closelistCmd func() error
-	clearlistCmd func() error
-	setlistCmd   func(errlist []*nvim.QuickfixError) error
-)
-
 // ErrorListType represents a neovim error list type.
 type ErrorListType string
 
@@ -50,82 +42,399 @@ const (
 	// Quickfix quickfix error list type.
 	Quickfix ErrorListType = "quickfix"
 	// LocationList locationlist error list type.
-	LocationList = "locationlist"
+	LocationList ErrorListType = "locationlist"
 )
 
-func getListCmd(v *nvim.Nvim) {
-	listtype = ErrorListType(config.ErrorListType)
-	switch listtype {
-	case Quickfix:
-		openlistCmd = func() error { return v.Command("copen") }
-		closelistCmd = func() error { return v.Command("cclose") }
-		clearlistCmd = func() error { return v.Command("cgetexpr ''") }
-		setlistCmd = func(errlist []*nvim.QuickfixError) error { return v.Call("setqflist", nil, errlist, "r") }
-	case LocationList:
-		openlistCmd = func() error { return v.Command("lopen") }
-		closelistCmd = func() error { return v.Command("lclose") }
-		clearlistCmd = func() error { return v.Command("lgetexpr ''") }
-		setlistCmd = func(errlist []*nvim.QuickfixError) error { return v.Call("setloclist", nil, 0, errlist, "r") }
+// ListTypeOf returns the error list type of the name command.
+// The per command config.ListType() takes precedence over config.ErrorListType().
+func ListTypeOf(name string) ErrorListType {
+	if t, ok := config.ListType()[name]; ok {
+		return parseListType(t)
 	}
+	return parseListType(config.ErrorListType())
 }
 
-// ErrorList merges the errlist map items and open the locationlist window.
-func ErrorList(v *nvim.Nvim, errors map[string][]*nvim.QuickfixError, keep bool) error {
-	if listtype == "" {
-		getListCmd(v)
+// parseListType parses the s list type. It also accepts the "location" short name.
+func parseListType(s string) ErrorListType {
+	switch s {
+	case string(Quickfix):
+		return Quickfix
+	default:
+		return LocationList
 	}
+}
 
-	if errors == nil || len(errors) == 0 {
-		defer clearlistCmd()
-		return closelistCmd()
+// listTitle is the title of the error list which set by nvim-go.
+const listTitle = "nvim-go"
+
+// SetList replaces the t type error list with list.
+// The w is the window of the locationlist, 0 means the current window.
+// The cwd is the Neovim current directory of the command, see the
+// normalizeFileNames.
+func SetList(v *nvim.Nvim, w nvim.Window, t ErrorListType, cwd string, list []*nvim.QuickfixError) error {
+	list = dedupList(normalizeFileNames(cwd, list))
+	title := map[string]interface{}{"title": listTitle}
+
+	batch := v.NewBatch()
+	if t == Quickfix {
+		batch.Call("setqflist", nil, list, "r")
+		batch.Call("setqflist", nil, []*nvim.QuickfixError{}, "a", title)
+	} else {
+		batch.Call("setloclist", nil, w, list, "r")
+		batch.Call("setloclist", nil, w, []*nvim.QuickfixError{}, "a", title)
 	}
+	return batch.Execute()
+}
 
-	var errlist []*nvim.QuickfixError
-	for _, err := range errors {
-		errlist = append(errlist, err...)
+// dedupList removes the duplicate entries of list which have the same file,
+// position and text, such as the merged build and vet results, in order of
+// the first seen. Returns list as is if config.QuickfixDedup() is disabled.
+func dedupList(list []*nvim.QuickfixError) []*nvim.QuickfixError {
+	return dedupAgainst(nil, list)
+}
+
+// dedupAgainst is like dedupList, but also removes the entries of list which
+// are already in existing.
+func dedupAgainst(existing, list []*nvim.QuickfixError) []*nvim.QuickfixError {
+	if !config.QuickfixDedup() || len(existing)+len(list) < 2 {
+		return list
 	}
-	if err := SetErrorlist(v, errlist); err != nil {
-		return err
+
+	type key struct {
+		fname     string
+		lnum, col int
+		text      string
+	}
+	seen := make(map[key]bool, len(existing)+len(list))
+	for _, e := range existing {
+		seen[key{fname: e.FileName, lnum: e.LNum, col: e.Col, text: e.Text}] = true
 	}
+	deduped := make([]*nvim.QuickfixError, 0, len(list))
+	for _, e := range list {
+		k := key{fname: e.FileName, lnum: e.LNum, col: e.Col, text: e.Text}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		deduped = append(deduped, e)
+	}
+	return deduped
+}
 
-	if keep {
-		w, err := v.CurrentWindow()
-		if err != nil {
+// normalizeFileNames returns the copy of list entries which FileName is
+// normalized against the cwd directory. The FileName is kept as the absolute
+// path if cwd is empty, such as the command which doesn't know the Neovim
+// current directory.
+func normalizeFileNames(cwd string, list []*nvim.QuickfixError) []*nvim.QuickfixError {
+	if len(list) == 0 {
+		return list
+	}
+
+	var root string
+	relative := cwd != "" && config.QuickfixRelativePaths()
+	if relative {
+		root = pathutil.FindVCSRoot(cwd)
+	}
+	normalized := make([]*nvim.QuickfixError, len(list))
+	for i, e := range list {
+		ne := *e
+		ne.FileName = normalizeFileName(cwd, root, e.FileName, relative)
+		normalized[i] = &ne
+	}
+
+	return normalized
+}
+
+// normalizeFileName returns the absolute path of fname, or if relative is
+// true, the relative path from cwd.
+// The fname outside of the root project directory is kept as the absolute path.
+func normalizeFileName(cwd, root, fname string, relative bool) string {
+	if fname == "" {
+		return fname
+	}
+
+	abs := fname
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(cwd, abs)
+	}
+	if !relative {
+		return abs
+	}
+
+	if r, err := filepath.Rel(root, abs); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return abs
+	}
+	return pathutil.Rel(cwd, abs)
+}
+
+// isOwnList reports whether the t type error list is set by nvim-go.
+func isOwnList(v *nvim.Nvim, w nvim.Window, t ErrorListType) (bool, error) {
+	title, size, err := listInfo(v, w, t)
+	if err != nil {
+		return false, err
+	}
+
+	// the empty list has nothing to protect
+	return title == listTitle || size == 0, nil
+}
+
+// listInfo returns the title and the size of the t type error list.
+func listInfo(v *nvim.Nvim, w nvim.Window, t ErrorListType) (string, int, error) {
+	var info struct {
+		Title string `msgpack:"title"`
+		Size  int    `msgpack:"size"`
+	}
+	what := map[string]interface{}{"title": 1, "size": 1}
+
+	var err error
+	if t == Quickfix {
+		err = v.Call("getqflist", &info, what)
+	} else {
+		err = v.Call("getloclist", &info, w, what)
+	}
+	return info.Title, info.Size, err
+}
+
+// listItems returns the entries of the t type error list. The FileName of
+// the entries is the absolute path of its buffer.
+func listItems(v *nvim.Nvim, w nvim.Window, t ErrorListType) ([]*nvim.QuickfixError, error) {
+	getlist := "getqflist()"
+	if t == LocationList {
+		getlist = fmt.Sprintf("getloclist(%d)", w)
+	}
+
+	var items []*nvim.QuickfixError
+	expr := fmt.Sprintf(`map(%s, {_, e -> {'filename': fnamemodify(bufname(e.bufnr), ':p'), 'lnum': e.lnum, 'col': e.col, 'text': e.text}})`, getlist)
+	if err := v.Eval(expr, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AppendList appends list to the t type error list.
+// The entries which are already in the list are skipped, such as the same
+// reference reported by the streamed guru results.
+// The w is the window of the locationlist, 0 means the current window.
+// The cwd is the Neovim current directory of the command, see the
+// normalizeFileNames.
+func AppendList(v *nvim.Nvim, w nvim.Window, t ErrorListType, cwd string, list []*nvim.QuickfixError) error {
+	var existing []*nvim.QuickfixError
+	if config.QuickfixDedup() {
+		var err error
+		if existing, err = listItems(v, w, t); err != nil {
 			return err
 		}
-		defer v.SetCurrentWindow(w)
 	}
-	return openlistCmd()
+	// normalize the both so that the file names are comparable
+	list = dedupAgainst(normalizeFileNames(cwd, existing), normalizeFileNames(cwd, list))
+	if len(list) == 0 {
+		return nil
+	}
+	if t == Quickfix {
+		return v.Call("setqflist", nil, list, "a")
+	}
+	return v.Call("setloclist", nil, w, list, "a")
 }
 
-// SetErrorlist set the error results data to Neovim error list.
-func SetErrorlist(v *nvim.Nvim, errlist []*nvim.QuickfixError) error {
-	if setlistCmd == nil {
-		getListCmd(v)
+// OpenList opens the t type error list window.
+// If list is empty, clears the stale entries of the prior run and closes the
+// window if config.QuickfixAutoClose() is enabled. The list which was not set
+// by nvim-go, such as the ":grep" result, is left as is.
+// The window height is fitted to the deduplicated list, see the listHeight.
+// If keep is true, keeps the cursor focus to the w window.
+func OpenList(v *nvim.Nvim, w nvim.Window, t ErrorListType, list []*nvim.QuickfixError, keep bool) error {
+	if len(list) == 0 {
+		return clearOwnList(v, w, t, config.QuickfixAutoClose())
 	}
 
-	return setlistCmd(errlist)
+	if err := openListWindow(v, w, t, len(dedupList(list))); err != nil {
+		return err
+	}
+	if keep {
+		return v.SetCurrentWindow(w)
+	}
+	return nil
 }
 
-// ClearErrorlist clear the Neovim error list.
-func ClearErrorlist(v *nvim.Nvim, close bool) error {
-	if clearlistCmd == nil {
-		getListCmd(v)
+// CloseList closes the t type error list window.
+func CloseList(v *nvim.Nvim, t ErrorListType) error {
+	return v.Command(listCmd(t, "close"))
+}
+
+// JumpFirstCmd returns the command of jump to the first item of t type error list.
+func JumpFirstCmd(t ErrorListType) string {
+	return JumpCmd(t, 1)
+}
+
+// JumpCmd returns the command of jump to the nth item of t type error list.
+func JumpCmd(t ErrorListType, n int) string {
+	if t == Quickfix {
+		return fmt.Sprintf("silent cc %d", n)
+	}
+	return fmt.Sprintf("silent ll %d", n)
+}
+
+// CycleCmd returns the command of jump to the next item of t type error list,
+// or the previous item if forward is false. It wraps around at the end of list.
+func CycleCmd(t ErrorListType, forward bool) string {
+	next, wrap := "next", "first"
+	if !forward {
+		next, wrap = "previous", "last"
+	}
+	// E553: No more items
+	return fmt.Sprintf("try | silent %s | catch /E553/ | silent %s | endtry", listCmd(t, next), listCmd(t, wrap))
+}
+
+// openListWindow opens the t type error list window which height is fitted
+// to the n entries. The window which is already opened keeps its size, since
+// it may be resized by the user.
+func openListWindow(v *nvim.Nvim, w nvim.Window, t ErrorListType, n int) error {
+	var info struct {
+		WinID int `msgpack:"winid"`
+	}
+	what := map[string]interface{}{"winid": 1}
+
+	var err error
+	if t == Quickfix {
+		err = v.Call("getqflist", &info, what)
+	} else {
+		err = v.Call("getloclist", &info, w, what)
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.WinID != 0 {
+		return v.Command(listCmd(t, "open"))
+	}
+	return v.Command(fmt.Sprintf("%s %d", listCmd(t, "open"), listHeight(n, config.QuickfixMaxHeight())))
+}
+
+// listHeight returns the error list window height of the n entries, which is
+// capped at max, and at least 1.
+func listHeight(n, max int) int {
+	if n > max {
+		n = max
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// listCmd returns the cmd command of t type error list. such as "copen" or "lopen".
+func listCmd(t ErrorListType, cmd string) string {
+	if t == Quickfix {
+		return "c" + cmd
+	}
+	return "l" + cmd
+}
+
+// clearOwnList clears the t type error list if it was set by nvim-go, and
+// closes its window if close is true.
+func clearOwnList(v *nvim.Nvim, w nvim.Window, t ErrorListType, close bool) error {
+	title, size, err := listInfo(v, w, t)
+	if err != nil {
+		return err
+	}
+	if size > 0 && title != listTitle {
+		return nil
 	}
 
 	if close {
-		defer closelistCmd()
+		defer CloseList(v, t)
+	}
+	if size == 0 {
+		// nothing to clear, and doesn't create the list of the window
+		return nil
+	}
+	return SetList(v, w, t, "", []*nvim.QuickfixError{})
+}
+
+// clearOwnLists clears the quickfix list and the location lists of the all
+// windows which were set by nvim-go, regardless of the config.ErrorListType.
+// Only the window of the current list is closed if close is true.
+func clearOwnLists(v *nvim.Nvim, close bool) error {
+	if err := clearOwnList(v, 0, Quickfix, close); err != nil {
+		return err
+	}
+
+	cur, err := v.CurrentWindow()
+	if err != nil {
+		return err
+	}
+	wins, err := v.Windows()
+	if err != nil {
+		return err
+	}
+	for _, w := range wins {
+		if err := clearOwnList(v, w, LocationList, close && w == cur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrorList merges the errlist map items per the commands error list type and
+// open the error list window. The cwd is the Neovim current directory of the
+// command, see the normalizeFileNames.
+func ErrorList(v *nvim.Nvim, cwd string, errors map[string][]*nvim.QuickfixError, keep bool) error {
+	if errors == nil || len(errors) == 0 {
+		return clearOwnLists(v, config.QuickfixAutoClose())
+	}
+
+	errlists := make(map[ErrorListType][]*nvim.QuickfixError)
+	for name, err := range errors {
+		t := ListTypeOf(name)
+		errlists[t] = append(errlists[t], err...)
+	}
+
+	w, err := v.CurrentWindow()
+	if err != nil {
+		return err
 	}
-	return clearlistCmd()
+	if keep {
+		defer v.SetCurrentWindow(w)
+	}
+	for _, t := range []ErrorListType{Quickfix, LocationList} {
+		errlist, ok := errlists[t]
+		if !ok {
+			continue
+		}
+		if err := SetList(v, w, t, cwd, errlist); err != nil {
+			return err
+		}
+		if err := OpenList(v, w, t, errlist, keep); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetErrorlist set the error results data to Neovim error list.
+// The cwd is the Neovim current directory of the command, see the
+// normalizeFileNames.
+func SetErrorlist(v *nvim.Nvim, cwd string, errlist []*nvim.QuickfixError) error {
+	return SetList(v, 0, parseListType(config.ErrorListType()), cwd, errlist)
+}
+
+// ClearErrorlist clear the Neovim error list which was set by nvim-go.
+// The window is closed if both close and config.QuickfixAutoClose() are true.
+func ClearErrorlist(v *nvim.Nvim, close bool) error {
+	return clearOwnList(v, 0, parseListType(config.ErrorListType()), close && config.QuickfixAutoClose())
 }
 
 // OpenLoclist open or close the current buffer's locationlist window.
+// The window height is fitted to the loclist, see the listHeight.
 func OpenLoclist(v *nvim.Nvim, w nvim.Window, loclist []*nvim.QuickfixError, keep bool) error {
 	if len(loclist) == 0 {
 		return v.Command("lclose")
 	}
 
-	v.Command("lopen")
+	if err := openListWindow(v, w, LocationList, len(loclist)); err != nil {
+		return err
+	}
 	if keep {
 		return v.SetCurrentWindow(w)
 	}
@@ -139,7 +448,7 @@ func CloseLoclist(v *nvim.Nvim) error {
 
 // SetQuickfix set the error results data to quickfix list.
 func SetQuickfix(b *nvim.Batch, qflist []*nvim.QuickfixError) error {
-	b.Call("setqflist", nil, qflist)
+	b.Call("setqflist", nil, dedupList(qflist))
 
 	return nil
 }
@@ -175,52 +484,94 @@ func GotoPos(v *nvim.Nvim, w nvim.Window, pos token.Position, cwd string) error
 
 // SplitPos parses a string of form 'token.Pos', and return the relative
 // filepath from the current working directory path.
+// The line and column are split from the right, so the filename may contain
+// the colons such as the Windows drive letter `C:\path\file.go:12:3`.
+// The missing line and column are 0.
 func SplitPos(pos string, cwd string) (string, int, int) {
-	position := strings.Split(pos, ":")
+	fname, last, ok := cutNumber(pos)
+	if !ok {
+		return relPath(pos, cwd), 0, 0
+	}
+	fname2, line, ok := cutNumber(fname)
+	if !ok {
+		// the "file:line" form
+		return relPath(fname, cwd), last, 0
+	}
 
-	fname := position[0]
-	line, err := strconv.ParseInt(position[1], 10, 64)
-	if err != nil {
-		line = 0
+	return relPath(fname2, cwd), line, last
+}
+
+// cutNumber cuts the trailing ":N" number of s, and reports whether found.
+func cutNumber(s string) (string, int, bool) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return s, 0, false
 	}
-	col, err := strconv.ParseInt(position[2], 10, 64)
+	n, err := strconv.Atoi(s[i+1:])
 	if err != nil {
-		col = 0
+		return s, 0, false
 	}
+	return s[:i], n, true
+}
 
+// relPath returns the relative path of fname from cwd if fname is under cwd.
+func relPath(fname, cwd string) string {
 	if strings.HasPrefix(fname, cwd) {
 		frel := strings.TrimPrefix(fname, cwd+string(filepath.Separator))
 		if fname != frel {
-			return frel, int(line), int(col)
+			return frel
 		}
 	}
 
-	return fname, int(line), int(col)
+	return fname
 }
 
-// regexp pattern: https://regex101.com/r/bUVZpH/2
-var errRe = regexp.MustCompile(`(?m)^(?:#\s([[:graph:]]+))?(?:[\s\t]+)?([^\s:]+):(\d+)(?::(\d+))?(?::)?\s(.*)`)
+var (
+	// errPkgRe matches the package header line. like "# nvim-go/command"
+	errPkgRe = regexp.MustCompile(`^#\s+([[:graph:]]+)`)
+	// errRe matches the error line. Also matches the "vet: " prefixed go vet message.
+	errRe = regexp.MustCompile(`^\s*(?:vet:\s+)?([^\s:]+):(\d+)(?::(\d+))?(?::)?\s(.*)`)
+	// errCaretRe matches the caret line of the cgo (C compiler) error. like "    ^~~~"
+	errCaretRe = regexp.MustCompile(`^\s*[\^~]+\s*$`)
+)
 
-// ParseError parses a typical Go tools error messages.
+// ParseError parses a typical Go tools error messages, such as the go build,
+// go vet and cgo errors.
+// The indented continuation lines of the compiler error, such as "have" and
+// "want" type suggestion, are attached to the preceding error text.
 func ParseError(errs []byte, cwd string, buildContext *ctx.Build, ignoreDirs []string) ([]*nvim.QuickfixError, error) {
 	var (
 		// packagePath for the save the error files parent directory.
 		// It will be re-assigned if "# " is in the error message.
 		packagePath string
 		errlist     []*nvim.QuickfixError
+		// last is the preceding error for attach the continuation lines.
+		last *nvim.QuickfixError
 	)
 
-	// m[1]: package path with "# " prefix
-	// m[2]: error files relative path
-	// m[3]: line number of error point
-	// m[4]: column number of error point
-	// m[5]: error description text
-	for _, m := range errRe.FindAllSubmatch(errs, -1) {
-		if m[1] != nil {
+	for _, line := range bytes.Split(errs, []byte{'\n'}) {
+		if m := errPkgRe.FindSubmatch(line); m != nil {
 			// Save the package path for the second subsequent errors
 			packagePath = string(m[1])
+			last = nil
+			continue
 		}
-		filename := string(m[2])
+
+		// m[1]: error files relative path
+		// m[2]: line number of error point
+		// m[3]: column number of error point
+		// m[4]: error description text
+		m := errRe.FindSubmatch(line)
+		if m == nil {
+			isIndented := len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+			if last != nil && isIndented && !errCaretRe.Match(line) {
+				last.Text += "\n" + string(bytes.TrimSpace(line))
+			} else if !isIndented {
+				last = nil
+			}
+			continue
+		}
+		filename := string(m[1])
 
 		// Avoid the local package error. like "package foo" and edit "cmd/foo/main.go"
 		if !strings.Contains(filename, "../") && (!filepath.IsAbs(filename) && packagePath != "") {
@@ -252,7 +603,13 @@ func ParseError(errs []byte, cwd string, buildContext *ctx.Build, ignoreDirs []s
 			}
 		case "gb":
 			// gb compiler error messages is relative filename path of project root dir
-			if !filepath.IsAbs(filename) {
+			switch {
+			case filepath.IsAbs(filename):
+				// nothing to do
+			case strings.HasPrefix(filename, "src"+string(filepath.Separator)):
+				// the go vet run by the gb vet reports the path relative to the project root
+				filename = filepath.Join(buildContext.ProjectRoot, filename)
+			default:
 				filename = filepath.Join(buildContext.ProjectRoot, "src", filename)
 			}
 		default:
@@ -263,25 +620,27 @@ func ParseError(errs []byte, cwd string, buildContext *ctx.Build, ignoreDirs []s
 		filename = pathutil.Rel(cwd, filename)
 		if ignoreDirs != nil {
 			if contains(filename, ignoreDirs) {
+				last = nil
 				continue
 			}
 		}
 
 		// line is necessary for error messages
-		line, err := strconv.Atoi(string(m[3]))
+		lnum, err := strconv.Atoi(string(m[2]))
 		if err != nil {
 			return nil, err
 		}
 
 		// Ignore err because fail strconv.Atoi will assign 0 to col
-		col, _ := strconv.Atoi(string(m[4]))
+		col, _ := strconv.Atoi(string(m[3]))
 
-		errlist = append(errlist, &nvim.QuickfixError{
+		last = &nvim.QuickfixError{
 			FileName: filename,
-			LNum:     line,
+			LNum:     lnum,
 			Col:      col,
-			Text:     string(bytes.TrimSpace(m[5])),
-		})
+			Text:     string(bytes.TrimSpace(m[4])),
+		}
+		errlist = append(errlist, last)
 	}
 
 	return errlist, nil
@@ -12,6 +12,7 @@ import (
 	"reflect"
 	"testing"
 
+	"nvim-go/config"
 	"nvim-go/ctx"
 
 	"github.com/neovim/go-client/nvim"
@@ -51,6 +52,53 @@ func TestSplitPos(t *testing.T) {
 			want1: 482,
 			want2: 18,
 		},
+		{
+			name: "relative path",
+			args: args{
+				pos: filepath.Join(cwd, "locationlist.go") + ":340:6",
+				cwd: cwd,
+			},
+			want:  "locationlist.go",
+			want1: 340,
+			want2: 6,
+		},
+		{
+			name: "Windows drive letter",
+			args: args{
+				pos: `C:\Go\src\strings\strings.go:287:6`,
+				cwd: cwd,
+			},
+			want:  `C:\Go\src\strings\strings.go`,
+			want1: 287,
+			want2: 6,
+		},
+		{
+			name: "Windows drive letter without column",
+			args: args{
+				pos: `C:\Go\src\strings\strings.go:287`,
+				cwd: cwd,
+			},
+			want:  `C:\Go\src\strings\strings.go`,
+			want1: 287,
+		},
+		{
+			name: "path contains colons",
+			args: args{
+				pos: "/tmp/a:b/c:1d/foo.go:3:14",
+				cwd: cwd,
+			},
+			want:  "/tmp/a:b/c:1d/foo.go",
+			want1: 3,
+			want2: 14,
+		},
+		{
+			name: "no position",
+			args: args{
+				pos: "-",
+				cwd: cwd,
+			},
+			want: "-",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -152,7 +200,7 @@ locationlist.go:199: ParseError redeclared in this block
 					FileName: "../src/nvim-go/nvim/quickfix/locationlist.go",
 					LNum:     199,
 					Col:      0,
-					Text:     "ParseError redeclared in this block",
+					Text:     "ParseError redeclared in this block\nprevious declaration at locationlist.go:149",
 				},
 			},
 			wantErr: false,
@@ -288,13 +336,13 @@ FATAL: command "build" failed: exit status 2`),
 					FileName: "../src/nvim-go/command/delve/delve.go",
 					LNum:     129,
 					Col:      0,
-					Text:     "too many arguments in call to d.startServer",
+					Text:     "too many arguments in call to d.startServer\nhave (string, []string, string)\nwant (serverConfig, serverConfig)",
 				},
 				&nvim.QuickfixError{
 					FileName: "../src/nvim-go/command/delve/delve.go",
 					LNum:     159,
 					Col:      0,
-					Text:     "too many arguments in call to d.startServer",
+					Text:     "too many arguments in call to d.startServer\nhave (string, nil, string)\nwant (serverConfig, serverConfig)",
 				},
 				&nvim.QuickfixError{
 					FileName: "../src/nvim-go/command/delve/server.go",
@@ -389,6 +437,87 @@ cmd/gaos/versions.go:14: initialization loop:
 			},
 			wantErr: false,
 		},
+		{
+			name: "go vet",
+			args: args{
+				errors: []byte(`vet: ./vet.go:25:2: unreachable code
+vet.go:40: arg s for printf verb %d of wrong type: string
+exit status 1`),
+				cwd: cwd,
+				buildContext: &ctx.Build{
+					Tool: "go",
+				},
+			},
+			want: []*nvim.QuickfixError{
+				&nvim.QuickfixError{
+					FileName: "vet.go",
+					LNum:     25,
+					Col:      2,
+					Text:     "unreachable code",
+				},
+				&nvim.QuickfixError{
+					FileName: "vet.go",
+					LNum:     40,
+					Col:      0,
+					Text:     "arg s for printf verb %d of wrong type: string",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "cgo",
+			args: args{
+				errors: []byte(`# nvim-go/cgo
+./cgo.go:5:10: fatal error: 'foo.h' file not found
+ #include "foo.h"
+          ^~~~~~~
+1 error generated.`),
+				cwd: cwd,
+				buildContext: &ctx.Build{
+					Tool:        "gb",
+					ProjectRoot: gbProjectDir,
+				},
+			},
+			want: []*nvim.QuickfixError{
+				&nvim.QuickfixError{
+					FileName: "../src/nvim-go/cgo/cgo.go",
+					LNum:     5,
+					Col:      10,
+					Text:     "fatal error: 'foo.h' file not found\n#include \"foo.h\"",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "go build multiline",
+			args: args{
+				errors: []byte(`# nvim-go/command
+buffer.go:10:2: cannot use x (type int) as type string in assignment
+	have (int)
+	want (string)
+buffer.go:12:6: undefined: y`),
+				cwd: cwd,
+				buildContext: &ctx.Build{
+					Tool:        "gb",
+					ProjectRoot: gbProjectDir,
+				},
+			},
+			want: []*nvim.QuickfixError{
+				&nvim.QuickfixError{
+					FileName: "../src/nvim-go/command/buffer.go",
+					LNum:     10,
+					Col:      2,
+					Text:     "cannot use x (type int) as type string in assignment\nhave (int)\nwant (string)",
+				},
+				&nvim.QuickfixError{
+					FileName: "../src/nvim-go/command/buffer.go",
+					LNum:     12,
+					Col:      6,
+					Text:     "undefined: y",
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	build.Default.GOPATH = gopath
@@ -414,3 +543,351 @@ cmd/gaos/versions.go:14: initialization loop:
 		})
 	}
 }
+
+func TestListTypeOf(t *testing.T) {
+	defer config.Set(config.Current())
+
+	tests := []struct {
+		name          string
+		errorListType string
+		listType      map[string]string
+		cmd           string
+		want          ErrorListType
+	}{
+		{
+			name:          "default locationlist",
+			errorListType: "locationlist",
+			cmd:           "Build",
+			want:          LocationList,
+		},
+		{
+			name:          "default quickfix",
+			errorListType: "quickfix",
+			cmd:           "Build",
+			want:          Quickfix,
+		},
+		{
+			name:          "per command quickfix",
+			errorListType: "locationlist",
+			listType:      map[string]string{"Build": "quickfix"},
+			cmd:           "Build",
+			want:          Quickfix,
+		},
+		{
+			name:          "per command location",
+			errorListType: "quickfix",
+			listType:      map[string]string{"Guru": "location"},
+			cmd:           "Guru",
+			want:          LocationList,
+		},
+		{
+			name:          "other command uses default",
+			errorListType: "locationlist",
+			listType:      map[string]string{"Build": "quickfix"},
+			cmd:           "Vet",
+			want:          LocationList,
+		},
+	}
+	for _, tt := range tests {
+		config.Update(func(cfg *config.Config) {
+			cfg.Global.ErrorListType = tt.errorListType
+			cfg.Global.ListType = tt.listType
+		})
+		if got := ListTypeOf(tt.cmd); got != tt.want {
+			t.Errorf("%q. ListTypeOf(%v) = %v, want %v", tt.name, tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestOpenList(t *testing.T) {
+	v := TestNvim(t)
+	w, err := v.CurrentWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := []*nvim.QuickfixError{{FileName: "foo.go", LNum: 1, Col: 1, Text: "foo"}}
+	tests := []struct {
+		name    string
+		t       ErrorListType
+		getlist string
+	}{
+		{name: "quickfix", t: Quickfix, getlist: "getqflist()"},
+		{name: "locationlist", t: LocationList, getlist: "getloclist(0)"},
+	}
+	for _, tt := range tests {
+		if err := SetList(v, w, tt.t, "", list); err != nil {
+			t.Fatalf("%q. SetList(%v) error = %v", tt.name, tt.t, err)
+		}
+		var got []interface{}
+		if err := v.Eval(tt.getlist, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(list) {
+			t.Errorf("%q. SetList(%v) %s = %v, want %d items", tt.name, tt.t, tt.getlist, got, len(list))
+		}
+
+		// keep the cursor focus to the source window
+		if err := OpenList(v, w, tt.t, list, true); err != nil {
+			t.Fatalf("%q. OpenList(%v) error = %v", tt.name, tt.t, err)
+		}
+		cw, err := v.CurrentWindow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cw != w {
+			t.Errorf("%q. OpenList(%v, keep: true) current window = %v, want %v", tt.name, tt.t, cw, w)
+		}
+		if err := CloseList(v, tt.t); err != nil {
+			t.Fatalf("%q. CloseList(%v) error = %v", tt.name, tt.t, err)
+		}
+	}
+}
+
+func TestOpenList_Empty(t *testing.T) {
+	defer config.Set(config.Update(func(cfg *config.Config) { cfg.Global.AutoClose = 1 }))
+
+	v := TestNvim(t)
+	w, err := v.CurrentWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := []*nvim.QuickfixError{{FileName: "foo.go", LNum: 1, Col: 1, Text: "foo"}}
+	qfwinid := func() int {
+		var info struct {
+			WinID int `msgpack:"winid"`
+		}
+		if err := v.Call("getqflist", &info, map[string]interface{}{"winid": 1}); err != nil {
+			t.Fatal(err)
+		}
+		return info.WinID
+	}
+
+	// prior run results
+	if err := SetList(v, w, Quickfix, "", list); err != nil {
+		t.Fatal(err)
+	}
+	if err := OpenList(v, w, Quickfix, list, true); err != nil {
+		t.Fatal(err)
+	}
+	if qfwinid() == 0 {
+		t.Fatal("OpenList did not open the quickfix window")
+	}
+
+	// empty result closes the window and clears the stale entries
+	if err := OpenList(v, w, Quickfix, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if id := qfwinid(); id != 0 {
+		t.Errorf("OpenList(empty) quickfix window = %v, want closed", id)
+	}
+	var got []interface{}
+	if err := v.Eval("getqflist()", &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("OpenList(empty) getqflist() = %v, want empty", got)
+	}
+
+	// the user list is left as is
+	if err := v.Call("setqflist", nil, list, "r"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Command("copen | wincmd p"); err != nil {
+		t.Fatal(err)
+	}
+	if err := OpenList(v, w, Quickfix, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if qfwinid() == 0 {
+		t.Errorf("OpenList(empty) closed the quickfix window which opened by the user")
+	}
+}
+
+func TestListHeight(t *testing.T) {
+	tests := []struct {
+		n, max int
+		want   int
+	}{
+		{n: 0, max: 10, want: 1},
+		{n: 1, max: 10, want: 1},
+		{n: 3, max: 10, want: 3},
+		{n: 10, max: 10, want: 10},
+		{n: 500, max: 10, want: 10},
+		{n: 5, max: 1, want: 1},
+	}
+	for _, tt := range tests {
+		if got := listHeight(tt.n, tt.max); got != tt.want {
+			t.Errorf("listHeight(%d, %d) = %d, want %d", tt.n, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestDedupList(t *testing.T) {
+	list := func() []*nvim.QuickfixError {
+		return []*nvim.QuickfixError{
+			{FileName: "foo.go", LNum: 1, Col: 2, Text: "foo"},
+			{FileName: "bar.go", LNum: 3, Col: 4, Text: "bar"},
+			{FileName: "foo.go", LNum: 1, Col: 2, Text: "foo"},
+			{FileName: "foo.go", LNum: 1, Col: 2, Text: "other message"},
+			{FileName: "foo.go", LNum: 1, Col: 3, Text: "foo"},
+			{FileName: "bar.go", LNum: 3, Col: 4, Text: "bar"},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		dedup int64
+		want  []*nvim.QuickfixError
+	}{
+		{
+			name:  "dedup",
+			dedup: 1,
+			want: []*nvim.QuickfixError{
+				{FileName: "foo.go", LNum: 1, Col: 2, Text: "foo"},
+				{FileName: "bar.go", LNum: 3, Col: 4, Text: "bar"},
+				{FileName: "foo.go", LNum: 1, Col: 2, Text: "other message"},
+				{FileName: "foo.go", LNum: 1, Col: 3, Text: "foo"},
+			},
+		},
+		{
+			name:  "disabled",
+			dedup: 0,
+			want:  list(),
+		},
+	}
+	for _, tt := range tests {
+		func() {
+			defer config.Set(config.Update(func(cfg *config.Config) { cfg.Global.Dedup = tt.dedup }))
+			if got := dedupList(list()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q. dedupList() = %v, want %v", tt.name, got, tt.want)
+			}
+		}()
+	}
+}
+
+func TestDedupAgainst(t *testing.T) {
+	defer config.Set(config.Update(func(cfg *config.Config) { cfg.Global.Dedup = 1 }))
+
+	existing := []*nvim.QuickfixError{
+		{FileName: "foo.go", LNum: 1, Col: 2, Text: "foo"},
+	}
+	list := []*nvim.QuickfixError{
+		{FileName: "foo.go", LNum: 1, Col: 2, Text: "foo"},
+		{FileName: "bar.go", LNum: 3, Col: 4, Text: "bar"},
+		{FileName: "bar.go", LNum: 3, Col: 4, Text: "bar"},
+	}
+	want := []*nvim.QuickfixError{
+		{FileName: "bar.go", LNum: 3, Col: 4, Text: "bar"},
+	}
+	if got := dedupAgainst(existing, list); !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupAgainst() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeFileName(t *testing.T) {
+	var (
+		root = filepath.FromSlash("/go/src/foo.org/foo")
+		cwd  = filepath.Join(root, "cmd", "foo")
+	)
+
+	tests := []struct {
+		name     string
+		fname    string
+		relative bool
+		want     string
+	}{
+		{
+			name:     "in-tree file of cwd",
+			fname:    filepath.Join(cwd, "main.go"),
+			relative: true,
+			want:     "main.go",
+		},
+		{
+			name:     "in-tree file of parent directory",
+			fname:    filepath.Join(root, "foo.go"),
+			relative: true,
+			want:     filepath.Join("..", "..", "foo.go"),
+		},
+		{
+			name:     "in-tree relative file",
+			fname:    filepath.Join("..", "..", "foo.go"),
+			relative: true,
+			want:     filepath.Join("..", "..", "foo.go"),
+		},
+		{
+			name:     "out-of-tree file",
+			fname:    filepath.FromSlash("/usr/local/go/src/fmt/print.go"),
+			relative: true,
+			want:     filepath.FromSlash("/usr/local/go/src/fmt/print.go"),
+		},
+		{
+			name:     "out-of-tree sibling project",
+			fname:    filepath.FromSlash("/go/src/foo.org/foobar/bar.go"),
+			relative: true,
+			want:     filepath.FromSlash("/go/src/foo.org/foobar/bar.go"),
+		},
+		{
+			name:     "absolute paths",
+			fname:    "main.go",
+			relative: false,
+			want:     filepath.Join(cwd, "main.go"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := normalizeFileName(cwd, root, tt.fname, tt.relative); got != tt.want {
+				t.Errorf("%q. normalizeFileName(%v, %v, %v, %v) = %v, want %v", tt.name, cwd, root, tt.fname, tt.relative, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFileNames(t *testing.T) {
+	defer config.Set(config.Update(func(cfg *config.Config) { cfg.Global.RelativePaths = 1 }))
+
+	cwd := filepath.FromSlash("/go/src/foo.org/foo")
+	abs := filepath.Join(cwd, "foo.go")
+	tests := []struct {
+		name string
+		cwd  string
+		want []string
+	}{
+		{name: "no cwd", cwd: "", want: []string{"bar.go", abs}},
+		{name: "cwd", cwd: cwd, want: []string{"bar.go", "foo.go"}},
+	}
+	for _, tt := range tests {
+		list := []*nvim.QuickfixError{{FileName: "bar.go"}, {FileName: abs}}
+		got := normalizeFileNames(tt.cwd, list)
+		var names []string
+		for _, e := range got {
+			names = append(names, e.FileName)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%q. normalizeFileNames(%v) = %v, want %v", tt.name, tt.cwd, names, tt.want)
+		}
+		if list[0].FileName != "bar.go" {
+			t.Errorf("%q. normalizeFileNames(%v) changed the list to %v", tt.name, tt.cwd, list[0].FileName)
+		}
+	}
+}
+
+func TestCycleCmd(t *testing.T) {
+	tests := []struct {
+		t       ErrorListType
+		forward bool
+		want    string
+	}{
+		{t: LocationList, forward: true, want: "try | silent lnext | catch /E553/ | silent lfirst | endtry"},
+		{t: LocationList, forward: false, want: "try | silent lprevious | catch /E553/ | silent llast | endtry"},
+		{t: Quickfix, forward: true, want: "try | silent cnext | catch /E553/ | silent cfirst | endtry"},
+	}
+	for _, tt := range tests {
+		if got := CycleCmd(tt.t, tt.forward); got != tt.want {
+			t.Errorf("CycleCmd(%v, %v) = %v, want %v", tt.t, tt.forward, got, tt.want)
+		}
+	}
+}
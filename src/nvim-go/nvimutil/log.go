@@ -0,0 +1,222 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// Level represents the verbosity level of the messages.
+type Level int
+
+const (
+	// LevelError is the level of the error messages, which are always echoed.
+	LevelError Level = iota
+	// LevelWarn is the level of the warning messages.
+	LevelWarn
+	// LevelInfo is the level of the informational messages, such as the
+	// guru query which found nothing.
+	LevelInfo
+	// LevelDebug is the level of the debug messages.
+	LevelDebug
+)
+
+var levelNames = [...]string{"error", "warn", "info", "debug"}
+
+func (l Level) String() string {
+	if l < LevelError || l > LevelDebug {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the Level of the s level name, or LevelInfo if s is
+// unknown.
+func ParseLevel(s string) Level {
+	for i, name := range levelNames {
+		if name == s {
+			return Level(i)
+		}
+	}
+	return LevelInfo
+}
+
+// Enabled reports whether the level messages are echoed by config.LogLevel.
+func Enabled(level Level) bool {
+	return level <= ParseLevel(config.LogLevel())
+}
+
+// maxLogLines is the maximum number of the log history lines.
+const maxLogLines = 1000
+
+// logBufferName is the buffer name of the GoLog buffer.
+const logBufferName = "__GoLog__"
+
+// logHistory holds the recent messages of all levels for the GoLog buffer.
+var logHistory struct {
+	mu    sync.Mutex
+	lines []string
+
+	// v and buffer are the opened GoLog buffer which the new lines are
+	// appended to, and empty reports whether the buffer has no lines yet.
+	v      *nvim.Nvim
+	buffer nvim.Buffer
+	empty  bool
+}
+
+// record appends the message to the log history with the timestamp, and to
+// the GoLog buffer if it's opened.
+func record(level Level, prefix, msg string) {
+	if prefix != "" {
+		msg = prefix + ": " + msg
+	}
+	line := fmt.Sprintf("%s [%s] %s", time.Now().Format("15:04:05.000"), level, msg)
+
+	logHistory.mu.Lock()
+	logHistory.lines = append(logHistory.lines, line)
+	if n := len(logHistory.lines) - maxLogLines; n > 0 {
+		logHistory.lines = append([]string(nil), logHistory.lines[n:]...)
+	}
+	v, b := logHistory.v, logHistory.buffer
+	start := -1
+	if logHistory.empty {
+		start = 0 // replace the initial empty line
+		logHistory.empty = false
+	}
+	logHistory.mu.Unlock()
+
+	// don't hold the lock over the request, the Neovim may be waiting for
+	// the other handler which logs
+	if v == nil {
+		return
+	}
+	if err := v.SetBufferLines(b, start, -1, true, ToBufferLines([]byte(line))); err != nil {
+		// the GoLog buffer was wiped
+		logHistory.mu.Lock()
+		if logHistory.buffer == b {
+			logHistory.v = nil
+		}
+		logHistory.mu.Unlock()
+	}
+}
+
+// LogLines returns the copy of the log history lines, which are the
+// timestamped messages of all levels in logged order.
+func LogLines() []string {
+	logHistory.mu.Lock()
+	defer logHistory.mu.Unlock()
+	return append([]string(nil), logHistory.lines...)
+}
+
+// OpenLogBuffer opens the GoLog scratch buffer which shows the log history,
+// and the later messages are appended to it while it's opened.
+func OpenLogBuffer(v *nvim.Nvim) error {
+	option := map[NvimOption]map[string]interface{}{
+		BufferOption: {
+			BufOptionBufhidden: BufhiddenWipe,
+			BufOptionBuflisted: false,
+			BufOptionBuftype:   BuftypeNofile,
+			BufOptionSwapfile:  false,
+		},
+	}
+	buf := NewBuffer(v)
+	buf.Reuse = true
+	if _, err := buf.Create(logBufferName, "", "belowright new", option); err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines := LogLines()
+	if err := buf.SetBufferLines(0, -1, true, []byte(strings.Join(lines, "\n"))); err != nil {
+		return err
+	}
+	logHistory.mu.Lock()
+	logHistory.v = v
+	logHistory.buffer = buf.Buffer()
+	logHistory.empty = len(lines) == 0
+	logHistory.mu.Unlock()
+
+	return errors.WithStack(v.Command("normal! G"))
+}
+
+// ClearLog clears the log history and the opened GoLog buffer.
+func ClearLog() error {
+	logHistory.mu.Lock()
+	logHistory.lines = nil
+	v, b := logHistory.v, logHistory.buffer
+	logHistory.empty = v != nil
+	logHistory.mu.Unlock()
+
+	if v == nil || !IsBufferValid(v, b) {
+		return nil
+	}
+	return errors.WithStack(v.SetBufferLines(b, 0, -1, true, nil))
+}
+
+// Logger writes the messages of the subsystem to the log history with the
+// subsystem prefix. The messages are never echoed, and are only seen in the
+// GoLog buffer.
+type Logger struct {
+	prefix string
+}
+
+// NewLogger returns the new Logger of the subsystem such as "delve".
+func NewLogger(subsystem string) *Logger {
+	return &Logger{prefix: subsystem}
+}
+
+// Printf logs the info level message.
+func (l *Logger) Printf(format string, a ...interface{}) {
+	record(LevelInfo, l.prefix, fmt.Sprintf(format, a...))
+}
+
+// Debugf logs the debug level message.
+func (l *Logger) Debugf(format string, a ...interface{}) {
+	record(LevelDebug, l.prefix, fmt.Sprintf(format, a...))
+}
+
+// Log records the message to the log history, and echoes it if the level is
+// enabled by config.LogLevel. The error messages are echoed as the vim
+// 'echoerr', and the warning messages are highlighted by WarningMsg.
+func Log(v *nvim.Nvim, level Level, prefix, format string, a ...interface{}) error {
+	msg := fmt.Sprintf(format, a...)
+	record(level, prefix, msg)
+	if !Enabled(level) {
+		return nil
+	}
+
+	switch level {
+	case LevelError:
+		if prefix != "" {
+			msg = prefix + ": " + msg
+		}
+		return v.WritelnErr(msg)
+	case LevelWarn:
+		return EchohlAfter(v, prefix, "WarningMsg", "%s", msg)
+	default:
+		if prefix != "" {
+			msg = prefix + ": " + msg
+		}
+		return v.WriteOut(msg + "\n")
+	}
+}
+
+// Info echoes the informational message such as the query which found
+// nothing. It's suppressed if config.LogLevel is "warn" or "error".
+func Info(v *nvim.Nvim, prefix, format string, a ...interface{}) error {
+	return Log(v, LevelInfo, prefix, format, a...)
+}
+
+// Debugf echoes the debug message only if config.LogLevel is "debug".
+func Debugf(v *nvim.Nvim, prefix, format string, a ...interface{}) error {
+	return Log(v, LevelDebug, prefix, format, a...)
+}
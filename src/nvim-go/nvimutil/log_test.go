@@ -0,0 +1,119 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import (
+	"strings"
+	"testing"
+
+	"nvim-go/config"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Level
+	}{
+		{s: "error", want: LevelError},
+		{s: "warn", want: LevelWarn},
+		{s: "info", want: LevelInfo},
+		{s: "debug", want: LevelDebug},
+		{s: "verbose", want: LevelInfo},
+	}
+	for _, tt := range tests {
+		if got := ParseLevel(tt.s); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		logLevel string
+		level    Level
+		want     bool
+	}{
+		{logLevel: "error", level: LevelError, want: true},
+		{logLevel: "error", level: LevelInfo, want: false},
+		{logLevel: "error", level: LevelDebug, want: false},
+		{logLevel: "info", level: LevelWarn, want: true},
+		{logLevel: "info", level: LevelInfo, want: true},
+		{logLevel: "info", level: LevelDebug, want: false},
+		{logLevel: "debug", level: LevelDebug, want: true},
+	}
+	defer config.Set(config.Current())
+	for _, tt := range tests {
+		config.Update(func(cfg *config.Config) { cfg.Global.LogLevel = tt.logLevel })
+		if got := Enabled(tt.level); got != tt.want {
+			t.Errorf("Enabled(%v) with %q level = %v, want %v", tt.level, tt.logLevel, got, tt.want)
+		}
+	}
+}
+
+func TestLog_Hidden(t *testing.T) {
+	defer config.Set(config.Current())
+	config.Update(func(cfg *config.Config) { cfg.Global.LogLevel = "error" })
+
+	// the nil Nvim panics if Log tries to echo the hidden message
+	if err := Log(nil, LevelDebug, "GoTest", "debug %d", 1); err != nil {
+		t.Fatalf("Log(debug) error = %v", err)
+	}
+	if err := Info(nil, "Guru", "referrers not found"); err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+
+	lines := LogLines()
+	if len(lines) < 2 {
+		t.Fatalf("LogLines() = %q, want the hidden messages", lines)
+	}
+	for i, want := range []string{"[debug] GoTest: debug 1", "[info] Guru: referrers not found"} {
+		if got := lines[len(lines)-2+i]; !strings.HasSuffix(got, want) {
+			t.Errorf("LogLines()[%d] = %q, want suffix %q", len(lines)-2+i, got, want)
+		}
+	}
+}
+
+func TestLogger(t *testing.T) {
+	NewLogger("delve").Debugf("state: %d", 1)
+
+	lines := LogLines()
+	if got, want := lines[len(lines)-1], "[debug] delve: state: 1"; !strings.HasSuffix(got, want) {
+		t.Errorf("LogLines() last = %q, want suffix %q", got, want)
+	}
+}
+
+func TestOpenLogBuffer(t *testing.T) {
+	n := TestNvim(t)
+	defer ClearLog()
+
+	if err := ClearLog(); err != nil {
+		t.Fatal(err)
+	}
+	if err := OpenLogBuffer(n); err != nil {
+		t.Fatal(err)
+	}
+	b, err := n.CurrentBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := NewLogger("delve")
+	logger.Printf("detached delve client")
+	logger.Debugf("thread:\n{}")
+
+	lines, err := n.BufferLines(b, 0, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"[info] delve: detached delve client", "[debug] delve: thread:", "{}"}
+	if len(lines) != len(want) {
+		t.Fatalf("GoLog buffer lines = %q, want %q", lines, want)
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(string(lines[i]), w) {
+			t.Errorf("GoLog buffer line %d = %q, want suffix %q", i+1, lines[i], w)
+		}
+	}
+}
@@ -18,6 +18,12 @@ const (
 	WindowVar
 	// TabpageVar tabpage var type.
 	TabpageVar
+	// BufferMapping buffer local normal mode mapping type with no remap. The
+	// key is the lhs and the value is the rhs.
+	BufferMapping
+	// BufferAutocmd buffer local autocmd type. The key is the event and the
+	// value is the command.
+	BufferAutocmd
 )
 
 const (
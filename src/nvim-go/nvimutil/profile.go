@@ -5,17 +5,98 @@
 package nvimutil
 
 import (
-	"log"
+	"sort"
+	"sync"
 	"time"
 
 	"nvim-go/config"
 )
 
-// Profile measurement of the time it took to any func and output log file.
+// maxProfileSamples is the number of the recent durations which kept for
+// the median of each name.
+const maxProfileSamples = 1000
+
+// ProfileStat represents the aggregated durations of a name.
+type ProfileStat struct {
+	Name   string
+	Count  int
+	Min    time.Duration
+	Median time.Duration
+	Max    time.Duration
+}
+
+// profileRecord represents the recorded durations of a name.
+type profileRecord struct {
+	count    int
+	min, max time.Duration
+	samples  []time.Duration // the recent durations, up to maxProfileSamples
+}
+
+var profiles = struct {
+	sync.Mutex
+	records map[string]*profileRecord
+}{records: make(map[string]*profileRecord)}
+
+// Profile measurement of the time it took to any func, records it to the
+// stats of name and output log file.
 // Usage: defer nvim.Profile(time.Now(), "func name")
 func Profile(start time.Time, name string) {
-	if config.DebugEnable {
-		elapsed := time.Since(start).Seconds()
-		log.Printf("%s: %fsec\n", name, elapsed)
+	elapsed := time.Since(start)
+	recordProfile(name, elapsed)
+
+	if config.DebugEnable() {
+		NewLogger("profile").Debugf("%s: %fsec", name, elapsed.Seconds())
+	}
+}
+
+// recordProfile records the elapsed duration to the stats of name.
+func recordProfile(name string, elapsed time.Duration) {
+	profiles.Lock()
+	defer profiles.Unlock()
+
+	r, ok := profiles.records[name]
+	if !ok {
+		r = &profileRecord{min: elapsed, max: elapsed}
+		profiles.records[name] = r
 	}
+	r.count++
+	if elapsed < r.min {
+		r.min = elapsed
+	}
+	if elapsed > r.max {
+		r.max = elapsed
+	}
+	if len(r.samples) == maxProfileSamples {
+		r.samples = append(r.samples[:0], r.samples[1:]...)
+	}
+	r.samples = append(r.samples, elapsed)
+}
+
+// ProfileStats returns the aggregated stats of the recorded names, sorted by
+// name. The median is of the recent durations.
+func ProfileStats() []ProfileStat {
+	profiles.Lock()
+	defer profiles.Unlock()
+
+	stats := make([]ProfileStat, 0, len(profiles.records))
+	for name, r := range profiles.records {
+		sorted := append([]time.Duration(nil), r.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		median := sorted[len(sorted)/2]
+		if len(sorted)%2 == 0 {
+			median = (sorted[len(sorted)/2-1] + median) / 2
+		}
+		stats = append(stats, ProfileStat{Name: name, Count: r.count, Min: r.min, Median: median, Max: r.max})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// ResetProfileStats clears the recorded stats.
+func ResetProfileStats() {
+	profiles.Lock()
+	defer profiles.Unlock()
+
+	profiles.records = make(map[string]*profileRecord)
 }
@@ -0,0 +1,51 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	ResetProfileStats()
+	defer ResetProfileStats()
+
+	var wg sync.WaitGroup
+	for _, d := range []time.Duration{3 * time.Second, 1 * time.Second, 4 * time.Second, 2 * time.Second} {
+		wg.Add(1)
+		go func(d time.Duration) {
+			defer wg.Done()
+			Profile(time.Now().Add(-d), "GoFoo")
+		}(d)
+	}
+	Profile(time.Now().Add(-time.Second), "GoBar")
+	wg.Wait()
+
+	stats := ProfileStats()
+	if len(stats) != 2 {
+		t.Fatalf("ProfileStats() = %v, want the 2 names", stats)
+	}
+	if stats[0].Name != "GoBar" || stats[0].Count != 1 {
+		t.Errorf("ProfileStats()[0] = %+v, want GoBar called once", stats[0])
+	}
+
+	got := stats[1]
+	if got.Name != "GoFoo" || got.Count != 4 {
+		t.Errorf("ProfileStats()[1] = %+v, want GoFoo called 4 times", got)
+	}
+	// the elapsed durations are slightly longer than the start offsets
+	approx := func(d, want time.Duration) bool { return want <= d && d < want+500*time.Millisecond }
+	if !approx(got.Min, 1*time.Second) {
+		t.Errorf("GoFoo min = %v, want 1s", got.Min)
+	}
+	if !approx(got.Median, 2500*time.Millisecond) {
+		t.Errorf("GoFoo median = %v, want 2.5s", got.Median)
+	}
+	if !approx(got.Max, 4*time.Second) {
+		t.Errorf("GoFoo max = %v, want 4s", got.Max)
+	}
+}
@@ -6,6 +6,10 @@ package nvimutil
 
 import (
 	"fmt"
+	"sync"
+	"unicode"
+
+	"nvim-go/config"
 
 	"github.com/neovim/go-client/nvim"
 	"github.com/pkg/errors"
@@ -43,6 +47,11 @@ const (
 	// RestartSymbol symbol of restart.
 	// ⟲  ANTICLOCKWISE GAPPED CIRCLE ARROW    (U+27F2)
 	RestartSymbol = "\u27f2"
+
+	// DefaultBreakpointSymbol fallback symbol of breakpoint if config.DelveBreakpointSymbol() is unset.
+	DefaultBreakpointSymbol = "B>"
+	// DefaultProgramCounterSymbol fallback symbol of program counter if config.DelvePCSymbol() is unset.
+	DefaultProgramCounterSymbol = "->"
 )
 
 // Sign represents a Neovim sign.
@@ -55,10 +64,96 @@ type Sign struct {
 	LastID   int
 	LastLine int
 	LastFile string
+
+	mu     sync.Mutex
+	placed map[int]string // map[id]file
+}
+
+// signHighlightDefaults is the default link destination of the sign highlight
+// groups which used if the colorscheme doesn't define it.
+var signHighlightDefaults = map[string]string{
+	"delveBreakpointSign":  "Error",
+	"delvePCSign":          "Search",
+	"delvePCLine":          "CursorLine",
+	"GoStaticcheckError":   "Error",
+	"GoStaticcheckWarning": "WarningMsg",
+}
+
+// signHighlights is the defined highlight groups of the signs.
+var signHighlights = struct {
+	sync.Mutex
+	groups map[string]bool
+}{groups: make(map[string]bool)}
+
+// highlighter represents a Neovim client which can define the highlight groups.
+type highlighter interface {
+	Call(fname string, result interface{}, args ...interface{}) error
+	Command(cmd string) error
+}
+
+// defineSignHighlight defines the group highlight group if it isn't already
+// defined, or if the user overrides it by config.SignHighlight().
+func defineSignHighlight(v highlighter, group string) error {
+	if group == "" {
+		return nil
+	}
+
+	if dest, ok := config.SignHighlight()[group]; ok {
+		return errors.WithStack(v.Command(fmt.Sprintf("highlight! link %s %s", group, dest)))
+	}
+
+	dest, ok := signHighlightDefaults[group]
+	if !ok {
+		return nil
+	}
+	var exists int
+	if err := v.Call("hlexists", &exists, group); err != nil {
+		return errors.WithStack(err)
+	}
+	if exists != 0 {
+		return nil
+	}
+
+	return errors.WithStack(v.Command(fmt.Sprintf("highlight default link %s %s", group, dest)))
+}
+
+// DefineSignHighlights re-defines the highlight groups of the defined signs.
+// Useful for the ColorScheme autocmd, because the colorscheme clears the
+// highlight groups.
+func DefineSignHighlights(v *nvim.Nvim) error {
+	signHighlights.Lock()
+	defer signHighlights.Unlock()
+
+	for group := range signHighlights.groups {
+		if err := defineSignHighlight(v, group); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // NewSign define new sign and return the Sign type structure.
+// The texthl and linehl highlight groups are defined with the fallback if the
+// colorscheme doesn't define it.
 func NewSign(v *nvim.Nvim, name, text, texthl, linehl string) (*Sign, error) {
+	if err := validateSignText(text); err != nil {
+		return nil, errors.Wrapf(err, "couldn't define %s sign", name)
+	}
+
+	signHighlights.Lock()
+	for _, group := range []string{texthl, linehl} {
+		if group == "" {
+			continue
+		}
+		if err := defineSignHighlight(v, group); err != nil {
+			signHighlights.Unlock()
+			return nil, err
+		}
+		signHighlights.groups[group] = true
+	}
+	signHighlights.Unlock()
+
 	cmd := fmt.Sprintf("sign define %s", name)
 	switch {
 	case text != "":
@@ -83,6 +178,32 @@ func NewSign(v *nvim.Nvim, name, text, texthl, linehl string) (*Sign, error) {
 	}, nil
 }
 
+// validateSignText validates the sign text which must be at most two display cells.
+func validateSignText(text string) error {
+	if w := displayWidth(text); w > 2 {
+		return errors.Errorf("sign text %q is %d display cells, must be at most 2", text, w)
+	}
+	return nil
+}
+
+// displayWidth returns the display cells of s.
+// The combining characters are zero width, and the East Asian wide characters are two width.
+func displayWidth(s string) int {
+	var w int
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me):
+			// zero width
+		case unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana),
+			r >= 0xFF01 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6:
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
+
 // Place places the sign to any file.
 func (s *Sign) Place(v *nvim.Nvim, id, line int, file string, clearLastSign bool) error {
 	if clearLastSign && s.LastID != 0 {
@@ -98,8 +219,90 @@ func (s *Sign) Place(v *nvim.Nvim, id, line int, file string, clearLastSign bool
 	if err := v.Command(place); err != nil {
 		return errors.WithStack(err)
 	}
+
+	s.mu.Lock()
+	s.LastID = id
+	s.LastLine = line
+	s.LastFile = file
+	s.track(id, file)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Update moves the sign to the line of file in place.
+// The sign keeps the ID of the first placed sign regardless of id, so the
+// repeated updates such as the program counter do not flicker and leak sign IDs.
+func (s *Sign) Update(v *nvim.Nvim, id, line int, file string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.LastID != 0 {
+		id = s.LastID
+	}
+	// TODO(zchee): workaroud for "unrecovered-panic" default breakpoint.
+	if id < 0 {
+		id = 99
+	}
+
+	batch := v.NewBatch()
+	if s.LastFile != "" && s.LastFile != file {
+		batch.Command(fmt.Sprintf("sign unplace %d file=%s", id, s.LastFile))
+	}
+	var placed int
+	batch.Call("sign_place", &placed, id, "", s.Name, file, map[string]interface{}{"lnum": line})
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if s.LastFile != file {
+		delete(s.placed, id)
+	}
 	s.LastID = id
+	s.LastLine = line
 	s.LastFile = file
+	s.track(id, file)
+
+	return nil
+}
+
+// track tracks the placed sign id of file.
+func (s *Sign) track(id int, file string) {
+	if s.placed == nil {
+		s.placed = make(map[int]string)
+	}
+	s.placed[id] = file
+}
+
+// Placed returns the placed sign IDs and files which tracked by Place and Update.
+func (s *Sign) Placed() map[int]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	placed := make(map[int]string, len(s.placed))
+	for id, file := range s.placed {
+		placed[id] = file
+	}
+	return placed
+}
+
+// Clear unplaces all of the placed signs which tracked by Place and Update.
+func (s *Sign) Clear(v *nvim.Nvim) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := v.NewBatch()
+	for id, file := range s.placed {
+		batch.Command(fmt.Sprintf("sign unplace %d file=%s", id, file))
+	}
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.placed = nil
+	s.LastID = 0
+	s.LastLine = 0
+	s.LastFile = ""
 
 	return nil
 }
@@ -111,6 +314,10 @@ func (s *Sign) Unplace(v *nvim.Nvim, id int, file string) error {
 		return errors.WithStack(err)
 	}
 
+	s.mu.Lock()
+	delete(s.placed, id)
+	s.mu.Unlock()
+
 	return nil
 }
 
@@ -121,5 +328,13 @@ func (s *Sign) UnplaceAll(v *nvim.Nvim, file string) error {
 		return errors.WithStack(err)
 	}
 
+	s.mu.Lock()
+	for id, f := range s.placed {
+		if f == file {
+			delete(s.placed, id)
+		}
+	}
+	s.mu.Unlock()
+
 	return nil
 }
@@ -0,0 +1,169 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nvim-go/config"
+)
+
+func TestSign_Update(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "sign.go")
+	if err := ioutil.WriteFile(file, []byte("package sign\n\nfunc a() {}\n\nfunc b() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n := TestNvim(t, file)
+	sign, err := NewSign(n, "test_pc", ProgramCounterSymbol, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, line := range []int{3, 5} {
+		if err := sign.Update(n, i+1, line, file); err != nil {
+			t.Fatal(err)
+		}
+		if sign.LastID != 1 {
+			t.Errorf("Update(%d, %d) LastID = %d, want 1", i+1, line, sign.LastID)
+		}
+	}
+
+	var placed []struct {
+		Signs []struct {
+			ID   int `msgpack:"id"`
+			Lnum int `msgpack:"lnum"`
+		} `msgpack:"signs"`
+	}
+	if err := n.Call("sign_getplaced", &placed, file); err != nil {
+		t.Fatal(err)
+	}
+	if len(placed) != 1 || len(placed[0].Signs) != 1 {
+		t.Fatalf("sign_getplaced(%q) = %v, want one sign", file, placed)
+	}
+	if got := placed[0].Signs[0]; got.ID != 1 || got.Lnum != 5 {
+		t.Errorf("placed sign = %+v, want {ID:1 Lnum:5}", got)
+	}
+
+	if err := sign.Clear(n); err != nil {
+		t.Fatal(err)
+	}
+	if got := sign.Placed(); len(got) != 0 {
+		t.Errorf("Placed() after Clear = %v, want empty", got)
+	}
+}
+
+// fakeHighlighter is a stub of the highlighter which returns the hlexists result from exists.
+type fakeHighlighter struct {
+	exists map[string]bool
+	cmds   []string
+}
+
+func (f *fakeHighlighter) Call(fname string, result interface{}, args ...interface{}) error {
+	if fname == "hlexists" {
+		if f.exists[args[0].(string)] {
+			*result.(*int) = 1
+		} else {
+			*result.(*int) = 0
+		}
+	}
+	return nil
+}
+
+func (f *fakeHighlighter) Command(cmd string) error {
+	f.cmds = append(f.cmds, cmd)
+	return nil
+}
+
+func TestDefineSignHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    string
+		exists   map[string]bool
+		override map[string]string
+		want     []string
+	}{
+		{
+			name:  "not defined breakpoint",
+			group: "delveBreakpointSign",
+			want:  []string{"highlight default link delveBreakpointSign Error"},
+		},
+		{
+			name:  "not defined program counter",
+			group: "delvePCSign",
+			want:  []string{"highlight default link delvePCSign Search"},
+		},
+		{
+			name:   "defined by colorscheme",
+			group:  "delveBreakpointSign",
+			exists: map[string]bool{"delveBreakpointSign": true},
+			want:   nil,
+		},
+		{
+			name:     "user override",
+			group:    "delveBreakpointSign",
+			exists:   map[string]bool{"delveBreakpointSign": true},
+			override: map[string]string{"delveBreakpointSign": "WarningMsg"},
+			want:     []string{"highlight! link delveBreakpointSign WarningMsg"},
+		},
+		{
+			name:  "unknown group",
+			group: "unknownSign",
+			want:  nil,
+		},
+	}
+	defer config.Set(config.Current())
+	for _, tt := range tests {
+		config.Update(func(cfg *config.Config) { cfg.Sign.Highlight = tt.override })
+		v := &fakeHighlighter{exists: tt.exists}
+		if err := defineSignHighlight(v, tt.group); err != nil {
+			t.Errorf("%q. defineSignHighlight(%v) error = %v", tt.name, tt.group, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.cmds, tt.want) {
+			t.Errorf("%q. defineSignHighlight(%v) = %v, want %v", tt.name, tt.group, v.cmds, tt.want)
+		}
+	}
+}
+
+func TestValidateSignText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "ascii", text: "B>", wantErr: false},
+		{name: "single glyph", text: BreakpointSymbol, wantErr: false},
+		{name: "nerd font glyph", text: "\uf111", wantErr: false},
+		{name: "wide character", text: "点", wantErr: false},
+		{name: "empty", text: "", wantErr: false},
+		{name: "over-long ascii", text: "BRK", wantErr: true},
+		{name: "over-long wide characters", text: "点点", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateSignText(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%q. validateSignText(%q) error = %v, wantErr %v", tt.name, tt.text, err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), "must be at most 2") {
+				t.Errorf("%q. validateSignText(%q) error = %v, want the display cells limit message", tt.name, tt.text, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import "sync"
+
+// StdioMu serializes the swaps of os.Stdout and os.Stderr. They are
+// process-wide, so the concurrent commands which capture the output of the
+// in-process tools, such as the delve terminal, gotests and gorename, would
+// capture each other's output without it.
+var StdioMu sync.Mutex
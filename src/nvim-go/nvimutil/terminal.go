@@ -60,10 +60,10 @@ func (t *Terminal) Create() (err error) {
 
 	switch {
 	case t.mode == "split":
-		t.Size = t.getSplitWindowSize(config.TerminalHeight, t.Nvim.WindowHeight)
+		t.Size = t.getSplitWindowSize(config.TerminalHeight(), t.Nvim.WindowHeight)
 		t.Buffer.Height = t.Size
 	case t.mode == "vsplit":
-		t.Size = t.getSplitWindowSize(config.TerminalWidth, t.Nvim.WindowWidth)
+		t.Size = t.getSplitWindowSize(config.TerminalWidth(), t.Nvim.WindowWidth)
 		t.Buffer.Width = t.Size
 	default:
 		// nothing to do
@@ -71,7 +71,7 @@ func (t *Terminal) Create() (err error) {
 
 	option := t.setTerminalOption()
 	name := fmt.Sprintf("| terminal %s", strings.Join(t.cmd, " "))
-	mode := fmt.Sprintf("%s %d%s", config.TerminalPosition, t.Size, t.mode)
+	mode := fmt.Sprintf("%s %d%s", config.TerminalPosition(), t.Size, t.mode)
 
 	t.Buffer.Create(name, FiletypeTerminal, mode, option)
 	t.Buffer.Name = t.Name
@@ -84,17 +84,17 @@ func (t *Terminal) Create() (err error) {
 	t.Batch.Command("highlight TermCursor gui=NONE guifg=NONE guibg=NONE")
 	t.Batch.Command("highlight TermCursorNC gui=NONE guifg=NONE guibg=NONE")
 
-	// Set autoclose buffer if the current buffer is only terminal
-	// TODO(zchee): convert to rpc way
-	t.Batch.Command("autocmd WinEnter <buffer> if winnr('$') == 1 | quit | endif")
-
 	return t.Batch.Execute()
 }
 
 // Run runs the command in the terminal buffer.
 func (t *Terminal) Run(cmd []string) error {
 	if t.Dir != "" {
-		defer pathutil.Chdir(t.Nvim, t.Dir)()
+		restore, err := pathutil.Chdir(t.Nvim, t.Dir)
+		if err != nil {
+			return err
+		}
+		defer restore()
 	}
 
 	if t.Buffer != nil && IsBufferValid(t.Nvim, t.buffer) {
@@ -107,7 +107,7 @@ func (t *Terminal) Run(cmd []string) error {
 		t.Create()
 	}
 	// Workaround for "autocmd BufEnter term://* startinsert"
-	if config.TerminalStopInsert {
+	if config.TerminalStopInsert() {
 		t.Nvim.Command("stopinsert")
 	}
 
@@ -163,6 +163,10 @@ func (t *Terminal) setTerminalOption() map[NvimOption]map[string]interface{} {
 	option[BufferOption] = bufoption
 	option[BufferVar] = bufvar
 	option[WindowOption] = windowoption
+	// close the terminal if it's the last window
+	option[BufferAutocmd] = map[string]interface{}{
+		"WinEnter": "if winnr('$') == 1 | quit | endif",
+	}
 
 	return option
 }